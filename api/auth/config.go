@@ -0,0 +1,104 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultConfig is the default configuration for the API authentication and
+// rate limiting interceptor.
+var DefaultConfig = Config{
+	Tokens:         nil,              // API tokens mapped to the identity of their owner
+	Certificates:   false,            // whether verified client certificates identify callers
+	RequestRate:    rate.Inf,         // maximum number of requests per second per identity
+	RequestBurst:   0,                // maximum burst of requests per identity
+	BandwidthRate:  rate.Inf,         // maximum number of response bytes per second per identity
+	BandwidthBurst: 0,                // maximum burst of response bytes per identity
+	Public:         nil,              // services that can be called without authentication or limits
+	Expiry:         10 * time.Minute, // duration after which the quotas of idle identities are dropped
+}
+
+// Config is the configuration of the API authentication and rate limiting
+// interceptor.
+type Config struct {
+	Tokens         map[string]string
+	Certificates   bool
+	RequestRate    rate.Limit
+	RequestBurst   int
+	BandwidthRate  rate.Limit
+	BandwidthBurst int
+	Public         []string
+	Expiry         time.Duration
+}
+
+// WithTokens sets the static API tokens that are accepted by the interceptor,
+// mapped to the identity of the client that owns them. Clients provide their
+// token in the `authorization` metadata, using the `bearer` scheme.
+func WithTokens(tokens map[string]string) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Tokens = tokens
+	}
+}
+
+// WithCertificates enables the identification of clients through the common
+// name of their verified TLS client certificate. It requires the GRPC server to
+// use transport credentials that verify client certificates.
+func WithCertificates() func(*Config) {
+	return func(cfg *Config) {
+		cfg.Certificates = true
+	}
+}
+
+// WithRequestLimit sets the maximum number of requests per second that each
+// identity can make, as well as the number of requests it can make in a burst.
+func WithRequestLimit(limit float64, burst int) func(*Config) {
+	return func(cfg *Config) {
+		cfg.RequestRate = rate.Limit(limit)
+		cfg.RequestBurst = burst
+	}
+}
+
+// WithBandwidthLimit sets the maximum number of response bytes per second that
+// are sent to each identity, as well as the number of bytes that can be sent in
+// a burst. Responses that exceed the limit are delayed rather than rejected.
+func WithBandwidthLimit(limit float64, burst int) func(*Config) {
+	return func(cfg *Config) {
+		cfg.BandwidthRate = rate.Limit(limit)
+		cfg.BandwidthBurst = burst
+	}
+}
+
+// WithPublicServices sets the names of services that can be called without
+// authentication and that are not subject to rate limits, such as the health
+// service queried by load balancers.
+func WithPublicServices(services ...string) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Public = services
+	}
+}
+
+// WithQuotaExpiry sets the duration after which the quotas of identities that
+// made no requests are dropped, so that the number of quotas kept in memory
+// does not grow with every caller ever seen. It should be longer than the time
+// it takes for the limits to replenish their bursts, as an identity that comes
+// back after its quota was dropped starts over with full bursts.
+func WithQuotaExpiry(expiry time.Duration) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Expiry = expiry
+	}
+}
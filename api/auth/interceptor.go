@@ -0,0 +1,454 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/tags"
+)
+
+const (
+	headerAuthorization = "authorization"
+	schemeBearer        = "bearer"
+	tagIdentity         = "auth.identity"
+	identityAnonymous   = "anonymous"
+)
+
+// Interceptor authenticates the callers of the DPS API and limits the rate at
+// which each of them can make requests and receive data. When neither tokens
+// nor certificates are configured, authentication is disabled and callers are
+// identified by their network address for the purpose of rate limiting. The
+// same checks and quotas apply to the GRPC API and to the REST API.
+type Interceptor struct {
+	cfg Config
+
+	mutex  sync.Mutex
+	quotas map[string]*quota
+	swept  time.Time
+}
+
+type quota struct {
+	requests  *rate.Limiter
+	bandwidth *rate.Limiter
+	seen      time.Time
+}
+
+// NewInterceptor creates a new API authentication and rate limiting interceptor.
+func NewInterceptor(options ...func(*Config)) *Interceptor {
+
+	cfg := DefaultConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	i := Interceptor{
+		cfg:    cfg,
+		quotas: make(map[string]*quota),
+		swept:  time.Now(),
+	}
+
+	return &i
+}
+
+// Unary returns the interceptor to use for unary GRPC calls.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		if i.public(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		quota, err := i.admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		err = throttle(ctx, quota.bandwidth, res)
+		if err != nil {
+			return nil, err
+		}
+
+		return res, nil
+	}
+}
+
+// Stream returns the interceptor to use for streaming GRPC calls. The request
+// limit applies to the call as a whole, while the bandwidth limit applies to
+// each message sent on the stream.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		if i.public(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		quota, err := i.admit(stream.Context())
+		if err != nil {
+			return err
+		}
+
+		throttled := throttledStream{
+			ServerStream: stream,
+			bandwidth:    quota.bandwidth,
+		}
+
+		return handler(srv, &throttled)
+	}
+}
+
+// Handler wraps the given HTTP handler, such as the REST gateway, so that its
+// requests are authenticated and limited in the same way as GRPC calls. Tokens
+// are read from the `Authorization` header and certificates from the verified
+// chains of the TLS connection. Rejected requests receive a JSON error in the
+// same format as the REST gateway uses.
+func (i *Interceptor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var chains [][]*x509.Certificate
+		if r.TLS != nil {
+			chains = r.TLS.VerifiedChains
+		}
+		identity, ok := i.identify(r.Header.Values(headerAuthorization), chains, r.RemoteAddr)
+		if !ok {
+			reject(w, http.StatusUnauthorized, status.New(codes.Unauthenticated, "missing or invalid credentials"))
+			return
+		}
+
+		q := i.quotaFor(identity)
+		if !q.requests.Allow() {
+			reject(w, http.StatusTooManyRequests, status.Newf(codes.ResourceExhausted, "request limit exceeded for %s", identity))
+			return
+		}
+
+		throttled := throttledWriter{
+			ResponseWriter: w,
+			ctx:            r.Context(),
+			bandwidth:      q.bandwidth,
+		}
+
+		next.ServeHTTP(&throttled, r)
+	})
+}
+
+// public checks whether the given method, in the `/service/method` format used
+// by GRPC, belongs to one of the public services.
+func (i *Interceptor) public(method string) bool {
+	parts := strings.SplitN(strings.TrimPrefix(method, "/"), "/", 2)
+	for _, service := range i.cfg.Public {
+		if parts[0] == service {
+			return true
+		}
+	}
+	return false
+}
+
+// admit authenticates the caller of the given context and checks that it has
+// not exceeded its request limit, before returning its quota.
+func (i *Interceptor) admit(ctx context.Context) (*quota, error) {
+
+	identity, err := i.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags.Extract(ctx).Set(tagIdentity, identity)
+
+	q := i.quotaFor(identity)
+	if !q.requests.Allow() {
+		return nil, status.Errorf(codes.ResourceExhausted, "request limit exceeded for %s", identity)
+	}
+
+	return q, nil
+}
+
+// authenticate returns the identity of the caller for the given context.
+func (i *Interceptor) authenticate(ctx context.Context) (string, error) {
+
+	var authorizations []string
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		authorizations = md.Get(headerAuthorization)
+	}
+
+	var chains [][]*x509.Certificate
+	address := ""
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		info, ok := p.AuthInfo.(credentials.TLSInfo)
+		if ok {
+			chains = info.State.VerifiedChains
+		}
+		if p.Addr != nil {
+			address = p.Addr.String()
+		}
+	}
+
+	identity, ok := i.identify(authorizations, chains, address)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing or invalid credentials")
+	}
+
+	return identity, nil
+}
+
+// identify returns the identity of a caller, based on the values of its
+// authorization headers, the verified chains of its client certificate and its
+// network address. It returns false if the caller could not be authenticated.
+func (i *Interceptor) identify(authorizations []string, chains [][]*x509.Certificate, address string) (string, bool) {
+
+	if len(i.cfg.Tokens) == 0 && !i.cfg.Certificates {
+		return addressIdentity(address), true
+	}
+
+	if i.cfg.Certificates {
+		identity, ok := certificateIdentity(chains)
+		if ok {
+			return identity, true
+		}
+	}
+
+	if len(i.cfg.Tokens) > 0 {
+		identity, ok := i.tokenIdentity(authorizations)
+		if ok {
+			return identity, true
+		}
+	}
+
+	return "", false
+}
+
+// tokenIdentity returns the identity for the bearer token in the given
+// authorization header values, if there is a valid one.
+func (i *Interceptor) tokenIdentity(authorizations []string) (string, bool) {
+
+	for _, value := range authorizations {
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], schemeBearer) {
+			continue
+		}
+
+		// We compare against every token in constant time, so that the
+		// response time does not leak information about valid tokens.
+		candidate := []byte(strings.TrimSpace(parts[1]))
+		identity := ""
+		for token, owner := range i.cfg.Tokens {
+			if subtle.ConstantTimeCompare(candidate, []byte(token)) == 1 {
+				identity = owner
+			}
+		}
+		if identity != "" {
+			return identity, true
+		}
+	}
+
+	return "", false
+}
+
+// quotaFor returns the quota for the given identity, creating it on first use.
+// Once per expiry duration, it also drops the quotas of identities that have
+// not made any request during the whole duration.
+func (i *Interceptor) quotaFor(identity string) *quota {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(i.swept) >= i.cfg.Expiry {
+		for id, q := range i.quotas {
+			if now.Sub(q.seen) >= i.cfg.Expiry {
+				delete(i.quotas, id)
+			}
+		}
+		i.swept = now
+	}
+
+	q, ok := i.quotas[identity]
+	if ok {
+		q.seen = now
+		return q
+	}
+
+	q = &quota{
+		requests:  newLimiter(i.cfg.RequestRate, i.cfg.RequestBurst),
+		bandwidth: newLimiter(i.cfg.BandwidthRate, i.cfg.BandwidthBurst),
+		seen:      now,
+	}
+	i.quotas[identity] = q
+
+	return q
+}
+
+// certificateIdentity returns the common name of the client certificate at the
+// start of the given verified chains, if there is one.
+func certificateIdentity(chains [][]*x509.Certificate) (string, bool) {
+
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return "", false
+	}
+	name := chains[0][0].Subject.CommonName
+	if name == "" {
+		return "", false
+	}
+
+	return name, true
+}
+
+// addressIdentity returns the host of the given network address of a caller,
+// which is used to identify unauthenticated callers.
+func addressIdentity(address string) string {
+
+	if address == "" {
+		return identityAnonymous
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
+// newLimiter creates a rate limiter for the given limit and burst. If no burst
+// is given, it defaults to the amount allowed in one second.
+func newLimiter(limit rate.Limit, burst int) *rate.Limiter {
+	if limit == rate.Inf {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(float64(limit))))
+	}
+	return rate.NewLimiter(limit, burst)
+}
+
+// throttle waits until the given message can be sent without exceeding the
+// given bandwidth limit. Messages larger than the burst are accounted for in
+// burst-sized chunks.
+func throttle(ctx context.Context, bandwidth *rate.Limiter, msg interface{}) error {
+
+	if bandwidth.Limit() == rate.Inf {
+		return nil
+	}
+	message, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	return wait(ctx, bandwidth, proto.Size(message))
+}
+
+// wait waits until the given number of bytes can be sent without exceeding the
+// given bandwidth limit, in burst-sized chunks.
+func wait(ctx context.Context, bandwidth *rate.Limiter, size int) error {
+
+	burst := bandwidth.Burst()
+	for size > 0 {
+		n := size
+		if n > burst {
+			n = burst
+		}
+		err := bandwidth.WaitN(ctx, n)
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			return status.Errorf(codes.ResourceExhausted, "bandwidth limit exceeded: %s", err)
+		}
+		size -= n
+	}
+
+	return nil
+}
+
+// throttledStream is a server stream that applies a bandwidth limit to the
+// messages it sends.
+type throttledStream struct {
+	grpc.ServerStream
+	bandwidth *rate.Limiter
+}
+
+// SendMsg waits for the bandwidth limit before sending the given message.
+func (t *throttledStream) SendMsg(m interface{}) error {
+	err := throttle(t.Context(), t.bandwidth, m)
+	if err != nil {
+		return err
+	}
+	return t.ServerStream.SendMsg(m)
+}
+
+// throttledWriter is an HTTP response writer that applies a bandwidth limit to
+// the response body it writes.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx       context.Context
+	bandwidth *rate.Limiter
+}
+
+// Write waits for the bandwidth limit before writing the given data.
+func (t *throttledWriter) Write(data []byte) (int, error) {
+	if t.bandwidth.Limit() != rate.Inf {
+		err := wait(t.ctx, t.bandwidth, len(data))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return t.ResponseWriter.Write(data)
+}
+
+// Flush flushes the underlying response writer, if it supports flushing, so
+// that streaming responses keep working behind the bandwidth limit.
+func (t *throttledWriter) Flush() {
+	flusher, ok := t.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// reject writes the given status as a JSON error response with the given HTTP
+// status code.
+func reject(w http.ResponseWriter, statusCode int, st *status.Status) {
+	res := struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{
+		Code:    st.Code().String(),
+		Message: st.Message(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(res)
+}
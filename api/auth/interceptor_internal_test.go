@@ -0,0 +1,470 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/tags"
+
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestNewInterceptor(t *testing.T) {
+	tokens := map[string]string{"token": "identity"}
+
+	i := NewInterceptor(
+		WithTokens(tokens),
+		WithCertificates(),
+		WithRequestLimit(10, 20),
+		WithBandwidthLimit(1000, 2000),
+		WithPublicServices("grpc.health.v1.Health"),
+		WithQuotaExpiry(time.Hour),
+	)
+
+	require.NotNil(t, i)
+	assert.Equal(t, tokens, i.cfg.Tokens)
+	assert.True(t, i.cfg.Certificates)
+	assert.Equal(t, rate.Limit(10), i.cfg.RequestRate)
+	assert.Equal(t, 20, i.cfg.RequestBurst)
+	assert.Equal(t, rate.Limit(1000), i.cfg.BandwidthRate)
+	assert.Equal(t, 2000, i.cfg.BandwidthBurst)
+	assert.Equal(t, []string{"grpc.health.v1.Health"}, i.cfg.Public)
+	assert.Equal(t, time.Hour, i.cfg.Expiry)
+	assert.NotNil(t, i.quotas)
+}
+
+func TestInterceptor_Unary(t *testing.T) {
+	tokens := map[string]string{"token": "explorer"}
+	address := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5005}
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "wallet"}}
+	info := credentials.TLSInfo{
+		State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{certificate}},
+		},
+	}
+
+	tests := []struct {
+		name string
+
+		options []func(*Config)
+		method  string
+		token   string
+		auth    credentials.AuthInfo
+		calls   int
+
+		mockErr error
+
+		wantIdentity string
+		wantCode     codes.Code
+	}{
+		{
+			name: "identifies by address without authentication",
+
+			calls: 1,
+
+			wantIdentity: "127.0.0.1",
+			wantCode:     codes.OK,
+		},
+		{
+			name: "identifies by token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			token:   "Bearer token",
+			calls:   1,
+
+			wantIdentity: "explorer",
+			wantCode:     codes.OK,
+		},
+		{
+			name: "identifies by certificate",
+
+			options: []func(*Config){WithTokens(tokens), WithCertificates()},
+			auth:    info,
+			calls:   1,
+
+			wantIdentity: "wallet",
+			wantCode:     codes.OK,
+		},
+		{
+			name: "skips authentication for public services",
+
+			options: []func(*Config){WithTokens(tokens), WithPublicServices("grpc.health.v1.Health")},
+			method:  "/grpc.health.v1.Health/Check",
+			calls:   1,
+
+			wantCode: codes.OK,
+		},
+		{
+			name: "handles missing token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			calls:   1,
+
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "handles invalid token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			token:   "Bearer invalid",
+			calls:   1,
+
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "handles invalid scheme",
+
+			options: []func(*Config){WithTokens(tokens)},
+			token:   "Basic token",
+			calls:   1,
+
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "handles missing certificate",
+
+			options: []func(*Config){WithCertificates()},
+			calls:   1,
+
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "handles exceeded request limit",
+
+			options: []func(*Config){WithRequestLimit(0.001, 1)},
+			calls:   2,
+
+			wantIdentity: "127.0.0.1",
+			wantCode:     codes.ResourceExhausted,
+		},
+		{
+			name: "handles handler failure",
+
+			calls:   1,
+			mockErr: mocks.GenericError,
+
+			wantIdentity: "127.0.0.1",
+			wantCode:     codes.Unknown,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			i := NewInterceptor(test.options...)
+			interceptor := i.Unary()
+
+			handler := func(context.Context, interface{}) (interface{}, error) {
+				return wrapperspb.Bytes(mocks.GenericBytes), test.mockErr
+			}
+
+			var err error
+			var ctx context.Context
+			for call := 0; call < test.calls; call++ {
+				ctx = tags.SetInContext(context.Background(), tags.NewTags())
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: address, AuthInfo: test.auth})
+				if test.token != "" {
+					ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(headerAuthorization, test.token))
+				}
+				_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+			}
+
+			assert.Equal(t, test.wantCode, status.Code(err))
+			if test.wantIdentity != "" {
+				assert.Equal(t, test.wantIdentity, tags.Extract(ctx).Values()[tagIdentity])
+			}
+		})
+	}
+}
+
+func TestInterceptor_Stream(t *testing.T) {
+	tokens := map[string]string{"token": "explorer"}
+	address := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5005}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: address})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(headerAuthorization, "bearer token"))
+
+		var sent []interface{}
+		stream := &serverStreamMock{
+			ContextFunc: func() context.Context {
+				return ctx
+			},
+			SendMsgFunc: func(m interface{}) error {
+				sent = append(sent, m)
+				return nil
+			},
+		}
+
+		msg := wrapperspb.Bytes(mocks.GenericBytes)
+		handler := func(_ interface{}, stream grpc.ServerStream) error {
+			return stream.SendMsg(msg)
+		}
+
+		i := NewInterceptor(WithTokens(tokens), WithBandwidthLimit(1_000_000, 0))
+		err := i.Stream()(nil, stream, &grpc.StreamServerInfo{}, handler)
+
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{msg}, sent)
+	})
+
+	t.Run("handles invalid token", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: address})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(headerAuthorization, "bearer invalid"))
+
+		stream := &serverStreamMock{
+			ContextFunc: func() context.Context {
+				return ctx
+			},
+		}
+
+		handler := func(interface{}, grpc.ServerStream) error {
+			t.Fatal("handler should not be called")
+			return nil
+		}
+
+		i := NewInterceptor(WithTokens(tokens))
+		err := i.Stream()(nil, stream, &grpc.StreamServerInfo{}, handler)
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("handles exceeded bandwidth limit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: address})
+
+		stream := &serverStreamMock{
+			ContextFunc: func() context.Context {
+				return ctx
+			},
+			SendMsgFunc: func(interface{}) error {
+				return nil
+			},
+		}
+
+		// With a limit of one byte per second, the message can only be sent
+		// after a delay that exceeds the context deadline.
+		msg := wrapperspb.Bytes(mocks.GenericBytes)
+		handler := func(_ interface{}, stream grpc.ServerStream) error {
+			return stream.SendMsg(msg)
+		}
+
+		i := NewInterceptor(WithBandwidthLimit(1, 0))
+		err := i.Stream()(nil, stream, &grpc.StreamServerInfo{}, handler)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInterceptor_Handler(t *testing.T) {
+	tokens := map[string]string{"token": "explorer"}
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "wallet"}}
+	state := &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{certificate}},
+	}
+
+	tests := []struct {
+		name string
+
+		options []func(*Config)
+		token   string
+		tls     *tls.ConnectionState
+		calls   int
+
+		wantStatus int
+		wantCalls  int
+	}{
+		{
+			name: "serves without authentication",
+
+			calls: 1,
+
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name: "serves with token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			token:   "Bearer token",
+			calls:   1,
+
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name: "serves with certificate",
+
+			options: []func(*Config){WithTokens(tokens), WithCertificates()},
+			tls:     state,
+			calls:   1,
+
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		{
+			name: "handles missing token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			calls:   1,
+
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "handles invalid token",
+
+			options: []func(*Config){WithTokens(tokens)},
+			token:   "Bearer invalid",
+			calls:   1,
+
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "handles exceeded request limit",
+
+			options: []func(*Config){WithTokens(tokens), WithRequestLimit(0.001, 1)},
+			token:   "Bearer token",
+			calls:   2,
+
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls++
+				_, _ = w.Write(mocks.GenericBytes)
+			})
+
+			i := NewInterceptor(test.options...)
+			handler := i.Handler(next)
+
+			var rec *httptest.ResponseRecorder
+			for call := 0; call < test.calls; call++ {
+				req := httptest.NewRequest(http.MethodGet, "/first", nil)
+				req.TLS = test.tls
+				if test.token != "" {
+					req.Header.Set("Authorization", test.token)
+				}
+				rec = httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+			}
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, test.wantCalls, calls)
+		})
+	}
+
+	t.Run("handles exceeded bandwidth limit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var err error
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err = w.Write(mocks.GenericBytes)
+		})
+
+		i := NewInterceptor(WithBandwidthLimit(1, 0))
+		req := httptest.NewRequest(http.MethodGet, "/first", nil).WithContext(ctx)
+		i.Handler(next).ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInterceptor_QuotaFor(t *testing.T) {
+	t.Run("reuses quota of identity", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewInterceptor()
+
+		first := i.quotaFor("explorer")
+		second := i.quotaFor("explorer")
+
+		assert.Same(t, first, second)
+	})
+
+	t.Run("drops quotas of idle identities", func(t *testing.T) {
+		t.Parallel()
+
+		i := NewInterceptor(WithQuotaExpiry(time.Minute))
+
+		idle := i.quotaFor("explorer")
+		active := i.quotaFor("wallet")
+
+		// Make the explorer idle for longer than the expiry duration and the
+		// last sweep old enough for the next lookup to trigger another one.
+		idle.seen = idle.seen.Add(-2 * time.Minute)
+		i.swept = i.swept.Add(-2 * time.Minute)
+
+		got := i.quotaFor("wallet")
+
+		assert.Same(t, active, got)
+		assert.Len(t, i.quotas, 1)
+		assert.NotContains(t, i.quotas, "explorer")
+	})
+}
+
+type serverStreamMock struct {
+	grpc.ServerStream
+
+	ContextFunc func() context.Context
+	SendMsgFunc func(m interface{}) error
+}
+
+func (s *serverStreamMock) Context() context.Context {
+	return s.ContextFunc()
+}
+
+func (s *serverStreamMock) SendMsg(m interface{}) error {
+	return s.SendMsgFunc(m)
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeyPair is a TLS certificate and private key loaded from disk. Whenever one
+// of the files is modified, the key pair is reloaded on the next handshake, so
+// that rotated certificates are used without restarting the process.
+type KeyPair struct {
+	certFile string
+	keyFile  string
+
+	mutex       sync.Mutex
+	modified    time.Time
+	certificate *tls.Certificate
+}
+
+// LoadKeyPair loads the TLS certificate and private key from the given files.
+func LoadKeyPair(certFile string, keyFile string) (*KeyPair, error) {
+
+	k := KeyPair{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	modified, err := k.lastModified()
+	if err != nil {
+		return nil, fmt.Errorf("could not check key pair files: %w", err)
+	}
+	err = k.load(modified)
+	if err != nil {
+		return nil, fmt.Errorf("could not load key pair: %w", err)
+	}
+
+	return &k, nil
+}
+
+// Certificate returns the current certificate of the key pair. If the files
+// were modified since they were last loaded, they are reloaded first. When the
+// reload fails, for example because only one of the files has been replaced so
+// far, the previous certificate is returned and the reload is retried on the
+// next call.
+func (k *KeyPair) Certificate() (*tls.Certificate, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	modified, err := k.lastModified()
+	if err != nil || !modified.After(k.modified) {
+		return k.certificate, nil
+	}
+
+	_ = k.load(modified)
+
+	return k.certificate, nil
+}
+
+func (k *KeyPair) load(modified time.Time) error {
+
+	certificate, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not parse key pair: %w", err)
+	}
+
+	k.certificate = &certificate
+	k.modified = modified
+
+	return nil
+}
+
+func (k *KeyPair) lastModified() (time.Time, error) {
+
+	cert, err := os.Stat(k.certFile)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not stat certificate file: %w", err)
+	}
+	key, err := os.Stat(k.keyFile)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not stat key file: %w", err)
+	}
+
+	modified := cert.ModTime()
+	if key.ModTime().After(modified) {
+		modified = key.ModTime()
+	}
+
+	return modified, nil
+}
+
+// LoadCertPool loads a pool of PEM-encoded CA certificates from the given
+// file.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	ok := pool.AppendCertsFromPEM(data)
+	if !ok {
+		return nil, errors.New("could not find valid CA certificates")
+	}
+
+	return pool, nil
+}
+
+// ServerTLS returns the TLS configuration for an API server using the given
+// key pair. If a pool of client CAs is given, client certificates are verified
+// against it, using the given client authentication policy.
+func ServerTLS(pair *KeyPair, clientCAs *x509.CertPool, clientAuth tls.ClientAuthType) *tls.Config {
+
+	config := tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return pair.Certificate()
+		},
+	}
+
+	if clientCAs != nil {
+		config.ClientCAs = clientCAs
+		config.ClientAuth = clientAuth
+	}
+
+	return &config
+}
+
+// ClientTLS returns the TLS configuration for an API client. If a pool of root
+// CAs is given, only server certificates issued by these CAs are accepted,
+// instead of those issued by the CAs of the system. If a key pair is given, it
+// is presented to servers that request a client certificate.
+func ClientTLS(pair *KeyPair, rootCAs *x509.CertPool) *tls.Config {
+
+	config := tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootCAs,
+	}
+
+	if pair != nil {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return pair.Certificate()
+		}
+	}
+
+	return &config
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadKeyPair(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		certFile, keyFile := writeKeyPair(t, t.TempDir(), "server", nil)
+
+		pair, err := LoadKeyPair(certFile, keyFile)
+
+		require.NoError(t, err)
+		got, err := pair.Certificate()
+		require.NoError(t, err)
+		assert.Equal(t, "server", commonName(t, got))
+	})
+
+	t.Run("handles missing files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		_, err := LoadKeyPair(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid files", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		certFile := filepath.Join(dir, "invalid.crt")
+		keyFile := filepath.Join(dir, "invalid.key")
+		require.NoError(t, os.WriteFile(certFile, []byte(`invalid`), 0600))
+		require.NoError(t, os.WriteFile(keyFile, []byte(`invalid`), 0600))
+
+		_, err := LoadKeyPair(certFile, keyFile)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyPair_Certificate(t *testing.T) {
+	t.Run("reloads rotated key pair", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		certFile, keyFile := writeKeyPair(t, dir, "before", nil)
+		pair, err := LoadKeyPair(certFile, keyFile)
+		require.NoError(t, err)
+
+		writeKeyPair(t, dir, "after", nil)
+		touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+
+		got, err := pair.Certificate()
+
+		require.NoError(t, err)
+		assert.Equal(t, "after", commonName(t, got))
+	})
+
+	t.Run("keeps previous key pair on invalid rotation", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		certFile, keyFile := writeKeyPair(t, dir, "before", nil)
+		pair, err := LoadKeyPair(certFile, keyFile)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(keyFile, []byte(`invalid`), 0600))
+		touch(t, time.Now().Add(time.Minute), keyFile)
+
+		got, err := pair.Certificate()
+
+		require.NoError(t, err)
+		assert.Equal(t, "before", commonName(t, got))
+	})
+}
+
+func TestLoadCertPool(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		certFile, _ := writeKeyPair(t, t.TempDir(), "ca", nil)
+
+		pool, err := LoadCertPool(certFile)
+
+		require.NoError(t, err)
+		assert.NotNil(t, pool)
+	})
+
+	t.Run("handles missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := LoadCertPool(filepath.Join(t.TempDir(), "missing.crt"))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid file", func(t *testing.T) {
+		t.Parallel()
+
+		caFile := filepath.Join(t.TempDir(), "invalid.crt")
+		require.NoError(t, os.WriteFile(caFile, []byte(`invalid`), 0600))
+
+		_, err := LoadCertPool(caFile)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestServerTLS_ClientTLS(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		caCert, caKey := newCertificate(t, "ca", nil)
+		caFile := filepath.Join(dir, "ca.crt")
+		writePEM(t, caFile, "CERTIFICATE", caCert.Raw)
+		serverCert, serverKey := writeKeyPair(t, dir, "server", &issuer{cert: caCert, key: caKey})
+		clientDir := t.TempDir()
+		clientCert, clientKey := writeKeyPair(t, clientDir, "client", &issuer{cert: caCert, key: caKey})
+
+		pool, err := LoadCertPool(caFile)
+		require.NoError(t, err)
+		serverPair, err := LoadKeyPair(serverCert, serverKey)
+		require.NoError(t, err)
+		clientPair, err := LoadKeyPair(clientCert, clientKey)
+		require.NoError(t, err)
+
+		serverConfig := ServerTLS(serverPair, pool, tls.RequireAndVerifyClientCert)
+		clientConfig := ClientTLS(clientPair, pool)
+		clientConfig.ServerName = "server"
+
+		state := handshake(t, serverConfig, clientConfig)
+
+		require.NoError(t, state.err)
+		require.NotEmpty(t, state.VerifiedChains)
+		assert.Equal(t, "client", state.VerifiedChains[0][0].Subject.CommonName)
+	})
+
+	t.Run("handles server certificate from other CA", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		caCert, _ := newCertificate(t, "ca", nil)
+		caFile := filepath.Join(dir, "ca.crt")
+		writePEM(t, caFile, "CERTIFICATE", caCert.Raw)
+		serverCert, serverKey := writeKeyPair(t, dir, "server", nil)
+
+		pool, err := LoadCertPool(caFile)
+		require.NoError(t, err)
+		serverPair, err := LoadKeyPair(serverCert, serverKey)
+		require.NoError(t, err)
+
+		serverConfig := ServerTLS(serverPair, nil, tls.NoClientCert)
+		clientConfig := ClientTLS(nil, pool)
+		clientConfig.ServerName = "server"
+
+		state := handshake(t, serverConfig, clientConfig)
+
+		assert.Error(t, state.err)
+	})
+
+	t.Run("handles missing client certificate", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		caCert, caKey := newCertificate(t, "ca", nil)
+		caFile := filepath.Join(dir, "ca.crt")
+		writePEM(t, caFile, "CERTIFICATE", caCert.Raw)
+		serverCert, serverKey := writeKeyPair(t, dir, "server", &issuer{cert: caCert, key: caKey})
+
+		pool, err := LoadCertPool(caFile)
+		require.NoError(t, err)
+		serverPair, err := LoadKeyPair(serverCert, serverKey)
+		require.NoError(t, err)
+
+		serverConfig := ServerTLS(serverPair, pool, tls.RequireAndVerifyClientCert)
+		clientConfig := ClientTLS(nil, pool)
+		clientConfig.ServerName = "server"
+
+		state := handshake(t, serverConfig, clientConfig)
+
+		assert.Error(t, state.err)
+	})
+}
+
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+type handshakeResult struct {
+	tls.ConnectionState
+	err error
+}
+
+// handshake runs a TLS handshake between the given server and client
+// configurations, and returns the connection state on the server side. The
+// server writes a single byte after the handshake, so that the client also
+// observes failures that the server only detects after the client is done.
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) handshakeResult {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	serverConn, err := listener.Accept()
+	require.NoError(t, err)
+
+	server := tls.Server(serverConn, serverConfig)
+	client := tls.Client(clientConn, clientConfig)
+
+	done := make(chan error, 1)
+	go func() {
+		defer client.Close()
+		err := client.Handshake()
+		if err == nil {
+			_, err = client.Read(make([]byte, 1))
+		}
+		done <- err
+	}()
+
+	err = server.Handshake()
+	if err == nil {
+		_, err = server.Write([]byte{1})
+	}
+	result := handshakeResult{ConnectionState: server.ConnectionState()}
+	_ = server.Close()
+
+	clientErr := <-done
+	if err == nil {
+		err = clientErr
+	}
+	result.err = err
+
+	return result
+}
+
+func newCertificate(t *testing.T, name string, parent *issuer) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := &template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func writeKeyPair(t *testing.T, dir string, name string, parent *issuer) (string, string) {
+	t.Helper()
+
+	cert, key := newCertificate(t, name, parent)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writePEM(t, certFile, "CERTIFICATE", cert.Raw)
+	writePEM(t, keyFile, "EC PRIVATE KEY", der)
+
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, path string, typ string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func touch(t *testing.T, modified time.Time, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		require.NoError(t, os.Chtimes(path, modified, modified))
+	}
+}
+
+func commonName(t *testing.T, certificate *tls.Certificate) string {
+	t.Helper()
+
+	require.NotEmpty(t, certificate.Certificate)
+	cert, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+
+	return cert.Subject.CommonName
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadTokens reads static API tokens from the given reader. Each non-empty line
+// holds the identity of a client, followed by its token, separated by
+// whitespace. Lines starting with `#` are ignored.
+func ReadTokens(reader io.Reader) (map[string]string, error) {
+
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(reader)
+	number := 0
+	for scanner.Scan() {
+		number++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid token entry on line %d: expected identity and token", number)
+		}
+
+		identity, token := fields[0], fields[1]
+		_, ok := tokens[token]
+		if ok {
+			return nil, fmt.Errorf("duplicate token on line %d", number)
+		}
+
+		tokens[token] = identity
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("could not scan tokens: %w", err)
+	}
+
+	return tokens, nil
+}
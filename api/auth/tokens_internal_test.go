@@ -0,0 +1,92 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTokens(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input string
+
+		wantTokens map[string]string
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			input: "# identity token\nexplorer   token1\n\n  wallet token2  \n",
+
+			wantTokens: map[string]string{
+				"token1": "explorer",
+				"token2": "wallet",
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles empty input",
+
+			input: "",
+
+			wantTokens: map[string]string{},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles missing token",
+
+			input: "explorer\n",
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles too many fields",
+
+			input: "explorer token1 token2\n",
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles duplicate token",
+
+			input: "explorer token1\nwallet token1\n",
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ReadTokens(strings.NewReader(test.input))
+
+			test.checkErr(t, err)
+			if err == nil {
+				assert.Equal(t, test.wantTokens, got)
+			}
+		})
+	}
+}
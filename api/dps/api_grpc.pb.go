@@ -20,20 +20,40 @@ const _ = grpc.SupportPackageIsVersion7
 type APIClient interface {
 	GetFirst(ctx context.Context, in *GetFirstRequest, opts ...grpc.CallOption) (*GetFirstResponse, error)
 	GetLast(ctx context.Context, in *GetLastRequest, opts ...grpc.CallOption) (*GetLastResponse, error)
+	GetFloor(ctx context.Context, in *GetFloorRequest, opts ...grpc.CallOption) (*GetFloorResponse, error)
 	GetHeightForBlock(ctx context.Context, in *GetHeightForBlockRequest, opts ...grpc.CallOption) (*GetHeightForBlockResponse, error)
 	GetCommit(ctx context.Context, in *GetCommitRequest, opts ...grpc.CallOption) (*GetCommitResponse, error)
 	GetHeader(ctx context.Context, in *GetHeaderRequest, opts ...grpc.CallOption) (*GetHeaderResponse, error)
+	GetHeaderByBlockID(ctx context.Context, in *GetHeaderByBlockIDRequest, opts ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error)
 	GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	ListHeaders(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (API_ListHeadersClient, error)
+	ListCommits(ctx context.Context, in *ListCommitsRequest, opts ...grpc.CallOption) (API_ListCommitsClient, error)
+	GetEventsRange(ctx context.Context, in *GetEventsRangeRequest, opts ...grpc.CallOption) (API_GetEventsRangeClient, error)
+	ListHeightsForEvent(ctx context.Context, in *ListHeightsForEventRequest, opts ...grpc.CallOption) (*ListHeightsForEventResponse, error)
 	GetRegisterValues(ctx context.Context, in *GetRegisterValuesRequest, opts ...grpc.CallOption) (*GetRegisterValuesResponse, error)
+	GetRegisterProofs(ctx context.Context, in *GetRegisterProofsRequest, opts ...grpc.CallOption) (*GetRegisterProofsResponse, error)
+	GetRegistersByKey(ctx context.Context, in *GetRegistersByKeyRequest, opts ...grpc.CallOption) (*GetRegistersByKeyResponse, error)
+	GetRegisterHistory(ctx context.Context, in *GetRegisterHistoryRequest, opts ...grpc.CallOption) (API_GetRegisterHistoryClient, error)
+	GetStateDiff(ctx context.Context, in *GetStateDiffRequest, opts ...grpc.CallOption) (API_GetStateDiffClient, error)
 	GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error)
 	ListCollectionsForHeight(ctx context.Context, in *ListCollectionsForHeightRequest, opts ...grpc.CallOption) (*ListCollectionsForHeightResponse, error)
+	GetHeightForCollection(ctx context.Context, in *GetHeightForCollectionRequest, opts ...grpc.CallOption) (*GetHeightForCollectionResponse, error)
 	GetGuarantee(ctx context.Context, in *GetGuaranteeRequest, opts ...grpc.CallOption) (*GetGuaranteeResponse, error)
 	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
 	GetHeightForTransaction(ctx context.Context, in *GetHeightForTransactionRequest, opts ...grpc.CallOption) (*GetHeightForTransactionResponse, error)
+	GetHeightForTimestamp(ctx context.Context, in *GetHeightForTimestampRequest, opts ...grpc.CallOption) (*GetHeightForTimestampResponse, error)
 	ListTransactionsForHeight(ctx context.Context, in *ListTransactionsForHeightRequest, opts ...grpc.CallOption) (*ListTransactionsForHeightResponse, error)
+	ListTransactionsForCollection(ctx context.Context, in *ListTransactionsForCollectionRequest, opts ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error)
+	ListTransactionsForAccount(ctx context.Context, in *ListTransactionsForAccountRequest, opts ...grpc.CallOption) (*ListTransactionsForAccountResponse, error)
 	GetResult(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*GetResultResponse, error)
 	GetSeal(ctx context.Context, in *GetSealRequest, opts ...grpc.CallOption) (*GetSealResponse, error)
 	ListSealsForHeight(ctx context.Context, in *ListSealsForHeightRequest, opts ...grpc.CallOption) (*ListSealsForHeightResponse, error)
+	GetHeightForSeal(ctx context.Context, in *GetHeightForSealRequest, opts ...grpc.CallOption) (*GetHeightForSealResponse, error)
+	SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (API_SubscribeBlocksClient, error)
+	GetAccountAtHeight(ctx context.Context, in *GetAccountAtHeightRequest, opts ...grpc.CallOption) (*GetAccountAtHeightResponse, error)
+	GetAccountKeyAtHeight(ctx context.Context, in *GetAccountKeyAtHeightRequest, opts ...grpc.CallOption) (*GetAccountKeyAtHeightResponse, error)
+	ExecuteScriptAtHeight(ctx context.Context, in *ExecuteScriptAtHeightRequest, opts ...grpc.CallOption) (*ExecuteScriptAtHeightResponse, error)
+	ExecuteTransactionAtHeight(ctx context.Context, in *ExecuteTransactionAtHeightRequest, opts ...grpc.CallOption) (*ExecuteTransactionAtHeightResponse, error)
 }
 
 type aPIClient struct {
@@ -62,6 +82,15 @@ func (c *aPIClient) GetLast(ctx context.Context, in *GetLastRequest, opts ...grp
 	return out, nil
 }
 
+func (c *aPIClient) GetFloor(ctx context.Context, in *GetFloorRequest, opts ...grpc.CallOption) (*GetFloorResponse, error) {
+	out := new(GetFloorResponse)
+	err := c.cc.Invoke(ctx, "/API/GetFloor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetHeightForBlock(ctx context.Context, in *GetHeightForBlockRequest, opts ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
 	out := new(GetHeightForBlockResponse)
 	err := c.cc.Invoke(ctx, "/API/GetHeightForBlock", in, out, opts...)
@@ -89,6 +118,15 @@ func (c *aPIClient) GetHeader(ctx context.Context, in *GetHeaderRequest, opts ..
 	return out, nil
 }
 
+func (c *aPIClient) GetHeaderByBlockID(ctx context.Context, in *GetHeaderByBlockIDRequest, opts ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error) {
+	out := new(GetHeaderByBlockIDResponse)
+	err := c.cc.Invoke(ctx, "/API/GetHeaderByBlockID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
 	out := new(GetEventsResponse)
 	err := c.cc.Invoke(ctx, "/API/GetEvents", in, out, opts...)
@@ -98,6 +136,111 @@ func (c *aPIClient) GetEvents(ctx context.Context, in *GetEventsRequest, opts ..
 	return out, nil
 }
 
+func (c *aPIClient) ListHeaders(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (API_ListHeadersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[0], "/API/ListHeaders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIListHeadersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_ListHeadersClient interface {
+	Recv() (*ListHeadersResponse, error)
+	grpc.ClientStream
+}
+
+type aPIListHeadersClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIListHeadersClient) Recv() (*ListHeadersResponse, error) {
+	m := new(ListHeadersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) ListCommits(ctx context.Context, in *ListCommitsRequest, opts ...grpc.CallOption) (API_ListCommitsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[1], "/API/ListCommits", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIListCommitsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_ListCommitsClient interface {
+	Recv() (*ListCommitsResponse, error)
+	grpc.ClientStream
+}
+
+type aPIListCommitsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIListCommitsClient) Recv() (*ListCommitsResponse, error) {
+	m := new(ListCommitsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) GetEventsRange(ctx context.Context, in *GetEventsRangeRequest, opts ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[2], "/API/GetEventsRange", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIGetEventsRangeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_GetEventsRangeClient interface {
+	Recv() (*GetEventsRangeResponse, error)
+	grpc.ClientStream
+}
+
+type aPIGetEventsRangeClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIGetEventsRangeClient) Recv() (*GetEventsRangeResponse, error) {
+	m := new(GetEventsRangeResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) ListHeightsForEvent(ctx context.Context, in *ListHeightsForEventRequest, opts ...grpc.CallOption) (*ListHeightsForEventResponse, error) {
+	out := new(ListHeightsForEventResponse)
+	err := c.cc.Invoke(ctx, "/API/ListHeightsForEvent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetRegisterValues(ctx context.Context, in *GetRegisterValuesRequest, opts ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
 	out := new(GetRegisterValuesResponse)
 	err := c.cc.Invoke(ctx, "/API/GetRegisterValues", in, out, opts...)
@@ -107,6 +250,88 @@ func (c *aPIClient) GetRegisterValues(ctx context.Context, in *GetRegisterValues
 	return out, nil
 }
 
+func (c *aPIClient) GetRegisterProofs(ctx context.Context, in *GetRegisterProofsRequest, opts ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+	out := new(GetRegisterProofsResponse)
+	err := c.cc.Invoke(ctx, "/API/GetRegisterProofs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetRegistersByKey(ctx context.Context, in *GetRegistersByKeyRequest, opts ...grpc.CallOption) (*GetRegistersByKeyResponse, error) {
+	out := new(GetRegistersByKeyResponse)
+	err := c.cc.Invoke(ctx, "/API/GetRegistersByKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetRegisterHistory(ctx context.Context, in *GetRegisterHistoryRequest, opts ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[3], "/API/GetRegisterHistory", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIGetRegisterHistoryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_GetRegisterHistoryClient interface {
+	Recv() (*GetRegisterHistoryResponse, error)
+	grpc.ClientStream
+}
+
+type aPIGetRegisterHistoryClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIGetRegisterHistoryClient) Recv() (*GetRegisterHistoryResponse, error) {
+	m := new(GetRegisterHistoryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) GetStateDiff(ctx context.Context, in *GetStateDiffRequest, opts ...grpc.CallOption) (API_GetStateDiffClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[4], "/API/GetStateDiff", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIGetStateDiffClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_GetStateDiffClient interface {
+	Recv() (*GetStateDiffResponse, error)
+	grpc.ClientStream
+}
+
+type aPIGetStateDiffClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIGetStateDiffClient) Recv() (*GetStateDiffResponse, error) {
+	m := new(GetStateDiffResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *aPIClient) GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error) {
 	out := new(GetCollectionResponse)
 	err := c.cc.Invoke(ctx, "/API/GetCollection", in, out, opts...)
@@ -125,6 +350,15 @@ func (c *aPIClient) ListCollectionsForHeight(ctx context.Context, in *ListCollec
 	return out, nil
 }
 
+func (c *aPIClient) GetHeightForCollection(ctx context.Context, in *GetHeightForCollectionRequest, opts ...grpc.CallOption) (*GetHeightForCollectionResponse, error) {
+	out := new(GetHeightForCollectionResponse)
+	err := c.cc.Invoke(ctx, "/API/GetHeightForCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetGuarantee(ctx context.Context, in *GetGuaranteeRequest, opts ...grpc.CallOption) (*GetGuaranteeResponse, error) {
 	out := new(GetGuaranteeResponse)
 	err := c.cc.Invoke(ctx, "/API/GetGuarantee", in, out, opts...)
@@ -152,6 +386,15 @@ func (c *aPIClient) GetHeightForTransaction(ctx context.Context, in *GetHeightFo
 	return out, nil
 }
 
+func (c *aPIClient) GetHeightForTimestamp(ctx context.Context, in *GetHeightForTimestampRequest, opts ...grpc.CallOption) (*GetHeightForTimestampResponse, error) {
+	out := new(GetHeightForTimestampResponse)
+	err := c.cc.Invoke(ctx, "/API/GetHeightForTimestamp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) ListTransactionsForHeight(ctx context.Context, in *ListTransactionsForHeightRequest, opts ...grpc.CallOption) (*ListTransactionsForHeightResponse, error) {
 	out := new(ListTransactionsForHeightResponse)
 	err := c.cc.Invoke(ctx, "/API/ListTransactionsForHeight", in, out, opts...)
@@ -161,6 +404,24 @@ func (c *aPIClient) ListTransactionsForHeight(ctx context.Context, in *ListTrans
 	return out, nil
 }
 
+func (c *aPIClient) ListTransactionsForCollection(ctx context.Context, in *ListTransactionsForCollectionRequest, opts ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error) {
+	out := new(ListTransactionsForCollectionResponse)
+	err := c.cc.Invoke(ctx, "/API/ListTransactionsForCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ListTransactionsForAccount(ctx context.Context, in *ListTransactionsForAccountRequest, opts ...grpc.CallOption) (*ListTransactionsForAccountResponse, error) {
+	out := new(ListTransactionsForAccountResponse)
+	err := c.cc.Invoke(ctx, "/API/ListTransactionsForAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *aPIClient) GetResult(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*GetResultResponse, error) {
 	out := new(GetResultResponse)
 	err := c.cc.Invoke(ctx, "/API/GetResult", in, out, opts...)
@@ -188,26 +449,123 @@ func (c *aPIClient) ListSealsForHeight(ctx context.Context, in *ListSealsForHeig
 	return out, nil
 }
 
+func (c *aPIClient) GetHeightForSeal(ctx context.Context, in *GetHeightForSealRequest, opts ...grpc.CallOption) (*GetHeightForSealResponse, error) {
+	out := new(GetHeightForSealResponse)
+	err := c.cc.Invoke(ctx, "/API/GetHeightForSeal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (API_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &API_ServiceDesc.Streams[5], "/API/SubscribeBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPISubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_SubscribeBlocksClient interface {
+	Recv() (*SubscribeBlocksResponse, error)
+	grpc.ClientStream
+}
+
+type aPISubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPISubscribeBlocksClient) Recv() (*SubscribeBlocksResponse, error) {
+	m := new(SubscribeBlocksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) GetAccountAtHeight(ctx context.Context, in *GetAccountAtHeightRequest, opts ...grpc.CallOption) (*GetAccountAtHeightResponse, error) {
+	out := new(GetAccountAtHeightResponse)
+	err := c.cc.Invoke(ctx, "/API/GetAccountAtHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) GetAccountKeyAtHeight(ctx context.Context, in *GetAccountKeyAtHeightRequest, opts ...grpc.CallOption) (*GetAccountKeyAtHeightResponse, error) {
+	out := new(GetAccountKeyAtHeightResponse)
+	err := c.cc.Invoke(ctx, "/API/GetAccountKeyAtHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ExecuteScriptAtHeight(ctx context.Context, in *ExecuteScriptAtHeightRequest, opts ...grpc.CallOption) (*ExecuteScriptAtHeightResponse, error) {
+	out := new(ExecuteScriptAtHeightResponse)
+	err := c.cc.Invoke(ctx, "/API/ExecuteScriptAtHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) ExecuteTransactionAtHeight(ctx context.Context, in *ExecuteTransactionAtHeightRequest, opts ...grpc.CallOption) (*ExecuteTransactionAtHeightResponse, error) {
+	out := new(ExecuteTransactionAtHeightResponse)
+	err := c.cc.Invoke(ctx, "/API/ExecuteTransactionAtHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIServer is the server API for API service.
 // All implementations should embed UnimplementedAPIServer
 // for forward compatibility
 type APIServer interface {
 	GetFirst(context.Context, *GetFirstRequest) (*GetFirstResponse, error)
 	GetLast(context.Context, *GetLastRequest) (*GetLastResponse, error)
+	GetFloor(context.Context, *GetFloorRequest) (*GetFloorResponse, error)
 	GetHeightForBlock(context.Context, *GetHeightForBlockRequest) (*GetHeightForBlockResponse, error)
 	GetCommit(context.Context, *GetCommitRequest) (*GetCommitResponse, error)
 	GetHeader(context.Context, *GetHeaderRequest) (*GetHeaderResponse, error)
+	GetHeaderByBlockID(context.Context, *GetHeaderByBlockIDRequest) (*GetHeaderByBlockIDResponse, error)
 	GetEvents(context.Context, *GetEventsRequest) (*GetEventsResponse, error)
+	ListHeaders(*ListHeadersRequest, API_ListHeadersServer) error
+	ListCommits(*ListCommitsRequest, API_ListCommitsServer) error
+	GetEventsRange(*GetEventsRangeRequest, API_GetEventsRangeServer) error
+	ListHeightsForEvent(context.Context, *ListHeightsForEventRequest) (*ListHeightsForEventResponse, error)
 	GetRegisterValues(context.Context, *GetRegisterValuesRequest) (*GetRegisterValuesResponse, error)
+	GetRegisterProofs(context.Context, *GetRegisterProofsRequest) (*GetRegisterProofsResponse, error)
+	GetRegistersByKey(context.Context, *GetRegistersByKeyRequest) (*GetRegistersByKeyResponse, error)
+	GetRegisterHistory(*GetRegisterHistoryRequest, API_GetRegisterHistoryServer) error
+	GetStateDiff(*GetStateDiffRequest, API_GetStateDiffServer) error
 	GetCollection(context.Context, *GetCollectionRequest) (*GetCollectionResponse, error)
 	ListCollectionsForHeight(context.Context, *ListCollectionsForHeightRequest) (*ListCollectionsForHeightResponse, error)
+	GetHeightForCollection(context.Context, *GetHeightForCollectionRequest) (*GetHeightForCollectionResponse, error)
 	GetGuarantee(context.Context, *GetGuaranteeRequest) (*GetGuaranteeResponse, error)
 	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
 	GetHeightForTransaction(context.Context, *GetHeightForTransactionRequest) (*GetHeightForTransactionResponse, error)
+	GetHeightForTimestamp(context.Context, *GetHeightForTimestampRequest) (*GetHeightForTimestampResponse, error)
 	ListTransactionsForHeight(context.Context, *ListTransactionsForHeightRequest) (*ListTransactionsForHeightResponse, error)
+	ListTransactionsForCollection(context.Context, *ListTransactionsForCollectionRequest) (*ListTransactionsForCollectionResponse, error)
+	ListTransactionsForAccount(context.Context, *ListTransactionsForAccountRequest) (*ListTransactionsForAccountResponse, error)
 	GetResult(context.Context, *GetResultRequest) (*GetResultResponse, error)
 	GetSeal(context.Context, *GetSealRequest) (*GetSealResponse, error)
 	ListSealsForHeight(context.Context, *ListSealsForHeightRequest) (*ListSealsForHeightResponse, error)
+	GetHeightForSeal(context.Context, *GetHeightForSealRequest) (*GetHeightForSealResponse, error)
+	SubscribeBlocks(*SubscribeBlocksRequest, API_SubscribeBlocksServer) error
+	GetAccountAtHeight(context.Context, *GetAccountAtHeightRequest) (*GetAccountAtHeightResponse, error)
+	GetAccountKeyAtHeight(context.Context, *GetAccountKeyAtHeightRequest) (*GetAccountKeyAtHeightResponse, error)
+	ExecuteScriptAtHeight(context.Context, *ExecuteScriptAtHeightRequest) (*ExecuteScriptAtHeightResponse, error)
+	ExecuteTransactionAtHeight(context.Context, *ExecuteTransactionAtHeightRequest) (*ExecuteTransactionAtHeightResponse, error)
 }
 
 // UnimplementedAPIServer should be embedded to have forward compatible implementations.
@@ -220,6 +578,9 @@ func (UnimplementedAPIServer) GetFirst(context.Context, *GetFirstRequest) (*GetF
 func (UnimplementedAPIServer) GetLast(context.Context, *GetLastRequest) (*GetLastResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLast not implemented")
 }
+func (UnimplementedAPIServer) GetFloor(context.Context, *GetFloorRequest) (*GetFloorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFloor not implemented")
+}
 func (UnimplementedAPIServer) GetHeightForBlock(context.Context, *GetHeightForBlockRequest) (*GetHeightForBlockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHeightForBlock not implemented")
 }
@@ -229,18 +590,48 @@ func (UnimplementedAPIServer) GetCommit(context.Context, *GetCommitRequest) (*Ge
 func (UnimplementedAPIServer) GetHeader(context.Context, *GetHeaderRequest) (*GetHeaderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHeader not implemented")
 }
+func (UnimplementedAPIServer) GetHeaderByBlockID(context.Context, *GetHeaderByBlockIDRequest) (*GetHeaderByBlockIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeaderByBlockID not implemented")
+}
 func (UnimplementedAPIServer) GetEvents(context.Context, *GetEventsRequest) (*GetEventsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEvents not implemented")
 }
+func (UnimplementedAPIServer) ListHeaders(*ListHeadersRequest, API_ListHeadersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListHeaders not implemented")
+}
+func (UnimplementedAPIServer) ListCommits(*ListCommitsRequest, API_ListCommitsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListCommits not implemented")
+}
+func (UnimplementedAPIServer) GetEventsRange(*GetEventsRangeRequest, API_GetEventsRangeServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetEventsRange not implemented")
+}
+func (UnimplementedAPIServer) ListHeightsForEvent(context.Context, *ListHeightsForEventRequest) (*ListHeightsForEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHeightsForEvent not implemented")
+}
 func (UnimplementedAPIServer) GetRegisterValues(context.Context, *GetRegisterValuesRequest) (*GetRegisterValuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRegisterValues not implemented")
 }
+func (UnimplementedAPIServer) GetRegisterProofs(context.Context, *GetRegisterProofsRequest) (*GetRegisterProofsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegisterProofs not implemented")
+}
+func (UnimplementedAPIServer) GetRegistersByKey(context.Context, *GetRegistersByKeyRequest) (*GetRegistersByKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegistersByKey not implemented")
+}
+func (UnimplementedAPIServer) GetRegisterHistory(*GetRegisterHistoryRequest, API_GetRegisterHistoryServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetRegisterHistory not implemented")
+}
+func (UnimplementedAPIServer) GetStateDiff(*GetStateDiffRequest, API_GetStateDiffServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetStateDiff not implemented")
+}
 func (UnimplementedAPIServer) GetCollection(context.Context, *GetCollectionRequest) (*GetCollectionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCollection not implemented")
 }
 func (UnimplementedAPIServer) ListCollectionsForHeight(context.Context, *ListCollectionsForHeightRequest) (*ListCollectionsForHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListCollectionsForHeight not implemented")
 }
+func (UnimplementedAPIServer) GetHeightForCollection(context.Context, *GetHeightForCollectionRequest) (*GetHeightForCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeightForCollection not implemented")
+}
 func (UnimplementedAPIServer) GetGuarantee(context.Context, *GetGuaranteeRequest) (*GetGuaranteeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetGuarantee not implemented")
 }
@@ -250,9 +641,18 @@ func (UnimplementedAPIServer) GetTransaction(context.Context, *GetTransactionReq
 func (UnimplementedAPIServer) GetHeightForTransaction(context.Context, *GetHeightForTransactionRequest) (*GetHeightForTransactionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHeightForTransaction not implemented")
 }
+func (UnimplementedAPIServer) GetHeightForTimestamp(context.Context, *GetHeightForTimestampRequest) (*GetHeightForTimestampResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeightForTimestamp not implemented")
+}
 func (UnimplementedAPIServer) ListTransactionsForHeight(context.Context, *ListTransactionsForHeightRequest) (*ListTransactionsForHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListTransactionsForHeight not implemented")
 }
+func (UnimplementedAPIServer) ListTransactionsForCollection(context.Context, *ListTransactionsForCollectionRequest) (*ListTransactionsForCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactionsForCollection not implemented")
+}
+func (UnimplementedAPIServer) ListTransactionsForAccount(context.Context, *ListTransactionsForAccountRequest) (*ListTransactionsForAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactionsForAccount not implemented")
+}
 func (UnimplementedAPIServer) GetResult(context.Context, *GetResultRequest) (*GetResultResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetResult not implemented")
 }
@@ -262,6 +662,24 @@ func (UnimplementedAPIServer) GetSeal(context.Context, *GetSealRequest) (*GetSea
 func (UnimplementedAPIServer) ListSealsForHeight(context.Context, *ListSealsForHeightRequest) (*ListSealsForHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListSealsForHeight not implemented")
 }
+func (UnimplementedAPIServer) GetHeightForSeal(context.Context, *GetHeightForSealRequest) (*GetHeightForSealResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeightForSeal not implemented")
+}
+func (UnimplementedAPIServer) SubscribeBlocks(*SubscribeBlocksRequest, API_SubscribeBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+func (UnimplementedAPIServer) GetAccountAtHeight(context.Context, *GetAccountAtHeightRequest) (*GetAccountAtHeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountAtHeight not implemented")
+}
+func (UnimplementedAPIServer) GetAccountKeyAtHeight(context.Context, *GetAccountKeyAtHeightRequest) (*GetAccountKeyAtHeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountKeyAtHeight not implemented")
+}
+func (UnimplementedAPIServer) ExecuteScriptAtHeight(context.Context, *ExecuteScriptAtHeightRequest) (*ExecuteScriptAtHeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteScriptAtHeight not implemented")
+}
+func (UnimplementedAPIServer) ExecuteTransactionAtHeight(context.Context, *ExecuteTransactionAtHeightRequest) (*ExecuteTransactionAtHeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteTransactionAtHeight not implemented")
+}
 
 // UnsafeAPIServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to APIServer will
@@ -310,6 +728,24 @@ func _API_GetLast_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetFloor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFloorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetFloor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetFloor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetFloor(ctx, req.(*GetFloorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetHeightForBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetHeightForBlockRequest)
 	if err := dec(in); err != nil {
@@ -364,6 +800,24 @@ func _API_GetHeader_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetHeaderByBlockID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeaderByBlockIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetHeaderByBlockID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetHeaderByBlockID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetHeaderByBlockID(ctx, req.(*GetHeaderByBlockIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetEventsRequest)
 	if err := dec(in); err != nil {
@@ -382,6 +836,87 @@ func _API_GetEvents_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_ListHeaders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListHeadersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).ListHeaders(m, &aPIListHeadersServer{stream})
+}
+
+type API_ListHeadersServer interface {
+	Send(*ListHeadersResponse) error
+	grpc.ServerStream
+}
+
+type aPIListHeadersServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIListHeadersServer) Send(m *ListHeadersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_ListCommits_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListCommitsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).ListCommits(m, &aPIListCommitsServer{stream})
+}
+
+type API_ListCommitsServer interface {
+	Send(*ListCommitsResponse) error
+	grpc.ServerStream
+}
+
+type aPIListCommitsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIListCommitsServer) Send(m *ListCommitsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_GetEventsRange_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetEventsRangeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).GetEventsRange(m, &aPIGetEventsRangeServer{stream})
+}
+
+type API_GetEventsRangeServer interface {
+	Send(*GetEventsRangeResponse) error
+	grpc.ServerStream
+}
+
+type aPIGetEventsRangeServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIGetEventsRangeServer) Send(m *GetEventsRangeResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_ListHeightsForEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHeightsForEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListHeightsForEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ListHeightsForEvent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListHeightsForEvent(ctx, req.(*ListHeightsForEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetRegisterValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRegisterValuesRequest)
 	if err := dec(in); err != nil {
@@ -400,6 +935,84 @@ func _API_GetRegisterValues_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetRegisterProofs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegisterProofsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetRegisterProofs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetRegisterProofs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetRegisterProofs(ctx, req.(*GetRegisterProofsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetRegistersByKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistersByKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetRegistersByKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetRegistersByKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetRegistersByKey(ctx, req.(*GetRegistersByKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetRegisterHistory_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetRegisterHistoryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).GetRegisterHistory(m, &aPIGetRegisterHistoryServer{stream})
+}
+
+type API_GetRegisterHistoryServer interface {
+	Send(*GetRegisterHistoryResponse) error
+	grpc.ServerStream
+}
+
+type aPIGetRegisterHistoryServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIGetRegisterHistoryServer) Send(m *GetRegisterHistoryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_GetStateDiff_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetStateDiffRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).GetStateDiff(m, &aPIGetStateDiffServer{stream})
+}
+
+type API_GetStateDiffServer interface {
+	Send(*GetStateDiffResponse) error
+	grpc.ServerStream
+}
+
+type aPIGetStateDiffServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIGetStateDiffServer) Send(m *GetStateDiffResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _API_GetCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetCollectionRequest)
 	if err := dec(in); err != nil {
@@ -436,6 +1049,24 @@ func _API_ListCollectionsForHeight_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetHeightForCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeightForCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetHeightForCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetHeightForCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetHeightForCollection(ctx, req.(*GetHeightForCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetGuarantee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetGuaranteeRequest)
 	if err := dec(in); err != nil {
@@ -490,6 +1121,24 @@ func _API_GetHeightForTransaction_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetHeightForTimestamp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeightForTimestampRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetHeightForTimestamp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetHeightForTimestamp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetHeightForTimestamp(ctx, req.(*GetHeightForTimestampRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_ListTransactionsForHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListTransactionsForHeightRequest)
 	if err := dec(in); err != nil {
@@ -508,6 +1157,42 @@ func _API_ListTransactionsForHeight_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_ListTransactionsForCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsForCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListTransactionsForCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ListTransactionsForCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListTransactionsForCollection(ctx, req.(*ListTransactionsForCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ListTransactionsForAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsForAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListTransactionsForAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ListTransactionsForAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListTransactionsForAccount(ctx, req.(*ListTransactionsForAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _API_GetResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetResultRequest)
 	if err := dec(in); err != nil {
@@ -562,6 +1247,117 @@ func _API_ListSealsForHeight_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_GetHeightForSeal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeightForSealRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetHeightForSeal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetHeightForSeal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetHeightForSeal(ctx, req.(*GetHeightForSealRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).SubscribeBlocks(m, &aPISubscribeBlocksServer{stream})
+}
+
+type API_SubscribeBlocksServer interface {
+	Send(*SubscribeBlocksResponse) error
+	grpc.ServerStream
+}
+
+type aPISubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPISubscribeBlocksServer) Send(m *SubscribeBlocksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_GetAccountAtHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountAtHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetAccountAtHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetAccountAtHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetAccountAtHeight(ctx, req.(*GetAccountAtHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_GetAccountKeyAtHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountKeyAtHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).GetAccountKeyAtHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/GetAccountKeyAtHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).GetAccountKeyAtHeight(ctx, req.(*GetAccountKeyAtHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ExecuteScriptAtHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteScriptAtHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ExecuteScriptAtHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ExecuteScriptAtHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ExecuteScriptAtHeight(ctx, req.(*ExecuteScriptAtHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_ExecuteTransactionAtHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteTransactionAtHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ExecuteTransactionAtHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ExecuteTransactionAtHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ExecuteTransactionAtHeight(ctx, req.(*ExecuteTransactionAtHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // API_ServiceDesc is the grpc.ServiceDesc for API service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -577,6 +1373,10 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLast",
 			Handler:    _API_GetLast_Handler,
 		},
+		{
+			MethodName: "GetFloor",
+			Handler:    _API_GetFloor_Handler,
+		},
 		{
 			MethodName: "GetHeightForBlock",
 			Handler:    _API_GetHeightForBlock_Handler,
@@ -589,14 +1389,30 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHeader",
 			Handler:    _API_GetHeader_Handler,
 		},
+		{
+			MethodName: "GetHeaderByBlockID",
+			Handler:    _API_GetHeaderByBlockID_Handler,
+		},
 		{
 			MethodName: "GetEvents",
 			Handler:    _API_GetEvents_Handler,
 		},
+		{
+			MethodName: "ListHeightsForEvent",
+			Handler:    _API_ListHeightsForEvent_Handler,
+		},
 		{
 			MethodName: "GetRegisterValues",
 			Handler:    _API_GetRegisterValues_Handler,
 		},
+		{
+			MethodName: "GetRegisterProofs",
+			Handler:    _API_GetRegisterProofs_Handler,
+		},
+		{
+			MethodName: "GetRegistersByKey",
+			Handler:    _API_GetRegistersByKey_Handler,
+		},
 		{
 			MethodName: "GetCollection",
 			Handler:    _API_GetCollection_Handler,
@@ -605,6 +1421,10 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListCollectionsForHeight",
 			Handler:    _API_ListCollectionsForHeight_Handler,
 		},
+		{
+			MethodName: "GetHeightForCollection",
+			Handler:    _API_GetHeightForCollection_Handler,
+		},
 		{
 			MethodName: "GetGuarantee",
 			Handler:    _API_GetGuarantee_Handler,
@@ -617,10 +1437,22 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHeightForTransaction",
 			Handler:    _API_GetHeightForTransaction_Handler,
 		},
+		{
+			MethodName: "GetHeightForTimestamp",
+			Handler:    _API_GetHeightForTimestamp_Handler,
+		},
 		{
 			MethodName: "ListTransactionsForHeight",
 			Handler:    _API_ListTransactionsForHeight_Handler,
 		},
+		{
+			MethodName: "ListTransactionsForCollection",
+			Handler:    _API_ListTransactionsForCollection_Handler,
+		},
+		{
+			MethodName: "ListTransactionsForAccount",
+			Handler:    _API_ListTransactionsForAccount_Handler,
+		},
 		{
 			MethodName: "GetResult",
 			Handler:    _API_GetResult_Handler,
@@ -633,7 +1465,58 @@ var API_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListSealsForHeight",
 			Handler:    _API_ListSealsForHeight_Handler,
 		},
+		{
+			MethodName: "GetHeightForSeal",
+			Handler:    _API_GetHeightForSeal_Handler,
+		},
+		{
+			MethodName: "GetAccountAtHeight",
+			Handler:    _API_GetAccountAtHeight_Handler,
+		},
+		{
+			MethodName: "GetAccountKeyAtHeight",
+			Handler:    _API_GetAccountKeyAtHeight_Handler,
+		},
+		{
+			MethodName: "ExecuteScriptAtHeight",
+			Handler:    _API_ExecuteScriptAtHeight_Handler,
+		},
+		{
+			MethodName: "ExecuteTransactionAtHeight",
+			Handler:    _API_ExecuteTransactionAtHeight_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListHeaders",
+			Handler:       _API_ListHeaders_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListCommits",
+			Handler:       _API_ListCommits_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetEventsRange",
+			Handler:       _API_GetEventsRange_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetRegisterHistory",
+			Handler:       _API_GetRegisterHistory_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetStateDiff",
+			Handler:       _API_GetStateDiff_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _API_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
 }
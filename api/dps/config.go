@@ -0,0 +1,160 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// MaxPageSize is the maximum number of entries that can be requested for a
+// single page of a paginated API method.
+const MaxPageSize = 1000
+
+// MaxBatchSize is the maximum number of heights that a streaming API method
+// loads from the index at once; larger ranges are streamed in several batches.
+const MaxBatchSize = 1000
+
+// MaxDiffRange is the maximum number of heights that a single state diff can
+// span. The changes of a diff are collected before they are sent, and their
+// number grows with the range, so larger diffs have to be split by the client.
+const MaxDiffRange = 100
+
+// DefaultConfig is the default configuration for the DPS API server.
+var DefaultConfig = Config{
+	PollInterval: 100 * time.Millisecond, // interval at which block subscriptions check for new heights
+	PageSize:     100,                    // number of entries per page for paginated requests without a limit
+	Proofs:       false,                  // whether register proofs are served
+	MaxPaths:     1000,                   // maximum number of registers per register request
+}
+
+// Config is the configuration of the DPS API server.
+type Config struct {
+	PollInterval time.Duration
+	PageSize     uint
+	Proofs       bool
+	MaxPaths     uint
+}
+
+// WithPollInterval sets the interval at which block subscriptions check the
+// index for newly indexed heights.
+func WithPollInterval(interval time.Duration) func(*Config) {
+	return func(cfg *Config) {
+		cfg.PollInterval = interval
+	}
+}
+
+// WithProofs enables serving register proofs. As the index does not store the
+// state trie, it has to be restored from the indexed payloads to generate
+// proofs, which is expensive in both CPU and memory. Proofs are therefore not
+// served unless enabled.
+func WithProofs() func(*Config) {
+	return func(cfg *Config) {
+		cfg.Proofs = true
+	}
+}
+
+// WithMaxPaths sets the maximum number of registers that a single request for
+// register values or proofs can ask for, so that one request cannot make the
+// server read an unbounded number of registers. Requests for more registers are
+// rejected; a maximum of zero allows any number of registers.
+func WithMaxPaths(max uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.MaxPaths = max
+	}
+}
+
+// WithPageSize sets the number of entries returned by paginated API methods
+// when the request does not specify a limit. It should not exceed the maximum
+// page size that requests can specify.
+func WithPageSize(size uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.PageSize = size
+	}
+}
+
+// DefaultIndexConfig is the default configuration for the DPS API index reader.
+var DefaultIndexConfig = IndexConfig{
+	Verify:     false,                  // whether register values are verified against the state commitment
+	Commits:    nil,                    // trusted source of state commitments used during verification
+	Timeout:    0,                      // deadline for each call to the API; zero means no deadline
+	Retries:    3,                      // number of times a call is retried when the API is unavailable
+	Backoff:    100 * time.Millisecond, // delay before the first retry, doubled for each following retry
+	MaxBackoff: 5 * time.Second,        // upper bound for the delay between retries
+}
+
+// IndexConfig is the configuration of the DPS API index reader.
+type IndexConfig struct {
+	Verify     bool
+	Commits    CommitSource
+	Timeout    time.Duration
+	Retries    uint
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// CommitSource is a trusted source of state commitments.
+type CommitSource interface {
+	Commit(height uint64) (flow.StateCommitment, error)
+}
+
+// WithVerification enables the verifying mode of the index reader, in which
+// register values are checked against proofs for the state commitment at the
+// requested height.
+func WithVerification() func(*IndexConfig) {
+	return func(cfg *IndexConfig) {
+		cfg.Verify = true
+	}
+}
+
+// WithTrustedCommits sets a trusted source of state commitments for the
+// verifying mode of the index reader. Proofs are then checked against the
+// trusted state commitment, and the state commitment of the API has to match
+// it for register values to be considered valid. Without a trusted source, the
+// state commitment of the API is used, which only guarantees that the values
+// are consistent with the state commitment that the API claims.
+func WithTrustedCommits(commits CommitSource) func(*IndexConfig) {
+	return func(cfg *IndexConfig) {
+		cfg.Commits = commits
+	}
+}
+
+// WithTimeout sets the deadline for each call of the index reader to the API.
+// When a call is retried, each attempt gets its own deadline. A timeout of zero
+// disables the deadline.
+func WithTimeout(timeout time.Duration) func(*IndexConfig) {
+	return func(cfg *IndexConfig) {
+		cfg.Timeout = timeout
+	}
+}
+
+// WithRetries sets how many times the index reader retries a call that failed
+// because the API was unavailable. All calls of the index reader are read-only,
+// so they can safely be retried.
+func WithRetries(retries uint) func(*IndexConfig) {
+	return func(cfg *IndexConfig) {
+		cfg.Retries = retries
+	}
+}
+
+// WithBackoff sets the delay before the first retry of a call, which is then
+// doubled for each following retry, up to the given maximum delay.
+func WithBackoff(initial time.Duration, max time.Duration) func(*IndexConfig) {
+	return func(cfg *IndexConfig) {
+		cfg.Backoff = initial
+		cfg.MaxBackoff = max
+	}
+}
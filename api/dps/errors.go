@@ -0,0 +1,85 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// statusErrorf formats an error in the same way as `fmt.Errorf` and converts it
+// into a GRPC status error, so that clients can distinguish between failures.
+// Requests that fail validation or wrap `dps.ErrInvalidArgument` are mapped to
+// `InvalidArgument`, while errors wrapping `dps.ErrNotFound` and
+// `dps.ErrOutOfRange` are mapped to `NotFound` and `OutOfRange`. All other
+// errors are returned as is, which GRPC reports as `Unknown`.
+func statusErrorf(format string, args ...interface{}) error {
+
+	err := fmt.Errorf(format, args...)
+
+	var invalid validator.ValidationErrors
+	switch {
+	case errors.As(err, &invalid), errors.Is(err, dps.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, dps.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dps.ErrOutOfRange):
+		return status.Error(codes.OutOfRange, err.Error())
+	default:
+		return err
+	}
+}
+
+// fromStatus converts a GRPC status error returned by the DPS API back into an
+// error that wraps the matching DPS error, so that callers of the index can
+// branch on them with `errors.Is`. Errors with other status codes are returned
+// as is.
+func fromStatus(err error) error {
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return fmt.Errorf("%s: %w", st.Message(), dps.ErrInvalidArgument)
+	case codes.NotFound:
+		return fmt.Errorf("%s: %w", st.Message(), dps.ErrNotFound)
+	case codes.OutOfRange:
+		return fmt.Errorf("%s: %w", st.Message(), dps.ErrOutOfRange)
+	default:
+		return err
+	}
+}
+
+// unavailable checks whether the given error, or any error it wraps, is a GRPC
+// status error with the `Unavailable` code, which indicates a transient failure
+// of the API that can be resolved by retrying.
+func unavailable(err error) bool {
+
+	var st interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &st) {
+		return false
+	}
+
+	return st.GRPCStatus().Code() == codes.Unavailable
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestStatusErrorf(t *testing.T) {
+	invalid := validator.New().Struct(&GetCommitRequest{})
+
+	tests := []struct {
+		name string
+
+		err error
+
+		wantCode codes.Code
+	}{
+		{
+			name:     "validation error",
+			err:      invalid,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "invalid argument",
+			err:      dps.ErrInvalidArgument,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "not found",
+			err:      dps.ErrNotFound,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "out of range",
+			err:      dps.ErrOutOfRange,
+			wantCode: codes.OutOfRange,
+		},
+		{
+			name:     "other error",
+			err:      mocks.GenericError,
+			wantCode: codes.Unknown,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := statusErrorf("could not do something: %w", test.err)
+
+			assert.Equal(t, test.wantCode, status.Code(err))
+			assert.Contains(t, err.Error(), "could not do something")
+		})
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		name string
+
+		err error
+
+		wantErr error
+	}{
+		{
+			name:    "invalid argument",
+			err:     status.Error(codes.InvalidArgument, "bad request"),
+			wantErr: dps.ErrInvalidArgument,
+		},
+		{
+			name:    "not found",
+			err:     status.Error(codes.NotFound, "could not get seal"),
+			wantErr: dps.ErrNotFound,
+		},
+		{
+			name:    "out of range",
+			err:     status.Error(codes.OutOfRange, "invalid height"),
+			wantErr: dps.ErrOutOfRange,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fromStatus(test.err)
+
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Contains(t, err.Error(), status.Convert(test.err).Message())
+		})
+	}
+
+	t.Run("other status codes", func(t *testing.T) {
+		t.Parallel()
+
+		err := status.Error(codes.Internal, "internal error")
+
+		assert.Equal(t, err, fromStatus(err))
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("could not do something: %w", mocks.GenericError)
+
+		assert.Equal(t, err, fromStatus(err))
+	})
+}
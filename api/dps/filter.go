@@ -0,0 +1,250 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// eventFilter selects events by type, type prefix, transaction ID and the
+// values of the fields of their decoded payloads. Event types ending with a
+// wildcard, such as `A.1654653399040a61.*`, match all event types that start
+// with the part before the wildcard.
+type eventFilter struct {
+	types      []flow.EventType
+	exact      map[flow.EventType]struct{}
+	prefixes   []string
+	txIDs      map[flow.Identifier]struct{}
+	predicates []predicate
+}
+
+// predicate is a comparison of the value of a field of a decoded event payload
+// to a literal value, such as `to == 0x1654653399040a61`.
+type predicate struct {
+	field string
+	equal bool
+	value string
+}
+
+// newEventFilter creates a filter for the given event types and prefixes,
+// transaction IDs and field predicates. Each kind of criteria is optional, but
+// an event has to satisfy all given kinds of criteria to be selected.
+func newEventFilter(types []string, txIDs [][]byte, fields []string) (*eventFilter, error) {
+
+	f := eventFilter{
+		exact: make(map[flow.EventType]struct{}),
+		txIDs: make(map[flow.Identifier]struct{}),
+	}
+
+	for _, typ := range types {
+		if strings.HasSuffix(typ, "*") {
+			f.prefixes = append(f.prefixes, strings.TrimSuffix(typ, "*"))
+			continue
+		}
+		f.types = append(f.types, flow.EventType(typ))
+		f.exact[flow.EventType(typ)] = struct{}{}
+	}
+
+	for _, txID := range txIDs {
+		f.txIDs[flow.HashToID(txID)] = struct{}{}
+	}
+
+	for _, field := range fields {
+		p, err := parsePredicate(field)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse field predicate (%s): %w", field, err)
+		}
+		f.predicates = append(f.predicates, p)
+	}
+
+	return &f, nil
+}
+
+// parsePredicate parses a field predicate of the form `field == value` or
+// `field != value`.
+func parsePredicate(field string) (predicate, error) {
+
+	for _, op := range []string{"==", "!="} {
+		parts := strings.SplitN(field, op, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		p := predicate{
+			field: strings.TrimSpace(parts[0]),
+			equal: op == "==",
+			value: strings.TrimSpace(parts[1]),
+		}
+		if p.field == "" || p.value == "" {
+			return predicate{}, fmt.Errorf("missing field name or value: %w", dps.ErrInvalidArgument)
+		}
+		return p, nil
+	}
+
+	return predicate{}, fmt.Errorf("missing comparison operator: %w", dps.ErrInvalidArgument)
+}
+
+// lookup returns the event types that the index can filter by itself. If type
+// prefixes are used, all event types have to be retrieved from the index, and
+// all events at a height are loaded for filtering; only those that also pass
+// the type and transaction ID filters have their payloads decoded, though.
+func (f *eventFilter) lookup() []flow.EventType {
+
+	if len(f.prefixes) > 0 {
+		return nil
+	}
+
+	return f.types
+}
+
+// apply returns the given events that match the filter, in the same order.
+func (f *eventFilter) apply(events []flow.Event) []flow.Event {
+
+	if len(f.prefixes) == 0 && len(f.txIDs) == 0 && len(f.predicates) == 0 {
+		return events
+	}
+
+	filtered := make([]flow.Event, 0, len(events))
+	for _, event := range events {
+		if f.match(event) {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered
+}
+
+// match checks whether the given event matches the filter. The type and
+// transaction ID filters are checked first, as they are cheap, so that only
+// the payloads of events that pass them are decoded for the field predicates.
+// Events whose payload can not be decoded as a Cadence event do not match any
+// field predicate, so that they don't make the whole request fail.
+func (f *eventFilter) match(event flow.Event) bool {
+
+	if len(f.exact) > 0 || len(f.prefixes) > 0 {
+		_, ok := f.exact[event.Type]
+		for _, prefix := range f.prefixes {
+			ok = ok || strings.HasPrefix(string(event.Type), prefix)
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	if len(f.txIDs) > 0 {
+		_, ok := f.txIDs[event.TransactionID]
+		if !ok {
+			return false
+		}
+	}
+
+	if len(f.predicates) == 0 {
+		return true
+	}
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return false
+	}
+	decoded, ok := value.(cadence.Event)
+	if !ok {
+		return false
+	}
+
+	for _, p := range f.predicates {
+		if !p.match(decoded) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// match checks whether the given decoded event satisfies the predicate. Events
+// without the predicate's field never satisfy it.
+func (p predicate) match(event cadence.Event) bool {
+
+	for index, field := range event.EventType.Fields {
+		if field.Identifier != p.field {
+			continue
+		}
+		return equal(event.Fields[index], p.value) == p.equal
+	}
+
+	return false
+}
+
+// equal checks whether the given Cadence value is equal to the given literal.
+// Optional values are compared by their inner value, or to `nil` if empty.
+// Addresses and fixed point numbers are compared by value, so that leading
+// zeroes and trailing decimals don't matter, while strings are compared to the
+// literal without quotes. All other values are compared by their Cadence
+// representation.
+func equal(value cadence.Value, literal string) bool {
+
+	for {
+		optional, ok := value.(cadence.Optional)
+		if !ok {
+			break
+		}
+		if optional.Value == nil {
+			return literal == "nil"
+		}
+		value = optional.Value
+	}
+
+	switch v := value.(type) {
+	case cadence.Address:
+		address, err := parseAddress(literal)
+		return err == nil && address == flow.Address(v)
+	case cadence.UFix64:
+		parsed, err := cadence.NewUFix64(literal)
+		return err == nil && parsed == v
+	case cadence.Fix64:
+		parsed, err := cadence.NewFix64(literal)
+		return err == nil && parsed == v
+	case cadence.String:
+		return strings.Trim(literal, `"`) == string(v)
+	default:
+		return value.String() == literal
+	}
+}
+
+// parseAddress parses a hexadecimal address literal, with or without prefix
+// and leading zeroes.
+func parseAddress(literal string) (flow.Address, error) {
+
+	literal = strings.TrimPrefix(literal, "0x")
+	if len(literal)%2 != 0 {
+		literal = "0" + literal
+	}
+
+	data, err := hex.DecodeString(literal)
+	if err != nil {
+		return flow.EmptyAddress, fmt.Errorf("could not decode address: %w", err)
+	}
+	if len(data) > flow.AddressLength {
+		return flow.EmptyAddress, fmt.Errorf("invalid address length (%d)", len(data))
+	}
+
+	return flow.BytesToAddress(data), nil
+}
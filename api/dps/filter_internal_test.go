@@ -0,0 +1,256 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/tests/utils"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestNewEventFilter(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		txID := mocks.GenericTransactionIDs(1)[0]
+		types := []string{"A.1654653399040a61.FlowToken.TokensDeposited", "A.1654653399040a61.*"}
+		fields := []string{"to == 0x1654653399040a61", "amount!=1.0"}
+
+		got, err := newEventFilter(types, [][]byte{mocks.ByteSlice(txID)}, fields)
+
+		require.NoError(t, err)
+		assert.Equal(t, []flow.EventType{"A.1654653399040a61.FlowToken.TokensDeposited"}, got.types)
+		assert.Equal(t, []string{"A.1654653399040a61."}, got.prefixes)
+		assert.Contains(t, got.txIDs, txID)
+		assert.Equal(t, []predicate{
+			{field: "to", equal: true, value: "0x1654653399040a61"},
+			{field: "amount", equal: false, value: "1.0"},
+		}, got.predicates)
+	})
+
+	t.Run("handles missing operator", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newEventFilter(nil, nil, []string{"to 0x1654653399040a61"})
+
+		assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+	})
+
+	t.Run("handles missing field name", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := newEventFilter(nil, nil, []string{"== 0x1654653399040a61"})
+
+		assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+	})
+}
+
+func TestEventFilter_Lookup(t *testing.T) {
+	t.Run("exact types", func(t *testing.T) {
+		t.Parallel()
+
+		types := mocks.GenericEventTypes(2)
+		filter, err := newEventFilter(convert.TypesToStrings(types), nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, types, filter.lookup())
+	})
+
+	t.Run("type prefixes", func(t *testing.T) {
+		t.Parallel()
+
+		types := convert.TypesToStrings(mocks.GenericEventTypes(2))
+		filter, err := newEventFilter(append(types, "A.*"), nil, nil)
+		require.NoError(t, err)
+
+		assert.Empty(t, filter.lookup())
+	})
+}
+
+func TestEventFilter_Apply(t *testing.T) {
+	events := mocks.GenericEvents(4, mocks.GenericEventTypes(2)...)
+
+	t.Run("without filters", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := newEventFilter(nil, nil, nil)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, events, got)
+	})
+
+	t.Run("type prefix", func(t *testing.T) {
+		t.Parallel()
+
+		prefix := string(events[1].Type[:3]) + "*"
+		filter, err := newEventFilter([]string{prefix}, nil, nil)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, []flow.Event{events[1], events[3]}, got)
+	})
+
+	t.Run("transaction IDs", func(t *testing.T) {
+		t.Parallel()
+
+		txIDs := [][]byte{mocks.ByteSlice(events[0].TransactionID), mocks.ByteSlice(events[2].TransactionID)}
+		filter, err := newEventFilter(nil, txIDs, nil)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, []flow.Event{events[0], events[2]}, got)
+	})
+
+	t.Run("field predicates", func(t *testing.T) {
+		t.Parallel()
+
+		fields := []string{"address == 0x" + mocks.GenericAddress(2).Hex()}
+		filter, err := newEventFilter(nil, nil, fields)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, []flow.Event{events[2]}, got)
+	})
+
+	t.Run("negated field predicates", func(t *testing.T) {
+		t.Parallel()
+
+		fields := []string{"address != 0x" + mocks.GenericAddress(2).Hex()}
+		filter, err := newEventFilter(nil, nil, fields)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, []flow.Event{events[0], events[1], events[3]}, got)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		t.Parallel()
+
+		filter, err := newEventFilter(nil, nil, []string{"missing == 1"})
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Empty(t, got)
+	})
+
+	t.Run("all filters", func(t *testing.T) {
+		t.Parallel()
+
+		types := []string{string(events[2].Type)}
+		txIDs := [][]byte{mocks.ByteSlice(events[2].TransactionID), mocks.ByteSlice(events[3].TransactionID)}
+		fields := []string{"address == 0x" + mocks.GenericAddress(2).Hex()}
+		filter, err := newEventFilter(types, txIDs, fields)
+		require.NoError(t, err)
+
+		got := filter.apply(events)
+
+		assert.Equal(t, []flow.Event{events[2]}, got)
+	})
+
+	t.Run("skips invalid payload", func(t *testing.T) {
+		t.Parallel()
+
+		invalid := mocks.GenericEvent(0)
+		invalid.Payload = mocks.GenericBytes
+
+		fields := []string{"address == 0x" + mocks.GenericAddress(2).Hex()}
+		filter, err := newEventFilter(nil, nil, fields)
+		require.NoError(t, err)
+
+		got := filter.apply([]flow.Event{invalid, events[2]})
+
+		assert.Equal(t, []flow.Event{events[2]}, got)
+	})
+}
+
+func TestPredicate_Match(t *testing.T) {
+	amount, err := cadence.NewUFix64("10.5")
+	require.NoError(t, err)
+
+	event := cadence.NewEvent([]cadence.Value{
+		amount,
+		cadence.NewOptional(cadence.NewAddress(flow.HexToAddress("1654653399040a61"))),
+		cadence.NewOptional(nil),
+		cadence.String("memo"),
+		cadence.NewUInt64(42),
+		cadence.NewAddress(flow.HexToAddress("01")),
+	}).WithType(&cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "FlowToken.TokensDeposited",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "from", Type: cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "memo", Type: cadence.StringType{}},
+			{Identifier: "count", Type: cadence.UInt64Type{}},
+			{Identifier: "admin", Type: cadence.AddressType{}},
+		},
+	})
+
+	// Make sure the event survives an encoding round trip, like event payloads
+	// from the index do.
+	decoded, err := json.Decode(json.MustEncode(event))
+	require.NoError(t, err)
+	event = decoded.(cadence.Event)
+
+	tests := []struct {
+		name  string
+		field string
+		want  bool
+	}{
+		{name: "fixed point number", field: "amount == 10.50", want: true},
+		{name: "different fixed point number", field: "amount == 10.4", want: false},
+		{name: "optional address", field: "to == 0x1654653399040a61", want: true},
+		{name: "address without leading zeroes", field: "admin == 0x1", want: true},
+		{name: "address with leading zeroes", field: "admin == 0x0000000000000001", want: true},
+		{name: "invalid address", field: "admin == 0x000000000000000001", want: false},
+		{name: "different optional address", field: "to == 0x01", want: false},
+		{name: "empty optional", field: "from == nil", want: true},
+		{name: "non-empty optional", field: "to != nil", want: true},
+		{name: "string with quotes", field: `memo == "memo"`, want: true},
+		{name: "string without quotes", field: "memo == memo", want: true},
+		{name: "integer", field: "count == 42", want: true},
+		{name: "different integer", field: "count != 42", want: false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := parsePredicate(test.field)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, p.match(event))
+		})
+	}
+}
@@ -15,12 +15,17 @@
 package dps
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 
+	"github.com/optakt/flow-dps/ledger/trie"
 	"github.com/optakt/flow-dps/models/convert"
 	"github.com/optakt/flow-dps/models/dps"
 )
@@ -30,29 +35,86 @@ import (
 // scripts, such that script invoker and execution state are on two different
 // machines across a network.
 type Index struct {
+	ctx    context.Context
 	client APIClient
 	codec  dps.Codec
+	cfg    IndexConfig
 }
 
 // IndexFromAPI creates a new instance of an index reader that uses the provided
 // GRPC API client to retrieve state from the index.
-func IndexFromAPI(client APIClient, codec dps.Codec) *Index {
+func IndexFromAPI(client APIClient, codec dps.Codec, options ...func(*IndexConfig)) *Index {
+
+	cfg := DefaultIndexConfig
+	for _, option := range options {
+		option(&cfg)
+	}
 
 	i := Index{
+		ctx:    context.Background(),
 		client: client,
 		codec:  codec,
+		cfg:    cfg,
 	}
 
 	return &i
 }
 
+// WithContext returns a copy of the index reader that makes all of its calls to
+// the API with the given context, so that they are aborted when the context is
+// canceled or its deadline expires.
+func (i *Index) WithContext(ctx context.Context) dps.Reader {
+	bound := *i
+	bound.ctx = ctx
+	return &bound
+}
+
+// call executes the given call to the API with the context of the index reader,
+// and a deadline for each attempt if a timeout is configured. When the call
+// fails because the API is unavailable, it is retried with exponential backoff
+// until the configured number of retries is exhausted or the context is done.
+func (i *Index) call(op func(ctx context.Context) error) error {
+
+	backoff := i.cfg.Backoff
+	for attempt := uint(0); ; attempt++ {
+
+		ctx := i.ctx
+		cancel := func() {}
+		if i.cfg.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(i.ctx, i.cfg.Timeout)
+		}
+		err := op(ctx)
+		cancel()
+
+		if err == nil || attempt >= i.cfg.Retries || !unavailable(err) {
+			return err
+		}
+
+		select {
+		case <-i.ctx.Done():
+			return fmt.Errorf("could not retry call: %w", i.ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > i.cfg.MaxBackoff {
+			backoff = i.cfg.MaxBackoff
+		}
+	}
+}
+
 // First returns the height of the first finalized block that was indexed.
 func (i *Index) First() (uint64, error) {
 
 	req := GetFirstRequest{}
-	res, err := i.client.GetFirst(context.Background(), &req)
+	var res *GetFirstResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetFirst(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("could not get first height: %w", err)
+		return 0, fmt.Errorf("could not get first height: %w", fromStatus(err))
 	}
 
 	return res.Height, nil
@@ -62,9 +124,32 @@ func (i *Index) First() (uint64, error) {
 func (i *Index) Last() (uint64, error) {
 
 	req := GetLastRequest{}
-	res, err := i.client.GetLast(context.Background(), &req)
+	var res *GetLastResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetLast(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("could not get last height: %w", err)
+		return 0, fmt.Errorf("could not get last height: %w", fromStatus(err))
+	}
+
+	return res.Height, nil
+}
+
+// Floor returns the lowest height at which the complete register state can be
+// read.
+func (i *Index) Floor() (uint64, error) {
+
+	req := GetFloorRequest{}
+	var res *GetFloorResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetFloor(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get retention floor: %w", fromStatus(err))
 	}
 
 	return res.Height, nil
@@ -76,9 +161,14 @@ func (i *Index) HeightForBlock(blockID flow.Identifier) (uint64, error) {
 	req := GetHeightForBlockRequest{
 		BlockID: blockID[:],
 	}
-	res, err := i.client.GetHeightForBlock(context.Background(), &req)
+	var res *GetHeightForBlockResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeightForBlock(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("could not get height: %w", err)
+		return 0, fmt.Errorf("could not get height: %w", fromStatus(err))
 	}
 
 	return res.Height, nil
@@ -91,9 +181,14 @@ func (i *Index) Commit(height uint64) (flow.StateCommitment, error) {
 	req := GetCommitRequest{
 		Height: height,
 	}
-	res, err := i.client.GetCommit(context.Background(), &req)
+	var res *GetCommitResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetCommit(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return flow.DummyStateCommitment, fmt.Errorf("could not get commit: %w", err)
+		return flow.DummyStateCommitment, fmt.Errorf("could not get commit: %w", fromStatus(err))
 	}
 
 	commit, err := flow.ToStateCommitment(res.Commit)
@@ -110,9 +205,14 @@ func (i *Index) Header(height uint64) (*flow.Header, error) {
 	req := GetHeaderRequest{
 		Height: height,
 	}
-	res, err := i.client.GetHeader(context.Background(), &req)
+	var res *GetHeaderResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeader(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get header: %w", err)
+		return nil, fmt.Errorf("could not get header: %w", fromStatus(err))
 	}
 
 	var header flow.Header
@@ -124,35 +224,357 @@ func (i *Index) Header(height uint64) (*flow.Header, error) {
 	return &header, nil
 }
 
+// HeaderByBlockID returns the header for the finalized block with the given
+// block identifier.
+func (i *Index) HeaderByBlockID(blockID flow.Identifier) (*flow.Header, error) {
+
+	req := GetHeaderByBlockIDRequest{
+		BlockID: blockID[:],
+	}
+	var res *GetHeaderByBlockIDResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeaderByBlockID(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", fromStatus(err))
+	}
+
+	var header flow.Header
+	err = i.codec.Unmarshal(res.Data, &header)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode header: %w", err)
+	}
+
+	return &header, nil
+}
+
+// Headers returns the headers for the finalized blocks at all heights between
+// the given start and end heights, both inclusive.
+func (i *Index) Headers(start uint64, end uint64) ([]*flow.Header, error) {
+
+	req := ListHeadersRequest{
+		Start: start,
+		End:   end,
+	}
+	var headers []*flow.Header
+	err := i.call(func(ctx context.Context) error {
+
+		stream, err := i.client.ListHeaders(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("could not list headers: %w", fromStatus(err))
+		}
+
+		headers = nil
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not receive header: %w", fromStatus(err))
+			}
+
+			var header flow.Header
+			err = i.codec.Unmarshal(res.Data, &header)
+			if err != nil {
+				return fmt.Errorf("could not decode header (height: %d): %w", res.Height, err)
+			}
+
+			headers = append(headers, &header)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// Commits returns the commitments of the execution state as it was after the
+// execution of the finalized blocks at all heights between the given start and
+// end heights, both inclusive, by height.
+func (i *Index) Commits(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+
+	req := ListCommitsRequest{
+		Start: start,
+		End:   end,
+	}
+	var commits map[uint64]flow.StateCommitment
+	err := i.call(func(ctx context.Context) error {
+
+		stream, err := i.client.ListCommits(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("could not list commits: %w", fromStatus(err))
+		}
+
+		commits = make(map[uint64]flow.StateCommitment)
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not receive commit: %w", fromStatus(err))
+			}
+
+			commit, err := flow.ToStateCommitment(res.Commit)
+			if err != nil {
+				return fmt.Errorf("could not convert commit (height: %d): %w", res.Height, err)
+			}
+
+			commits[res.Height] = commit
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
 // Values returns the Ledger values of the execution state at the given paths
 // as they were after the execution of the finalized block at the given height.
 // For compatibility with existing Flow execution node code, a path that is not
 // found within the indexed execution state returns a nil value without error.
+// In verifying mode, the values are checked against register proofs for the
+// state commitment at the given height, and a mismatch returns an error that
+// wraps `dps.ErrInvalidProof`.
 func (i *Index) Values(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
 
 	req := GetRegisterValuesRequest{
 		Height: height,
 		Paths:  convert.PathsToBytes(paths),
 	}
-	res, err := i.client.GetRegisterValues(context.Background(), &req)
+	var res *GetRegisterValuesResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetRegisterValues(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get registers: %w", err)
+		return nil, fmt.Errorf("could not get registers: %w", fromStatus(err))
 	}
 
 	values := convert.BytesToValues(res.Values)
 
+	if !i.cfg.Verify {
+		return values, nil
+	}
+
+	err = i.verify(height, paths, values)
+	if err != nil {
+		return nil, fmt.Errorf("could not verify values: %w", err)
+	}
+
 	return values, nil
 }
 
+// verify checks the given register values against proofs for the state
+// commitment at the given height. If a trusted source of state commitments is
+// configured, the proofs are checked against its state commitment, which the
+// state commitment of the API has to match. Otherwise, the state commitment is
+// provided by the API itself.
+func (i *Index) verify(height uint64, paths []ledger.Path, values []ledger.Value) error {
+
+	commit, err := i.Commit(height)
+	if err != nil {
+		return fmt.Errorf("could not get commit: %w", err)
+	}
+
+	if i.cfg.Commits != nil {
+		trusted, err := i.cfg.Commits.Commit(height)
+		if err != nil {
+			return fmt.Errorf("could not get trusted commit: %w", err)
+		}
+		if commit != trusted {
+			return fmt.Errorf("commit does not match trusted commit (height: %d, commit: %x, trusted: %x): %w", height, commit, trusted, dps.ErrUntrusted)
+		}
+		commit = trusted
+	}
+
+	proofs, err := i.Proofs(height, paths)
+	if err != nil {
+		return fmt.Errorf("could not get proofs: %w", err)
+	}
+	if len(proofs.Proofs) != len(paths) || len(values) != len(paths) {
+		return fmt.Errorf("mismatch between paths, values and proofs (paths: %d, values: %d, proofs: %d): %w", len(paths), len(values), len(proofs.Proofs), dps.ErrInvalidProof)
+	}
+
+	root := ledger.RootHash(commit)
+	for index, path := range paths {
+		proof := proofs.Proofs[index]
+		if !trie.Verify(path, proof, root) {
+			return fmt.Errorf("proof does not match commit (path: %x, commit: %x): %w", path, commit, dps.ErrInvalidProof)
+		}
+
+		// A non-inclusion proof means that the register is empty.
+		var value ledger.Value
+		if proof.Inclusion {
+			value = proof.Payload.Value
+		}
+		if !bytes.Equal(values[index], value) {
+			return fmt.Errorf("value does not match proof (path: %x): %w", path, dps.ErrInvalidProof)
+		}
+	}
+
+	return nil
+}
+
+// Proofs returns batch proofs for the given paths of the execution state as it
+// was after the execution of the finalized block at the given height. They can
+// be verified against the state commitment at the same height.
+func (i *Index) Proofs(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+
+	req := GetRegisterProofsRequest{
+		Height: height,
+		Paths:  convert.PathsToBytes(paths),
+	}
+	var res *GetRegisterProofsResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetRegisterProofs(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get proofs: %w", fromStatus(err))
+	}
+
+	proofs, err := convert.BytesToProofs(res.Proofs)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert proofs: %w", err)
+	}
+
+	return proofs, nil
+}
+
+// RegisterHistory returns the ledger payloads that were written to the given
+// path by the finalized blocks at all heights between the given start and end
+// heights, both inclusive, by height.
+func (i *Index) RegisterHistory(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+
+	req := GetRegisterHistoryRequest{
+		Path:  path[:],
+		Start: start,
+		End:   end,
+	}
+	var payloads map[uint64]*ledger.Payload
+	err := i.call(func(ctx context.Context) error {
+
+		stream, err := i.client.GetRegisterHistory(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("could not get register history: %w", fromStatus(err))
+		}
+
+		payloads = make(map[uint64]*ledger.Payload)
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not receive payload: %w", fromStatus(err))
+			}
+
+			var payload ledger.Payload
+			err = i.codec.Unmarshal(res.Data, &payload)
+			if err != nil {
+				return fmt.Errorf("could not decode payload (height: %d): %w", res.Height, err)
+			}
+
+			payloads[res.Height] = &payload
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payloads, nil
+}
+
+// StateDiff calls the given callback with the change to each ledger register
+// between the execution state after the finalized block at the given start
+// height and the one after the finalized block at the given end height, in
+// ascending order of ledger paths.
+func (i *Index) StateDiff(start uint64, end uint64, process func(change dps.Change) error) error {
+
+	req := GetStateDiffRequest{
+		Start: start,
+		End:   end,
+	}
+	var last *ledger.Path
+	err := i.call(func(ctx context.Context) error {
+
+		stream, err := i.client.GetStateDiff(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("could not get state diff: %w", fromStatus(err))
+		}
+
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not receive change: %w", fromStatus(err))
+			}
+
+			path, err := ledger.ToPath(res.Path)
+			if err != nil {
+				return fmt.Errorf("could not convert path: %w", err)
+			}
+
+			// The changes are streamed in ascending order of paths, so when the
+			// call is retried, we skip the changes that were already processed.
+			if last != nil && bytes.Compare(path[:], last[:]) <= 0 {
+				continue
+			}
+
+			var before *ledger.Payload
+			if len(res.Before) > 0 {
+				before = &ledger.Payload{}
+				err = i.codec.Unmarshal(res.Before, before)
+				if err != nil {
+					return fmt.Errorf("could not decode payload before (path: %x): %w", path, err)
+				}
+			}
+			var after ledger.Payload
+			err = i.codec.Unmarshal(res.After, &after)
+			if err != nil {
+				return fmt.Errorf("could not decode payload after (path: %x): %w", path, err)
+			}
+
+			change := dps.Change{
+				Path:   path,
+				Before: before,
+				After:  &after,
+			}
+			err = process(change)
+			if err != nil {
+				return fmt.Errorf("could not process change (path: %x): %w", path, err)
+			}
+			last = &path
+		}
+	})
+
+	return err
+}
+
 // Collection returns the collection with the given ID.
 func (i *Index) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
 
 	req := GetCollectionRequest{
 		CollectionID: collID[:],
 	}
-	res, err := i.client.GetCollection(context.Background(), &req)
+	var res *GetCollectionResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetCollection(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get collection: %w", err)
+		return nil, fmt.Errorf("could not get collection: %w", fromStatus(err))
 	}
 
 	var collection flow.LightCollection
@@ -170,9 +592,14 @@ func (i *Index) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
 	req := ListCollectionsForHeightRequest{
 		Height: height,
 	}
-	res, err := i.client.ListCollectionsForHeight(context.Background(), &req)
+	var res *ListCollectionsForHeightResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.ListCollectionsForHeight(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get transactions: %w", err)
+		return nil, fmt.Errorf("could not get transactions: %w", fromStatus(err))
 	}
 
 	collIDs := make([]flow.Identifier, 0, len(res.CollectionIDs))
@@ -183,15 +610,39 @@ func (i *Index) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
 	return collIDs, nil
 }
 
+// HeightForCollection returns the height of the given collection ID.
+func (i *Index) HeightForCollection(collID flow.Identifier) (uint64, error) {
+
+	req := GetHeightForCollectionRequest{
+		CollectionID: collID[:],
+	}
+	var res *GetHeightForCollectionResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeightForCollection(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height: %w", fromStatus(err))
+	}
+
+	return res.Height, nil
+}
+
 // Guarantee returns the collection guarantee for the given collection ID.
 func (i *Index) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
 
 	req := GetGuaranteeRequest{
 		CollectionID: collID[:],
 	}
-	res, err := i.client.GetGuarantee(context.Background(), &req)
+	var res *GetGuaranteeResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetGuarantee(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get guarantee: %w", err)
+		return nil, fmt.Errorf("could not get guarantee: %w", fromStatus(err))
 	}
 
 	var guarantee flow.CollectionGuarantee
@@ -209,9 +660,14 @@ func (i *Index) Transaction(txID flow.Identifier) (*flow.TransactionBody, error)
 	req := GetTransactionRequest{
 		TransactionID: txID[:],
 	}
-	res, err := i.client.GetTransaction(context.Background(), &req)
+	var res *GetTransactionResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetTransaction(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get transaction: %w", err)
+		return nil, fmt.Errorf("could not get transaction: %w", fromStatus(err))
 	}
 
 	var transaction flow.TransactionBody
@@ -229,9 +685,34 @@ func (i *Index) HeightForTransaction(txID flow.Identifier) (uint64, error) {
 	req := GetHeightForTransactionRequest{
 		TransactionID: txID[:],
 	}
-	res, err := i.client.GetHeightForTransaction(context.Background(), &req)
+	var res *GetHeightForTransactionResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeightForTransaction(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height: %w", fromStatus(err))
+	}
+
+	return res.Height, nil
+}
+
+// HeightForTime returns the height of the last finalized block with a timestamp
+// at or before the given time.
+func (i *Index) HeightForTime(timestamp time.Time) (uint64, error) {
+
+	req := GetHeightForTimestampRequest{
+		Timestamp: timestamp.UnixNano(),
+	}
+	var res *GetHeightForTimestampResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeightForTimestamp(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("could not get height: %w", err)
+		return 0, fmt.Errorf("could not get height: %w", fromStatus(err))
 	}
 
 	return res.Height, nil
@@ -243,9 +724,38 @@ func (i *Index) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
 	req := ListTransactionsForHeightRequest{
 		Height: height,
 	}
-	res, err := i.client.ListTransactionsForHeight(context.Background(), &req)
+	var res *ListTransactionsForHeightResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.ListTransactionsForHeight(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get transactions: %w", fromStatus(err))
+	}
+
+	txIDs := make([]flow.Identifier, 0, len(res.TransactionIDs))
+	for _, txID := range res.TransactionIDs {
+		txIDs = append(txIDs, flow.HashToID(txID))
+	}
+
+	return txIDs, nil
+}
+
+// TransactionsByCollection returns the transaction IDs within the given collection.
+func (i *Index) TransactionsByCollection(collID flow.Identifier) ([]flow.Identifier, error) {
+
+	req := ListTransactionsForCollectionRequest{
+		CollectionID: collID[:],
+	}
+	var res *ListTransactionsForCollectionResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.ListTransactionsForCollection(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get transactions: %w", err)
+		return nil, fmt.Errorf("could not get transactions: %w", fromStatus(err))
 	}
 
 	txIDs := make([]flow.Identifier, 0, len(res.TransactionIDs))
@@ -256,15 +766,82 @@ func (i *Index) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
 	return txIDs, nil
 }
 
+// TransactionsByAccount returns up to the given limit of transactions that the
+// account with the given address took part in between the given start and end
+// heights, both inclusive, after the given transaction ID. Results are requested
+// from the API in pages, until the limit is reached or the last page is received;
+// a limit of zero returns all transactions in the range.
+func (i *Index) TransactionsByAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+
+	txs := make([]dps.AccountTransaction, 0)
+	for {
+		size := uint(MaxPageSize)
+		if limit > 0 && limit-uint(len(txs)) < size {
+			size = limit - uint(len(txs))
+		}
+
+		req := ListTransactionsForAccountRequest{
+			Address: address[:],
+			Start:   start,
+			End:     end,
+			Limit:   uint32(size),
+		}
+		if after != flow.ZeroID {
+			req.After = after[:]
+		}
+		var res *ListTransactionsForAccountResponse
+		err := i.call(func(ctx context.Context) error {
+			var err error
+			res, err = i.client.ListTransactionsForAccount(ctx, &req)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not list transactions for account: %w", fromStatus(err))
+		}
+
+		for _, transaction := range res.Transactions {
+			var roles dps.Role
+			if transaction.Proposer {
+				roles |= dps.RoleProposer
+			}
+			if transaction.Payer {
+				roles |= dps.RolePayer
+			}
+			if transaction.Authorizer {
+				roles |= dps.RoleAuthorizer
+			}
+			tx := dps.AccountTransaction{
+				Height:        transaction.Height,
+				TransactionID: flow.HashToID(transaction.TransactionID),
+				Roles:         roles,
+			}
+			txs = append(txs, tx)
+		}
+
+		if uint(len(res.Transactions)) < size || uint(len(txs)) == limit {
+			return txs, nil
+		}
+
+		last := txs[len(txs)-1]
+		start = last.Height
+		after = last.TransactionID
+	}
+}
+
 // Result returns the result for a given transaction ID.
 func (i *Index) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
 
 	req := GetResultRequest{
 		TransactionID: txID[:],
 	}
-	res, err := i.client.GetResult(context.Background(), &req)
+	var res *GetResultResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetResult(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get transaction result: %w", err)
+		return nil, fmt.Errorf("could not get transaction result: %w", fromStatus(err))
 	}
 
 	var result flow.TransactionResult
@@ -286,9 +863,14 @@ func (i *Index) Events(height uint64, types ...flow.EventType) ([]flow.Event, er
 		Height: height,
 		Types:  tt,
 	}
-	res, err := i.client.GetEvents(context.Background(), &req)
+	var res *GetEventsResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetEvents(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get events: %w", err)
+		return nil, fmt.Errorf("could not get events: %w", fromStatus(err))
 	}
 
 	var events []flow.Event
@@ -300,15 +882,90 @@ func (i *Index) Events(height uint64, types ...flow.EventType) ([]flow.Event, er
 	return events, nil
 }
 
+// EventsRange returns the events of all transactions that were part of the
+// finalized blocks at all heights between the given start and end heights, both
+// inclusive, by height. It can optionally filter them by event type; if no event
+// types are given, all events are returned.
+func (i *Index) EventsRange(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+
+	req := GetEventsRangeRequest{
+		Start: start,
+		End:   end,
+		Types: convert.TypesToStrings(types),
+	}
+	var events map[uint64][]flow.Event
+	err := i.call(func(ctx context.Context) error {
+
+		stream, err := i.client.GetEventsRange(ctx, &req)
+		if err != nil {
+			return fmt.Errorf("could not get events range: %w", fromStatus(err))
+		}
+
+		events = make(map[uint64][]flow.Event)
+		for {
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("could not receive events: %w", fromStatus(err))
+			}
+
+			var evts []flow.Event
+			err = i.codec.Unmarshal(res.Data, &evts)
+			if err != nil {
+				return fmt.Errorf("could not decode events (height: %d): %w", res.Height, err)
+			}
+
+			events[res.Height] = evts
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// EventHeights returns the heights between the given start and end heights,
+// both inclusive, of the finalized blocks that contain events of the given type.
+func (i *Index) EventHeights(typ flow.EventType, start uint64, end uint64) ([]uint64, error) {
+
+	req := ListHeightsForEventRequest{
+		Type:  string(typ),
+		Start: start,
+		End:   end,
+	}
+	var res *ListHeightsForEventResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.ListHeightsForEvent(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list heights for event: %w", fromStatus(err))
+	}
+
+	heights := make([]uint64, 0, len(res.Heights))
+	heights = append(heights, res.Heights...)
+
+	return heights, nil
+}
+
 // Seal returns the seal with the given ID.
 func (i *Index) Seal(sealID flow.Identifier) (*flow.Seal, error) {
 
 	req := GetSealRequest{
 		SealID: sealID[:],
 	}
-	res, err := i.client.GetSeal(context.Background(), &req)
+	var res *GetSealResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetSeal(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get seal: %w", err)
+		return nil, fmt.Errorf("could not get seal: %w", fromStatus(err))
 	}
 
 	var seal flow.Seal
@@ -326,9 +983,14 @@ func (i *Index) SealsByHeight(height uint64) ([]flow.Identifier, error) {
 	req := ListSealsForHeightRequest{
 		Height: height,
 	}
-	res, err := i.client.ListSealsForHeight(context.Background(), &req)
+	var res *ListSealsForHeightResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.ListSealsForHeight(ctx, &req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("could not get seals: %w", err)
+		return nil, fmt.Errorf("could not get seals: %w", fromStatus(err))
 	}
 
 	sealIDs := make([]flow.Identifier, 0, len(res.SealIDs))
@@ -338,3 +1000,22 @@ func (i *Index) SealsByHeight(height uint64) ([]flow.Identifier, error) {
 
 	return sealIDs, nil
 }
+
+// HeightForSeal returns the height of the given seal ID.
+func (i *Index) HeightForSeal(sealID flow.Identifier) (uint64, error) {
+
+	req := GetHeightForSealRequest{
+		SealID: sealID[:],
+	}
+	var res *GetHeightForSealResponse
+	err := i.call(func(ctx context.Context) error {
+		var err error
+		res, err = i.client.GetHeightForSeal(ctx, &req)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height: %w", fromStatus(err))
+	}
+
+	return res.Height, nil
+}
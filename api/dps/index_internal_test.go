@@ -16,14 +16,23 @@ package dps
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/ledger/trie"
 	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/testing/mocks"
 )
 
@@ -36,6 +45,196 @@ func TestIndexFromAPI(t *testing.T) {
 	require.NotNil(t, index)
 	assert.Equal(t, mock, index.client)
 	assert.NotNil(t, mock, index.codec)
+	assert.Equal(t, DefaultIndexConfig, index.cfg)
+}
+
+func TestIndex_WithContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, mocks.GenericHeight)
+
+	index := IndexFromAPI(&apiMock{
+		GetFirstFunc: func(got context.Context, _ *GetFirstRequest, _ ...grpc.CallOption) (*GetFirstResponse, error) {
+			assert.Equal(t, mocks.GenericHeight, got.Value(key{}))
+			return &GetFirstResponse{Height: mocks.GenericHeight}, nil
+		},
+	}, mocks.BaselineCodec(t))
+
+	bound := index.WithContext(ctx)
+
+	require.IsType(t, &Index{}, bound)
+	assert.Equal(t, ctx, bound.(*Index).ctx)
+	assert.Equal(t, context.Background(), index.ctx)
+
+	got, err := bound.First()
+
+	require.NoError(t, err)
+	assert.Equal(t, mocks.GenericHeight, got)
+}
+
+func TestIndex_Call(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	cfg := DefaultIndexConfig
+	cfg.Backoff = time.Millisecond
+	cfg.MaxBackoff = 2 * time.Millisecond
+
+	t.Run("nominal case with retries", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		index := Index{
+			ctx: context.Background(),
+			cfg: cfg,
+			client: &apiMock{
+				GetFirstFunc: func(context.Context, *GetFirstRequest, ...grpc.CallOption) (*GetFirstResponse, error) {
+					calls++
+					if calls <= 2 {
+						return nil, unavailable
+					}
+					return &GetFirstResponse{Height: mocks.GenericHeight}, nil
+				},
+			},
+		}
+
+		got, err := index.First()
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, got)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("nominal case with retried stream", func(t *testing.T) {
+		t.Parallel()
+
+		commit := mocks.GenericCommit(0)
+
+		calls := 0
+		index := Index{
+			ctx: context.Background(),
+			cfg: cfg,
+			client: &apiMock{
+				ListCommitsFunc: func(context.Context, *ListCommitsRequest, ...grpc.CallOption) (API_ListCommitsClient, error) {
+					calls++
+					sent := false
+					stream := &listCommitsClientMock{
+						RecvFunc: func() (*ListCommitsResponse, error) {
+							if !sent {
+								sent = true
+								return &ListCommitsResponse{Height: mocks.GenericHeight, Commit: commit[:]}, nil
+							}
+							if calls == 1 {
+								return nil, unavailable
+							}
+							return nil, io.EOF
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		got, err := index.Commits(mocks.GenericHeight, mocks.GenericHeight)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[uint64]flow.StateCommitment{mocks.GenericHeight: commit}, got)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("handles exhausted retries", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		index := Index{
+			ctx: context.Background(),
+			cfg: cfg,
+			client: &apiMock{
+				GetFirstFunc: func(context.Context, *GetFirstRequest, ...grpc.CallOption) (*GetFirstResponse, error) {
+					calls++
+					return nil, unavailable
+				},
+			},
+		}
+
+		_, err := index.First()
+
+		assert.Error(t, err)
+		assert.Equal(t, int(cfg.Retries)+1, calls)
+	})
+
+	t.Run("does not retry other failures", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		index := Index{
+			ctx: context.Background(),
+			cfg: cfg,
+			client: &apiMock{
+				GetFirstFunc: func(context.Context, *GetFirstRequest, ...grpc.CallOption) (*GetFirstResponse, error) {
+					calls++
+					return nil, status.Error(codes.NotFound, "not found")
+				},
+			},
+		}
+
+		_, err := index.First()
+
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("handles canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		calls := 0
+		index := Index{
+			ctx: ctx,
+			cfg: cfg,
+			client: &apiMock{
+				GetFirstFunc: func(context.Context, *GetFirstRequest, ...grpc.CallOption) (*GetFirstResponse, error) {
+					calls++
+					cancel()
+					return nil, unavailable
+				},
+			},
+		}
+
+		_, err := index.First()
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("sets deadline for each attempt", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := cfg
+		cfg.Timeout = time.Minute
+
+		var deadlines []time.Time
+		index := Index{
+			ctx: context.Background(),
+			cfg: cfg,
+			client: &apiMock{
+				GetFirstFunc: func(ctx context.Context, _ *GetFirstRequest, _ ...grpc.CallOption) (*GetFirstResponse, error) {
+					deadline, ok := ctx.Deadline()
+					assert.True(t, ok)
+					deadlines = append(deadlines, deadline)
+					if len(deadlines) == 1 {
+						return nil, unavailable
+					}
+					return &GetFirstResponse{Height: mocks.GenericHeight}, nil
+				},
+			},
+		}
+
+		_, err := index.First()
+
+		require.NoError(t, err)
+		require.Len(t, deadlines, 2)
+		assert.True(t, deadlines[1].After(deadlines[0]))
+	})
 }
 
 func TestIndex_First(t *testing.T) {
@@ -114,6 +313,44 @@ func TestIndex_Last(t *testing.T) {
 	})
 }
 
+func TestIndex_Floor(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetFloorFunc: func(_ context.Context, in *GetFloorRequest, _ ...grpc.CallOption) (*GetFloorResponse, error) {
+					assert.NotNil(t, in)
+
+					return &GetFloorResponse{
+						Height: mocks.GenericHeight,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.Floor()
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, got)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetFloorFunc: func(context.Context, *GetFloorRequest, ...grpc.CallOption) (*GetFloorResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Floor()
+		assert.Error(t, err)
+	})
+}
+
 func TestIndex_Header(t *testing.T) {
 	// We need to use the proper encoding to support nanoseconds
 	// and timezones in timestamps.
@@ -195,208 +432,1239 @@ func TestIndex_Header(t *testing.T) {
 	})
 }
 
-func TestIndex_Commit(t *testing.T) {
-	commit := mocks.GenericCommit(0)
+func TestIndex_HeaderByBlockID(t *testing.T) {
+	// We need to use the proper encoding to support nanoseconds
+	// and timezones in timestamps.
+	options := cbor.CanonicalEncOptions()
+	options.Time = cbor.TimeRFC3339Nano
+	encoder, err := options.EncMode()
+	require.NoError(t, err)
+
+	header := mocks.GenericHeader
+	blockID := header.ID()
+	data, err := encoder.Marshal(header)
+	require.NoError(t, err)
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
 		index := Index{
+			codec: codec,
 			client: &apiMock{
-				GetCommitFunc: func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
-					assert.Equal(t, mocks.GenericHeight, in.Height)
+				GetHeaderByBlockIDFunc: func(_ context.Context, in *GetHeaderByBlockIDRequest, _ ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error) {
+					assert.Equal(t, blockID[:], in.BlockID)
 
-					return &GetCommitResponse{
-						Height: mocks.GenericHeight,
-						Commit: commit[:],
+					return &GetHeaderByBlockIDResponse{
+						BlockID: in.BlockID,
+						Height:  header.Height,
+						Data:    data,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Commit(mocks.GenericHeight)
+		got, err := index.HeaderByBlockID(blockID)
 
 		require.NoError(t, err)
-		assert.Equal(t, commit, got)
+		assert.Equal(t, header, got)
 	})
 
-	codec := mocks.BaselineCodec(t)
-	codec.UnmarshalFunc = cbor.Unmarshal
-
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
 		index := Index{
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetCommitFunc: func(context.Context, *GetCommitRequest, ...grpc.CallOption) (*GetCommitResponse, error) {
+				GetHeaderByBlockIDFunc: func(context.Context, *GetHeaderByBlockIDRequest, ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Commit(mocks.GenericHeight)
+		_, err := index.HeaderByBlockID(blockID)
 
 		assert.Error(t, err)
 	})
 
-	t.Run("handles invalid indexed data", func(t *testing.T) {
+	t.Run("handles decoding failures", func(t *testing.T) {
 		t.Parallel()
 
 		codec := mocks.BaselineCodec(t)
 		codec.UnmarshalFunc = cbor.Unmarshal
 
 		index := Index{
+			codec: codec,
 			client: &apiMock{
-				GetCommitFunc: func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
-					assert.Equal(t, mocks.GenericHeight, in.Height)
-
-					return &GetCommitResponse{
-						Height: mocks.GenericHeight,
-						Commit: []byte(`not a commit`),
+				GetHeaderByBlockIDFunc: func(_ context.Context, in *GetHeaderByBlockIDRequest, _ ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error) {
+					return &GetHeaderByBlockIDResponse{
+						BlockID: in.BlockID,
+						Height:  header.Height,
+						Data:    []byte(`invalid data`),
 					}, nil
 				},
 			},
 		}
 
-		_, err := index.Commit(mocks.GenericHeight)
+		_, err := index.HeaderByBlockID(blockID)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Values(t *testing.T) {
-	paths := mocks.GenericLedgerPaths(6)
-	values := mocks.GenericLedgerValues(6)
+func TestIndex_Commit(t *testing.T) {
+	commit := mocks.GenericCommit(0)
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
 		index := Index{
 			client: &apiMock{
-				GetRegisterValuesFunc: func(_ context.Context, in *GetRegisterValuesRequest, _ ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
-					require.Len(t, in.Paths, 6)
-					codec := mocks.BaselineCodec(t)
-					codec.UnmarshalFunc = cbor.Unmarshal
-
-					assert.Equal(t, convert.PathsToBytes(paths), in.Paths)
-					assert.Equal(t, in.Height, mocks.GenericHeight)
+				GetCommitFunc: func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Height)
 
-					return &GetRegisterValuesResponse{
+					return &GetCommitResponse{
 						Height: mocks.GenericHeight,
-						Paths:  convert.PathsToBytes(paths),
-						Values: convert.ValuesToBytes(values),
+						Commit: commit[:],
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Values(mocks.GenericHeight, paths)
+		got, err := index.Commit(mocks.GenericHeight)
 
 		require.NoError(t, err)
-		assert.Equal(t, values, got)
+		assert.Equal(t, commit, got)
 	})
 
+	codec := mocks.BaselineCodec(t)
+	codec.UnmarshalFunc = cbor.Unmarshal
+
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
+		index := Index{
+			client: &apiMock{
+				GetCommitFunc: func(context.Context, *GetCommitRequest, ...grpc.CallOption) (*GetCommitResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Commit(mocks.GenericHeight)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
 		codec := mocks.BaselineCodec(t)
 		codec.UnmarshalFunc = cbor.Unmarshal
+
 		index := Index{
 			client: &apiMock{
-				GetRegisterValuesFunc: func(context.Context, *GetRegisterValuesRequest, ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
-					return nil, mocks.GenericError
+				GetCommitFunc: func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Height)
+
+					return &GetCommitResponse{
+						Height: mocks.GenericHeight,
+						Commit: []byte(`not a commit`),
+					}, nil
 				},
 			},
 		}
 
-		_, err := index.Values(mocks.GenericHeight, paths)
+		_, err := index.Commit(mocks.GenericHeight)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Height(t *testing.T) {
-	header := mocks.GenericHeader
-	blockID := header.ID()
+func TestIndex_Headers(t *testing.T) {
+	headers := []*flow.Header{
+		{Height: mocks.GenericHeight},
+		{Height: mocks.GenericHeight + 1},
+	}
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
+		var responses []*ListHeadersResponse
+		for _, header := range headers {
+			data, err := cbor.Marshal(header)
+			require.NoError(t, err)
+			responses = append(responses, &ListHeadersResponse{Height: header.Height, Data: data})
+		}
+
 		codec := mocks.BaselineCodec(t)
 		codec.UnmarshalFunc = cbor.Unmarshal
 
 		index := Index{
+			codec: codec,
 			client: &apiMock{
-				GetHeightForBlockFunc: func(_ context.Context, in *GetHeightForBlockRequest, _ ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
-					assert.Equal(t, blockID[:], in.BlockID)
-
-					return &GetHeightForBlockResponse{
-						BlockID: blockID[:],
-						Height:  header.Height,
-					}, nil
+				ListHeadersFunc: func(_ context.Context, in *ListHeadersRequest, _ ...grpc.CallOption) (API_ListHeadersClient, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+1, in.End)
+
+					stream := &listHeadersClientMock{
+						RecvFunc: func() (*ListHeadersResponse, error) {
+							if len(responses) == 0 {
+								return nil, io.EOF
+							}
+							res := responses[0]
+							responses = responses[1:]
+							return res, nil
+						},
+					}
+
+					return stream, nil
 				},
 			},
 		}
 
-		got, err := index.HeightForBlock(blockID)
+		got, err := index.Headers(mocks.GenericHeight, mocks.GenericHeight+1)
 
 		require.NoError(t, err)
-		assert.Equal(t, header.Height, got)
+		assert.Equal(t, headers, got)
 	})
 
-	codec := mocks.BaselineCodec(t)
-	codec.UnmarshalFunc = cbor.Unmarshal
-
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
 		index := Index{
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetHeightForBlockFunc: func(context.Context, *GetHeightForBlockRequest, ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
+				ListHeadersFunc: func(context.Context, *ListHeadersRequest, ...grpc.CallOption) (API_ListHeadersClient, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.HeightForBlock(blockID)
+		_, err := index.Headers(mocks.GenericHeight, mocks.GenericHeight+1)
 
 		assert.Error(t, err)
 	})
-}
-
-func TestIndex_Collection(t *testing.T) {
-	collection := mocks.GenericCollection(0)
-	collID := collection.ID()
-	data, err := cbor.Marshal(collection)
-	require.NoError(t, err)
 
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("handles stream failures", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				ListHeadersFunc: func(context.Context, *ListHeadersRequest, ...grpc.CallOption) (API_ListHeadersClient, error) {
+					stream := &listHeadersClientMock{
+						RecvFunc: func() (*ListHeadersResponse, error) {
+							return nil, mocks.GenericError
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.Headers(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				ListHeadersFunc: func(context.Context, *ListHeadersRequest, ...grpc.CallOption) (API_ListHeadersClient, error) {
+					stream := &listHeadersClientMock{
+						RecvFunc: func() (*ListHeadersResponse, error) {
+							return &ListHeadersResponse{Height: mocks.GenericHeight, Data: []byte(`invalid data`)}, nil
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.Headers(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Commits(t *testing.T) {
+	commits := mocks.GenericCommits(2)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var responses []*ListCommitsResponse
+		for i, commit := range commits {
+			responses = append(responses, &ListCommitsResponse{Height: mocks.GenericHeight + uint64(i), Commit: mocks.ByteSlice(commit)})
+		}
+
+		index := Index{
+			client: &apiMock{
+				ListCommitsFunc: func(_ context.Context, in *ListCommitsRequest, _ ...grpc.CallOption) (API_ListCommitsClient, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+1, in.End)
+
+					stream := &listCommitsClientMock{
+						RecvFunc: func() (*ListCommitsResponse, error) {
+							if len(responses) == 0 {
+								return nil, io.EOF
+							}
+							res := responses[0]
+							responses = responses[1:]
+							return res, nil
+						},
+					}
+
+					return stream, nil
+				},
+			},
+		}
+
+		got, err := index.Commits(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		require.NoError(t, err)
+		want := map[uint64]flow.StateCommitment{
+			mocks.GenericHeight:     commits[0],
+			mocks.GenericHeight + 1: commits[1],
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				ListCommitsFunc: func(context.Context, *ListCommitsRequest, ...grpc.CallOption) (API_ListCommitsClient, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Commits(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles stream failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				ListCommitsFunc: func(context.Context, *ListCommitsRequest, ...grpc.CallOption) (API_ListCommitsClient, error) {
+					stream := &listCommitsClientMock{
+						RecvFunc: func() (*ListCommitsResponse, error) {
+							return nil, mocks.GenericError
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.Commits(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				ListCommitsFunc: func(context.Context, *ListCommitsRequest, ...grpc.CallOption) (API_ListCommitsClient, error) {
+					stream := &listCommitsClientMock{
+						RecvFunc: func() (*ListCommitsResponse, error) {
+							return &ListCommitsResponse{Height: mocks.GenericHeight, Commit: []byte(`invalid data`)}, nil
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.Commits(mocks.GenericHeight, mocks.GenericHeight+1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Values(t *testing.T) {
+	paths := mocks.GenericLedgerPaths(6)
+	values := mocks.GenericLedgerValues(6)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetRegisterValuesFunc: func(_ context.Context, in *GetRegisterValuesRequest, _ ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
+					require.Len(t, in.Paths, 6)
+					codec := mocks.BaselineCodec(t)
+					codec.UnmarshalFunc = cbor.Unmarshal
+
+					assert.Equal(t, convert.PathsToBytes(paths), in.Paths)
+					assert.Equal(t, in.Height, mocks.GenericHeight)
+
+					return &GetRegisterValuesResponse{
+						Height: mocks.GenericHeight,
+						Paths:  convert.PathsToBytes(paths),
+						Values: convert.ValuesToBytes(values),
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.Values(mocks.GenericHeight, paths)
+
+		require.NoError(t, err)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+		index := Index{
+			client: &apiMock{
+				GetRegisterValuesFunc: func(context.Context, *GetRegisterValuesRequest, ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_ValuesVerified(t *testing.T) {
+	paths := mocks.GenericLedgerPaths(6)
+	payloads := mocks.GenericLedgerPayloads(6)
+
+	// We only insert the first five registers into the trie, so that the last
+	// path gets a non-inclusion proof.
+	registers := make([]ledger.Path, 5)
+	copy(registers, paths)
+	values := make([]ledger.Value, 0, len(paths))
+	inserted := make([]ledger.Payload, 0, len(registers))
+	for _, payload := range payloads[:5] {
+		values = append(values, payload.Value)
+		inserted = append(inserted, *payload)
+	}
+	values = append(values, ledger.Value{})
+
+	tree, err := trie.NewEmptyTrie().Mutate(registers, inserted)
+	require.NoError(t, err)
+	commit := flow.StateCommitment(tree.RootHash())
+	proofs := tree.Prove(paths)
+
+	client := func() *apiMock {
+		return &apiMock{
+			GetRegisterValuesFunc: func(_ context.Context, in *GetRegisterValuesRequest, _ ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
+				return &GetRegisterValuesResponse{
+					Height: in.Height,
+					Paths:  in.Paths,
+					Values: convert.ValuesToBytes(values),
+				}, nil
+			},
+			GetCommitFunc: func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
+				return &GetCommitResponse{
+					Height: in.Height,
+					Commit: commit[:],
+				}, nil
+			},
+			GetRegisterProofsFunc: func(_ context.Context, in *GetRegisterProofsRequest, _ ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+				return &GetRegisterProofsResponse{
+					Height: in.Height,
+					Paths:  in.Paths,
+					Proofs: convert.ProofsToBytes(proofs),
+				}, nil
+			},
+		}
+	}
+
+	codec := mocks.BaselineCodec(t)
+	codec.UnmarshalFunc = cbor.Unmarshal
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := IndexFromAPI(client(), codec, WithVerification())
+
+		got, err := index.Values(mocks.GenericHeight, paths)
+
+		require.NoError(t, err)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("nominal case with trusted commits", func(t *testing.T) {
+		t.Parallel()
+
+		commits := mocks.BaselineReader(t)
+		commits.CommitFunc = func(height uint64) (flow.StateCommitment, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			return commit, nil
+		}
+
+		index := IndexFromAPI(client(), codec, WithVerification(), WithTrustedCommits(commits))
+
+		got, err := index.Values(mocks.GenericHeight, paths)
+
+		require.NoError(t, err)
+		assert.Equal(t, values, got)
+	})
+
+	t.Run("handles commit mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		api := client()
+		api.GetCommitFunc = func(_ context.Context, in *GetCommitRequest, _ ...grpc.CallOption) (*GetCommitResponse, error) {
+			commit := mocks.GenericCommit(0)
+			return &GetCommitResponse{
+				Height: in.Height,
+				Commit: commit[:],
+			}, nil
+		}
+
+		index := IndexFromAPI(api, codec, WithVerification())
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.ErrorIs(t, err, dps.ErrInvalidProof)
+	})
+
+	t.Run("handles value mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		api := client()
+		api.GetRegisterValuesFunc = func(_ context.Context, in *GetRegisterValuesRequest, _ ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
+			return &GetRegisterValuesResponse{
+				Height: in.Height,
+				Paths:  in.Paths,
+				Values: convert.ValuesToBytes(mocks.GenericLedgerValues(6)),
+			}, nil
+		}
+
+		index := IndexFromAPI(api, codec, WithVerification())
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.ErrorIs(t, err, dps.ErrInvalidProof)
+	})
+
+	t.Run("handles missing proofs", func(t *testing.T) {
+		t.Parallel()
+
+		api := client()
+		api.GetRegisterProofsFunc = func(_ context.Context, in *GetRegisterProofsRequest, _ ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+			return &GetRegisterProofsResponse{
+				Height: in.Height,
+				Paths:  in.Paths,
+				Proofs: convert.ProofsToBytes(proofs)[:3],
+			}, nil
+		}
+
+		index := IndexFromAPI(api, codec, WithVerification())
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.ErrorIs(t, err, dps.ErrInvalidProof)
+	})
+
+	t.Run("handles untrusted commit with matching proofs", func(t *testing.T) {
+		t.Parallel()
+
+		// The API forges a consistent commit and proofs, which only the
+		// trusted commit can reveal.
+		commits := mocks.BaselineReader(t)
+		commits.CommitFunc = func(uint64) (flow.StateCommitment, error) {
+			return mocks.GenericCommit(0), nil
+		}
+
+		index := IndexFromAPI(client(), codec, WithVerification(), WithTrustedCommits(commits))
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.ErrorIs(t, err, dps.ErrUntrusted)
+	})
+
+	t.Run("handles trusted commit failure", func(t *testing.T) {
+		t.Parallel()
+
+		commits := mocks.BaselineReader(t)
+		commits.CommitFunc = func(uint64) (flow.StateCommitment, error) {
+			return flow.DummyStateCommitment, mocks.GenericError
+		}
+
+		index := IndexFromAPI(client(), codec, WithVerification(), WithTrustedCommits(commits))
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles proof retrieval failure", func(t *testing.T) {
+		t.Parallel()
+
+		api := client()
+		api.GetRegisterProofsFunc = func(context.Context, *GetRegisterProofsRequest, ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+			return nil, mocks.GenericError
+		}
+
+		index := IndexFromAPI(api, codec, WithVerification())
+
+		_, err := index.Values(mocks.GenericHeight, paths)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, dps.ErrInvalidProof)
+	})
+}
+
+func TestIndex_Proofs(t *testing.T) {
+	paths := mocks.GenericLedgerPaths(6)
+	proofs := mocks.GenericTrieBatchProof(6)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetRegisterProofsFunc: func(_ context.Context, in *GetRegisterProofsRequest, _ ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+					assert.Equal(t, convert.PathsToBytes(paths), in.Paths)
+					assert.Equal(t, in.Height, mocks.GenericHeight)
+
+					return &GetRegisterProofsResponse{
+						Height: mocks.GenericHeight,
+						Paths:  convert.PathsToBytes(paths),
+						Proofs: convert.ProofsToBytes(proofs),
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.Proofs(mocks.GenericHeight, paths)
+
+		require.NoError(t, err)
+		assert.True(t, proofs.Equals(got))
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetRegisterProofsFunc: func(context.Context, *GetRegisterProofsRequest, ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Proofs(mocks.GenericHeight, paths)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid proofs", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetRegisterProofsFunc: func(context.Context, *GetRegisterProofsRequest, ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+					return &GetRegisterProofsResponse{
+						Height: mocks.GenericHeight,
+						Paths:  convert.PathsToBytes(paths[:1]),
+						Proofs: [][]byte{mocks.GenericBytes},
+					}, nil
+				},
+			},
+		}
+
+		_, err := index.Proofs(mocks.GenericHeight, paths)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Height(t *testing.T) {
+	header := mocks.GenericHeader
+	blockID := header.ID()
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForBlockFunc: func(_ context.Context, in *GetHeightForBlockRequest, _ ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
+					assert.Equal(t, blockID[:], in.BlockID)
+
+					return &GetHeightForBlockResponse{
+						BlockID: blockID[:],
+						Height:  header.Height,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.HeightForBlock(blockID)
+
+		require.NoError(t, err)
+		assert.Equal(t, header.Height, got)
+	})
+
+	codec := mocks.BaselineCodec(t)
+	codec.UnmarshalFunc = cbor.Unmarshal
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForBlockFunc: func(context.Context, *GetHeightForBlockRequest, ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.HeightForBlock(blockID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_HeightForTime(t *testing.T) {
+	timestamp := mocks.GenericHeader.Timestamp
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForTimestampFunc: func(_ context.Context, in *GetHeightForTimestampRequest, _ ...grpc.CallOption) (*GetHeightForTimestampResponse, error) {
+					assert.Equal(t, timestamp.UnixNano(), in.Timestamp)
+
+					return &GetHeightForTimestampResponse{
+						Timestamp: in.Timestamp,
+						Height:    mocks.GenericHeight,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.HeightForTime(timestamp)
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForTimestampFunc: func(context.Context, *GetHeightForTimestampRequest, ...grpc.CallOption) (*GetHeightForTimestampResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.HeightForTime(timestamp)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_RegisterHistory(t *testing.T) {
+	path := mocks.GenericLedgerPath(0)
+	payloads := map[uint64]*ledger.Payload{
+		mocks.GenericHeight:     mocks.GenericLedgerPayload(0),
+		mocks.GenericHeight + 2: mocks.GenericLedgerPayload(1),
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var responses []*GetRegisterHistoryResponse
+		for _, height := range []uint64{mocks.GenericHeight, mocks.GenericHeight + 2} {
+			data, err := cbor.Marshal(payloads[height])
+			require.NoError(t, err)
+			responses = append(responses, &GetRegisterHistoryResponse{Height: height, Path: path[:], Data: data})
+		}
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetRegisterHistoryFunc: func(_ context.Context, in *GetRegisterHistoryRequest, _ ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+					assert.Equal(t, path[:], in.Path)
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+2, in.End)
+
+					stream := &getRegisterHistoryClientMock{
+						RecvFunc: func() (*GetRegisterHistoryResponse, error) {
+							if len(responses) == 0 {
+								return nil, io.EOF
+							}
+							res := responses[0]
+							responses = responses[1:]
+							return res, nil
+						},
+					}
+
+					return stream, nil
+				},
+			},
+		}
+
+		got, err := index.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+2)
+
+		require.NoError(t, err)
+		assert.Equal(t, payloads, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				GetRegisterHistoryFunc: func(context.Context, *GetRegisterHistoryRequest, ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+2)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles stream failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				GetRegisterHistoryFunc: func(context.Context, *GetRegisterHistoryRequest, ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+					stream := &getRegisterHistoryClientMock{
+						RecvFunc: func() (*GetRegisterHistoryResponse, error) {
+							return nil, mocks.GenericError
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+2)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetRegisterHistoryFunc: func(context.Context, *GetRegisterHistoryRequest, ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+					stream := &getRegisterHistoryClientMock{
+						RecvFunc: func() (*GetRegisterHistoryResponse, error) {
+							return &GetRegisterHistoryResponse{Height: mocks.GenericHeight, Data: []byte(`invalid data`)}, nil
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+2)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_StateDiff(t *testing.T) {
+	changes := mocks.GenericChanges(2)
+	changes[0].Before = nil
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var responses []*GetStateDiffResponse
+		for i := range changes {
+			var before []byte
+			if changes[i].Before != nil {
+				var err error
+				before, err = cbor.Marshal(changes[i].Before)
+				require.NoError(t, err)
+			}
+			after, err := cbor.Marshal(changes[i].After)
+			require.NoError(t, err)
+			responses = append(responses, &GetStateDiffResponse{Path: changes[i].Path[:], Before: before, After: after})
+		}
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetStateDiffFunc: func(_ context.Context, in *GetStateDiffRequest, _ ...grpc.CallOption) (API_GetStateDiffClient, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+2, in.End)
+
+					stream := &getStateDiffClientMock{
+						RecvFunc: func() (*GetStateDiffResponse, error) {
+							if len(responses) == 0 {
+								return nil, io.EOF
+							}
+							res := responses[0]
+							responses = responses[1:]
+							return res, nil
+						},
+					}
+
+					return stream, nil
+				},
+			},
+		}
+
+		var got []dps.Change
+		err := index.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(change dps.Change) error {
+			got = append(got, change)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, changes, got)
+	})
+
+	t.Run("nominal case with retried stream", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := DefaultIndexConfig
+		cfg.Backoff = time.Millisecond
+		cfg.MaxBackoff = 2 * time.Millisecond
+
+		var responses []*GetStateDiffResponse
+		for i := range changes {
+			after, err := cbor.Marshal(changes[i].After)
+			require.NoError(t, err)
+			responses = append(responses, &GetStateDiffResponse{Path: changes[i].Path[:], After: after})
+		}
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		// The first stream fails after the first change, and the second one
+		// starts over from the beginning.
+		calls := 0
+		index := Index{
+			ctx:   context.Background(),
+			cfg:   cfg,
+			codec: codec,
+			client: &apiMock{
+				GetStateDiffFunc: func(context.Context, *GetStateDiffRequest, ...grpc.CallOption) (API_GetStateDiffClient, error) {
+					calls++
+					sent := 0
+					stream := &getStateDiffClientMock{
+						RecvFunc: func() (*GetStateDiffResponse, error) {
+							if calls == 1 && sent == 1 {
+								return nil, status.Error(codes.Unavailable, "unavailable")
+							}
+							if sent == len(responses) {
+								return nil, io.EOF
+							}
+							sent++
+							return responses[sent-1], nil
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		var got []ledger.Path
+		err := index.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(change dps.Change) error {
+			got = append(got, change.Path)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []ledger.Path{changes[0].Path, changes[1].Path}, got)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				GetStateDiffFunc: func(context.Context, *GetStateDiffRequest, ...grpc.CallOption) (API_GetStateDiffClient, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		err := index.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(dps.Change) error {
+			return nil
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles stream failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				GetStateDiffFunc: func(context.Context, *GetStateDiffRequest, ...grpc.CallOption) (API_GetStateDiffClient, error) {
+					stream := &getStateDiffClientMock{
+						RecvFunc: func() (*GetStateDiffResponse, error) {
+							return nil, mocks.GenericError
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		err := index.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(dps.Change) error {
+			return nil
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		path := mocks.GenericLedgerPath(0)
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetStateDiffFunc: func(context.Context, *GetStateDiffRequest, ...grpc.CallOption) (API_GetStateDiffClient, error) {
+					stream := &getStateDiffClientMock{
+						RecvFunc: func() (*GetStateDiffResponse, error) {
+							return &GetStateDiffResponse{Path: path[:], After: []byte(`invalid data`)}, nil
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		err := index.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(dps.Change) error {
+			return nil
+		})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Collection(t *testing.T) {
+	collection := mocks.GenericCollection(0)
+	collID := collection.ID()
+	data, err := cbor.Marshal(collection)
+	require.NoError(t, err)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetCollectionFunc: func(_ context.Context, in *GetCollectionRequest, _ ...grpc.CallOption) (*GetCollectionResponse, error) {
+					assert.Equal(t, collID[:], in.CollectionID)
+
+					return &GetCollectionResponse{
+						CollectionID: collID[:],
+						Data:         data,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.Collection(collID)
+
+		require.NoError(t, err)
+		assert.Equal(t, collection, got)
+	})
+
+	t.Run("handles index failure on GetCollection", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetCollectionFunc: func(context.Context, *GetCollectionRequest, ...grpc.CallOption) (*GetCollectionResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Collection(collID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_ListCollectionsForHeight(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		collIDs := mocks.GenericCollectionIDs(4)
+		data := make([][]byte, 0, len(collIDs))
+		for _, collID := range collIDs {
+			collID := collID
+			data = append(data, collID[:])
+		}
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				ListCollectionsForHeightFunc: func(_ context.Context, in *ListCollectionsForHeightRequest, _ ...grpc.CallOption) (*ListCollectionsForHeightResponse, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Height)
+
+					return &ListCollectionsForHeightResponse{
+						Height:        in.Height,
+						CollectionIDs: data,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.CollectionsByHeight(mocks.GenericHeight)
+
+		require.NoError(t, err)
+		assert.Equal(t, collIDs, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				ListCollectionsForHeightFunc: func(context.Context, *ListCollectionsForHeightRequest, ...grpc.CallOption) (*ListCollectionsForHeightResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.CollectionsByHeight(mocks.GenericHeight)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_HeightForCollection(t *testing.T) {
+	collID := mocks.GenericCollection(0).ID()
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForCollectionFunc: func(_ context.Context, in *GetHeightForCollectionRequest, _ ...grpc.CallOption) (*GetHeightForCollectionResponse, error) {
+					assert.Equal(t, collID[:], in.CollectionID)
+
+					return &GetHeightForCollectionResponse{
+						CollectionID: in.CollectionID,
+						Height:       mocks.GenericHeight,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.HeightForCollection(collID)
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetHeightForCollectionFunc: func(context.Context, *GetHeightForCollectionRequest, ...grpc.CallOption) (*GetHeightForCollectionResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.HeightForCollection(collID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Guarantee(t *testing.T) {
+	guarantee := mocks.GenericGuarantee(0)
+	collID := guarantee.ID()
+	testGuaranteeBytes, err := cbor.Marshal(guarantee)
+	require.NoError(t, err)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
 
 		index := Index{
 			codec: codec,
 			client: &apiMock{
-				GetCollectionFunc: func(_ context.Context, in *GetCollectionRequest, _ ...grpc.CallOption) (*GetCollectionResponse, error) {
+				GetGuaranteeFunc: func(_ context.Context, in *GetGuaranteeRequest, _ ...grpc.CallOption) (*GetGuaranteeResponse, error) {
 					assert.Equal(t, collID[:], in.CollectionID)
 
-					return &GetCollectionResponse{
+					return &GetGuaranteeResponse{
 						CollectionID: collID[:],
-						Data:         data,
+						Data:         testGuaranteeBytes,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Collection(collID)
+		got, err := index.Guarantee(collID)
 
 		require.NoError(t, err)
-		assert.Equal(t, collection, got)
+		assert.Equal(t, guarantee, got)
 	})
 
-	t.Run("handles index failure on GetCollection", func(t *testing.T) {
+	t.Run("handles index failure on GetGuarantee", func(t *testing.T) {
 		t.Parallel()
 
 		codec := mocks.BaselineCodec(t)
@@ -405,47 +1673,250 @@ func TestIndex_Collection(t *testing.T) {
 		index := Index{
 			codec: codec,
 			client: &apiMock{
-				GetCollectionFunc: func(context.Context, *GetCollectionRequest, ...grpc.CallOption) (*GetCollectionResponse, error) {
+				GetGuaranteeFunc: func(context.Context, *GetGuaranteeRequest, ...grpc.CallOption) (*GetGuaranteeResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Collection(collID)
+		_, err := index.Guarantee(guarantee.ID())
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_ListCollectionsForHeight(t *testing.T) {
+func TestIndex_Transaction(t *testing.T) {
+	tx := mocks.GenericTransaction(0)
+	txID := tx.ID()
+
+	data, err := cbor.Marshal(tx)
+	require.NoError(t, err)
+
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		collIDs := mocks.GenericCollectionIDs(4)
-		data := make([][]byte, 0, len(collIDs))
-		for _, collID := range collIDs {
-			collID := collID
-			data = append(data, collID[:])
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetTransactionFunc: func(_ context.Context, in *GetTransactionRequest, _ ...grpc.CallOption) (*GetTransactionResponse, error) {
+					assert.Equal(t, txID[:], in.TransactionID)
+
+					return &GetTransactionResponse{
+						TransactionID: txID[:],
+						Data:          data,
+					}, nil
+				},
+			},
 		}
 
+		got, err := index.Transaction(txID)
+
+		require.NoError(t, err)
+		assert.Equal(t, tx, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
 		index := Index{
-			codec: mocks.BaselineCodec(t),
+			codec: codec,
 			client: &apiMock{
-				ListCollectionsForHeightFunc: func(_ context.Context, in *ListCollectionsForHeightRequest, _ ...grpc.CallOption) (*ListCollectionsForHeightResponse, error) {
+				GetTransactionFunc: func(context.Context, *GetTransactionRequest, ...grpc.CallOption) (*GetTransactionResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Transaction(tx.ID())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Result(t *testing.T) {
+	result := mocks.GenericResult(0)
+	txID := result.TransactionID
+
+	data, err := cbor.Marshal(result)
+	require.NoError(t, err)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetResultFunc: func(_ context.Context, in *GetResultRequest, _ ...grpc.CallOption) (*GetResultResponse, error) {
+					assert.Equal(t, txID[:], in.TransactionID)
+
+					return &GetResultResponse{
+						TransactionID: txID[:],
+						Data:          data,
+					}, nil
+				},
+			},
+		}
+
+		got, err := index.Result(txID)
+
+		require.NoError(t, err)
+		assert.Equal(t, result, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			client: &apiMock{
+				GetResultFunc: func(context.Context, *GetResultRequest, ...grpc.CallOption) (*GetResultResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Result(result.ID())
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_Events(t *testing.T) {
+	events := mocks.GenericEvents(4)
+	types := mocks.GenericEventTypes(2)
+
+	data, err := cbor.Marshal(events)
+	require.NoError(t, err)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetEventsFunc: func(_ context.Context, in *GetEventsRequest, _ ...grpc.CallOption) (*GetEventsResponse, error) {
 					assert.Equal(t, mocks.GenericHeight, in.Height)
+					assert.Equal(t, convert.TypesToStrings(types), in.Types)
 
-					return &ListCollectionsForHeightResponse{
-						Height:        in.Height,
-						CollectionIDs: data,
+					return &GetEventsResponse{
+						Height: mocks.GenericHeight,
+						Types:  convert.TypesToStrings(types),
+						Data:   data,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.CollectionsByHeight(mocks.GenericHeight)
+		got, err := index.Events(mocks.GenericHeight, types...)
 
 		require.NoError(t, err)
-		assert.Equal(t, collIDs, got)
+		assert.Equal(t, events, got)
+	})
+
+	t.Run("handles index failures", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetEventsFunc: func(context.Context, *GetEventsRequest, ...grpc.CallOption) (*GetEventsResponse, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.Events(mocks.GenericHeight, types...)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetEventsFunc: func(context.Context, *GetEventsRequest, ...grpc.CallOption) (*GetEventsResponse, error) {
+					return &GetEventsResponse{
+						Height: mocks.GenericHeight,
+						Types:  convert.TypesToStrings(types),
+						Data:   []byte(`invalid data`),
+					}, nil
+				},
+			},
+		}
+
+		_, err := index.Events(mocks.GenericHeight, types...)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIndex_EventsRange(t *testing.T) {
+	events := map[uint64][]flow.Event{
+		mocks.GenericHeight:     mocks.GenericEvents(2),
+		mocks.GenericHeight + 2: mocks.GenericEvents(4),
+	}
+	types := mocks.GenericEventTypes(2)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		var responses []*GetEventsRangeResponse
+		for _, height := range []uint64{mocks.GenericHeight, mocks.GenericHeight + 2} {
+			data, err := cbor.Marshal(events[height])
+			require.NoError(t, err)
+			responses = append(responses, &GetEventsRangeResponse{Height: height, Types: convert.TypesToStrings(types), Data: data})
+		}
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetEventsRangeFunc: func(_ context.Context, in *GetEventsRangeRequest, _ ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+2, in.End)
+					assert.Equal(t, convert.TypesToStrings(types), in.Types)
+
+					stream := &getEventsRangeClientMock{
+						RecvFunc: func() (*GetEventsRangeResponse, error) {
+							if len(responses) == 0 {
+								return nil, io.EOF
+							}
+							res := responses[0]
+							responses = responses[1:]
+							return res, nil
+						},
+					}
+
+					return stream, nil
+				},
+			},
+		}
+
+		got, err := index.EventsRange(mocks.GenericHeight, mocks.GenericHeight+2, types...)
+
+		require.NoError(t, err)
+		assert.Equal(t, events, got)
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
@@ -454,286 +1925,340 @@ func TestIndex_ListCollectionsForHeight(t *testing.T) {
 		index := Index{
 			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				ListCollectionsForHeightFunc: func(context.Context, *ListCollectionsForHeightRequest, ...grpc.CallOption) (*ListCollectionsForHeightResponse, error) {
-					return nil, mocks.GenericError
+				GetEventsRangeFunc: func(context.Context, *GetEventsRangeRequest, ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+					return nil, mocks.GenericError
+				},
+			},
+		}
+
+		_, err := index.EventsRange(mocks.GenericHeight, mocks.GenericHeight+2, types...)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles stream failures", func(t *testing.T) {
+		t.Parallel()
+
+		index := Index{
+			codec: mocks.BaselineCodec(t),
+			client: &apiMock{
+				GetEventsRangeFunc: func(context.Context, *GetEventsRangeRequest, ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+					stream := &getEventsRangeClientMock{
+						RecvFunc: func() (*GetEventsRangeResponse, error) {
+							return nil, mocks.GenericError
+						},
+					}
+					return stream, nil
+				},
+			},
+		}
+
+		_, err := index.EventsRange(mocks.GenericHeight, mocks.GenericHeight+2, types...)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles invalid indexed data", func(t *testing.T) {
+		t.Parallel()
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = cbor.Unmarshal
+
+		index := Index{
+			codec: codec,
+			client: &apiMock{
+				GetEventsRangeFunc: func(context.Context, *GetEventsRangeRequest, ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+					stream := &getEventsRangeClientMock{
+						RecvFunc: func() (*GetEventsRangeResponse, error) {
+							return &GetEventsRangeResponse{Height: mocks.GenericHeight, Data: []byte(`invalid data`)}, nil
+						},
+					}
+					return stream, nil
 				},
 			},
 		}
 
-		_, err := index.CollectionsByHeight(mocks.GenericHeight)
+		_, err := index.EventsRange(mocks.GenericHeight, mocks.GenericHeight+2, types...)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Guarantee(t *testing.T) {
-	guarantee := mocks.GenericGuarantee(0)
-	collID := guarantee.ID()
-	testGuaranteeBytes, err := cbor.Marshal(guarantee)
-	require.NoError(t, err)
+func TestIndex_EventHeights(t *testing.T) {
+	typ := mocks.GenericEventType(0)
+	heights := []uint64{mocks.GenericHeight, mocks.GenericHeight + 2}
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
-
 		index := Index{
-			codec: codec,
 			client: &apiMock{
-				GetGuaranteeFunc: func(_ context.Context, in *GetGuaranteeRequest, _ ...grpc.CallOption) (*GetGuaranteeResponse, error) {
-					assert.Equal(t, collID[:], in.CollectionID)
-
-					return &GetGuaranteeResponse{
-						CollectionID: collID[:],
-						Data:         testGuaranteeBytes,
+				ListHeightsForEventFunc: func(_ context.Context, in *ListHeightsForEventRequest, _ ...grpc.CallOption) (*ListHeightsForEventResponse, error) {
+					assert.Equal(t, string(typ), in.Type)
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+2, in.End)
+
+					return &ListHeightsForEventResponse{
+						Type:    in.Type,
+						Start:   in.Start,
+						End:     in.End,
+						Heights: heights,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Guarantee(collID)
+		got, err := index.EventHeights(typ, mocks.GenericHeight, mocks.GenericHeight+2)
 
 		require.NoError(t, err)
-		assert.Equal(t, guarantee, got)
+		assert.Equal(t, heights, got)
 	})
 
-	t.Run("handles index failure on GetGuarantee", func(t *testing.T) {
+	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
-
 		index := Index{
-			codec: codec,
 			client: &apiMock{
-				GetGuaranteeFunc: func(context.Context, *GetGuaranteeRequest, ...grpc.CallOption) (*GetGuaranteeResponse, error) {
+				ListHeightsForEventFunc: func(context.Context, *ListHeightsForEventRequest, ...grpc.CallOption) (*ListHeightsForEventResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Guarantee(guarantee.ID())
+		_, err := index.EventHeights(typ, mocks.GenericHeight, mocks.GenericHeight+2)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Transaction(t *testing.T) {
-	tx := mocks.GenericTransaction(0)
-	txID := tx.ID()
-
-	data, err := cbor.Marshal(tx)
-	require.NoError(t, err)
+func TestIndex_Seals(t *testing.T) {
+	seal := mocks.GenericSeal(0)
+	sealID := seal.ID()
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
+		data, err := cbor.Marshal(seal)
+		require.NoError(t, err)
+
 		codec := mocks.BaselineCodec(t)
 		codec.UnmarshalFunc = cbor.Unmarshal
 
 		index := Index{
 			codec: codec,
 			client: &apiMock{
-				GetTransactionFunc: func(_ context.Context, in *GetTransactionRequest, _ ...grpc.CallOption) (*GetTransactionResponse, error) {
-					assert.Equal(t, txID[:], in.TransactionID)
+				GetSealFunc: func(_ context.Context, in *GetSealRequest, _ ...grpc.CallOption) (*GetSealResponse, error) {
+					assert.Equal(t, sealID[:], in.SealID)
 
-					return &GetTransactionResponse{
-						TransactionID: txID[:],
-						Data:          data,
+					return &GetSealResponse{
+						SealID: sealID[:],
+						Data:   data,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Transaction(txID)
+		got, err := index.Seal(sealID)
 
 		require.NoError(t, err)
-		assert.Equal(t, tx, got)
+		assert.Equal(t, seal, got)
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
-
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetTransactionFunc: func(context.Context, *GetTransactionRequest, ...grpc.CallOption) (*GetTransactionResponse, error) {
+				GetSealFunc: func(context.Context, *GetSealRequest, ...grpc.CallOption) (*GetSealResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Transaction(tx.ID())
+		_, err := index.Seal(sealID)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Result(t *testing.T) {
-	result := mocks.GenericResult(0)
-	txID := result.TransactionID
-
-	data, err := cbor.Marshal(result)
-	require.NoError(t, err)
+func TestIndex_ListSealsForHeight(t *testing.T) {
+	sealIDs := mocks.GenericSealIDs(4)
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
+		data := make([][]byte, 0, len(sealIDs))
+		for _, sealID := range sealIDs {
+			data = append(data, mocks.ByteSlice(sealID))
+		}
 
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetResultFunc: func(_ context.Context, in *GetResultRequest, _ ...grpc.CallOption) (*GetResultResponse, error) {
-					assert.Equal(t, txID[:], in.TransactionID)
+				ListSealsForHeightFunc: func(_ context.Context, in *ListSealsForHeightRequest, _ ...grpc.CallOption) (*ListSealsForHeightResponse, error) {
+					assert.Equal(t, mocks.GenericHeight, in.Height)
 
-					return &GetResultResponse{
-						TransactionID: txID[:],
-						Data:          data,
+					return &ListSealsForHeightResponse{
+						Height:  in.Height,
+						SealIDs: data,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Result(txID)
+		got, err := index.SealsByHeight(mocks.GenericHeight)
 
 		require.NoError(t, err)
-		assert.Equal(t, result, got)
+		assert.Equal(t, sealIDs, got)
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
 		index := Index{
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetResultFunc: func(context.Context, *GetResultRequest, ...grpc.CallOption) (*GetResultResponse, error) {
+				ListSealsForHeightFunc: func(context.Context, *ListSealsForHeightRequest, ...grpc.CallOption) (*ListSealsForHeightResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Result(result.ID())
+		_, err := index.SealsByHeight(mocks.GenericHeight)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_Events(t *testing.T) {
-	events := mocks.GenericEvents(4)
-	types := mocks.GenericEventTypes(2)
-
-	data, err := cbor.Marshal(events)
-	require.NoError(t, err)
+func TestIndex_ListTransactionsForCollection(t *testing.T) {
+	collID := mocks.GenericCollection(0).ID()
+	txIDs := mocks.GenericTransactionIDs(4)
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
+		data := make([][]byte, 0, len(txIDs))
+		for _, txID := range txIDs {
+			data = append(data, mocks.ByteSlice(txID))
+		}
 
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetEventsFunc: func(_ context.Context, in *GetEventsRequest, _ ...grpc.CallOption) (*GetEventsResponse, error) {
-					assert.Equal(t, mocks.GenericHeight, in.Height)
-					assert.Equal(t, convert.TypesToStrings(types), in.Types)
+				ListTransactionsForCollectionFunc: func(_ context.Context, in *ListTransactionsForCollectionRequest, _ ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error) {
+					assert.Equal(t, collID[:], in.CollectionID)
 
-					return &GetEventsResponse{
-						Height: mocks.GenericHeight,
-						Types:  convert.TypesToStrings(types),
-						Data:   data,
+					return &ListTransactionsForCollectionResponse{
+						CollectionID:   in.CollectionID,
+						TransactionIDs: data,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.Events(mocks.GenericHeight, types...)
+		got, err := index.TransactionsByCollection(collID)
 
 		require.NoError(t, err)
-		assert.Equal(t, events, got)
+		assert.Equal(t, txIDs, got)
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
-
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetEventsFunc: func(context.Context, *GetEventsRequest, ...grpc.CallOption) (*GetEventsResponse, error) {
+				ListTransactionsForCollectionFunc: func(context.Context, *ListTransactionsForCollectionRequest, ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Events(mocks.GenericHeight, types...)
+		_, err := index.TransactionsByCollection(collID)
 
 		assert.Error(t, err)
 	})
+}
 
-	t.Run("handles invalid indexed data", func(t *testing.T) {
-		t.Parallel()
+func TestIndex_TransactionsByAccount(t *testing.T) {
+	address := mocks.GenericAddress(0)
+	txs := mocks.GenericAccountTransactions(4)
 
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
+	data := make([]*AccountTransaction, 0, len(txs))
+	for _, tx := range txs {
+		data = append(data, &AccountTransaction{
+			Height:        tx.Height,
+			TransactionID: mocks.ByteSlice(tx.TransactionID),
+			Payer:         true,
+			Authorizer:    true,
+		})
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
 
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetEventsFunc: func(context.Context, *GetEventsRequest, ...grpc.CallOption) (*GetEventsResponse, error) {
-					return &GetEventsResponse{
-						Height: mocks.GenericHeight,
-						Types:  convert.TypesToStrings(types),
-						Data:   []byte(`invalid data`),
+				ListTransactionsForAccountFunc: func(_ context.Context, in *ListTransactionsForAccountRequest, _ ...grpc.CallOption) (*ListTransactionsForAccountResponse, error) {
+					assert.Equal(t, address[:], in.Address)
+					assert.Equal(t, mocks.GenericHeight, in.Start)
+					assert.Equal(t, mocks.GenericHeight+3, in.End)
+					assert.Equal(t, txs[0].TransactionID[:], in.After)
+					assert.Equal(t, uint32(4), in.Limit)
+
+					return &ListTransactionsForAccountResponse{
+						Address:      in.Address,
+						Start:        in.Start,
+						End:          in.End,
+						Transactions: data,
 					}, nil
 				},
 			},
 		}
 
-		_, err := index.Events(mocks.GenericHeight, types...)
+		got, err := index.TransactionsByAccount(address, mocks.GenericHeight, mocks.GenericHeight+3, txs[0].TransactionID, 4)
 
-		assert.Error(t, err)
+		require.NoError(t, err)
+		assert.Equal(t, txs, got)
 	})
-}
-
-func TestIndex_Seals(t *testing.T) {
-	seal := mocks.GenericSeal(0)
-	sealID := seal.ID()
 
-	t.Run("nominal case", func(t *testing.T) {
+	t.Run("requests pages until the last one without limit", func(t *testing.T) {
 		t.Parallel()
 
-		data, err := cbor.Marshal(seal)
-		require.NoError(t, err)
-
-		codec := mocks.BaselineCodec(t)
-		codec.UnmarshalFunc = cbor.Unmarshal
+		full := make([]*AccountTransaction, 0, MaxPageSize)
+		for i := 0; i < MaxPageSize; i++ {
+			full = append(full, data[0])
+		}
 
+		calls := 0
 		index := Index{
-			codec: codec,
+			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetSealFunc: func(_ context.Context, in *GetSealRequest, _ ...grpc.CallOption) (*GetSealResponse, error) {
-					assert.Equal(t, sealID[:], in.SealID)
-
-					return &GetSealResponse{
-						SealID: sealID[:],
-						Data:   data,
-					}, nil
+				ListTransactionsForAccountFunc: func(_ context.Context, in *ListTransactionsForAccountRequest, _ ...grpc.CallOption) (*ListTransactionsForAccountResponse, error) {
+					calls++
+					assert.Equal(t, uint32(MaxPageSize), in.Limit)
+
+					if calls == 1 {
+						assert.Equal(t, mocks.GenericHeight, in.Start)
+						assert.Empty(t, in.After)
+						return &ListTransactionsForAccountResponse{Transactions: full}, nil
+					}
+
+					assert.Equal(t, txs[0].Height, in.Start)
+					assert.Equal(t, txs[0].TransactionID[:], in.After)
+					return &ListTransactionsForAccountResponse{Transactions: data[1:]}, nil
 				},
 			},
 		}
 
-		got, err := index.Seal(sealID)
+		got, err := index.TransactionsByAccount(address, mocks.GenericHeight, mocks.GenericHeight+3, flow.ZeroID, 0)
 
 		require.NoError(t, err)
-		assert.Equal(t, seal, got)
+		assert.Equal(t, 2, calls)
+		assert.Len(t, got, MaxPageSize+len(data)-1)
+		assert.Equal(t, txs[1:], got[MaxPageSize:])
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
@@ -742,84 +2267,97 @@ func TestIndex_Seals(t *testing.T) {
 		index := Index{
 			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				GetSealFunc: func(context.Context, *GetSealRequest, ...grpc.CallOption) (*GetSealResponse, error) {
+				ListTransactionsForAccountFunc: func(context.Context, *ListTransactionsForAccountRequest, ...grpc.CallOption) (*ListTransactionsForAccountResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.Seal(sealID)
+		_, err := index.TransactionsByAccount(address, mocks.GenericHeight, mocks.GenericHeight+3, flow.ZeroID, 4)
 
 		assert.Error(t, err)
 	})
 }
 
-func TestIndex_ListSealsForHeight(t *testing.T) {
-	sealIDs := mocks.GenericSealIDs(4)
+func TestIndex_HeightForSeal(t *testing.T) {
+	sealID := mocks.GenericSeal(0).ID()
 
 	t.Run("nominal case", func(t *testing.T) {
 		t.Parallel()
 
-		data := make([][]byte, 0, len(sealIDs))
-		for _, sealID := range sealIDs {
-			data = append(data, mocks.ByteSlice(sealID))
-		}
-
 		index := Index{
-			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				ListSealsForHeightFunc: func(_ context.Context, in *ListSealsForHeightRequest, _ ...grpc.CallOption) (*ListSealsForHeightResponse, error) {
-					assert.Equal(t, mocks.GenericHeight, in.Height)
+				GetHeightForSealFunc: func(_ context.Context, in *GetHeightForSealRequest, _ ...grpc.CallOption) (*GetHeightForSealResponse, error) {
+					assert.Equal(t, sealID[:], in.SealID)
 
-					return &ListSealsForHeightResponse{
-						Height:  in.Height,
-						SealIDs: data,
+					return &GetHeightForSealResponse{
+						SealID: in.SealID,
+						Height: mocks.GenericHeight,
 					}, nil
 				},
 			},
 		}
 
-		got, err := index.SealsByHeight(mocks.GenericHeight)
+		got, err := index.HeightForSeal(sealID)
 
 		require.NoError(t, err)
-		assert.Equal(t, sealIDs, got)
+		assert.Equal(t, mocks.GenericHeight, got)
 	})
 
 	t.Run("handles index failures", func(t *testing.T) {
 		t.Parallel()
 
 		index := Index{
-			codec: mocks.BaselineCodec(t),
 			client: &apiMock{
-				ListSealsForHeightFunc: func(context.Context, *ListSealsForHeightRequest, ...grpc.CallOption) (*ListSealsForHeightResponse, error) {
+				GetHeightForSealFunc: func(context.Context, *GetHeightForSealRequest, ...grpc.CallOption) (*GetHeightForSealResponse, error) {
 					return nil, mocks.GenericError
 				},
 			},
 		}
 
-		_, err := index.SealsByHeight(mocks.GenericHeight)
+		_, err := index.HeightForSeal(sealID)
 
 		assert.Error(t, err)
 	})
 }
 
 type apiMock struct {
-	GetFirstFunc                  func(ctx context.Context, in *GetFirstRequest, opts ...grpc.CallOption) (*GetFirstResponse, error)
-	GetLastFunc                   func(ctx context.Context, in *GetLastRequest, opts ...grpc.CallOption) (*GetLastResponse, error)
-	GetHeightForBlockFunc         func(ctx context.Context, in *GetHeightForBlockRequest, opts ...grpc.CallOption) (*GetHeightForBlockResponse, error)
-	GetCommitFunc                 func(ctx context.Context, in *GetCommitRequest, opts ...grpc.CallOption) (*GetCommitResponse, error)
-	GetHeaderFunc                 func(ctx context.Context, in *GetHeaderRequest, opts ...grpc.CallOption) (*GetHeaderResponse, error)
-	GetEventsFunc                 func(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
-	GetRegisterValuesFunc         func(ctx context.Context, in *GetRegisterValuesRequest, opts ...grpc.CallOption) (*GetRegisterValuesResponse, error)
-	GetCollectionFunc             func(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error)
-	ListCollectionsForHeightFunc  func(ctx context.Context, in *ListCollectionsForHeightRequest, opts ...grpc.CallOption) (*ListCollectionsForHeightResponse, error)
-	GetGuaranteeFunc              func(ctx context.Context, in *GetGuaranteeRequest, opts ...grpc.CallOption) (*GetGuaranteeResponse, error)
-	GetTransactionFunc            func(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
-	GetHeightForTransactionFunc   func(ctx context.Context, in *GetHeightForTransactionRequest, opts ...grpc.CallOption) (*GetHeightForTransactionResponse, error)
-	ListTransactionsForHeightFunc func(ctx context.Context, in *ListTransactionsForHeightRequest, opts ...grpc.CallOption) (*ListTransactionsForHeightResponse, error)
-	GetResultFunc                 func(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*GetResultResponse, error)
-	GetSealFunc                   func(ctx context.Context, in *GetSealRequest, opts ...grpc.CallOption) (*GetSealResponse, error)
-	ListSealsForHeightFunc        func(ctx context.Context, in *ListSealsForHeightRequest, opts ...grpc.CallOption) (*ListSealsForHeightResponse, error)
+	GetFirstFunc                      func(ctx context.Context, in *GetFirstRequest, opts ...grpc.CallOption) (*GetFirstResponse, error)
+	GetLastFunc                       func(ctx context.Context, in *GetLastRequest, opts ...grpc.CallOption) (*GetLastResponse, error)
+	GetFloorFunc                      func(ctx context.Context, in *GetFloorRequest, opts ...grpc.CallOption) (*GetFloorResponse, error)
+	GetHeightForBlockFunc             func(ctx context.Context, in *GetHeightForBlockRequest, opts ...grpc.CallOption) (*GetHeightForBlockResponse, error)
+	GetCommitFunc                     func(ctx context.Context, in *GetCommitRequest, opts ...grpc.CallOption) (*GetCommitResponse, error)
+	GetHeaderFunc                     func(ctx context.Context, in *GetHeaderRequest, opts ...grpc.CallOption) (*GetHeaderResponse, error)
+	GetHeaderByBlockIDFunc            func(ctx context.Context, in *GetHeaderByBlockIDRequest, opts ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error)
+	GetEventsFunc                     func(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error)
+	ListHeadersFunc                   func(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (API_ListHeadersClient, error)
+	ListCommitsFunc                   func(ctx context.Context, in *ListCommitsRequest, opts ...grpc.CallOption) (API_ListCommitsClient, error)
+	GetEventsRangeFunc                func(ctx context.Context, in *GetEventsRangeRequest, opts ...grpc.CallOption) (API_GetEventsRangeClient, error)
+	ListHeightsForEventFunc           func(ctx context.Context, in *ListHeightsForEventRequest, opts ...grpc.CallOption) (*ListHeightsForEventResponse, error)
+	GetRegisterValuesFunc             func(ctx context.Context, in *GetRegisterValuesRequest, opts ...grpc.CallOption) (*GetRegisterValuesResponse, error)
+	GetRegistersByKeyFunc             func(ctx context.Context, in *GetRegistersByKeyRequest, opts ...grpc.CallOption) (*GetRegistersByKeyResponse, error)
+	GetRegisterHistoryFunc            func(ctx context.Context, in *GetRegisterHistoryRequest, opts ...grpc.CallOption) (API_GetRegisterHistoryClient, error)
+	GetStateDiffFunc                  func(ctx context.Context, in *GetStateDiffRequest, opts ...grpc.CallOption) (API_GetStateDiffClient, error)
+	GetRegisterProofsFunc             func(ctx context.Context, in *GetRegisterProofsRequest, opts ...grpc.CallOption) (*GetRegisterProofsResponse, error)
+	GetCollectionFunc                 func(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error)
+	ListCollectionsForHeightFunc      func(ctx context.Context, in *ListCollectionsForHeightRequest, opts ...grpc.CallOption) (*ListCollectionsForHeightResponse, error)
+	GetHeightForCollectionFunc        func(ctx context.Context, in *GetHeightForCollectionRequest, opts ...grpc.CallOption) (*GetHeightForCollectionResponse, error)
+	GetGuaranteeFunc                  func(ctx context.Context, in *GetGuaranteeRequest, opts ...grpc.CallOption) (*GetGuaranteeResponse, error)
+	GetTransactionFunc                func(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	GetHeightForTransactionFunc       func(ctx context.Context, in *GetHeightForTransactionRequest, opts ...grpc.CallOption) (*GetHeightForTransactionResponse, error)
+	GetHeightForTimestampFunc         func(ctx context.Context, in *GetHeightForTimestampRequest, opts ...grpc.CallOption) (*GetHeightForTimestampResponse, error)
+	ListTransactionsForHeightFunc     func(ctx context.Context, in *ListTransactionsForHeightRequest, opts ...grpc.CallOption) (*ListTransactionsForHeightResponse, error)
+	ListTransactionsForCollectionFunc func(ctx context.Context, in *ListTransactionsForCollectionRequest, opts ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error)
+	ListTransactionsForAccountFunc    func(ctx context.Context, in *ListTransactionsForAccountRequest, opts ...grpc.CallOption) (*ListTransactionsForAccountResponse, error)
+	GetResultFunc                     func(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*GetResultResponse, error)
+	GetSealFunc                       func(ctx context.Context, in *GetSealRequest, opts ...grpc.CallOption) (*GetSealResponse, error)
+	ListSealsForHeightFunc            func(ctx context.Context, in *ListSealsForHeightRequest, opts ...grpc.CallOption) (*ListSealsForHeightResponse, error)
+	GetHeightForSealFunc              func(ctx context.Context, in *GetHeightForSealRequest, opts ...grpc.CallOption) (*GetHeightForSealResponse, error)
+	SubscribeBlocksFunc               func(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (API_SubscribeBlocksClient, error)
+	GetAccountAtHeightFunc            func(ctx context.Context, in *GetAccountAtHeightRequest, opts ...grpc.CallOption) (*GetAccountAtHeightResponse, error)
+	GetAccountKeyAtHeightFunc         func(ctx context.Context, in *GetAccountKeyAtHeightRequest, opts ...grpc.CallOption) (*GetAccountKeyAtHeightResponse, error)
+	ExecuteScriptAtHeightFunc         func(ctx context.Context, in *ExecuteScriptAtHeightRequest, opts ...grpc.CallOption) (*ExecuteScriptAtHeightResponse, error)
+	ExecuteTransactionAtHeightFunc    func(ctx context.Context, in *ExecuteTransactionAtHeightRequest, opts ...grpc.CallOption) (*ExecuteTransactionAtHeightResponse, error)
 }
 
 func (a *apiMock) GetFirst(ctx context.Context, in *GetFirstRequest, opts ...grpc.CallOption) (*GetFirstResponse, error) {
@@ -830,6 +2368,10 @@ func (a *apiMock) GetLast(ctx context.Context, in *GetLastRequest, opts ...grpc.
 	return a.GetLastFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) GetFloor(ctx context.Context, in *GetFloorRequest, opts ...grpc.CallOption) (*GetFloorResponse, error) {
+	return a.GetFloorFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetHeightForBlock(ctx context.Context, in *GetHeightForBlockRequest, opts ...grpc.CallOption) (*GetHeightForBlockResponse, error) {
 	return a.GetHeightForBlockFunc(ctx, in, opts...)
 }
@@ -842,14 +2384,50 @@ func (a *apiMock) GetHeader(ctx context.Context, in *GetHeaderRequest, opts ...g
 	return a.GetHeaderFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) GetHeaderByBlockID(ctx context.Context, in *GetHeaderByBlockIDRequest, opts ...grpc.CallOption) (*GetHeaderByBlockIDResponse, error) {
+	return a.GetHeaderByBlockIDFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*GetEventsResponse, error) {
 	return a.GetEventsFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) ListHeaders(ctx context.Context, in *ListHeadersRequest, opts ...grpc.CallOption) (API_ListHeadersClient, error) {
+	return a.ListHeadersFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) ListCommits(ctx context.Context, in *ListCommitsRequest, opts ...grpc.CallOption) (API_ListCommitsClient, error) {
+	return a.ListCommitsFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetEventsRange(ctx context.Context, in *GetEventsRangeRequest, opts ...grpc.CallOption) (API_GetEventsRangeClient, error) {
+	return a.GetEventsRangeFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) ListHeightsForEvent(ctx context.Context, in *ListHeightsForEventRequest, opts ...grpc.CallOption) (*ListHeightsForEventResponse, error) {
+	return a.ListHeightsForEventFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetRegisterValues(ctx context.Context, in *GetRegisterValuesRequest, opts ...grpc.CallOption) (*GetRegisterValuesResponse, error) {
 	return a.GetRegisterValuesFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) GetRegisterProofs(ctx context.Context, in *GetRegisterProofsRequest, opts ...grpc.CallOption) (*GetRegisterProofsResponse, error) {
+	return a.GetRegisterProofsFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetRegistersByKey(ctx context.Context, in *GetRegistersByKeyRequest, opts ...grpc.CallOption) (*GetRegistersByKeyResponse, error) {
+	return a.GetRegistersByKeyFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetRegisterHistory(ctx context.Context, in *GetRegisterHistoryRequest, opts ...grpc.CallOption) (API_GetRegisterHistoryClient, error) {
+	return a.GetRegisterHistoryFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetStateDiff(ctx context.Context, in *GetStateDiffRequest, opts ...grpc.CallOption) (API_GetStateDiffClient, error) {
+	return a.GetStateDiffFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetCollection(ctx context.Context, in *GetCollectionRequest, opts ...grpc.CallOption) (*GetCollectionResponse, error) {
 	return a.GetCollectionFunc(ctx, in, opts...)
 }
@@ -858,6 +2436,10 @@ func (a *apiMock) ListCollectionsForHeight(ctx context.Context, in *ListCollecti
 	return a.ListCollectionsForHeightFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) GetHeightForCollection(ctx context.Context, in *GetHeightForCollectionRequest, opts ...grpc.CallOption) (*GetHeightForCollectionResponse, error) {
+	return a.GetHeightForCollectionFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetGuarantee(ctx context.Context, in *GetGuaranteeRequest, opts ...grpc.CallOption) (*GetGuaranteeResponse, error) {
 	return a.GetGuaranteeFunc(ctx, in, opts...)
 }
@@ -870,10 +2452,22 @@ func (a *apiMock) GetHeightForTransaction(ctx context.Context, in *GetHeightForT
 	return a.GetHeightForTransactionFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) GetHeightForTimestamp(ctx context.Context, in *GetHeightForTimestampRequest, opts ...grpc.CallOption) (*GetHeightForTimestampResponse, error) {
+	return a.GetHeightForTimestampFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) ListTransactionsForHeight(ctx context.Context, in *ListTransactionsForHeightRequest, opts ...grpc.CallOption) (*ListTransactionsForHeightResponse, error) {
 	return a.ListTransactionsForHeightFunc(ctx, in, opts...)
 }
 
+func (a *apiMock) ListTransactionsForCollection(ctx context.Context, in *ListTransactionsForCollectionRequest, opts ...grpc.CallOption) (*ListTransactionsForCollectionResponse, error) {
+	return a.ListTransactionsForCollectionFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) ListTransactionsForAccount(ctx context.Context, in *ListTransactionsForAccountRequest, opts ...grpc.CallOption) (*ListTransactionsForAccountResponse, error) {
+	return a.ListTransactionsForAccountFunc(ctx, in, opts...)
+}
+
 func (a *apiMock) GetResult(ctx context.Context, in *GetResultRequest, opts ...grpc.CallOption) (*GetResultResponse, error) {
 	return a.GetResultFunc(ctx, in, opts...)
 }
@@ -885,3 +2479,77 @@ func (a *apiMock) GetSeal(ctx context.Context, in *GetSealRequest, opts ...grpc.
 func (a *apiMock) ListSealsForHeight(ctx context.Context, in *ListSealsForHeightRequest, opts ...grpc.CallOption) (*ListSealsForHeightResponse, error) {
 	return a.ListSealsForHeightFunc(ctx, in, opts...)
 }
+
+func (a *apiMock) GetHeightForSeal(ctx context.Context, in *GetHeightForSealRequest, opts ...grpc.CallOption) (*GetHeightForSealResponse, error) {
+	return a.GetHeightForSealFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (API_SubscribeBlocksClient, error) {
+	return a.SubscribeBlocksFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetAccountAtHeight(ctx context.Context, in *GetAccountAtHeightRequest, opts ...grpc.CallOption) (*GetAccountAtHeightResponse, error) {
+	return a.GetAccountAtHeightFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) GetAccountKeyAtHeight(ctx context.Context, in *GetAccountKeyAtHeightRequest, opts ...grpc.CallOption) (*GetAccountKeyAtHeightResponse, error) {
+	return a.GetAccountKeyAtHeightFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) ExecuteScriptAtHeight(ctx context.Context, in *ExecuteScriptAtHeightRequest, opts ...grpc.CallOption) (*ExecuteScriptAtHeightResponse, error) {
+	return a.ExecuteScriptAtHeightFunc(ctx, in, opts...)
+}
+
+func (a *apiMock) ExecuteTransactionAtHeight(ctx context.Context, in *ExecuteTransactionAtHeightRequest, opts ...grpc.CallOption) (*ExecuteTransactionAtHeightResponse, error) {
+	return a.ExecuteTransactionAtHeightFunc(ctx, in, opts...)
+}
+
+type listHeadersClientMock struct {
+	grpc.ClientStream
+
+	RecvFunc func() (*ListHeadersResponse, error)
+}
+
+func (l *listHeadersClientMock) Recv() (*ListHeadersResponse, error) {
+	return l.RecvFunc()
+}
+
+type listCommitsClientMock struct {
+	grpc.ClientStream
+
+	RecvFunc func() (*ListCommitsResponse, error)
+}
+
+func (l *listCommitsClientMock) Recv() (*ListCommitsResponse, error) {
+	return l.RecvFunc()
+}
+
+type getRegisterHistoryClientMock struct {
+	grpc.ClientStream
+
+	RecvFunc func() (*GetRegisterHistoryResponse, error)
+}
+
+func (g *getRegisterHistoryClientMock) Recv() (*GetRegisterHistoryResponse, error) {
+	return g.RecvFunc()
+}
+
+type getStateDiffClientMock struct {
+	grpc.ClientStream
+
+	RecvFunc func() (*GetStateDiffResponse, error)
+}
+
+func (g *getStateDiffClientMock) Recv() (*GetStateDiffResponse, error) {
+	return g.RecvFunc()
+}
+
+type getEventsRangeClientMock struct {
+	grpc.ClientStream
+
+	RecvFunc func() (*GetEventsRangeResponse, error)
+}
+
+func (g *getEventsRangeClientMock) Recv() (*GetEventsRangeResponse, error) {
+	return g.RecvFunc()
+}
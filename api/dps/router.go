@@ -0,0 +1,568 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Route associates a spork with the index that serves its heights.
+type Route struct {
+	Spork Spork
+	Index dps.Reader
+}
+
+// Router implements the `dps.Reader` interface on top of the indexes of
+// multiple sporks. Calls for a height are routed to the index of the spork that
+// covers it, calls for a range of heights are split across the sporks that
+// cover it, and lookups by identifier are sent to all sporks.
+type Router struct {
+	routes []Route
+}
+
+// NewRouter creates a new router over the given routes. The height ranges of
+// the sporks can not overlap.
+func NewRouter(routes ...Route) (*Router, error) {
+
+	sorted := make([]Route, len(routes))
+	copy(sorted, routes)
+	sort.Slice(sorted, func(i int, j int) bool {
+		return sorted[i].Spork.First < sorted[j].Spork.First
+	})
+
+	sporks := make(Sporks, 0, len(sorted))
+	for _, route := range sorted {
+		sporks = append(sporks, route.Spork)
+	}
+	err := sporks.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid routes: %w", err)
+	}
+
+	r := Router{
+		routes: sorted,
+	}
+
+	return &r, nil
+}
+
+// RouterFromAPIs creates a new router over the given spork table, where each
+// spork is served by the DPS API client at the same position in the given
+// slice of clients. The given options are applied to the index of each spork.
+func RouterFromAPIs(sporks Sporks, clients []APIClient, codec dps.Codec, options ...func(*IndexConfig)) (*Router, error) {
+
+	if len(sporks) != len(clients) {
+		return nil, fmt.Errorf("mismatching number of sporks and clients (sporks: %d, clients: %d)", len(sporks), len(clients))
+	}
+
+	routes := make([]Route, 0, len(sporks))
+	for i, spork := range sporks {
+		route := Route{
+			Spork: spork,
+			Index: IndexFromAPI(clients[i], codec, options...),
+		}
+		routes = append(routes, route)
+	}
+
+	return NewRouter(routes...)
+}
+
+// WithContext returns a copy of the router where the index of each spork that
+// can be bound to a context is bound to the given context.
+func (r *Router) WithContext(ctx context.Context) dps.Reader {
+
+	routes := make([]Route, 0, len(r.routes))
+	for _, route := range r.routes {
+		index, ok := route.Index.(dps.ContextReader)
+		if ok {
+			route.Index = index.WithContext(ctx)
+		}
+		routes = append(routes, route)
+	}
+
+	bound := Router{
+		routes: routes,
+	}
+
+	return &bound
+}
+
+// First returns the first height of the earliest spork.
+func (r *Router) First() (uint64, error) {
+	return r.routes[0].Index.First()
+}
+
+// Last returns the last height of the latest spork.
+func (r *Router) Last() (uint64, error) {
+	return r.routes[len(r.routes)-1].Index.Last()
+}
+
+// Floor returns the lowest height at which the complete register state of the
+// earliest spork can be read.
+func (r *Router) Floor() (uint64, error) {
+	return r.routes[0].Index.Floor()
+}
+
+// HeightForBlock returns the height of the given block ID, from whichever spork
+// includes it.
+func (r *Router) HeightForBlock(blockID flow.Identifier) (uint64, error) {
+	height, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.HeightForBlock(blockID)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height for block: %w", err)
+	}
+	return height.(uint64), nil
+}
+
+// HeightForTransaction returns the height of the given transaction ID, from
+// whichever spork includes it.
+func (r *Router) HeightForTransaction(txID flow.Identifier) (uint64, error) {
+	height, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.HeightForTransaction(txID)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height for transaction: %w", err)
+	}
+	return height.(uint64), nil
+}
+
+// HeightForTime returns the height of the last block with a timestamp that is
+// not after the given timestamp, starting the search from the latest spork.
+func (r *Router) HeightForTime(timestamp time.Time) (uint64, error) {
+	for i := len(r.routes) - 1; i >= 0; i-- {
+		height, err := r.routes[i].Index.HeightForTime(timestamp)
+		if errors.Is(err, dps.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not get height for time (spork: %s): %w", r.routes[i].Spork.Name, err)
+		}
+		return height, nil
+	}
+	return 0, fmt.Errorf("could not find height for time: %w", dps.ErrNotFound)
+}
+
+// HeightForCollection returns the height of the given collection ID, from
+// whichever spork includes it.
+func (r *Router) HeightForCollection(collID flow.Identifier) (uint64, error) {
+	height, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.HeightForCollection(collID)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height for collection: %w", err)
+	}
+	return height.(uint64), nil
+}
+
+// HeightForSeal returns the height of the given seal ID, from whichever spork
+// includes it.
+func (r *Router) HeightForSeal(sealID flow.Identifier) (uint64, error) {
+	height, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.HeightForSeal(sealID)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not get height for seal: %w", err)
+	}
+	return height.(uint64), nil
+}
+
+// Commit returns the state commitment at the given height.
+func (r *Router) Commit(height uint64) (flow.StateCommitment, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return flow.DummyStateCommitment, err
+	}
+	return index.Commit(height)
+}
+
+// Header returns the block header at the given height.
+func (r *Router) Header(height uint64) (*flow.Header, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Header(height)
+}
+
+// HeaderByBlockID returns the header of the given block ID, from whichever spork
+// includes it.
+func (r *Router) HeaderByBlockID(blockID flow.Identifier) (*flow.Header, error) {
+	header, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.HeaderByBlockID(blockID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get header for block: %w", err)
+	}
+	return header.(*flow.Header), nil
+}
+
+// Events returns the events of the given types at the given height.
+func (r *Router) Events(height uint64, types ...flow.EventType) ([]flow.Event, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Events(height, types...)
+}
+
+// Values returns the register values of the given paths at the given height.
+func (r *Router) Values(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Values(height, paths)
+}
+
+// Proofs returns the proofs for the given paths at the given height.
+func (r *Router) Proofs(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.Proofs(height, paths)
+}
+
+// Headers returns the block headers for the given range of heights, which can
+// span multiple sporks.
+func (r *Router) Headers(start uint64, end uint64) ([]*flow.Header, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	var headers []*flow.Header
+	for _, segment := range segments {
+		part, err := segment.index.Headers(segment.start, segment.end)
+		if err != nil {
+			return nil, fmt.Errorf("could not get headers (spork: %s): %w", segment.name, err)
+		}
+		headers = append(headers, part...)
+	}
+	return headers, nil
+}
+
+// Commits returns the state commitments for the given range of heights, which
+// can span multiple sporks.
+func (r *Router) Commits(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	commits := make(map[uint64]flow.StateCommitment)
+	for _, segment := range segments {
+		part, err := segment.index.Commits(segment.start, segment.end)
+		if err != nil {
+			return nil, fmt.Errorf("could not get commits (spork: %s): %w", segment.name, err)
+		}
+		for height, commit := range part {
+			commits[height] = commit
+		}
+	}
+	return commits, nil
+}
+
+// EventsRange returns the events of the given types for the given range of
+// heights, which can span multiple sporks.
+func (r *Router) EventsRange(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[uint64][]flow.Event)
+	for _, segment := range segments {
+		part, err := segment.index.EventsRange(segment.start, segment.end, types...)
+		if err != nil {
+			return nil, fmt.Errorf("could not get events (spork: %s): %w", segment.name, err)
+		}
+		for height, evts := range part {
+			events[height] = evts
+		}
+	}
+	return events, nil
+}
+
+// EventHeights returns the heights with events of the given type for the given
+// range of heights, which can span multiple sporks.
+func (r *Router) EventHeights(typ flow.EventType, start uint64, end uint64) ([]uint64, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	heights := make([]uint64, 0)
+	for _, segment := range segments {
+		part, err := segment.index.EventHeights(typ, segment.start, segment.end)
+		if err != nil {
+			return nil, fmt.Errorf("could not get event heights (spork: %s): %w", segment.name, err)
+		}
+		heights = append(heights, part...)
+	}
+	return heights, nil
+}
+
+// RegisterHistory returns the changes of the given register for the given
+// range of heights, which can span multiple sporks.
+func (r *Router) RegisterHistory(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	payloads := make(map[uint64]*ledger.Payload)
+	for _, segment := range segments {
+		part, err := segment.index.RegisterHistory(path, segment.start, segment.end)
+		if err != nil {
+			return nil, fmt.Errorf("could not get register history (spork: %s): %w", segment.name, err)
+		}
+		for height, payload := range part {
+			payloads[height] = payload
+		}
+	}
+	return payloads, nil
+}
+
+// StateDiff returns the registers that changed between the given heights. Both
+// heights need to be part of the same spork.
+func (r *Router) StateDiff(start uint64, end uint64, process func(change dps.Change) error) error {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return err
+	}
+	if len(segments) > 1 {
+		return fmt.Errorf("state diff can not span multiple sporks: %w", dps.ErrInvalidArgument)
+	}
+	return segments[0].index.StateDiff(start, end, process)
+}
+
+// Collection returns the collection with the given ID, from whichever spork
+// includes it.
+func (r *Router) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
+	collection, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.Collection(collID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get collection: %w", err)
+	}
+	return collection.(*flow.LightCollection), nil
+}
+
+// Guarantee returns the guarantee for the given collection ID, from whichever
+// spork includes it.
+func (r *Router) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
+	guarantee, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.Guarantee(collID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get guarantee: %w", err)
+	}
+	return guarantee.(*flow.CollectionGuarantee), nil
+}
+
+// Transaction returns the transaction with the given ID, from whichever spork
+// includes it.
+func (r *Router) Transaction(txID flow.Identifier) (*flow.TransactionBody, error) {
+	transaction, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.Transaction(txID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get transaction: %w", err)
+	}
+	return transaction.(*flow.TransactionBody), nil
+}
+
+// Seal returns the seal with the given ID, from whichever spork includes it.
+func (r *Router) Seal(sealID flow.Identifier) (*flow.Seal, error) {
+	seal, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.Seal(sealID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get seal: %w", err)
+	}
+	return seal.(*flow.Seal), nil
+}
+
+// Result returns the result of the transaction with the given ID, from
+// whichever spork includes it.
+func (r *Router) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
+	result, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.Result(txID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get result: %w", err)
+	}
+	return result.(*flow.TransactionResult), nil
+}
+
+// CollectionsByHeight returns the collection IDs at the given height.
+func (r *Router) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.CollectionsByHeight(height)
+}
+
+// TransactionsByHeight returns the transaction IDs at the given height.
+func (r *Router) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.TransactionsByHeight(height)
+}
+
+// TransactionsByCollection returns the transaction IDs of the given collection
+// ID, from whichever spork includes it.
+func (r *Router) TransactionsByCollection(collID flow.Identifier) ([]flow.Identifier, error) {
+	txIDs, err := r.fanOut(func(index dps.Reader) (interface{}, error) {
+		return index.TransactionsByCollection(collID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get transactions for collection: %w", err)
+	}
+	return txIDs.([]flow.Identifier), nil
+}
+
+// TransactionsByAccount returns up to the given limit of transactions of the
+// given account for the given range of heights, which can span multiple sporks.
+// The transaction ID to start after only applies to the first spork, as later
+// sporks start at later heights.
+func (r *Router) TransactionsByAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+	segments, err := r.split(start, end)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]dps.AccountTransaction, 0)
+	for _, segment := range segments {
+		remaining := uint(0)
+		if limit > 0 {
+			remaining = limit - uint(len(txs))
+		}
+		part, err := segment.index.TransactionsByAccount(address, segment.start, segment.end, after, remaining)
+		if err != nil {
+			return nil, fmt.Errorf("could not get transactions for account (spork: %s): %w", segment.name, err)
+		}
+		txs = append(txs, part...)
+		if limit > 0 && uint(len(txs)) >= limit {
+			break
+		}
+		after = flow.ZeroID
+	}
+	return txs, nil
+}
+
+// SealsByHeight returns the seal IDs at the given height.
+func (r *Router) SealsByHeight(height uint64) ([]flow.Identifier, error) {
+	index, err := r.route(height)
+	if err != nil {
+		return nil, err
+	}
+	return index.SealsByHeight(height)
+}
+
+// route returns the index of the spork that covers the given height.
+func (r *Router) route(height uint64) (dps.Reader, error) {
+	for _, route := range r.routes {
+		if height >= route.Spork.First && height <= route.Spork.Last {
+			return route.Index, nil
+		}
+	}
+	return nil, fmt.Errorf("no spork for height %d: %w", height, dps.ErrOutOfRange)
+}
+
+type segment struct {
+	name  string
+	index dps.Reader
+	start uint64
+	end   uint64
+}
+
+// split splits the given range of heights into one segment per spork that
+// covers part of it. Every height of the range needs to be covered.
+func (r *Router) split(start uint64, end uint64) ([]segment, error) {
+
+	if start > end {
+		return nil, fmt.Errorf("start height above end height (start: %d, end: %d): %w", start, end, dps.ErrInvalidArgument)
+	}
+
+	var segments []segment
+	next := start
+	for _, route := range r.routes {
+		if route.Spork.Last < next {
+			continue
+		}
+		if route.Spork.First > next {
+			break
+		}
+		s := segment{
+			name:  route.Spork.Name,
+			index: route.Index,
+			start: next,
+			end:   end,
+		}
+		if route.Spork.Last < end {
+			s.end = route.Spork.Last
+		}
+		segments = append(segments, s)
+		if s.end == end {
+			return segments, nil
+		}
+		next = s.end + 1
+	}
+
+	return nil, fmt.Errorf("no spork for height %d: %w", next, dps.ErrOutOfRange)
+}
+
+// fanOut executes the given lookup on the indexes of all sporks concurrently.
+// It returns the result of the earliest spork for which the lookup succeeded.
+// If the lookup failed for all sporks, it returns a not found error, unless one
+// of the failures was due to another reason.
+func (r *Router) fanOut(lookup func(index dps.Reader) (interface{}, error)) (interface{}, error) {
+
+	results := make([]interface{}, len(r.routes))
+	errs := make([]error, len(r.routes))
+	var wg sync.WaitGroup
+	for i, route := range r.routes {
+		wg.Add(1)
+		go func(i int, index dps.Reader) {
+			defer wg.Done()
+			results[i], errs[i] = lookup(index)
+		}(i, route.Index)
+	}
+	wg.Wait()
+
+	for i := range r.routes {
+		if errs[i] == nil {
+			return results[i], nil
+		}
+	}
+
+	for i, err := range errs {
+		if !errors.Is(err, dps.ErrNotFound) {
+			return nil, fmt.Errorf("could not look up in spork %s: %w", r.routes[i].Spork.Name, err)
+		}
+	}
+
+	return nil, dps.ErrNotFound
+}
@@ -0,0 +1,497 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestNewRouter(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := Route{Spork: Spork{Name: "spork-1", API: "spork1", First: 1, Last: 100}, Index: mocks.BaselineReader(t)}
+		second := Route{Spork: Spork{Name: "spork-2", API: "spork2", First: 101, Last: math.MaxUint64}, Index: mocks.BaselineReader(t)}
+
+		router, err := NewRouter(second, first)
+
+		require.NoError(t, err)
+		assert.Implements(t, (*dps.Reader)(nil), router)
+		assert.Equal(t, []Route{first, second}, router.routes)
+	})
+
+	t.Run("handles overlapping sporks", func(t *testing.T) {
+		t.Parallel()
+
+		first := Route{Spork: Spork{Name: "spork-1", API: "spork1", First: 1, Last: 100}, Index: mocks.BaselineReader(t)}
+		second := Route{Spork: Spork{Name: "spork-2", API: "spork2", First: 50, Last: 200}, Index: mocks.BaselineReader(t)}
+
+		_, err := NewRouter(first, second)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing routes", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewRouter()
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRouterFromAPIs(t *testing.T) {
+	sporks := Sporks{
+		{Name: "spork-1", API: "spork1", First: 1, Last: 100},
+		{Name: "spork-2", API: "spork2", First: 101, Last: math.MaxUint64},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		router, err := RouterFromAPIs(sporks, []APIClient{&apiMock{}, &apiMock{}}, mocks.BaselineCodec(t))
+
+		require.NoError(t, err)
+		require.Len(t, router.routes, 2)
+		assert.Equal(t, sporks[0], router.routes[0].Spork)
+		assert.IsType(t, &Index{}, router.routes[0].Index)
+		assert.Equal(t, sporks[1], router.routes[1].Spork)
+		assert.IsType(t, &Index{}, router.routes[1].Index)
+	})
+
+	t.Run("handles mismatching clients", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := RouterFromAPIs(sporks, []APIClient{&apiMock{}}, mocks.BaselineCodec(t))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRouter_FirstLast(t *testing.T) {
+	first := mocks.BaselineReader(t)
+	first.FirstFunc = func() (uint64, error) {
+		return 1, nil
+	}
+	first.FloorFunc = func() (uint64, error) {
+		return 50, nil
+	}
+	second := mocks.BaselineReader(t)
+	second.LastFunc = func() (uint64, error) {
+		return 200, nil
+	}
+
+	router := baselineRouter(t, first, second)
+
+	got, err := router.First()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), got)
+
+	got, err = router.Last()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200), got)
+
+	got, err = router.Floor()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50), got)
+}
+
+func TestRouter_Header(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.HeaderFunc = func(uint64) (*flow.Header, error) {
+			t.Error("header should not be requested from first spork")
+			return nil, mocks.GenericError
+		}
+		second := mocks.BaselineReader(t)
+		second.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, uint64(150), height)
+			return mocks.GenericHeader, nil
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.Header(150)
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeader, got)
+	})
+
+	t.Run("handles height outside of sporks", func(t *testing.T) {
+		t.Parallel()
+
+		router := baselineRouter(t, mocks.BaselineReader(t), mocks.BaselineReader(t))
+
+		_, err := router.Header(201)
+
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+}
+
+func TestRouter_Headers(t *testing.T) {
+	header1 := &flow.Header{Height: 99}
+	header2 := &flow.Header{Height: 100}
+	header3 := &flow.Header{Height: 101}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.HeadersFunc = func(start uint64, end uint64) ([]*flow.Header, error) {
+			assert.Equal(t, uint64(99), start)
+			assert.Equal(t, uint64(100), end)
+			return []*flow.Header{header1, header2}, nil
+		}
+		second := mocks.BaselineReader(t)
+		second.HeadersFunc = func(start uint64, end uint64) ([]*flow.Header, error) {
+			assert.Equal(t, uint64(101), start)
+			assert.Equal(t, uint64(101), end)
+			return []*flow.Header{header3}, nil
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.Headers(99, 101)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*flow.Header{header1, header2, header3}, got)
+	})
+
+	t.Run("handles range outside of sporks", func(t *testing.T) {
+		t.Parallel()
+
+		router := baselineRouter(t, mocks.BaselineReader(t), mocks.BaselineReader(t))
+
+		_, err := router.Headers(150, 250)
+
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+
+	t.Run("handles invalid range", func(t *testing.T) {
+		t.Parallel()
+
+		router := baselineRouter(t, mocks.BaselineReader(t), mocks.BaselineReader(t))
+
+		_, err := router.Headers(101, 99)
+
+		assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		second := mocks.BaselineReader(t)
+		second.HeadersFunc = func(uint64, uint64) ([]*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		router := baselineRouter(t, mocks.BaselineReader(t), second)
+
+		_, err := router.Headers(99, 101)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRouter_EventsRange(t *testing.T) {
+	first := mocks.BaselineReader(t)
+	first.EventsRangeFunc = func(start uint64, end uint64, _ ...flow.EventType) (map[uint64][]flow.Event, error) {
+		assert.Equal(t, uint64(100), start)
+		assert.Equal(t, uint64(100), end)
+		return map[uint64][]flow.Event{100: mocks.GenericEvents(1)}, nil
+	}
+	second := mocks.BaselineReader(t)
+	second.EventsRangeFunc = func(start uint64, end uint64, _ ...flow.EventType) (map[uint64][]flow.Event, error) {
+		assert.Equal(t, uint64(101), start)
+		assert.Equal(t, uint64(102), end)
+		return map[uint64][]flow.Event{102: mocks.GenericEvents(2)}, nil
+	}
+
+	router := baselineRouter(t, first, second)
+
+	got, err := router.EventsRange(100, 102)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64][]flow.Event{100: mocks.GenericEvents(1), 102: mocks.GenericEvents(2)}, got)
+}
+
+func TestRouter_EventHeights(t *testing.T) {
+	typ := mocks.GenericEventType(0)
+
+	first := mocks.BaselineReader(t)
+	first.EventHeightsFunc = func(gotType flow.EventType, start uint64, end uint64) ([]uint64, error) {
+		assert.Equal(t, typ, gotType)
+		assert.Equal(t, uint64(100), start)
+		assert.Equal(t, uint64(100), end)
+		return []uint64{100}, nil
+	}
+	second := mocks.BaselineReader(t)
+	second.EventHeightsFunc = func(gotType flow.EventType, start uint64, end uint64) ([]uint64, error) {
+		assert.Equal(t, typ, gotType)
+		assert.Equal(t, uint64(101), start)
+		assert.Equal(t, uint64(102), end)
+		return []uint64{102}, nil
+	}
+
+	router := baselineRouter(t, first, second)
+
+	got, err := router.EventHeights(typ, 100, 102)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100, 102}, got)
+}
+
+func TestRouter_TransactionsByAccount(t *testing.T) {
+	address := mocks.GenericAddress(0)
+	txs := mocks.GenericAccountTransactions(3)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.TransactionsByAccountFunc = func(gotAddress flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+			assert.Equal(t, address, gotAddress)
+			assert.Equal(t, uint64(100), start)
+			assert.Equal(t, uint64(100), end)
+			assert.Equal(t, txs[0].TransactionID, after)
+			assert.Equal(t, uint(3), limit)
+			return txs[1:2], nil
+		}
+		second := mocks.BaselineReader(t)
+		second.TransactionsByAccountFunc = func(gotAddress flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+			assert.Equal(t, address, gotAddress)
+			assert.Equal(t, uint64(101), start)
+			assert.Equal(t, uint64(102), end)
+			assert.Equal(t, flow.ZeroID, after)
+			assert.Equal(t, uint(2), limit)
+			return txs[2:], nil
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.TransactionsByAccount(address, 100, 102, txs[0].TransactionID, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, txs[1:], got)
+	})
+
+	t.Run("stops once limit is reached", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.TransactionsByAccountFunc = func(flow.Address, uint64, uint64, flow.Identifier, uint) ([]dps.AccountTransaction, error) {
+			return txs[:2], nil
+		}
+		second := mocks.BaselineReader(t)
+		second.TransactionsByAccountFunc = func(flow.Address, uint64, uint64, flow.Identifier, uint) ([]dps.AccountTransaction, error) {
+			t.Error("unexpected call to second spork")
+			return nil, nil
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.TransactionsByAccount(address, 100, 102, flow.ZeroID, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, txs[:2], got)
+	})
+}
+
+func TestRouter_StateDiff(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		router := baselineRouter(t, mocks.BaselineReader(t), mocks.BaselineReader(t))
+
+		var got []dps.Change
+		err := router.StateDiff(101, 150, func(change dps.Change) error {
+			got = append(got, change)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericChanges(4), got)
+	})
+
+	t.Run("handles range across sporks", func(t *testing.T) {
+		t.Parallel()
+
+		router := baselineRouter(t, mocks.BaselineReader(t), mocks.BaselineReader(t))
+
+		err := router.StateDiff(50, 150, func(dps.Change) error {
+			return nil
+		})
+
+		assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+	})
+}
+
+func TestRouter_HeightForTime(t *testing.T) {
+	timestamp := time.Unix(1600000000, 0).UTC()
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.HeightForTimeFunc = func(got time.Time) (uint64, error) {
+			assert.Equal(t, timestamp, got)
+			return 42, nil
+		}
+		second := mocks.BaselineReader(t)
+		second.HeightForTimeFunc = func(time.Time) (uint64, error) {
+			return 0, dps.ErrNotFound
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.HeightForTime(timestamp)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), got)
+	})
+
+	t.Run("handles timestamp before sporks", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeightForTimeFunc = func(time.Time) (uint64, error) {
+			return 0, dps.ErrNotFound
+		}
+
+		router := baselineRouter(t, index, index)
+
+		_, err := router.HeightForTime(timestamp)
+
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeightForTimeFunc = func(time.Time) (uint64, error) {
+			return 0, mocks.GenericError
+		}
+
+		router := baselineRouter(t, mocks.BaselineReader(t), index)
+
+		_, err := router.HeightForTime(timestamp)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
+
+func TestRouter_Transaction(t *testing.T) {
+	tx := mocks.GenericTransaction(0)
+	notFound := func(flow.Identifier) (*flow.TransactionBody, error) {
+		return nil, dps.ErrNotFound
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.TransactionFunc = notFound
+		second := mocks.BaselineReader(t)
+		second.TransactionFunc = func(txID flow.Identifier) (*flow.TransactionBody, error) {
+			assert.Equal(t, tx.ID(), txID)
+			return tx, nil
+		}
+
+		router := baselineRouter(t, first, second)
+
+		got, err := router.Transaction(tx.ID())
+
+		require.NoError(t, err)
+		assert.Equal(t, tx, got)
+	})
+
+	t.Run("handles transaction in no spork", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.TransactionFunc = notFound
+
+		router := baselineRouter(t, index, index)
+
+		_, err := router.Transaction(tx.ID())
+
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+	})
+
+	t.Run("handles index failure", func(t *testing.T) {
+		t.Parallel()
+
+		first := mocks.BaselineReader(t)
+		first.TransactionFunc = notFound
+		second := mocks.BaselineReader(t)
+		second.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return nil, mocks.GenericError
+		}
+
+		router := baselineRouter(t, first, second)
+
+		_, err := router.Transaction(tx.ID())
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+}
+
+func TestRouter_HeightForBlock(t *testing.T) {
+	blockID := mocks.GenericHeader.ID()
+
+	first := mocks.BaselineReader(t)
+	first.HeightForBlockFunc = func(flow.Identifier) (uint64, error) {
+		return 0, dps.ErrNotFound
+	}
+	second := mocks.BaselineReader(t)
+	second.HeightForBlockFunc = func(got flow.Identifier) (uint64, error) {
+		assert.Equal(t, blockID, got)
+		return 150, nil
+	}
+
+	router := baselineRouter(t, first, second)
+
+	got, err := router.HeightForBlock(blockID)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150), got)
+}
+
+// baselineRouter creates a router with a first spork covering the heights 1 to
+// 100 and a second spork covering the heights 101 to 200.
+func baselineRouter(t *testing.T, first dps.Reader, second dps.Reader) *Router {
+	t.Helper()
+
+	router, err := NewRouter(
+		Route{Spork: Spork{Name: "spork-1", API: "spork1", First: 1, Last: 100}, Index: first},
+		Route{Spork: Spork{Name: "spork-2", API: "spork2", First: 101, Last: 200}, Index: second},
+	)
+	require.NoError(t, err)
+
+	return router
+}
@@ -16,10 +16,16 @@ package dps
 
 import (
 	"context"
-	"fmt"
+	"sort"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/convert"
@@ -31,19 +37,31 @@ import (
 // This is generally an on-disk interface, but could be a GRPC-based index as
 // well, in which case there is a double redirection.
 type Server struct {
-	index dps.Reader
-	codec dps.Codec
+	index  dps.Reader
+	codec  dps.Codec
+	invoke dps.Invoker
+	cfg    Config
 
 	validate *validator.Validate
 }
 
 // NewServer creates a new server, using the provided index reader as a backend
-// for data retrieval.
-func NewServer(index dps.Reader, codec dps.Codec) *Server {
+// for data retrieval and the provided invoker to retrieve accounts and execute
+// Cadence code. The invoker should be shared between all requests, so that its
+// register cache is reused across heights. Without an invoker, the server does
+// not support account retrieval, script execution and transaction dry runs.
+func NewServer(index dps.Reader, codec dps.Codec, invoke dps.Invoker, options ...func(*Config)) *Server {
+
+	cfg := DefaultConfig
+	for _, option := range options {
+		option(&cfg)
+	}
 
 	s := Server{
 		index:    index,
 		codec:    codec,
+		invoke:   invoke,
+		cfg:      cfg,
 		validate: validator.New(),
 	}
 
@@ -55,7 +73,7 @@ func (s *Server) GetFirst(_ context.Context, _ *GetFirstRequest) (*GetFirstRespo
 
 	height, err := s.index.First()
 	if err != nil {
-		return nil, fmt.Errorf("could not get first height: %w", err)
+		return nil, statusErrorf("could not get first height: %w", err)
 	}
 
 	res := GetFirstResponse{
@@ -70,7 +88,7 @@ func (s *Server) GetLast(_ context.Context, _ *GetLastRequest) (*GetLastResponse
 
 	height, err := s.index.Last()
 	if err != nil {
-		return nil, fmt.Errorf("could not get last height: %w", err)
+		return nil, statusErrorf("could not get last height: %w", err)
 	}
 
 	res := GetLastResponse{
@@ -80,19 +98,34 @@ func (s *Server) GetLast(_ context.Context, _ *GetLastRequest) (*GetLastResponse
 	return &res, nil
 }
 
+// GetFloor implements the `GetFloor` method of the generated GRPC server.
+func (s *Server) GetFloor(_ context.Context, _ *GetFloorRequest) (*GetFloorResponse, error) {
+
+	height, err := s.index.Floor()
+	if err != nil {
+		return nil, statusErrorf("could not get retention floor: %w", err)
+	}
+
+	res := GetFloorResponse{
+		Height: height,
+	}
+
+	return &res, nil
+}
+
 // GetHeightForBlock implements the `GetHeightForBlock` method of the generated GRPC
 // server.
 func (s *Server) GetHeightForBlock(_ context.Context, req *GetHeightForBlockRequest) (*GetHeightForBlockResponse, error) {
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	blockID := flow.HashToID(req.BlockID)
 	height, err := s.index.HeightForBlock(blockID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get height for block: %w", err)
+		return nil, statusErrorf("could not get height for block: %w", err)
 	}
 
 	res := GetHeightForBlockResponse{
@@ -108,12 +141,12 @@ func (s *Server) GetCommit(_ context.Context, req *GetCommitRequest) (*GetCommit
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	commit, err := s.index.Commit(req.Height)
 	if err != nil {
-		return nil, fmt.Errorf("could not get commit: %w", err)
+		return nil, statusErrorf("could not get commit: %w", err)
 	}
 
 	res := GetCommitResponse{
@@ -129,17 +162,17 @@ func (s *Server) GetHeader(_ context.Context, req *GetHeaderRequest) (*GetHeader
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	header, err := s.index.Header(req.Height)
 	if err != nil {
-		return nil, fmt.Errorf("could not get header: %w", err)
+		return nil, statusErrorf("could not get header: %w", err)
 	}
 
 	data, err := s.codec.Marshal(header)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode header: %w", err)
+		return nil, statusErrorf("could not encode header: %w", err)
 	}
 
 	res := GetHeaderResponse{
@@ -150,24 +183,231 @@ func (s *Server) GetHeader(_ context.Context, req *GetHeaderRequest) (*GetHeader
 	return &res, nil
 }
 
+// GetHeaderByBlockID implements the `GetHeaderByBlockID` method of the generated GRPC
+// server.
+func (s *Server) GetHeaderByBlockID(_ context.Context, req *GetHeaderByBlockIDRequest) (*GetHeaderByBlockIDResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	blockID := flow.HashToID(req.BlockID)
+	header, err := s.index.HeaderByBlockID(blockID)
+	if err != nil {
+		return nil, statusErrorf("could not get header by block ID: %w", err)
+	}
+
+	data, err := s.codec.Marshal(header)
+	if err != nil {
+		return nil, statusErrorf("could not encode header: %w", err)
+	}
+
+	res := GetHeaderByBlockIDResponse{
+		BlockID: req.BlockID,
+		Height:  header.Height,
+		Data:    data,
+	}
+
+	return &res, nil
+}
+
 // GetEvents implements the `GetEvents` method of the generated GRPC server.
 func (s *Server) GetEvents(_ context.Context, req *GetEventsRequest) (*GetEventsResponse, error) {
 
-	types := convert.StringsToTypes(req.Types)
-	events, err := s.index.Events(req.Height, types...)
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	filter, err := newEventFilter(req.Types, req.TransactionIDs, req.Fields)
 	if err != nil {
-		return nil, fmt.Errorf("could not get events: %w", err)
+		return nil, statusErrorf("invalid event filter: %w", err)
 	}
 
-	data, err := s.codec.Marshal(events)
+	events, err := s.index.Events(req.Height, filter.lookup()...)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode events: %w", err)
+		return nil, statusErrorf("could not get events: %w", err)
 	}
 
+	events = filter.apply(events)
+
 	res := GetEventsResponse{
 		Height: req.Height,
 		Types:  req.Types,
-		Data:   data,
+	}
+
+	// If decoded events are requested, we include each event with its payload
+	// as JSON-Cadence instead of the encoded slice of events.
+	if req.Decoded {
+		res.Events = make([]*Event, 0, len(events))
+		for _, event := range events {
+			res.Events = append(res.Events, decodeEvent(event))
+		}
+		return &res, nil
+	}
+
+	res.Data, err = s.codec.Marshal(events)
+	if err != nil {
+		return nil, statusErrorf("could not encode events: %w", err)
+	}
+
+	return &res, nil
+}
+
+// ListHeaders implements the `ListHeaders` method of the generated GRPC server.
+// It streams the headers for all heights between the requested start and end
+// heights, both inclusive, one height at a time.
+func (s *Server) ListHeaders(req *ListHeadersRequest, stream API_ListHeadersServer) error {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return statusErrorf("bad request: %w", err)
+	}
+
+	return batches(req.Start, req.End, func(start uint64, end uint64) error {
+
+		headers, err := s.index.Headers(start, end)
+		if err != nil {
+			return statusErrorf("could not get headers: %w", err)
+		}
+
+		for _, header := range headers {
+
+			data, err := s.codec.Marshal(header)
+			if err != nil {
+				return statusErrorf("could not encode header (height: %d): %w", header.Height, err)
+			}
+
+			res := ListHeadersResponse{
+				Height: header.Height,
+				Data:   data,
+			}
+
+			err = stream.Send(&res)
+			if err != nil {
+				return statusErrorf("could not send header (height: %d): %w", header.Height, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListCommits implements the `ListCommits` method of the generated GRPC server.
+// It streams the state commitments for all heights between the requested start
+// and end heights, both inclusive, one height at a time.
+func (s *Server) ListCommits(req *ListCommitsRequest, stream API_ListCommitsServer) error {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return statusErrorf("bad request: %w", err)
+	}
+
+	return batches(req.Start, req.End, func(start uint64, end uint64) error {
+
+		commits, err := s.index.Commits(start, end)
+		if err != nil {
+			return statusErrorf("could not get commits: %w", err)
+		}
+
+		for height := start; height <= end; height++ {
+
+			commit, ok := commits[height]
+			if !ok {
+				continue
+			}
+
+			res := ListCommitsResponse{
+				Height: height,
+				Commit: commit[:],
+			}
+
+			err = stream.Send(&res)
+			if err != nil {
+				return statusErrorf("could not send commit (height: %d): %w", height, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetEventsRange implements the `GetEventsRange` method of the generated GRPC
+// server. It streams the events for all heights between the requested start and
+// end heights, both inclusive, one height at a time. Heights without any
+// matching events are skipped.
+func (s *Server) GetEventsRange(req *GetEventsRangeRequest, stream API_GetEventsRangeServer) error {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return statusErrorf("bad request: %w", err)
+	}
+
+	filter, err := newEventFilter(req.Types, req.TransactionIDs, req.Fields)
+	if err != nil {
+		return statusErrorf("invalid event filter: %w", err)
+	}
+
+	return batches(req.Start, req.End, func(start uint64, end uint64) error {
+
+		events, err := s.index.EventsRange(start, end, filter.lookup()...)
+		if err != nil {
+			return statusErrorf("could not get events: %w", err)
+		}
+
+		for height := start; height <= end; height++ {
+
+			evts, ok := events[height]
+			if !ok {
+				continue
+			}
+
+			evts = filter.apply(evts)
+			if len(evts) == 0 {
+				continue
+			}
+
+			data, err := s.codec.Marshal(evts)
+			if err != nil {
+				return statusErrorf("could not encode events (height: %d): %w", height, err)
+			}
+
+			res := GetEventsRangeResponse{
+				Height: height,
+				Types:  req.Types,
+				Data:   data,
+			}
+
+			err = stream.Send(&res)
+			if err != nil {
+				return statusErrorf("could not send events (height: %d): %w", height, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListHeightsForEvent implements the `ListHeightsForEvent` method of the
+// generated GRPC server.
+func (s *Server) ListHeightsForEvent(_ context.Context, req *ListHeightsForEventRequest) (*ListHeightsForEventResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	heights, err := s.index.EventHeights(flow.EventType(req.Type), req.Start, req.End)
+	if err != nil {
+		return nil, statusErrorf("could not list heights for event: %w", err)
+	}
+
+	res := ListHeightsForEventResponse{
+		Type:    req.Type,
+		Start:   req.Start,
+		End:     req.End,
+		Heights: heights,
 	}
 
 	return &res, nil
@@ -179,17 +419,20 @@ func (s *Server) GetRegisterValues(_ context.Context, req *GetRegisterValuesRequ
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
+	}
+	if s.cfg.MaxPaths > 0 && uint(len(req.Paths)) > s.cfg.MaxPaths {
+		return nil, statusErrorf("bad request: request exceeds %d paths: %w", s.cfg.MaxPaths, dps.ErrInvalidArgument)
 	}
 
 	paths, err := convert.BytesToPaths(req.Paths)
 	if err != nil {
-		return nil, fmt.Errorf("could not convert paths: %w", err)
+		return nil, statusErrorf("could not convert paths: %w", err)
 	}
 
 	values, err := s.index.Values(req.Height, paths)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve values: %w", err)
+		return nil, statusErrorf("could not retrieve values: %w", err)
 	}
 
 	res := GetRegisterValuesResponse{
@@ -201,24 +444,201 @@ func (s *Server) GetRegisterValues(_ context.Context, req *GetRegisterValuesRequ
 	return &res, nil
 }
 
+// GetRegistersByKey implements the `GetRegistersByKey` method of the generated
+// GRPC server. The registers are returned in the same order as the given
+// register IDs.
+func (s *Server) GetRegistersByKey(_ context.Context, req *GetRegistersByKeyRequest) (*GetRegistersByKeyResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+	if s.cfg.MaxPaths > 0 && uint(len(req.RegisterIDs)) > s.cfg.MaxPaths {
+		return nil, statusErrorf("bad request: request exceeds %d registers: %w", s.cfg.MaxPaths, dps.ErrInvalidArgument)
+	}
+
+	regIDs := make([]flow.RegisterID, 0, len(req.RegisterIDs))
+	for _, regID := range req.RegisterIDs {
+		regIDs = append(regIDs, flow.NewRegisterID(string(regID.Owner), string(regID.Controller), string(regID.Key)))
+	}
+
+	values, err := dps.RegistersByKey(s.index, req.Height, regIDs)
+	if err != nil {
+		return nil, statusErrorf("could not retrieve registers: %w", err)
+	}
+
+	registers := make([]*Register, 0, len(req.RegisterIDs))
+	for index, regID := range req.RegisterIDs {
+		register := Register{
+			RegisterID: regID,
+			Value:      values[regIDs[index]],
+		}
+		registers = append(registers, &register)
+	}
+
+	res := GetRegistersByKeyResponse{
+		Height:    req.Height,
+		Registers: registers,
+	}
+
+	return &res, nil
+}
+
+// GetRegisterProofs implements the `GetRegisterProofs` method of the generated
+// GRPC server. It is only served when proofs were enabled, as restoring the
+// state trie to generate them is expensive.
+func (s *Server) GetRegisterProofs(_ context.Context, req *GetRegisterProofsRequest) (*GetRegisterProofsResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+	if s.cfg.MaxPaths > 0 && uint(len(req.Paths)) > s.cfg.MaxPaths {
+		return nil, statusErrorf("bad request: request exceeds %d paths: %w", s.cfg.MaxPaths, dps.ErrInvalidArgument)
+	}
+
+	if !s.cfg.Proofs {
+		return nil, status.Error(codes.Unimplemented, "register proofs not enabled")
+	}
+
+	paths, err := convert.BytesToPaths(req.Paths)
+	if err != nil {
+		return nil, statusErrorf("could not convert paths: %w", err)
+	}
+
+	proofs, err := s.index.Proofs(req.Height, paths)
+	if err != nil {
+		return nil, statusErrorf("could not retrieve proofs: %w", err)
+	}
+
+	res := GetRegisterProofsResponse{
+		Height: req.Height,
+		Paths:  req.Paths,
+		Proofs: convert.ProofsToBytes(proofs),
+	}
+
+	return &res, nil
+}
+
+// GetRegisterHistory implements the `GetRegisterHistory` method of the
+// generated GRPC server. It streams the payloads written to the requested path
+// for all heights between the requested start and end heights, both inclusive,
+// one height at a time. Heights at which the path was not written are skipped.
+func (s *Server) GetRegisterHistory(req *GetRegisterHistoryRequest, stream API_GetRegisterHistoryServer) error {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return statusErrorf("bad request: %w", err)
+	}
+
+	path, err := ledger.ToPath(req.Path)
+	if err != nil {
+		return statusErrorf("could not convert path: %w", err)
+	}
+	return batches(req.Start, req.End, func(start uint64, end uint64) error {
+
+		payloads, err := s.index.RegisterHistory(path, start, end)
+		if err != nil {
+			return statusErrorf("could not get register history: %w", err)
+		}
+
+		for height := start; height <= end; height++ {
+
+			payload, ok := payloads[height]
+			if !ok {
+				continue
+			}
+
+			data, err := s.codec.Marshal(payload)
+			if err != nil {
+				return statusErrorf("could not encode payload (height: %d): %w", height, err)
+			}
+
+			res := GetRegisterHistoryResponse{
+				Height: height,
+				Path:   req.Path,
+				Data:   data,
+			}
+
+			err = stream.Send(&res)
+			if err != nil {
+				return statusErrorf("could not send payload (height: %d): %w", height, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetStateDiff implements the `GetStateDiff` method of the generated GRPC
+// server. It streams the changes to all ledger registers between the requested
+// start and end heights, one register at a time. The payload before a change is
+// left empty if the register did not exist at the start height. The range can
+// span at most `MaxDiffRange` heights.
+func (s *Server) GetStateDiff(req *GetStateDiffRequest, stream API_GetStateDiffServer) error {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return statusErrorf("bad request: %w", err)
+	}
+	if req.End-req.Start > MaxDiffRange {
+		return statusErrorf("bad request: range exceeds %d heights: %w", MaxDiffRange, dps.ErrInvalidArgument)
+	}
+
+	send := func(change dps.Change) error {
+
+		var before []byte
+		var err error
+		if change.Before != nil {
+			before, err = s.codec.Marshal(change.Before)
+			if err != nil {
+				return statusErrorf("could not encode payload before (path: %x): %w", change.Path, err)
+			}
+		}
+		after, err := s.codec.Marshal(change.After)
+		if err != nil {
+			return statusErrorf("could not encode payload after (path: %x): %w", change.Path, err)
+		}
+
+		res := GetStateDiffResponse{
+			Path:   change.Path[:],
+			Before: before,
+			After:  after,
+		}
+
+		err = stream.Send(&res)
+		if err != nil {
+			return statusErrorf("could not send change (path: %x): %w", change.Path, err)
+		}
+
+		return nil
+	}
+	err = s.index.StateDiff(req.Start, req.End, send)
+	if err != nil {
+		return statusErrorf("could not get state diff: %w", err)
+	}
+
+	return nil
+}
+
 // GetCollection implements the `GetCollection` method of the generated GRPC
 // server.
 func (s *Server) GetCollection(_ context.Context, req *GetCollectionRequest) (*GetCollectionResponse, error) {
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	collID := flow.HashToID(req.CollectionID)
 	collection, err := s.index.Collection(collID)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve collection: %w", err)
+		return nil, statusErrorf("could not retrieve collection: %w", err)
 	}
 
 	data, err := s.codec.Marshal(collection)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode collection: %w", err)
+		return nil, statusErrorf("could not encode collection: %w", err)
 	}
 
 	res := GetCollectionResponse{
@@ -235,11 +655,11 @@ func (s *Server) ListCollectionsForHeight(_ context.Context, req *ListCollection
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 	collIDs, err := s.index.CollectionsByHeight(req.Height)
 	if err != nil {
-		return nil, fmt.Errorf("could not list collections by height: %w", err)
+		return nil, statusErrorf("could not list collections by height: %w", err)
 	}
 
 	rawIDs := make([][]byte, 0, len(collIDs))
@@ -255,24 +675,47 @@ func (s *Server) ListCollectionsForHeight(_ context.Context, req *ListCollection
 	return &res, nil
 }
 
+// GetHeightForCollection implements the `GetHeightForCollection` method of the generated GRPC
+// server.
+func (s *Server) GetHeightForCollection(_ context.Context, req *GetHeightForCollectionRequest) (*GetHeightForCollectionResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	collID := flow.HashToID(req.CollectionID)
+	height, err := s.index.HeightForCollection(collID)
+	if err != nil {
+		return nil, statusErrorf("could not get height for collection: %w", err)
+	}
+
+	res := GetHeightForCollectionResponse{
+		CollectionID: req.CollectionID,
+		Height:       height,
+	}
+
+	return &res, nil
+}
+
 // GetGuarantee implements the `GetGuarantee` method of the generated GRPC
 // server.
 func (s *Server) GetGuarantee(_ context.Context, req *GetGuaranteeRequest) (*GetGuaranteeResponse, error) {
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	collID := flow.HashToID(req.CollectionID)
 	guarantee, err := s.index.Guarantee(collID)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve guarantee: %w", err)
+		return nil, statusErrorf("could not retrieve guarantee: %w", err)
 	}
 
 	data, err := s.codec.Marshal(guarantee)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode guarantee: %w", err)
+		return nil, statusErrorf("could not encode guarantee: %w", err)
 	}
 
 	res := GetGuaranteeResponse{
@@ -289,23 +732,46 @@ func (s *Server) GetTransaction(_ context.Context, req *GetTransactionRequest) (
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	txID := flow.HashToID(req.TransactionID)
 	transaction, err := s.index.Transaction(txID)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve transaction: %w", err)
-	}
-
-	data, err := s.codec.Marshal(transaction)
-	if err != nil {
-		return nil, fmt.Errorf("could not encode transaction: %w", err)
+		return nil, statusErrorf("could not retrieve transaction: %w", err)
 	}
 
 	res := GetTransactionResponse{
 		TransactionID: req.TransactionID,
-		Data:          data,
+	}
+
+	// If a decoded transaction is requested, we include the script as text and
+	// the arguments as JSON-Cadence instead of the encoded transaction. If the
+	// arguments can not be decoded, we fall back to the encoded transaction,
+	// so that the transaction can still be retrieved.
+	if req.Decoded {
+		arguments, err := convert.TransactionArguments(transaction)
+		if err == nil {
+			res.Transaction = &Transaction{
+				ReferenceBlockID: transaction.ReferenceBlockID[:],
+				Script:           string(transaction.Script),
+				Arguments:        arguments,
+				GasLimit:         transaction.GasLimit,
+				ProposalKey: &ProposalKey{
+					Address:        transaction.ProposalKey.Address.Bytes(),
+					KeyIndex:       transaction.ProposalKey.KeyIndex,
+					SequenceNumber: transaction.ProposalKey.SequenceNumber,
+				},
+				Payer:       transaction.Payer.Bytes(),
+				Authorizers: convert.AddressesToBytes(transaction.Authorizers),
+			}
+			return &res, nil
+		}
+	}
+
+	res.Data, err = s.codec.Marshal(transaction)
+	if err != nil {
+		return nil, statusErrorf("could not encode transaction: %w", err)
 	}
 
 	return &res, nil
@@ -317,13 +783,13 @@ func (s *Server) GetHeightForTransaction(_ context.Context, req *GetHeightForTra
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	txID := flow.HashToID(req.TransactionID)
 	height, err := s.index.HeightForTransaction(txID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get height for transaction: %w", err)
+		return nil, statusErrorf("could not get height for transaction: %w", err)
 	}
 
 	res := GetHeightForTransactionResponse{
@@ -334,18 +800,41 @@ func (s *Server) GetHeightForTransaction(_ context.Context, req *GetHeightForTra
 	return &res, nil
 }
 
+// GetHeightForTimestamp implements the `GetHeightForTimestamp` method of the generated GRPC
+// server.
+func (s *Server) GetHeightForTimestamp(_ context.Context, req *GetHeightForTimestampRequest) (*GetHeightForTimestampResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	timestamp := time.Unix(0, req.Timestamp).UTC()
+	height, err := s.index.HeightForTime(timestamp)
+	if err != nil {
+		return nil, statusErrorf("could not get height for timestamp: %w", err)
+	}
+
+	res := GetHeightForTimestampResponse{
+		Timestamp: req.Timestamp,
+		Height:    height,
+	}
+
+	return &res, nil
+}
+
 // ListTransactionsForHeight implements the `ListTransactionsForHeight` method of the generated GRPC
 // server.
 func (s *Server) ListTransactionsForHeight(_ context.Context, req *ListTransactionsForHeightRequest) (*ListTransactionsForHeightResponse, error) {
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	txIDs, err := s.index.TransactionsByHeight(req.Height)
 	if err != nil {
-		return nil, fmt.Errorf("could not list transactions by height: %w", err)
+		return nil, statusErrorf("could not list transactions by height: %w", err)
 	}
 
 	transactionIDs := make([][]byte, 0, len(txIDs))
@@ -361,29 +850,112 @@ func (s *Server) ListTransactionsForHeight(_ context.Context, req *ListTransacti
 	return &res, nil
 }
 
+// ListTransactionsForCollection implements the `ListTransactionsForCollection` method of the generated GRPC
+// server.
+func (s *Server) ListTransactionsForCollection(_ context.Context, req *ListTransactionsForCollectionRequest) (*ListTransactionsForCollectionResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	collID := flow.HashToID(req.CollectionID)
+	txIDs, err := s.index.TransactionsByCollection(collID)
+	if err != nil {
+		return nil, statusErrorf("could not list transactions by collection: %w", err)
+	}
+
+	transactionIDs := make([][]byte, 0, len(txIDs))
+	for _, txID := range txIDs {
+		transactionIDs = append(transactionIDs, convert.IDToHash(txID))
+	}
+
+	res := ListTransactionsForCollectionResponse{
+		CollectionID:   req.CollectionID,
+		TransactionIDs: transactionIDs,
+	}
+
+	return &res, nil
+}
+
+// ListTransactionsForAccount implements the `ListTransactionsForAccount` method
+// of the generated GRPC server. Transactions are returned in pages, ordered by
+// height and transaction ID; a page with fewer transactions than the limit is
+// the last one, and the next page starts after the last transaction of a page.
+func (s *Server) ListTransactionsForAccount(_ context.Context, req *ListTransactionsForAccountRequest) (*ListTransactionsForAccountResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	address := flow.BytesToAddress(req.Address)
+	after := flow.ZeroID
+	if len(req.After) > 0 {
+		after = flow.HashToID(req.After)
+	}
+	limit := uint(req.Limit)
+	if limit == 0 {
+		limit = s.cfg.PageSize
+	}
+
+	txs, err := s.index.TransactionsByAccount(address, req.Start, req.End, after, limit)
+	if err != nil {
+		return nil, statusErrorf("could not list transactions by account: %w", err)
+	}
+
+	transactions := make([]*AccountTransaction, 0, len(txs))
+	for _, tx := range txs {
+		transaction := AccountTransaction{
+			Height:        tx.Height,
+			TransactionID: convert.IDToHash(tx.TransactionID),
+			Proposer:      tx.Roles.Has(dps.RoleProposer),
+			Payer:         tx.Roles.Has(dps.RolePayer),
+			Authorizer:    tx.Roles.Has(dps.RoleAuthorizer),
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	res := ListTransactionsForAccountResponse{
+		Address:      req.Address,
+		Start:        req.Start,
+		End:          req.End,
+		Transactions: transactions,
+	}
+
+	return &res, nil
+}
+
 // GetResult implements the `GetResult` method of the generated GRPC
 // server.
 func (s *Server) GetResult(_ context.Context, req *GetResultRequest) (*GetResultResponse, error) {
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	txID := flow.HashToID(req.TransactionID)
 	result, err := s.index.Result(txID)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve transaction result: %w", err)
-	}
-
-	data, err := s.codec.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("could not encode transaction result: %w", err)
+		return nil, statusErrorf("could not retrieve transaction result: %w", err)
 	}
 
 	res := GetResultResponse{
 		TransactionID: req.TransactionID,
-		Data:          data,
+	}
+
+	if req.Decoded {
+		res.Result = &Result{
+			ErrorMessage:    result.ErrorMessage,
+			ComputationUsed: result.ComputationUsed,
+		}
+		return &res, nil
+	}
+
+	res.Data, err = s.codec.Marshal(result)
+	if err != nil {
+		return nil, statusErrorf("could not encode transaction result: %w", err)
 	}
 
 	return &res, nil
@@ -395,18 +967,18 @@ func (s *Server) GetSeal(_ context.Context, req *GetSealRequest) (*GetSealRespon
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	sealID := flow.HashToID(req.SealID)
 	seal, err := s.index.Seal(sealID)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve seal: %w", err)
+		return nil, statusErrorf("could not retrieve seal: %w", err)
 	}
 
 	data, err := s.codec.Marshal(seal)
 	if err != nil {
-		return nil, fmt.Errorf("could not encode seal: %w", err)
+		return nil, statusErrorf("could not encode seal: %w", err)
 	}
 
 	res := GetSealResponse{
@@ -423,12 +995,12 @@ func (s *Server) ListSealsForHeight(_ context.Context, req *ListSealsForHeightRe
 
 	err := s.validate.Struct(req)
 	if err != nil {
-		return nil, fmt.Errorf("bad request: %w", err)
+		return nil, statusErrorf("bad request: %w", err)
 	}
 
 	sealIDs, err := s.index.SealsByHeight(req.Height)
 	if err != nil {
-		return nil, fmt.Errorf("could not list seals by height: %w", err)
+		return nil, statusErrorf("could not list seals by height: %w", err)
 	}
 
 	sIDs := make([][]byte, 0, len(sealIDs))
@@ -443,3 +1015,340 @@ func (s *Server) ListSealsForHeight(_ context.Context, req *ListSealsForHeightRe
 
 	return &res, nil
 }
+
+// GetHeightForSeal implements the `GetHeightForSeal` method of the generated GRPC
+// server.
+func (s *Server) GetHeightForSeal(_ context.Context, req *GetHeightForSealRequest) (*GetHeightForSealResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	sealID := flow.HashToID(req.SealID)
+	height, err := s.index.HeightForSeal(sealID)
+	if err != nil {
+		return nil, statusErrorf("could not get height for seal: %w", err)
+	}
+
+	res := GetHeightForSealResponse{
+		SealID: req.SealID,
+		Height: height,
+	}
+
+	return &res, nil
+}
+
+// SubscribeBlocks implements the `SubscribeBlocks` method of the generated GRPC
+// server. It first replays all indexed heights from the requested start height
+// onwards, and then keeps streaming new heights as soon as they are indexed,
+// until the client closes the stream.
+func (s *Server) SubscribeBlocks(req *SubscribeBlocksRequest, stream API_SubscribeBlocksServer) error {
+
+	first, err := s.index.First()
+	if err != nil {
+		return statusErrorf("could not get first height: %w", err)
+	}
+
+	// A start height of zero means that the client wants to receive all heights
+	// that are available in the index.
+	next := req.Start
+	if next == 0 {
+		next = first
+	}
+	if next < first {
+		return statusErrorf("start height below first indexed height (start: %d, first: %d): %w", next, first, dps.ErrOutOfRange)
+	}
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+
+		// We always send all heights between the next one we expect and the
+		// last indexed one. On the first pass, this replays everything that
+		// was already indexed before the subscription.
+		last, err := s.index.Last()
+		if err != nil {
+			return statusErrorf("could not get last height: %w", err)
+		}
+
+		for ; next <= last; next++ {
+
+			header, err := s.index.Header(next)
+			if err != nil {
+				return statusErrorf("could not get header (height: %d): %w", next, err)
+			}
+
+			commit, err := s.index.Commit(next)
+			if err != nil {
+				return statusErrorf("could not get commit (height: %d): %w", next, err)
+			}
+
+			blockID := header.ID()
+			res := SubscribeBlocksResponse{
+				Height:  next,
+				BlockID: blockID[:],
+				Commit:  commit[:],
+			}
+
+			err = stream.Send(&res)
+			if err != nil {
+				return statusErrorf("could not send block (height: %d): %w", next, err)
+			}
+		}
+
+		// Once we have caught up with the index, we wait for the next check,
+		// unless the client has gone away in the meantime.
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAccountAtHeight implements the `GetAccountAtHeight` method of the
+// generated GRPC server. It requires the server to be configured with an
+// invoker.
+func (s *Server) GetAccountAtHeight(ctx context.Context, req *GetAccountAtHeightRequest) (*GetAccountAtHeightResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	if s.invoke == nil {
+		return nil, status.Error(codes.Unimplemented, "account retrieval not supported")
+	}
+
+	address := flow.BytesToAddress(req.Address)
+	account, err := s.invoke.Account(ctx, req.Height, address)
+	if err != nil {
+		return nil, statusErrorf("could not get account: %w", err)
+	}
+
+	keys := make([][]byte, 0, len(account.Keys))
+	for _, key := range account.Keys {
+		data, err := flow.EncodeAccountPublicKey(key)
+		if err != nil {
+			return nil, statusErrorf("could not encode account key (index: %d): %w", key.Index, err)
+		}
+		keys = append(keys, data)
+	}
+
+	// Contracts are sorted by name, so that the response is deterministic.
+	names := make([]string, 0, len(account.Contracts))
+	for name := range account.Contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	contracts := make([]*AccountContract, 0, len(names))
+	for _, name := range names {
+		contract := AccountContract{
+			Name: name,
+			Code: account.Contracts[name],
+		}
+		contracts = append(contracts, &contract)
+	}
+
+	res := GetAccountAtHeightResponse{
+		Height:    req.Height,
+		Address:   account.Address[:],
+		Balance:   account.Balance,
+		Keys:      keys,
+		Contracts: contracts,
+	}
+
+	return &res, nil
+}
+
+// GetAccountKeyAtHeight implements the `GetAccountKeyAtHeight` method of the
+// generated GRPC server. It requires the server to be configured with an
+// invoker.
+func (s *Server) GetAccountKeyAtHeight(ctx context.Context, req *GetAccountKeyAtHeightRequest) (*GetAccountKeyAtHeightResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	if s.invoke == nil {
+		return nil, status.Error(codes.Unimplemented, "account key retrieval not supported")
+	}
+
+	address := flow.BytesToAddress(req.Address)
+	key, err := s.invoke.Key(ctx, req.Height, address, int(req.Index))
+	if err != nil {
+		return nil, statusErrorf("could not get account key: %w", err)
+	}
+
+	data, err := flow.EncodeAccountPublicKey(*key)
+	if err != nil {
+		return nil, statusErrorf("could not encode account key: %w", err)
+	}
+
+	res := GetAccountKeyAtHeightResponse{
+		Height:  req.Height,
+		Address: req.Address,
+		Index:   req.Index,
+		Key:     data,
+	}
+
+	return &res, nil
+}
+
+// ExecuteScriptAtHeight implements the `ExecuteScriptAtHeight` method of the
+// generated GRPC server. Both the script arguments and the result are encoded
+// as JSON-Cadence. It requires the server to be configured with an invoker.
+func (s *Server) ExecuteScriptAtHeight(ctx context.Context, req *ExecuteScriptAtHeightRequest) (*ExecuteScriptAtHeightResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	if s.invoke == nil {
+		return nil, status.Error(codes.Unimplemented, "script execution not supported")
+	}
+
+	args := make([]cadence.Value, 0, len(req.Arguments))
+	for _, argument := range req.Arguments {
+		arg, err := json.Decode(argument)
+		if err != nil {
+			return nil, statusErrorf("could not decode script argument (%v): %w", err, dps.ErrInvalidArgument)
+		}
+		args = append(args, arg)
+	}
+
+	value, err := s.invoke.Script(ctx, req.Height, req.Script, args)
+	if err != nil {
+		return nil, statusErrorf("could not execute script: %w", err)
+	}
+
+	result, err := json.Encode(value)
+	if err != nil {
+		return nil, statusErrorf("could not encode script result: %w", err)
+	}
+
+	res := ExecuteScriptAtHeightResponse{
+		Height: req.Height,
+		Result: result,
+	}
+
+	return &res, nil
+}
+
+// ExecuteTransactionAtHeight implements the `ExecuteTransactionAtHeight` method
+// of the generated GRPC server. The transaction is executed against the state at
+// the given height, but none of its changes are persisted, and it does not need
+// to be signed.
+func (s *Server) ExecuteTransactionAtHeight(ctx context.Context, req *ExecuteTransactionAtHeightRequest) (*ExecuteTransactionAtHeightResponse, error) {
+
+	err := s.validate.Struct(req)
+	if err != nil {
+		return nil, statusErrorf("bad request: %w", err)
+	}
+
+	if s.invoke == nil {
+		return nil, status.Error(codes.Unimplemented, "transaction execution not supported")
+	}
+
+	tx := flow.TransactionBody{
+		ReferenceBlockID: flow.HashToID(req.Transaction.ReferenceBlockID),
+		Script:           []byte(req.Transaction.Script),
+		Arguments:        req.Transaction.Arguments,
+		GasLimit:         req.Transaction.GasLimit,
+		Payer:            flow.BytesToAddress(req.Transaction.Payer),
+	}
+	if req.Transaction.ProposalKey != nil {
+		tx.ProposalKey = flow.ProposalKey{
+			Address:        flow.BytesToAddress(req.Transaction.ProposalKey.Address),
+			KeyIndex:       req.Transaction.ProposalKey.KeyIndex,
+			SequenceNumber: req.Transaction.ProposalKey.SequenceNumber,
+		}
+	}
+	for _, authorizer := range req.Transaction.Authorizers {
+		tx.Authorizers = append(tx.Authorizers, flow.BytesToAddress(authorizer))
+	}
+
+	run, err := s.invoke.Transaction(ctx, req.Height, &tx)
+	if err != nil {
+		return nil, statusErrorf("could not execute transaction: %w", err)
+	}
+
+	events := make([]*Event, 0, len(run.Events))
+	for _, event := range run.Events {
+		events = append(events, decodeEvent(event))
+	}
+
+	writes := make([]*Register, 0, len(run.Writes))
+	for _, write := range run.Writes {
+		register := Register{
+			RegisterID: &RegisterID{
+				Owner:      []byte(write.Key.Owner),
+				Controller: []byte(write.Key.Controller),
+				Key:        []byte(write.Key.Key),
+			},
+			Value: write.Value,
+		}
+		writes = append(writes, &register)
+	}
+
+	res := ExecuteTransactionAtHeightResponse{
+		Height:          req.Height,
+		Failed:          run.Failed,
+		ErrorMessage:    run.ErrorMessage,
+		Events:          events,
+		ComputationUsed: run.ComputationUsed,
+		Fees:            run.Fees,
+		Writes:          writes,
+	}
+
+	return &res, nil
+}
+
+// decodeEvent converts the given event into its decoded representation, with
+// the payload as JSON-Cadence. If the payload can not be decoded, the event
+// keeps its original payload and is marked as undecoded, so that a single
+// malformed event does not fail the whole response.
+func decodeEvent(event flow.Event) *Event {
+
+	decoded := Event{
+		Type:             string(event.Type),
+		TransactionID:    convert.IDToHash(event.TransactionID),
+		TransactionIndex: event.TransactionIndex,
+		EventIndex:       event.EventIndex,
+	}
+
+	payload, err := convert.EventPayload(event)
+	if err != nil {
+		decoded.Payload = event.Payload
+		decoded.Undecoded = true
+		return &decoded
+	}
+	decoded.Payload = payload
+
+	return &decoded
+}
+
+// batches splits the given range of heights, both inclusive, into consecutive
+// batches of at most `MaxBatchSize` heights and calls the given function for
+// each of them in order. It allows streaming methods to load only one batch of
+// a range from the index at a time, no matter how large the requested range is.
+func batches(start uint64, end uint64, process func(start uint64, end uint64) error) error {
+	for {
+		last := end
+		if end-start >= MaxBatchSize {
+			last = start + MaxBatchSize - 1
+		}
+		err := process(start, last)
+		if err != nil {
+			return err
+		}
+		if last == end {
+			return nil
+		}
+		start = last + 1
+	}
+}
@@ -20,6 +20,7 @@ package dps_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,7 +53,7 @@ func TestIntegrationServer_GetFirst(t *testing.T) {
 		require.NoError(t, writer.First(mocks.GenericHeight))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetFirstRequest{}
 		resp, err := server.GetFirst(context.Background(), req)
@@ -73,7 +74,7 @@ func TestIntegrationServer_GetFirst(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetFirstRequest{}
 		_, err := server.GetFirst(context.Background(), req)
@@ -100,7 +101,7 @@ func TestIntegrationServer_GetLast(t *testing.T) {
 		require.NoError(t, writer.Last(mocks.GenericHeight))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetLastRequest{}
 		resp, err := server.GetLast(context.Background(), req)
@@ -121,7 +122,7 @@ func TestIntegrationServer_GetLast(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetLastRequest{}
 		_, err := server.GetLast(context.Background(), req)
@@ -150,7 +151,7 @@ func TestIntegrationServer_GetHeightForBlock(t *testing.T) {
 		require.NoError(t, writer.Height(blockID, height))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeightForBlockRequest{
 			BlockID: blockID[:],
@@ -173,7 +174,7 @@ func TestIntegrationServer_GetHeightForBlock(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeightForBlockRequest{
 			BlockID: blockID[:],
@@ -204,7 +205,7 @@ func TestIntegrationServer_GetCommit(t *testing.T) {
 		require.NoError(t, writer.Commit(height, commit))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetCommitRequest{
 			Height: height,
@@ -228,7 +229,7 @@ func TestIntegrationServer_GetCommit(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetCommitRequest{
 			Height: height,
@@ -259,7 +260,7 @@ func TestIntegrationServer_GetHeader(t *testing.T) {
 		require.NoError(t, writer.Header(height, header))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeaderRequest{
 			Height: height,
@@ -286,7 +287,7 @@ func TestIntegrationServer_GetHeader(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeaderRequest{
 			Height: height,
@@ -324,7 +325,7 @@ func TestIntegrationServer_GetEvents(t *testing.T) {
 		require.NoError(t, writer.Events(height, events))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetEventsRequest{
 			Height: height,
@@ -357,7 +358,7 @@ func TestIntegrationServer_GetEvents(t *testing.T) {
 		require.NoError(t, writer.Events(height, events))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetEventsRequest{
 			Types:  []string{string(withdrawalType)},
@@ -385,7 +386,7 @@ func TestIntegrationServer_GetEvents(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetEventsRequest{
 			Height: height,
@@ -419,7 +420,7 @@ func TestIntegrationServer_GetRegisterValues(t *testing.T) {
 		require.NoError(t, writer.Payloads(height, paths, payloads))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetRegisterValuesRequest{
 			Height: height,
@@ -455,7 +456,7 @@ func TestIntegrationServer_GetRegisterValues(t *testing.T) {
 		require.NoError(t, writer.Payloads(height, paths, payloads))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetRegisterValuesRequest{
 			Height: height,
@@ -478,7 +479,7 @@ func TestIntegrationServer_GetRegisterValues(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetRegisterValuesRequest{
 			Height: height,
@@ -510,7 +511,7 @@ func TestIntegrationServer_GetCollection(t *testing.T) {
 		require.NoError(t, writer.Collections(mocks.GenericHeight, collections))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetCollectionRequest{
 			CollectionID: collID[:],
@@ -537,7 +538,7 @@ func TestIntegrationServer_GetCollection(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetCollectionRequest{
 			CollectionID: collID[:],
@@ -568,7 +569,7 @@ func TestIntegrationServer_ListCollectionsForHeight(t *testing.T) {
 		require.NoError(t, writer.Collections(height, collections))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListCollectionsForHeightRequest{
 			Height: mocks.GenericHeight,
@@ -597,7 +598,7 @@ func TestIntegrationServer_ListCollectionsForHeight(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListCollectionsForHeightRequest{
 			Height: mocks.GenericHeight,
@@ -628,7 +629,7 @@ func TestIntegrationServer_GetGuarantee(t *testing.T) {
 		require.NoError(t, writer.Guarantees(mocks.GenericHeight, guarantees))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetGuaranteeRequest{
 			CollectionID: collID[:],
@@ -655,7 +656,7 @@ func TestIntegrationServer_GetGuarantee(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetGuaranteeRequest{
 			CollectionID: collID[:],
@@ -686,7 +687,7 @@ func TestIntegrationServer_GetTransaction(t *testing.T) {
 		require.NoError(t, writer.Transactions(mocks.GenericHeight, transactions))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetTransactionRequest{
 			TransactionID: txID[:],
@@ -713,7 +714,7 @@ func TestIntegrationServer_GetTransaction(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetTransactionRequest{
 			TransactionID: txID[:],
@@ -744,7 +745,7 @@ func TestIntegrationServer_GetHeightForTransaction(t *testing.T) {
 		require.NoError(t, writer.Transactions(mocks.GenericHeight, transactions))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeightForTransactionRequest{
 			TransactionID: txID[:],
@@ -768,7 +769,7 @@ func TestIntegrationServer_GetHeightForTransaction(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetHeightForTransactionRequest{
 			TransactionID: txID[:],
@@ -779,6 +780,61 @@ func TestIntegrationServer_GetHeightForTransaction(t *testing.T) {
 	})
 }
 
+func TestIntegrationServer_GetHeightForTimestamp(t *testing.T) {
+	header := mocks.GenericHeader
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		codec := zbor.NewCodec()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		storage := storage.New(codec)
+		reader := index.NewReader(db, storage)
+		writer := index.NewWriter(db, storage)
+
+		// Insert mock data in database.
+		require.NoError(t, writer.Header(header.Height, header))
+		require.NoError(t, writer.Last(header.Height))
+		require.NoError(t, writer.Close())
+
+		server := dps.NewServer(reader, codec, nil)
+
+		req := &dps.GetHeightForTimestampRequest{
+			Timestamp: header.Timestamp.Add(time.Second).UnixNano(),
+		}
+		resp, err := server.GetHeightForTimestamp(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, req.Timestamp, resp.Timestamp)
+		assert.Equal(t, header.Height, resp.Height)
+	})
+
+	t.Run("handles indexer failure on HeightForTime", func(t *testing.T) {
+		t.Parallel()
+
+		codec := zbor.NewCodec()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		storage := storage.New(codec)
+		// No data is written in the database, so the index should fail to retrieve anything.
+		reader := index.NewReader(db, storage)
+
+		server := dps.NewServer(reader, codec, nil)
+
+		req := &dps.GetHeightForTimestampRequest{
+			Timestamp: header.Timestamp.UnixNano(),
+		}
+		_, err := server.GetHeightForTimestamp(context.Background(), req)
+
+		assert.Error(t, err)
+	})
+}
+
 func TestIntegrationServer_ListTransactionsForHeight(t *testing.T) {
 	transactions := mocks.GenericTransactions(4)
 	height := mocks.GenericHeight
@@ -799,7 +855,7 @@ func TestIntegrationServer_ListTransactionsForHeight(t *testing.T) {
 		require.NoError(t, writer.Transactions(height, transactions))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListTransactionsForHeightRequest{
 			Height: mocks.GenericHeight,
@@ -828,7 +884,7 @@ func TestIntegrationServer_ListTransactionsForHeight(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListTransactionsForHeightRequest{
 			Height: mocks.GenericHeight,
@@ -859,7 +915,7 @@ func TestIntegrationServer_GetResult(t *testing.T) {
 		require.NoError(t, writer.Results(results))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetResultRequest{
 			TransactionID: txID[:],
@@ -886,7 +942,7 @@ func TestIntegrationServer_GetResult(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetResultRequest{
 			TransactionID: txID[:],
@@ -917,7 +973,7 @@ func TestIntegrationServer_GetSeal(t *testing.T) {
 		require.NoError(t, writer.Seals(mocks.GenericHeight, seals))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetSealRequest{
 			SealID: sealID[:],
@@ -944,7 +1000,7 @@ func TestIntegrationServer_GetSeal(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.GetSealRequest{
 			SealID: sealID[:],
@@ -975,7 +1031,7 @@ func TestIntegrationServer_ListSealsForHeight(t *testing.T) {
 		require.NoError(t, writer.Seals(height, seals))
 		require.NoError(t, writer.Close())
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListSealsForHeightRequest{
 			Height: mocks.GenericHeight,
@@ -1004,7 +1060,7 @@ func TestIntegrationServer_ListSealsForHeight(t *testing.T) {
 		// No data is written in the database, so the index should fail to retrieve anything.
 		reader := index.NewReader(db, storage)
 
-		server := dps.NewServer(reader, codec)
+		server := dps.NewServer(reader, codec, nil)
 
 		req := &dps.ListSealsForHeightRequest{
 			Height: mocks.GenericHeight,
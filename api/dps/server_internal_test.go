@@ -16,29 +16,40 @@ package dps
 
 import (
 	"context"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/testing/mocks"
 )
 
 func TestNewServer(t *testing.T) {
 	index := mocks.BaselineReader(t)
 	codec := mocks.BaselineCodec(t)
+	invoke := mocks.BaselineInvoker(t)
 
-	s := NewServer(index, codec)
+	s := NewServer(index, codec, invoke)
 
 	assert.NotNil(t, s)
 	assert.NotNil(t, s.codec)
 	assert.Equal(t, index, s.index)
 	assert.Equal(t, codec, s.codec)
+	assert.Equal(t, invoke, s.invoke)
+	assert.Equal(t, DefaultConfig, s.cfg)
 	assert.NotNil(t, s.validate)
 }
 
@@ -161,6 +172,66 @@ func TestServer_GetLast(t *testing.T) {
 	}
 }
 
+func TestServer_GetFloor(t *testing.T) {
+
+	tests := []struct {
+		name string
+
+		mockErr error
+
+		wantRes *GetFloorResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			mockErr: nil,
+
+			wantRes: &GetFloorResponse{
+				Height: mocks.GenericHeight,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "error case",
+
+			mockErr: mocks.GenericError,
+
+			wantRes: nil,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.FloorFunc = func() (uint64, error) {
+				return mocks.GenericHeight, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			req := &GetFloorRequest{}
+
+			gotRes, gotErr := s.GetFloor(context.Background(), req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
+			}
+		})
+	}
+}
+
 func TestServer_GetHeightForBlock(t *testing.T) {
 	blockID := mocks.GenericHeader.ID()
 	tests := []struct {
@@ -389,12 +460,102 @@ func TestServer_GetHeader(t *testing.T) {
 	}
 }
 
+func TestServer_GetHeaderByBlockID(t *testing.T) {
+	header := mocks.GenericHeader
+	blockID := header.ID()
+
+	tests := []struct {
+		name string
+
+		req *GetHeaderByBlockIDRequest
+
+		mockHeader *flow.Header
+		mockErr    error
+
+		wantRes *GetHeaderByBlockIDResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetHeaderByBlockIDRequest{
+				BlockID: mocks.ByteSlice(blockID),
+			},
+
+			mockHeader: header,
+			mockErr:    nil,
+
+			wantRes: &GetHeaderByBlockIDResponse{
+				BlockID: mocks.ByteSlice(blockID),
+				Height:  header.Height,
+				Data:    mocks.GenericBytes,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid block ID",
+
+			req: &GetHeaderByBlockIDRequest{
+				BlockID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error case",
+
+			req: &GetHeaderByBlockIDRequest{
+				BlockID: mocks.ByteSlice(blockID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			codec := mocks.BaselineCodec(t)
+			codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+				assert.IsType(t, &flow.Header{}, v)
+				return mocks.GenericBytes, nil
+			}
+
+			index := mocks.BaselineReader(t)
+			index.HeaderByBlockIDFunc = func(got flow.Identifier) (*flow.Header, error) {
+				assert.Equal(t, blockID, got)
+				return test.mockHeader, test.mockErr
+			}
+
+			s := Server{
+				codec:    codec,
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetHeaderByBlockID(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
+			}
+		})
+	}
+}
+
 func TestServer_GetEvents(t *testing.T) {
 	tests := []struct {
 		name string
 
 		reqHeight uint64
 		reqTypes  []flow.EventType
+		reqFields []string
 
 		mockEvents []flow.Event
 		mockErr    error
@@ -424,6 +585,37 @@ func TestServer_GetEvents(t *testing.T) {
 
 			checkErr: require.NoError,
 		},
+		{
+			name: "nominal case with type prefix",
+
+			reqHeight: mocks.GenericHeight,
+			reqTypes:  []flow.EventType{flow.EventType(string(mocks.GenericEventType(0)[:2]) + "*")},
+
+			mockEvents: mocks.GenericEvents(4, mocks.GenericEventTypes(2)...),
+			mockErr:    nil,
+
+			wantHeight: mocks.GenericHeight,
+			wantTypes:  nil,
+			wantRes: &GetEventsResponse{
+				Height: mocks.GenericHeight,
+				Types:  []string{string(mocks.GenericEventType(0)[:2]) + "*"},
+				Data:   mocks.GenericBytes,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid field predicate",
+
+			reqHeight: mocks.GenericHeight,
+			reqFields: []string{"address"},
+
+			wantHeight: 0,
+			wantTypes:  nil,
+			wantRes:    nil,
+
+			checkErr: require.Error,
+		},
 		{
 			name: "error case",
 
@@ -470,11 +662,12 @@ func TestServer_GetEvents(t *testing.T) {
 			req := &GetEventsRequest{
 				Height: test.reqHeight,
 				Types:  convert.TypesToStrings(test.reqTypes),
+				Fields: test.reqFields,
 			}
 			gotRes, gotErr := s.GetEvents(context.Background(), req)
 
 			test.checkErr(t, gotErr)
-			assert.Equal(t, mocks.GenericHeight, gotHeight)
+			assert.Equal(t, test.wantHeight, gotHeight)
 			assert.Equal(t, test.wantTypes, gotTypes)
 			if gotErr == nil {
 				assert.Equal(t, test.wantRes, gotRes)
@@ -483,69 +676,76 @@ func TestServer_GetEvents(t *testing.T) {
 	}
 }
 
-func TestServer_GetRegisterValues(t *testing.T) {
+func TestServer_ListHeaders(t *testing.T) {
+	headers := []*flow.Header{
+		{Height: mocks.GenericHeight},
+		{Height: mocks.GenericHeight + 1},
+		{Height: mocks.GenericHeight + 2},
+	}
+
 	tests := []struct {
 		name string
 
-		req *GetRegisterValuesRequest
+		req *ListHeadersRequest
 
-		mockErr error
+		mockHeaders []*flow.Header
+		mockErr     error
+		mockSendErr error
 
-		want *GetRegisterValuesResponse
+		wantHeights []uint64
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetRegisterValuesRequest{
-				Height: mocks.GenericHeight,
-				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			req: &ListHeadersRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
-			mockErr: nil,
+			mockHeaders: headers,
 
-			want: &GetRegisterValuesResponse{
-				Height: mocks.GenericHeight,
-				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
-				Values: convert.ValuesToBytes(mocks.GenericLedgerValues(6)),
-			},
+			wantHeights: []uint64{mocks.GenericHeight, mocks.GenericHeight + 1, mocks.GenericHeight + 2},
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles missing paths",
+			name: "handles invalid range",
 
-			req: &GetRegisterValuesRequest{
-				Height: mocks.GenericHeight,
+			req: &ListHeadersRequest{
+				Start: mocks.GenericHeight + 2,
+				End:   mocks.GenericHeight,
 			},
 
-			want: nil,
+			mockHeaders: headers,
 
 			checkErr: require.Error,
 		},
 		{
-			name: "handles paths with invalid lengths",
+			name: "handles index failure",
 
-			req: &GetRegisterValuesRequest{
-				Height: mocks.GenericHeight,
-				Paths:  [][]byte{mocks.GenericBytes},
+			req: &ListHeadersRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
-			want: nil,
+			mockErr: mocks.GenericError,
 
 			checkErr: require.Error,
 		},
 		{
-			name: "error case",
+			name: "handles send failure",
 
-			req: &GetRegisterValuesRequest{
-				Height: mocks.GenericHeight,
-				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			req: &ListHeadersRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
-			mockErr: mocks.GenericError,
 
-			want: nil,
+			mockHeaders: headers,
+			mockSendErr: mocks.GenericError,
+
+			wantHeights: []uint64{mocks.GenericHeight},
 
 			checkErr: require.Error,
 		},
@@ -556,80 +756,131 @@ func TestServer_GetRegisterValues(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			var gotHeight uint64
-			var gotPaths []ledger.Path
+			codec := mocks.BaselineCodec(t)
+			codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+				assert.IsType(t, &flow.Header{}, v)
+				return mocks.GenericBytes, nil
+			}
+
 			index := mocks.BaselineReader(t)
-			index.ValuesFunc = func(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
-				gotHeight = height
-				gotPaths = paths
-				return mocks.GenericLedgerValues(6), test.mockErr
+			index.HeadersFunc = func(start uint64, end uint64) ([]*flow.Header, error) {
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				return test.mockHeaders, test.mockErr
+			}
+
+			var gotHeights []uint64
+			stream := &listHeadersMock{
+				SendFunc: func(res *ListHeadersResponse) error {
+					assert.Equal(t, mocks.GenericBytes, res.Data)
+					gotHeights = append(gotHeights, res.Height)
+					return test.mockSendErr
+				},
 			}
 
 			s := Server{
+				codec:    codec,
 				index:    index,
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetRegisterValues(context.Background(), test.req)
+			gotErr := s.ListHeaders(test.req, stream)
 
 			test.checkErr(t, gotErr)
-			if test.want != nil {
-				assert.Equal(t, test.want.Height, gotHeight)
-				assert.ElementsMatch(t, test.want.Paths, convert.PathsToBytes(gotPaths))
-
-				require.NotNil(t, gotRes)
-				assert.Equal(t, test.want.Height, gotRes.Height)
-				assert.ElementsMatch(t, test.want.Values, gotRes.Values)
-				assert.ElementsMatch(t, test.want.Paths, gotRes.Paths)
-			}
+			assert.Equal(t, test.wantHeights, gotHeights)
 		})
 	}
 }
 
-func TestServer_GetCollection(t *testing.T) {
-	collection := mocks.GenericCollection(0)
-
+func TestServer_ListCommits(t *testing.T) {
 	tests := []struct {
 		name string
 
-		req *GetCollectionRequest
+		req *ListCommitsRequest
 
-		mockCollection *flow.LightCollection
-		mockErr        error
+		mockCommits map[uint64]flow.StateCommitment
+		mockErr     error
+		mockSendErr error
+
+		wantHeights []uint64
+		wantCommits [][]byte
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetCollectionRequest{
-				CollectionID: mocks.ByteSlice(collection.ID()),
+			req: &ListCommitsRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 1,
 			},
 
-			mockCollection: collection,
+			mockCommits: map[uint64]flow.StateCommitment{
+				mocks.GenericHeight:     mocks.GenericCommit(0),
+				mocks.GenericHeight + 1: mocks.GenericCommit(1),
+			},
+
+			wantHeights: []uint64{mocks.GenericHeight, mocks.GenericHeight + 1},
+			wantCommits: [][]byte{mocks.ByteSlice(mocks.GenericCommit(0)), mocks.ByteSlice(mocks.GenericCommit(1))},
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid collection ID",
+			name: "derives heights from index",
 
-			req: &GetCollectionRequest{
-				CollectionID: mocks.GenericBytes,
+			req: &ListCommitsRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
-			mockCollection: collection,
+			mockCommits: map[uint64]flow.StateCommitment{
+				mocks.GenericHeight + 2: mocks.GenericCommit(1),
+				mocks.GenericHeight:     mocks.GenericCommit(0),
+			},
+
+			wantHeights: []uint64{mocks.GenericHeight, mocks.GenericHeight + 2},
+			wantCommits: [][]byte{mocks.ByteSlice(mocks.GenericCommit(0)), mocks.ByteSlice(mocks.GenericCommit(1))},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid range",
+
+			req: &ListCommitsRequest{
+				Start: mocks.GenericHeight + 1,
+				End:   mocks.GenericHeight,
+			},
 
 			checkErr: require.Error,
 		},
 		{
 			name: "handles index failure",
 
-			req: &GetCollectionRequest{
-				CollectionID: mocks.ByteSlice(collection.ID()),
+			req: &ListCommitsRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 1,
 			},
 
-			mockCollection: collection,
-			mockErr:        mocks.GenericError,
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles send failure",
+
+			req: &ListCommitsRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 1,
+			},
+
+			mockCommits: map[uint64]flow.StateCommitment{
+				mocks.GenericHeight:     mocks.GenericCommit(0),
+				mocks.GenericHeight + 1: mocks.GenericCommit(1),
+			},
+			mockSendErr: mocks.GenericError,
+
+			wantHeights: []uint64{mocks.GenericHeight},
+			wantCommits: [][]byte{mocks.ByteSlice(mocks.GenericCommit(0))},
 
 			checkErr: require.Error,
 		},
@@ -641,56 +892,108 @@ func TestServer_GetCollection(t *testing.T) {
 			t.Parallel()
 
 			index := mocks.BaselineReader(t)
-			index.CollectionFunc = func(id flow.Identifier) (*flow.LightCollection, error) {
-				return test.mockCollection, test.mockErr
+			index.CommitsFunc = func(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				return test.mockCommits, test.mockErr
+			}
+
+			var gotHeights []uint64
+			var gotCommits [][]byte
+			stream := &listCommitsMock{
+				SendFunc: func(res *ListCommitsResponse) error {
+					gotHeights = append(gotHeights, res.Height)
+					gotCommits = append(gotCommits, res.Commit)
+					return test.mockSendErr
+				},
 			}
 
 			s := Server{
-				codec:    mocks.BaselineCodec(t),
 				index:    index,
 				validate: validator.New(),
 			}
 
-			wantID := test.mockCollection.ID()
-			gotRes, gotErr := s.GetCollection(context.Background(), test.req)
+			gotErr := s.ListCommits(test.req, stream)
 
 			test.checkErr(t, gotErr)
-
-			if gotRes != nil {
-				assert.Equal(t, gotRes.CollectionID, wantID[:])
-				assert.NotEmpty(t, gotRes.Data)
-			}
+			assert.Equal(t, test.wantHeights, gotHeights)
+			assert.Equal(t, test.wantCommits, gotCommits)
 		})
 	}
 }
 
-func TestServer_ListCollectionsForHeight(t *testing.T) {
+func TestServer_GetEventsRange(t *testing.T) {
+	events := map[uint64][]flow.Event{
+		mocks.GenericHeight:     mocks.GenericEvents(2),
+		mocks.GenericHeight + 2: mocks.GenericEvents(4),
+	}
+
 	tests := []struct {
 		name string
 
-		reqHeight uint64
+		req *GetEventsRangeRequest
 
-		mockCollections []flow.Identifier
-		mockErr         error
+		mockEvents  map[uint64][]flow.Event
+		mockErr     error
+		mockSendErr error
+
+		wantHeights []uint64
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			reqHeight: mocks.GenericHeight,
+			req: &GetEventsRangeRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
+				Types: convert.TypesToStrings(mocks.GenericEventTypes(2)),
+			},
 
-			mockCollections: mocks.GenericCollectionIDs(5),
+			mockEvents: events,
+
+			wantHeights: []uint64{mocks.GenericHeight, mocks.GenericHeight + 2},
 
 			checkErr: require.NoError,
 		},
+		{
+			name: "handles invalid range",
+
+			req: &GetEventsRangeRequest{
+				Start: mocks.GenericHeight + 2,
+				End:   mocks.GenericHeight,
+				Types: convert.TypesToStrings(mocks.GenericEventTypes(2)),
+			},
+
+			checkErr: require.Error,
+		},
 		{
 			name: "handles index failure",
 
-			reqHeight: mocks.GenericHeight,
+			req: &GetEventsRangeRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
+				Types: convert.TypesToStrings(mocks.GenericEventTypes(2)),
+			},
 
 			mockErr: mocks.GenericError,
 
+			checkErr: require.Error,
+		},
+		{
+			name: "handles send failure",
+
+			req: &GetEventsRangeRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
+				Types: convert.TypesToStrings(mocks.GenericEventTypes(2)),
+			},
+
+			mockEvents:  events,
+			mockSendErr: mocks.GenericError,
+
+			wantHeights: []uint64{mocks.GenericHeight},
+
 			checkErr: require.Error,
 		},
 	}
@@ -700,61 +1003,84 @@ func TestServer_ListCollectionsForHeight(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
+			codec := mocks.BaselineCodec(t)
+			codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+				assert.IsType(t, []flow.Event{}, v)
+				return mocks.GenericBytes, nil
+			}
+
 			index := mocks.BaselineReader(t)
-			index.CollectionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-				return test.mockCollections, test.mockErr
+			index.EventsRangeFunc = func(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				assert.Equal(t, test.req.Types, convert.TypesToStrings(types))
+				return test.mockEvents, test.mockErr
+			}
+
+			var gotHeights []uint64
+			stream := &getEventsRangeMock{
+				SendFunc: func(res *GetEventsRangeResponse) error {
+					assert.Equal(t, test.req.Types, res.Types)
+					assert.Equal(t, mocks.GenericBytes, res.Data)
+					gotHeights = append(gotHeights, res.Height)
+					return test.mockSendErr
+				},
 			}
 
 			s := Server{
+				codec:    codec,
 				index:    index,
 				validate: validator.New(),
 			}
 
-			req := &ListCollectionsForHeightRequest{
-				Height: mocks.GenericHeight,
-			}
-			gotRes, gotErr := s.ListCollectionsForHeight(context.Background(), req)
+			gotErr := s.GetEventsRange(test.req, stream)
 
 			test.checkErr(t, gotErr)
-			if gotErr == nil {
-				assert.Equal(t, gotRes.Height, mocks.GenericHeight)
-				assert.Len(t, gotRes.CollectionIDs, len(test.mockCollections))
-				for _, want := range test.mockCollections {
-					assert.Contains(t, gotRes.CollectionIDs, want[:])
-				}
-			}
+			assert.Equal(t, test.wantHeights, gotHeights)
 		})
 	}
 }
 
-func TestServer_GetGuarantee(t *testing.T) {
-	guarantee := mocks.GenericGuarantee(0)
+func TestServer_ListHeightsForEvent(t *testing.T) {
+	typ := mocks.GenericEventType(0)
+	heights := []uint64{mocks.GenericHeight, mocks.GenericHeight + 2}
 	tests := []struct {
 		name string
 
-		req *GetGuaranteeRequest
+		req *ListHeightsForEventRequest
 
 		mockErr error
 
-		wantGuarantee *flow.CollectionGuarantee
-
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetGuaranteeRequest{
-				CollectionID: mocks.ByteSlice(guarantee.CollectionID),
+			req: &ListHeightsForEventRequest{
+				Type:  string(typ),
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
-			wantGuarantee: guarantee,
-			checkErr:      require.NoError,
+			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid collection ID",
+			name: "handles missing type",
 
-			req: &GetGuaranteeRequest{
-				CollectionID: mocks.GenericBytes,
+			req: &ListHeightsForEventRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid range",
+
+			req: &ListHeightsForEventRequest{
+				Type:  string(typ),
+				Start: mocks.GenericHeight + 2,
+				End:   mocks.GenericHeight,
 			},
 
 			checkErr: require.Error,
@@ -762,8 +1088,10 @@ func TestServer_GetGuarantee(t *testing.T) {
 		{
 			name: "handles index failure",
 
-			req: &GetGuaranteeRequest{
-				CollectionID: mocks.ByteSlice(guarantee.CollectionID),
+			req: &ListHeightsForEventRequest{
+				Type:  string(typ),
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
 			mockErr: mocks.GenericError,
@@ -778,68 +1106,110 @@ func TestServer_GetGuarantee(t *testing.T) {
 			t.Parallel()
 
 			index := mocks.BaselineReader(t)
-			index.GuaranteeFunc = func(id flow.Identifier) (*flow.CollectionGuarantee, error) {
-				return test.wantGuarantee, test.mockErr
+			index.EventHeightsFunc = func(gotType flow.EventType, start uint64, end uint64) ([]uint64, error) {
+				assert.Equal(t, typ, gotType)
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+
+				return heights, test.mockErr
 			}
 
 			s := Server{
-				codec:    mocks.BaselineCodec(t),
 				index:    index,
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetGuarantee(context.Background(), test.req)
+			gotRes, gotErr := s.ListHeightsForEvent(context.Background(), test.req)
 
 			test.checkErr(t, gotErr)
 			if gotErr == nil {
-				assert.Equal(t, gotRes.CollectionID, test.req.CollectionID)
-				assert.NotEmpty(t, gotRes.Data)
+				assert.Equal(t, test.req.Type, gotRes.Type)
+				assert.Equal(t, test.req.Start, gotRes.Start)
+				assert.Equal(t, test.req.End, gotRes.End)
+				assert.Equal(t, heights, gotRes.Heights)
 			}
 		})
 	}
 }
 
-func TestServer_GetTransaction(t *testing.T) {
-	tx := mocks.GenericTransaction(0)
+func TestServer_GetRegisterValues(t *testing.T) {
 	tests := []struct {
 		name string
 
-		req *GetTransactionRequest
+		req      *GetRegisterValuesRequest
+		maxPaths uint
 
 		mockErr error
 
-		wantTransaction *flow.TransactionBody
+		want *GetRegisterValuesResponse
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetTransactionRequest{
-				TransactionID: mocks.ByteSlice(tx.ID()),
+			req: &GetRegisterValuesRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
 			},
 
-			wantTransaction: tx,
-			checkErr:        require.NoError,
+			mockErr: nil,
+
+			want: &GetRegisterValuesResponse{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+				Values: convert.ValuesToBytes(mocks.GenericLedgerValues(6)),
+			},
+
+			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid transaction ID",
+			name: "handles missing paths",
 
-			req: &GetTransactionRequest{
-				TransactionID: mocks.GenericBytes,
+			req: &GetRegisterValuesRequest{
+				Height: mocks.GenericHeight,
 			},
 
+			want: nil,
+
 			checkErr: require.Error,
 		},
 		{
-			name: "handles index failure",
+			name: "handles paths with invalid lengths",
 
-			req: &GetTransactionRequest{
-				TransactionID: mocks.ByteSlice(tx.ID()),
+			req: &GetRegisterValuesRequest{
+				Height: mocks.GenericHeight,
+				Paths:  [][]byte{mocks.GenericBytes},
+			},
+
+			want: nil,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles too many paths",
+
+			req: &GetRegisterValuesRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
 			},
+			maxPaths: 5,
+
+			want: nil,
 
+			checkErr: require.Error,
+		},
+		{
+			name: "error case",
+
+			req: &GetRegisterValuesRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			},
 			mockErr: mocks.GenericError,
 
+			want: nil,
+
 			checkErr: require.Error,
 		},
 	}
@@ -849,69 +1219,123 @@ func TestServer_GetTransaction(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
+			var gotHeight uint64
+			var gotPaths []ledger.Path
 			index := mocks.BaselineReader(t)
-			index.TransactionFunc = func(transactionID flow.Identifier) (*flow.TransactionBody, error) {
-				return test.wantTransaction, test.mockErr
+			index.ValuesFunc = func(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
+				gotHeight = height
+				gotPaths = paths
+				return mocks.GenericLedgerValues(6), test.mockErr
 			}
 
 			s := Server{
-				codec:    mocks.BaselineCodec(t),
 				index:    index,
+				cfg:      Config{MaxPaths: test.maxPaths},
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetTransaction(context.Background(), test.req)
+			gotRes, gotErr := s.GetRegisterValues(context.Background(), test.req)
 
 			test.checkErr(t, gotErr)
+			if test.want != nil {
+				assert.Equal(t, test.want.Height, gotHeight)
+				assert.ElementsMatch(t, test.want.Paths, convert.PathsToBytes(gotPaths))
 
-			if test.wantTransaction != nil {
-				assert.Equal(t, gotRes.TransactionID, mocks.ByteSlice(tx.ID()))
-				assert.NotEmpty(t, gotRes.Data)
+				require.NotNil(t, gotRes)
+				assert.Equal(t, test.want.Height, gotRes.Height)
+				assert.ElementsMatch(t, test.want.Values, gotRes.Values)
+				assert.ElementsMatch(t, test.want.Paths, gotRes.Paths)
 			}
 		})
 	}
 }
 
-func TestServer_GetHeightForTransaction(t *testing.T) {
-	blockID := mocks.GenericHeader.ID()
+func TestServer_GetRegistersByKey(t *testing.T) {
+	owner := mocks.GenericAddress(0).Bytes()
+	regIDs := []*RegisterID{
+		{Owner: owner, Controller: nil, Key: []byte("public_key_0")},
+		{Owner: owner, Controller: owner, Key: []byte("contract_names")},
+	}
+	values := mocks.GenericLedgerValues(2)
+
+	var paths []ledger.Path
+	for _, regID := range regIDs {
+		path, err := convert.RegisterIDToPath(flow.NewRegisterID(string(regID.Owner), string(regID.Controller), string(regID.Key)))
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+
 	tests := []struct {
 		name string
 
-		req *GetHeightForTransactionRequest
+		req      *GetRegistersByKeyRequest
+		maxPaths uint
 
-		mockErr error
+		mockValues []ledger.Value
+		mockErr    error
 
-		wantTxID flow.Identifier
+		want *GetRegistersByKeyResponse
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetHeightForTransactionRequest{
-				TransactionID: mocks.ByteSlice(blockID),
+			req: &GetRegistersByKeyRequest{
+				Height:      mocks.GenericHeight,
+				RegisterIDs: regIDs,
 			},
 
-			mockErr: nil,
+			mockValues: values,
 
-			wantTxID: blockID,
+			want: &GetRegistersByKeyResponse{
+				Height: mocks.GenericHeight,
+				Registers: []*Register{
+					{RegisterID: regIDs[0], Value: values[0]},
+					{RegisterID: regIDs[1], Value: values[1]},
+				},
+			},
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid transaction ID",
+			name: "handles missing register IDs",
 
-			req: &GetHeightForTransactionRequest{
-				TransactionID: mocks.GenericBytes,
+			req: &GetRegistersByKeyRequest{
+				Height: mocks.GenericHeight,
 			},
 
 			checkErr: require.Error,
 		},
 		{
-			name: "error handling",
+			name: "handles too many register IDs",
 
-			req: &GetHeightForTransactionRequest{
-				TransactionID: mocks.ByteSlice(blockID),
+			req: &GetRegistersByKeyRequest{
+				Height:      mocks.GenericHeight,
+				RegisterIDs: regIDs,
+			},
+			maxPaths: 1,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles mismatching number of values",
+
+			req: &GetRegistersByKeyRequest{
+				Height:      mocks.GenericHeight,
+				RegisterIDs: regIDs,
+			},
+
+			mockValues: values[:1],
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetRegistersByKeyRequest{
+				Height:      mocks.GenericHeight,
+				RegisterIDs: regIDs,
 			},
 
 			mockErr: mocks.GenericError,
@@ -926,117 +1350,213 @@ func TestServer_GetHeightForTransaction(t *testing.T) {
 			t.Parallel()
 
 			index := mocks.BaselineReader(t)
-			index.HeightForTransactionFunc = func(blockID flow.Identifier) (uint64, error) {
-				return mocks.GenericHeight, test.mockErr
+			index.ValuesFunc = func(height uint64, got []ledger.Path) ([]ledger.Value, error) {
+				assert.Equal(t, mocks.GenericHeight, height)
+				assert.Equal(t, paths, got)
+				return test.mockValues, test.mockErr
 			}
 
 			s := Server{
 				index:    index,
+				cfg:      Config{MaxPaths: test.maxPaths},
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetHeightForTransaction(context.Background(), test.req)
+			got, err := s.GetRegistersByKey(context.Background(), test.req)
 
-			test.checkErr(t, gotErr)
-			if gotErr == nil {
-				assert.Equal(t, mocks.GenericHeight, gotRes.Height)
-				assert.Equal(t, test.wantTxID[:], gotRes.TransactionID)
+			test.checkErr(t, err)
+			if err == nil {
+				assert.Equal(t, test.want, got)
 			}
 		})
 	}
 }
 
-func TestServer_ListTransactionsForHeight(t *testing.T) {
+func TestServer_GetRegisterProofs(t *testing.T) {
 	tests := []struct {
 		name string
 
-		reqHeight uint64
+		req *GetRegisterProofsRequest
 
-		mockTransactions []flow.Identifier
-		mockErr          error
+		mockErr  error
+		noProofs bool
+		maxPaths uint
+
+		want *GetRegisterProofsResponse
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			reqHeight: mocks.GenericHeight,
+			req: &GetRegisterProofsRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			},
 
-			mockTransactions: mocks.GenericTransactionIDs(5),
+			mockErr: nil,
+
+			want: &GetRegisterProofsResponse{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+				Proofs: convert.ProofsToBytes(mocks.GenericTrieBatchProof(6)),
+			},
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles index failure",
+			name: "handles missing paths",
 
-			reqHeight: mocks.GenericHeight,
+			req: &GetRegisterProofsRequest{
+				Height: mocks.GenericHeight,
+			},
 
-			mockErr: mocks.GenericError,
+			want: nil,
 
 			checkErr: require.Error,
 		},
-	}
+		{
+			name: "handles paths with invalid lengths",
 
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			t.Parallel()
+			req: &GetRegisterProofsRequest{
+				Height: mocks.GenericHeight,
+				Paths:  [][]byte{mocks.GenericBytes},
+			},
 
-			index := mocks.BaselineReader(t)
-			index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-				return test.mockTransactions, test.mockErr
-			}
+			want: nil,
 
-			s := Server{
-				index:    index,
-				validate: validator.New(),
-			}
+			checkErr: require.Error,
+		},
+		{
+			name: "handles too many paths",
 
-			req := &ListTransactionsForHeightRequest{
+			req: &GetRegisterProofsRequest{
 				Height: mocks.GenericHeight,
-			}
-			gotRes, gotErr := s.ListTransactionsForHeight(context.Background(), req)
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			},
+			maxPaths: 5,
 
-			test.checkErr(t, gotErr)
-			if gotErr == nil {
-				assert.Equal(t, gotRes.Height, mocks.GenericHeight)
-				assert.Len(t, gotRes.TransactionIDs, len(test.mockTransactions))
-				for _, want := range test.mockTransactions {
-					assert.Contains(t, gotRes.TransactionIDs, want[:])
-				}
+			want: nil,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles disabled proofs",
+
+			req: &GetRegisterProofsRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			},
+			noProofs: true,
+
+			want: nil,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error case",
+
+			req: &GetRegisterProofsRequest{
+				Height: mocks.GenericHeight,
+				Paths:  convert.PathsToBytes(mocks.GenericLedgerPaths(6)),
+			},
+			mockErr: mocks.GenericError,
+
+			want: nil,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotHeight uint64
+			var gotPaths []ledger.Path
+			index := mocks.BaselineReader(t)
+			index.ProofsFunc = func(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+				gotHeight = height
+				gotPaths = paths
+				return mocks.GenericTrieBatchProof(6), test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				cfg:      Config{Proofs: !test.noProofs, MaxPaths: test.maxPaths},
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetRegisterProofs(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if test.want != nil {
+				assert.Equal(t, test.want.Height, gotHeight)
+				assert.Equal(t, test.want.Paths, convert.PathsToBytes(gotPaths))
+
+				require.NotNil(t, gotRes)
+				assert.Equal(t, test.want.Height, gotRes.Height)
+				assert.Equal(t, test.want.Proofs, gotRes.Proofs)
+				assert.Equal(t, test.want.Paths, gotRes.Paths)
 			}
 		})
 	}
 }
 
-func TestServer_GetResult(t *testing.T) {
-	result := mocks.GenericResult(0)
+func TestServer_GetRegisterHistory(t *testing.T) {
+	path := mocks.GenericLedgerPath(0)
+	payloads := map[uint64]*ledger.Payload{
+		mocks.GenericHeight + 2: mocks.GenericLedgerPayload(1),
+		mocks.GenericHeight:     mocks.GenericLedgerPayload(0),
+	}
+
 	tests := []struct {
 		name string
 
-		req *GetResultRequest
+		req *GetRegisterHistoryRequest
 
-		mockResult *flow.TransactionResult
-		mockErr    error
+		mockPayloads map[uint64]*ledger.Payload
+		mockErr      error
+		mockSendErr  error
+
+		wantHeights []uint64
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetResultRequest{
-				TransactionID: mocks.ByteSlice(result.TransactionID),
+			req: &GetRegisterHistoryRequest{
+				Path:  path[:],
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 4,
 			},
 
-			mockResult: result,
+			mockPayloads: payloads,
+
+			wantHeights: []uint64{mocks.GenericHeight, mocks.GenericHeight + 2},
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid transaction ID",
+			name: "handles invalid path",
 
-			req: &GetResultRequest{
-				TransactionID: mocks.GenericBytes,
+			req: &GetRegisterHistoryRequest{
+				Path:  mocks.GenericBytes,
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 4,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid range",
+
+			req: &GetRegisterHistoryRequest{
+				Path:  path[:],
+				Start: mocks.GenericHeight + 4,
+				End:   mocks.GenericHeight,
 			},
 
 			checkErr: require.Error,
@@ -1044,12 +1564,30 @@ func TestServer_GetResult(t *testing.T) {
 		{
 			name: "handles index failure",
 
-			req: &GetResultRequest{
-				TransactionID: mocks.ByteSlice(result.TransactionID),
+			req: &GetRegisterHistoryRequest{
+				Path:  path[:],
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 4,
 			},
 
 			mockErr: mocks.GenericError,
 
+			checkErr: require.Error,
+		},
+		{
+			name: "handles send failure",
+
+			req: &GetRegisterHistoryRequest{
+				Path:  path[:],
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 4,
+			},
+
+			mockPayloads: payloads,
+			mockSendErr:  mocks.GenericError,
+
+			wantHeights: []uint64{mocks.GenericHeight},
+
 			checkErr: require.Error,
 		},
 	}
@@ -1059,56 +1597,109 @@ func TestServer_GetResult(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
+			codec := mocks.BaselineCodec(t)
+			codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+				assert.IsType(t, &ledger.Payload{}, v)
+				return mocks.GenericBytes, nil
+			}
+
 			index := mocks.BaselineReader(t)
-			index.ResultFunc = func(transactionID flow.Identifier) (*flow.TransactionResult, error) {
-				return test.mockResult, test.mockErr
+			index.RegisterHistoryFunc = func(gotPath ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+				assert.Equal(t, path, gotPath)
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				return test.mockPayloads, test.mockErr
+			}
+
+			var gotHeights []uint64
+			stream := &getRegisterHistoryMock{
+				SendFunc: func(res *GetRegisterHistoryResponse) error {
+					assert.Equal(t, test.req.Path, res.Path)
+					assert.Equal(t, mocks.GenericBytes, res.Data)
+					gotHeights = append(gotHeights, res.Height)
+					return test.mockSendErr
+				},
 			}
 
 			s := Server{
-				codec:    mocks.BaselineCodec(t),
+				codec:    codec,
 				index:    index,
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetResult(context.Background(), test.req)
+			gotErr := s.GetRegisterHistory(test.req, stream)
 
 			test.checkErr(t, gotErr)
-			if gotErr == nil {
-				assert.Equal(t, gotRes.TransactionID, mocks.ByteSlice(result.TransactionID))
-				assert.NotEmpty(t, gotRes.Data)
-			}
+			assert.Equal(t, test.wantHeights, gotHeights)
 		})
 	}
 }
 
-func TestServer_GetSeal(t *testing.T) {
-	seal := mocks.GenericSeal(0)
+func TestServer_GetStateDiff(t *testing.T) {
+	changes := mocks.GenericChanges(3)
+	changes[0].Before = nil
+	paths := make([][]byte, 0, len(changes))
+	for i := range changes {
+		paths = append(paths, changes[i].Path[:])
+	}
+
 	tests := []struct {
 		name string
 
-		req *GetSealRequest
+		req *GetStateDiffRequest
 
-		mockSeal *flow.Seal
-		mockErr  error
+		mockChanges []dps.Change
+		mockErr     error
+		mockSendErr error
+
+		wantPaths [][]byte
 
 		checkErr require.ErrorAssertionFunc
 	}{
 		{
 			name: "nominal case",
 
-			req: &GetSealRequest{
-				SealID: mocks.ByteSlice(seal.ID()),
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
 
-			mockSeal: seal,
+			mockChanges: changes,
+
+			wantPaths: paths,
 
 			checkErr: require.NoError,
 		},
 		{
-			name: "handles invalid seal ID",
+			name: "nominal case with maximum range",
 
-			req: &GetSealRequest{
-				SealID: mocks.GenericBytes,
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + MaxDiffRange,
+			},
+
+			mockChanges: changes,
+
+			wantPaths: paths,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid range",
+
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight + 2,
+				End:   mocks.GenericHeight,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles range above maximum",
+
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + MaxDiffRange + 1,
 			},
 
 			checkErr: require.Error,
@@ -1116,11 +1707,28 @@ func TestServer_GetSeal(t *testing.T) {
 		{
 			name: "handles index failure",
 
-			req: &GetSealRequest{
-				SealID: mocks.ByteSlice(seal.ID()),
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
 			},
+
 			mockErr: mocks.GenericError,
 
+			checkErr: require.Error,
+		},
+		{
+			name: "handles send failure",
+
+			req: &GetStateDiffRequest{
+				Start: mocks.GenericHeight,
+				End:   mocks.GenericHeight + 2,
+			},
+
+			mockChanges: changes,
+			mockSendErr: mocks.GenericError,
+
+			wantPaths: paths[:1],
+
 			checkErr: require.Error,
 		},
 	}
@@ -1130,9 +1738,113 @@ func TestServer_GetSeal(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
+			codec := mocks.BaselineCodec(t)
+			codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+				assert.IsType(t, &ledger.Payload{}, v)
+				return mocks.GenericBytes, nil
+			}
+
 			index := mocks.BaselineReader(t)
-			index.SealFunc = func(sealID flow.Identifier) (*flow.Seal, error) {
-				return test.mockSeal, test.mockErr
+			index.StateDiffFunc = func(start uint64, end uint64, process func(dps.Change) error) error {
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				if test.mockErr != nil {
+					return test.mockErr
+				}
+				for _, change := range test.mockChanges {
+					err := process(change)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			var gotPaths [][]byte
+			stream := &getStateDiffMock{
+				SendFunc: func(res *GetStateDiffResponse) error {
+					if len(gotPaths) == 0 {
+						assert.Empty(t, res.Before)
+					} else {
+						assert.Equal(t, mocks.GenericBytes, res.Before)
+					}
+					assert.Equal(t, mocks.GenericBytes, res.After)
+					gotPaths = append(gotPaths, res.Path)
+					return test.mockSendErr
+				},
+			}
+
+			s := Server{
+				codec:    codec,
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotErr := s.GetStateDiff(test.req, stream)
+
+			test.checkErr(t, gotErr)
+			assert.Equal(t, test.wantPaths, gotPaths)
+		})
+	}
+}
+
+func TestServer_GetCollection(t *testing.T) {
+	collection := mocks.GenericCollection(0)
+
+	tests := []struct {
+		name string
+
+		req *GetCollectionRequest
+
+		mockCollection *flow.LightCollection
+		mockErr        error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetCollectionRequest{
+				CollectionID: mocks.ByteSlice(collection.ID()),
+			},
+
+			mockCollection: collection,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid collection ID",
+
+			req: &GetCollectionRequest{
+				CollectionID: mocks.GenericBytes,
+			},
+
+			mockCollection: collection,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetCollectionRequest{
+				CollectionID: mocks.ByteSlice(collection.ID()),
+			},
+
+			mockCollection: collection,
+			mockErr:        mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.CollectionFunc = func(id flow.Identifier) (*flow.LightCollection, error) {
+				return test.mockCollection, test.mockErr
 			}
 
 			s := Server{
@@ -1141,27 +1853,27 @@ func TestServer_GetSeal(t *testing.T) {
 				validate: validator.New(),
 			}
 
-			gotRes, gotErr := s.GetSeal(context.Background(), test.req)
+			wantID := test.mockCollection.ID()
+			gotRes, gotErr := s.GetCollection(context.Background(), test.req)
 
 			test.checkErr(t, gotErr)
 
-			if gotErr == nil {
-				assert.Equal(t, gotRes.SealID, test.req.SealID)
+			if gotRes != nil {
+				assert.Equal(t, gotRes.CollectionID, wantID[:])
 				assert.NotEmpty(t, gotRes.Data)
 			}
 		})
 	}
 }
 
-func TestServer_ListSealsForHeight(t *testing.T) {
-	sealIDs := mocks.GenericSealIDs(5)
+func TestServer_ListCollectionsForHeight(t *testing.T) {
 	tests := []struct {
 		name string
 
 		reqHeight uint64
 
-		mockSeals []flow.Identifier
-		mockErr   error
+		mockCollections []flow.Identifier
+		mockErr         error
 
 		checkErr require.ErrorAssertionFunc
 	}{
@@ -1170,7 +1882,7 @@ func TestServer_ListSealsForHeight(t *testing.T) {
 
 			reqHeight: mocks.GenericHeight,
 
-			mockSeals: sealIDs,
+			mockCollections: mocks.GenericCollectionIDs(5),
 
 			checkErr: require.NoError,
 		},
@@ -1191,30 +1903,1929 @@ func TestServer_ListSealsForHeight(t *testing.T) {
 			t.Parallel()
 
 			index := mocks.BaselineReader(t)
-			index.SealsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
-				return test.mockSeals, test.mockErr
+			index.CollectionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+				return test.mockCollections, test.mockErr
 			}
 
 			s := Server{
-				codec:    mocks.BaselineCodec(t),
 				index:    index,
 				validate: validator.New(),
 			}
 
-			req := ListSealsForHeightRequest{
+			req := &ListCollectionsForHeightRequest{
 				Height: mocks.GenericHeight,
 			}
-
-			gotRes, gotErr := s.ListSealsForHeight(context.Background(), &req)
+			gotRes, gotErr := s.ListCollectionsForHeight(context.Background(), req)
 
 			test.checkErr(t, gotErr)
 			if gotErr == nil {
-				assert.Equal(t, gotRes.Height, test.reqHeight)
-				assert.Len(t, gotRes.SealIDs, len(test.mockSeals))
-				for _, want := range test.mockSeals {
-					assert.Contains(t, gotRes.SealIDs, want[:])
+				assert.Equal(t, gotRes.Height, mocks.GenericHeight)
+				assert.Len(t, gotRes.CollectionIDs, len(test.mockCollections))
+				for _, want := range test.mockCollections {
+					assert.Contains(t, gotRes.CollectionIDs, want[:])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_GetHeightForCollection(t *testing.T) {
+	collID := mocks.GenericCollection(0).ID()
+
+	tests := []struct {
+		name string
+
+		req *GetHeightForCollectionRequest
+
+		mockErr error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetHeightForCollectionRequest{
+				CollectionID: mocks.ByteSlice(collID),
+			},
+
+			mockErr: nil,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid collection ID",
+
+			req: &GetHeightForCollectionRequest{
+				CollectionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error handling",
+
+			req: &GetHeightForCollectionRequest{
+				CollectionID: mocks.ByteSlice(collID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.HeightForCollectionFunc = func(got flow.Identifier) (uint64, error) {
+				assert.Equal(t, collID, got)
+				return mocks.GenericHeight, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetHeightForCollection(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, mocks.GenericHeight, gotRes.Height)
+				assert.Equal(t, collID[:], gotRes.CollectionID)
+			}
+		})
+	}
+}
+
+func TestServer_GetGuarantee(t *testing.T) {
+	guarantee := mocks.GenericGuarantee(0)
+	tests := []struct {
+		name string
+
+		req *GetGuaranteeRequest
+
+		mockErr error
+
+		wantGuarantee *flow.CollectionGuarantee
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetGuaranteeRequest{
+				CollectionID: mocks.ByteSlice(guarantee.CollectionID),
+			},
+
+			wantGuarantee: guarantee,
+			checkErr:      require.NoError,
+		},
+		{
+			name: "handles invalid collection ID",
+
+			req: &GetGuaranteeRequest{
+				CollectionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetGuaranteeRequest{
+				CollectionID: mocks.ByteSlice(guarantee.CollectionID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.GuaranteeFunc = func(id flow.Identifier) (*flow.CollectionGuarantee, error) {
+				return test.wantGuarantee, test.mockErr
+			}
+
+			s := Server{
+				codec:    mocks.BaselineCodec(t),
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetGuarantee(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, gotRes.CollectionID, test.req.CollectionID)
+				assert.NotEmpty(t, gotRes.Data)
+			}
+		})
+	}
+}
+
+func TestServer_GetTransaction(t *testing.T) {
+	tx := mocks.GenericTransaction(0)
+	tests := []struct {
+		name string
+
+		req *GetTransactionRequest
+
+		mockErr error
+
+		wantTransaction *flow.TransactionBody
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetTransactionRequest{
+				TransactionID: mocks.ByteSlice(tx.ID()),
+			},
+
+			wantTransaction: tx,
+			checkErr:        require.NoError,
+		},
+		{
+			name: "handles invalid transaction ID",
+
+			req: &GetTransactionRequest{
+				TransactionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetTransactionRequest{
+				TransactionID: mocks.ByteSlice(tx.ID()),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.TransactionFunc = func(transactionID flow.Identifier) (*flow.TransactionBody, error) {
+				return test.wantTransaction, test.mockErr
+			}
+
+			s := Server{
+				codec:    mocks.BaselineCodec(t),
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetTransaction(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+
+			if test.wantTransaction != nil {
+				assert.Equal(t, gotRes.TransactionID, mocks.ByteSlice(tx.ID()))
+				assert.NotEmpty(t, gotRes.Data)
+			}
+		})
+	}
+}
+
+func TestServer_GetHeightForTransaction(t *testing.T) {
+	blockID := mocks.GenericHeader.ID()
+	tests := []struct {
+		name string
+
+		req *GetHeightForTransactionRequest
+
+		mockErr error
+
+		wantTxID flow.Identifier
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetHeightForTransactionRequest{
+				TransactionID: mocks.ByteSlice(blockID),
+			},
+
+			mockErr: nil,
+
+			wantTxID: blockID,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid transaction ID",
+
+			req: &GetHeightForTransactionRequest{
+				TransactionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error handling",
+
+			req: &GetHeightForTransactionRequest{
+				TransactionID: mocks.ByteSlice(blockID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.HeightForTransactionFunc = func(blockID flow.Identifier) (uint64, error) {
+				return mocks.GenericHeight, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetHeightForTransaction(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, mocks.GenericHeight, gotRes.Height)
+				assert.Equal(t, test.wantTxID[:], gotRes.TransactionID)
+			}
+		})
+	}
+}
+
+func TestServer_GetHeightForTimestamp(t *testing.T) {
+	timestamp := mocks.GenericHeader.Timestamp
+	tests := []struct {
+		name string
+
+		req *GetHeightForTimestampRequest
+
+		mockErr error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetHeightForTimestampRequest{
+				Timestamp: timestamp.UnixNano(),
+			},
+
+			mockErr: nil,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles missing timestamp",
+
+			req: &GetHeightForTimestampRequest{},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles negative timestamp",
+
+			req: &GetHeightForTimestampRequest{
+				Timestamp: -timestamp.UnixNano(),
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error handling",
+
+			req: &GetHeightForTimestampRequest{
+				Timestamp: timestamp.UnixNano(),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.HeightForTimeFunc = func(got time.Time) (uint64, error) {
+				assert.True(t, timestamp.Equal(got))
+
+				return mocks.GenericHeight, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetHeightForTimestamp(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, mocks.GenericHeight, gotRes.Height)
+				assert.Equal(t, test.req.Timestamp, gotRes.Timestamp)
+			}
+		})
+	}
+}
+
+func TestServer_ListTransactionsForHeight(t *testing.T) {
+	tests := []struct {
+		name string
+
+		reqHeight uint64
+
+		mockTransactions []flow.Identifier
+		mockErr          error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			reqHeight: mocks.GenericHeight,
+
+			mockTransactions: mocks.GenericTransactionIDs(5),
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles index failure",
+
+			reqHeight: mocks.GenericHeight,
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.TransactionsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+				return test.mockTransactions, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			req := &ListTransactionsForHeightRequest{
+				Height: mocks.GenericHeight,
+			}
+			gotRes, gotErr := s.ListTransactionsForHeight(context.Background(), req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, gotRes.Height, mocks.GenericHeight)
+				assert.Len(t, gotRes.TransactionIDs, len(test.mockTransactions))
+				for _, want := range test.mockTransactions {
+					assert.Contains(t, gotRes.TransactionIDs, want[:])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ListTransactionsForCollection(t *testing.T) {
+	collID := mocks.GenericCollection(0).ID()
+
+	tests := []struct {
+		name string
+
+		req *ListTransactionsForCollectionRequest
+
+		mockTransactions []flow.Identifier
+		mockErr          error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &ListTransactionsForCollectionRequest{
+				CollectionID: mocks.ByteSlice(collID),
+			},
+
+			mockTransactions: mocks.GenericTransactionIDs(5),
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid collection ID",
+
+			req: &ListTransactionsForCollectionRequest{
+				CollectionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &ListTransactionsForCollectionRequest{
+				CollectionID: mocks.ByteSlice(collID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.TransactionsByCollectionFunc = func(got flow.Identifier) ([]flow.Identifier, error) {
+				assert.Equal(t, collID, got)
+				return test.mockTransactions, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.ListTransactionsForCollection(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, collID[:], gotRes.CollectionID)
+				assert.Len(t, gotRes.TransactionIDs, len(test.mockTransactions))
+				for _, want := range test.mockTransactions {
+					assert.Contains(t, gotRes.TransactionIDs, want[:])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ListTransactionsForAccount(t *testing.T) {
+	address := mocks.GenericAddress(0)
+	txs := mocks.GenericAccountTransactions(4)
+
+	tests := []struct {
+		name string
+
+		req *ListTransactionsForAccountRequest
+
+		mockTransactions []dps.AccountTransaction
+		mockErr          error
+
+		wantAfter flow.Identifier
+		wantLimit uint
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+				After:   mocks.ByteSlice(txs[0].TransactionID),
+				Limit:   4,
+			},
+
+			mockTransactions: txs,
+
+			wantAfter: txs[0].TransactionID,
+			wantLimit: 4,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "uses default page size without limit",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+			},
+
+			mockTransactions: txs,
+
+			wantAfter: flow.ZeroID,
+			wantLimit: DefaultConfig.PageSize,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid address",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: mocks.GenericBytes,
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid transaction ID to start after",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+				After:   mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles limit above maximum page size",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+				Limit:   MaxPageSize + 1,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid range",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight + 3,
+				End:     mocks.GenericHeight,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &ListTransactionsForAccountRequest{
+				Address: address[:],
+				Start:   mocks.GenericHeight,
+				End:     mocks.GenericHeight + 3,
+			},
+
+			mockErr: mocks.GenericError,
+
+			wantLimit: DefaultConfig.PageSize,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.TransactionsByAccountFunc = func(gotAddress flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+				assert.Equal(t, address, gotAddress)
+				assert.Equal(t, test.req.Start, start)
+				assert.Equal(t, test.req.End, end)
+				assert.Equal(t, test.wantAfter, after)
+				assert.Equal(t, test.wantLimit, limit)
+				return test.mockTransactions, test.mockErr
+			}
+
+			s := Server{
+				cfg:      DefaultConfig,
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.ListTransactionsForAccount(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, address[:], gotRes.Address)
+				require.Len(t, gotRes.Transactions, len(test.mockTransactions))
+				for i, want := range test.mockTransactions {
+					got := gotRes.Transactions[i]
+					assert.Equal(t, want.Height, got.Height)
+					assert.Equal(t, want.TransactionID[:], got.TransactionID)
+					assert.False(t, got.Proposer)
+					assert.True(t, got.Payer)
+					assert.True(t, got.Authorizer)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_GetResult(t *testing.T) {
+	result := mocks.GenericResult(0)
+	tests := []struct {
+		name string
+
+		req *GetResultRequest
+
+		mockResult *flow.TransactionResult
+		mockErr    error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetResultRequest{
+				TransactionID: mocks.ByteSlice(result.TransactionID),
+			},
+
+			mockResult: result,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid transaction ID",
+
+			req: &GetResultRequest{
+				TransactionID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetResultRequest{
+				TransactionID: mocks.ByteSlice(result.TransactionID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.ResultFunc = func(transactionID flow.Identifier) (*flow.TransactionResult, error) {
+				return test.mockResult, test.mockErr
+			}
+
+			s := Server{
+				codec:    mocks.BaselineCodec(t),
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetResult(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, gotRes.TransactionID, mocks.ByteSlice(result.TransactionID))
+				assert.NotEmpty(t, gotRes.Data)
+			}
+		})
+	}
+}
+
+func TestServer_GetEventsDecoded(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		events := mocks.GenericEvents(4)
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return events, nil
+		}
+
+		s := Server{
+			codec:    mocks.BaselineCodec(t),
+			index:    index,
+			validate: validator.New(),
+		}
+
+		req := &GetEventsRequest{
+			Height:  mocks.GenericHeight,
+			Decoded: true,
+		}
+		got, err := s.GetEvents(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Data)
+		require.Len(t, got.Events, len(events))
+		for i, event := range events {
+			assert.Equal(t, string(event.Type), got.Events[i].Type)
+			assert.Equal(t, mocks.ByteSlice(event.TransactionID), got.Events[i].TransactionID)
+			assert.Equal(t, event.TransactionIndex, got.Events[i].TransactionIndex)
+			assert.Equal(t, event.EventIndex, got.Events[i].EventIndex)
+			assert.JSONEq(t, string(event.Payload), string(got.Events[i].Payload))
+			assert.False(t, got.Events[i].Undecoded)
+		}
+	})
+
+	t.Run("handles invalid payload", func(t *testing.T) {
+		t.Parallel()
+
+		event := mocks.GenericEvent(0)
+		event.Payload = mocks.GenericBytes
+
+		index := mocks.BaselineReader(t)
+		index.EventsFunc = func(uint64, ...flow.EventType) ([]flow.Event, error) {
+			return []flow.Event{event}, nil
+		}
+
+		s := Server{
+			codec:    mocks.BaselineCodec(t),
+			index:    index,
+			validate: validator.New(),
+		}
+
+		req := &GetEventsRequest{
+			Height:  mocks.GenericHeight,
+			Decoded: true,
+		}
+		got, err := s.GetEvents(context.Background(), req)
+
+		require.NoError(t, err)
+		require.Len(t, got.Events, 1)
+		assert.True(t, got.Events[0].Undecoded)
+		assert.Equal(t, event.Payload, got.Events[0].Payload)
+		assert.Equal(t, event.EventIndex, got.Events[0].EventIndex)
+	})
+}
+
+func TestServer_GetTransactionDecoded(t *testing.T) {
+	tx := mocks.GenericTransaction(0)
+	tx.Script = mocks.GenericBytes
+	tx.Arguments = [][]byte{json.MustEncode(mocks.GenericAmount(0))}
+	tx.ProposalKey = flow.ProposalKey{
+		Address:        mocks.GenericAddress(0),
+		KeyIndex:       1,
+		SequenceNumber: 2,
+	}
+	tx.Payer = mocks.GenericAddress(1)
+	tx.Authorizers = mocks.GenericAddresses(2)
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return tx, nil
+		}
+
+		s := Server{
+			codec:    mocks.BaselineCodec(t),
+			index:    index,
+			validate: validator.New(),
+		}
+
+		req := &GetTransactionRequest{
+			TransactionID: mocks.ByteSlice(tx.ID()),
+			Decoded:       true,
+		}
+		got, err := s.GetTransaction(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Data)
+		require.NotNil(t, got.Transaction)
+		assert.Equal(t, mocks.ByteSlice(tx.ReferenceBlockID), got.Transaction.ReferenceBlockID)
+		assert.Equal(t, string(tx.Script), got.Transaction.Script)
+		require.Len(t, got.Transaction.Arguments, 1)
+		assert.JSONEq(t, string(tx.Arguments[0]), string(got.Transaction.Arguments[0]))
+		require.NotNil(t, got.Transaction.ProposalKey)
+		assert.Equal(t, tx.ProposalKey.Address.Bytes(), got.Transaction.ProposalKey.Address)
+		assert.Equal(t, tx.ProposalKey.KeyIndex, got.Transaction.ProposalKey.KeyIndex)
+		assert.Equal(t, tx.ProposalKey.SequenceNumber, got.Transaction.ProposalKey.SequenceNumber)
+		assert.Equal(t, tx.Payer.Bytes(), got.Transaction.Payer)
+		assert.Equal(t, convert.AddressesToBytes(tx.Authorizers), got.Transaction.Authorizers)
+	})
+
+	t.Run("handles invalid arguments", func(t *testing.T) {
+		t.Parallel()
+
+		invalid := *tx
+		invalid.Arguments = [][]byte{mocks.GenericBytes}
+
+		index := mocks.BaselineReader(t)
+		index.TransactionFunc = func(flow.Identifier) (*flow.TransactionBody, error) {
+			return &invalid, nil
+		}
+
+		s := Server{
+			codec:    mocks.BaselineCodec(t),
+			index:    index,
+			validate: validator.New(),
+		}
+
+		req := &GetTransactionRequest{
+			TransactionID: mocks.ByteSlice(tx.ID()),
+			Decoded:       true,
+		}
+		got, err := s.GetTransaction(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Nil(t, got.Transaction)
+		assert.NotEmpty(t, got.Data)
+	})
+}
+
+func TestServer_GetResultDecoded(t *testing.T) {
+	result := mocks.GenericResult(0)
+	result.ErrorMessage = "error"
+	result.ComputationUsed = 42
+
+	index := mocks.BaselineReader(t)
+	index.ResultFunc = func(flow.Identifier) (*flow.TransactionResult, error) {
+		return result, nil
+	}
+
+	s := Server{
+		codec:    mocks.BaselineCodec(t),
+		index:    index,
+		validate: validator.New(),
+	}
+
+	req := &GetResultRequest{
+		TransactionID: mocks.ByteSlice(result.TransactionID),
+		Decoded:       true,
+	}
+	got, err := s.GetResult(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Empty(t, got.Data)
+	require.NotNil(t, got.Result)
+	assert.Equal(t, result.ErrorMessage, got.Result.ErrorMessage)
+	assert.Equal(t, result.ComputationUsed, got.Result.ComputationUsed)
+}
+
+func TestServer_GetSeal(t *testing.T) {
+	seal := mocks.GenericSeal(0)
+	tests := []struct {
+		name string
+
+		req *GetSealRequest
+
+		mockSeal *flow.Seal
+		mockErr  error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetSealRequest{
+				SealID: mocks.ByteSlice(seal.ID()),
+			},
+
+			mockSeal: seal,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid seal ID",
+
+			req: &GetSealRequest{
+				SealID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles index failure",
+
+			req: &GetSealRequest{
+				SealID: mocks.ByteSlice(seal.ID()),
+			},
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.SealFunc = func(sealID flow.Identifier) (*flow.Seal, error) {
+				return test.mockSeal, test.mockErr
+			}
+
+			s := Server{
+				codec:    mocks.BaselineCodec(t),
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetSeal(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+
+			if gotErr == nil {
+				assert.Equal(t, gotRes.SealID, test.req.SealID)
+				assert.NotEmpty(t, gotRes.Data)
+			}
+		})
+	}
+}
+
+func TestServer_ListSealsForHeight(t *testing.T) {
+	sealIDs := mocks.GenericSealIDs(5)
+	tests := []struct {
+		name string
+
+		reqHeight uint64
+
+		mockSeals []flow.Identifier
+		mockErr   error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			reqHeight: mocks.GenericHeight,
+
+			mockSeals: sealIDs,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles index failure",
+
+			reqHeight: mocks.GenericHeight,
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.SealsByHeightFunc = func(height uint64) ([]flow.Identifier, error) {
+				return test.mockSeals, test.mockErr
+			}
+
+			s := Server{
+				codec:    mocks.BaselineCodec(t),
+				index:    index,
+				validate: validator.New(),
+			}
+
+			req := ListSealsForHeightRequest{
+				Height: mocks.GenericHeight,
+			}
+
+			gotRes, gotErr := s.ListSealsForHeight(context.Background(), &req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, gotRes.Height, test.reqHeight)
+				assert.Len(t, gotRes.SealIDs, len(test.mockSeals))
+				for _, want := range test.mockSeals {
+					assert.Contains(t, gotRes.SealIDs, want[:])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_GetHeightForSeal(t *testing.T) {
+	sealID := mocks.GenericSeal(0).ID()
+
+	tests := []struct {
+		name string
+
+		req *GetHeightForSealRequest
+
+		mockErr error
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetHeightForSealRequest{
+				SealID: mocks.ByteSlice(sealID),
+			},
+
+			mockErr: nil,
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid seal ID",
+
+			req: &GetHeightForSealRequest{
+				SealID: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "error handling",
+
+			req: &GetHeightForSealRequest{
+				SealID: mocks.ByteSlice(sealID),
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.HeightForSealFunc = func(got flow.Identifier) (uint64, error) {
+				assert.Equal(t, sealID, got)
+				return mocks.GenericHeight, test.mockErr
+			}
+
+			s := Server{
+				index:    index,
+				validate: validator.New(),
+			}
+
+			gotRes, gotErr := s.GetHeightForSeal(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, mocks.GenericHeight, gotRes.Height)
+				assert.Equal(t, sealID[:], gotRes.SealID)
+			}
+		})
+	}
+}
+
+func TestServer_SubscribeBlocks(t *testing.T) {
+	first := mocks.GenericHeight - 2
+	tests := []struct {
+		name string
+
+		req *SubscribeBlocksRequest
+
+		mockLasts   []uint64
+		mockLastErr error
+		mockSendErr error
+
+		wantHeights []uint64
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &SubscribeBlocksRequest{
+				Start: first + 1,
+			},
+
+			mockLasts: []uint64{mocks.GenericHeight},
+
+			wantHeights: []uint64{first + 1, mocks.GenericHeight},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "replays from first height without start height",
+
+			req: &SubscribeBlocksRequest{},
+
+			mockLasts: []uint64{mocks.GenericHeight},
+
+			wantHeights: []uint64{first, first + 1, mocks.GenericHeight},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "streams newly indexed heights",
+
+			req: &SubscribeBlocksRequest{
+				Start: first,
+			},
+
+			mockLasts: []uint64{first, first, first + 1, mocks.GenericHeight},
+
+			wantHeights: []uint64{first, first + 1, mocks.GenericHeight},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles start height below first height",
+
+			req: &SubscribeBlocksRequest{
+				Start: first - 1,
+			},
+
+			checkErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.Equal(t, codes.OutOfRange, status.Code(err))
+			},
+		},
+		{
+			name: "handles index failure on Last",
+
+			req: &SubscribeBlocksRequest{},
+
+			mockLasts:   []uint64{mocks.GenericHeight},
+			mockLastErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles stream failure on Send",
+
+			req: &SubscribeBlocksRequest{},
+
+			mockLasts:   []uint64{mocks.GenericHeight},
+			mockSendErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			index := mocks.BaselineReader(t)
+			index.FirstFunc = func() (uint64, error) {
+				return first, nil
+			}
+			index.LastFunc = func() (uint64, error) {
+				last := test.mockLasts[len(test.mockLasts)-1]
+				if calls < len(test.mockLasts) {
+					last = test.mockLasts[calls]
+				}
+				calls++
+				return last, test.mockLastErr
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var gotHeights []uint64
+			stream := &subscribeBlocksMock{
+				ctx: ctx,
+				SendFunc: func(res *SubscribeBlocksResponse) error {
+					assert.Equal(t, mocks.ByteSlice(mocks.GenericHeader.ID()), res.BlockID)
+					assert.Equal(t, mocks.ByteSlice(mocks.GenericCommit(0)), res.Commit)
+
+					gotHeights = append(gotHeights, res.Height)
+					if len(gotHeights) == len(test.wantHeights) {
+						cancel()
+					}
+					return test.mockSendErr
+				},
+			}
+
+			s := Server{
+				index:    index,
+				cfg:      Config{PollInterval: time.Millisecond},
+				validate: validator.New(),
+			}
+
+			gotErr := s.SubscribeBlocks(test.req, stream)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantHeights, gotHeights)
+			}
+		})
+	}
+}
+
+func TestServer_GetAccountAtHeight(t *testing.T) {
+	account := mocks.GenericAccount
+	account.Contracts = map[string][]byte{
+		"Token":   []byte(`pub contract Token {}`),
+		"Account": []byte(`pub contract Account {}`),
+	}
+
+	key, err := flow.EncodeAccountPublicKey(account.Keys[0])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+
+		req *GetAccountAtHeightRequest
+
+		mockAccount *flow.Account
+		mockErr     error
+		noInvoker   bool
+
+		wantRes *GetAccountAtHeightResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetAccountAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: account.Address[:],
+			},
+
+			mockAccount: &account,
+
+			wantRes: &GetAccountAtHeightResponse{
+				Height:  mocks.GenericHeight,
+				Address: account.Address[:],
+				Balance: account.Balance,
+				Keys:    [][]byte{key},
+				Contracts: []*AccountContract{
+					{Name: "Account", Code: account.Contracts["Account"]},
+					{Name: "Token", Code: account.Contracts["Token"]},
+				},
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid address",
+
+			req: &GetAccountAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: mocks.GenericBytes,
+			},
+
+			mockAccount: &account,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing invoker",
+
+			req: &GetAccountAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: account.Address[:],
+			},
+
+			noInvoker: true,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invoker failure",
+
+			req: &GetAccountAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: account.Address[:],
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			invoke := mocks.BaselineInvoker(t)
+			invoke.AccountFunc = func(_ context.Context, height uint64, address flow.Address) (*flow.Account, error) {
+				assert.Equal(t, test.req.Height, height)
+				assert.Equal(t, test.req.Address, address[:])
+				return test.mockAccount, test.mockErr
+			}
+
+			s := Server{
+				index:    mocks.BaselineReader(t),
+				invoke:   invoke,
+				validate: validator.New(),
+			}
+			if test.noInvoker {
+				s.invoke = nil
+			}
+
+			gotRes, gotErr := s.GetAccountAtHeight(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
+			}
+		})
+	}
+}
+
+func TestServer_GetAccountKeyAtHeight(t *testing.T) {
+	key, err := flow.EncodeAccountPublicKey(mocks.GenericAccount.Keys[0])
+	require.NoError(t, err)
+
+	address := mocks.GenericAddress(0)
+
+	tests := []struct {
+		name string
+
+		req *GetAccountKeyAtHeightRequest
+
+		mockErr   error
+		noInvoker bool
+
+		wantRes *GetAccountKeyAtHeightResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &GetAccountKeyAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: address[:],
+				Index:   0,
+			},
+
+			wantRes: &GetAccountKeyAtHeightResponse{
+				Height:  mocks.GenericHeight,
+				Address: address[:],
+				Index:   0,
+				Key:     key,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles invalid address",
+
+			req: &GetAccountKeyAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: mocks.GenericBytes,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing invoker",
+
+			req: &GetAccountKeyAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: address[:],
+			},
+
+			noInvoker: true,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invoker failure",
+
+			req: &GetAccountKeyAtHeightRequest{
+				Height:  mocks.GenericHeight,
+				Address: address[:],
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			invoke := mocks.BaselineInvoker(t)
+			invoke.KeyFunc = func(_ context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+				assert.Equal(t, test.req.Height, height)
+				assert.Equal(t, test.req.Address, address[:])
+				assert.Equal(t, int(test.req.Index), index)
+				if test.mockErr != nil {
+					return nil, test.mockErr
 				}
+				return &mocks.GenericAccount.Keys[0], nil
+			}
+
+			s := Server{
+				index:    mocks.BaselineReader(t),
+				invoke:   invoke,
+				validate: validator.New(),
+			}
+			if test.noInvoker {
+				s.invoke = nil
+			}
+
+			gotRes, gotErr := s.GetAccountKeyAtHeight(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
+			}
+		})
+	}
+}
+
+func TestServer_ExecuteScriptAtHeight(t *testing.T) {
+	script := []byte(`pub fun main(): UFix64 { return 0.0 }`)
+
+	argument, err := json.Encode(mocks.GenericAmount(0))
+	require.NoError(t, err)
+	result, err := json.Encode(mocks.GenericAmount(1))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+
+		req *ExecuteScriptAtHeightRequest
+
+		mockErr   error
+		noInvoker bool
+
+		wantRes *ExecuteScriptAtHeightResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &ExecuteScriptAtHeightRequest{
+				Height:    mocks.GenericHeight,
+				Script:    script,
+				Arguments: [][]byte{argument},
+			},
+
+			wantRes: &ExecuteScriptAtHeightResponse{
+				Height: mocks.GenericHeight,
+				Result: result,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles missing script",
+
+			req: &ExecuteScriptAtHeightRequest{
+				Height: mocks.GenericHeight,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid arguments",
+
+			req: &ExecuteScriptAtHeightRequest{
+				Height:    mocks.GenericHeight,
+				Script:    script,
+				Arguments: [][]byte{[]byte(`invalid argument`)},
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing invoker",
+
+			req: &ExecuteScriptAtHeightRequest{
+				Height: mocks.GenericHeight,
+				Script: script,
+			},
+
+			noInvoker: true,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invoker failure",
+
+			req: &ExecuteScriptAtHeightRequest{
+				Height: mocks.GenericHeight,
+				Script: script,
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			invoke := mocks.BaselineInvoker(t)
+			invoke.ScriptFunc = func(_ context.Context, height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+				assert.Equal(t, test.req.Height, height)
+				assert.Equal(t, test.req.Script, script)
+				assert.Len(t, parameters, len(test.req.Arguments))
+				return mocks.GenericAmount(1), test.mockErr
+			}
+
+			s := Server{
+				index:    mocks.BaselineReader(t),
+				invoke:   invoke,
+				validate: validator.New(),
+			}
+			if test.noInvoker {
+				s.invoke = nil
+			}
+
+			gotRes, gotErr := s.ExecuteScriptAtHeight(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
+			}
+		})
+	}
+}
+
+func TestServer_ExecuteTransactionAtHeight(t *testing.T) {
+	argument, err := json.Encode(mocks.GenericAmount(0))
+	require.NoError(t, err)
+
+	tx := &Transaction{
+		ReferenceBlockID: mocks.GenericBlockIDs(1)[0][:],
+		Script:           `transaction { execute {} }`,
+		Arguments:        [][]byte{argument},
+		GasLimit:         9999,
+		ProposalKey: &ProposalKey{
+			Address:        mocks.GenericAddress(0).Bytes(),
+			KeyIndex:       1,
+			SequenceNumber: 2,
+		},
+		Payer:       mocks.GenericAddress(1).Bytes(),
+		Authorizers: [][]byte{mocks.GenericAddress(2).Bytes()},
+	}
+
+	var events []*Event
+	for _, event := range mocks.GenericDryRun.Events {
+		payload, err := convert.EventPayload(event)
+		require.NoError(t, err)
+		events = append(events, &Event{
+			Type:             string(event.Type),
+			TransactionID:    convert.IDToHash(event.TransactionID),
+			TransactionIndex: event.TransactionIndex,
+			EventIndex:       event.EventIndex,
+			Payload:          payload,
+		})
+	}
+
+	write := mocks.GenericDryRun.Writes[0]
+	writes := []*Register{
+		{
+			RegisterID: &RegisterID{
+				Owner:      []byte(write.Key.Owner),
+				Controller: []byte(write.Key.Controller),
+				Key:        []byte(write.Key.Key),
+			},
+			Value: write.Value,
+		},
+	}
+
+	tests := []struct {
+		name string
+
+		req *ExecuteTransactionAtHeightRequest
+
+		mockRun   *dps.DryRun
+		mockErr   error
+		noInvoker bool
+
+		wantRes *ExecuteTransactionAtHeightResponse
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			req: &ExecuteTransactionAtHeightRequest{
+				Height:      mocks.GenericHeight,
+				Transaction: tx,
+			},
+
+			mockRun: &mocks.GenericDryRun,
+
+			wantRes: &ExecuteTransactionAtHeightResponse{
+				Height:          mocks.GenericHeight,
+				Events:          events,
+				ComputationUsed: mocks.GenericDryRun.ComputationUsed,
+				Fees:            mocks.GenericDryRun.Fees,
+				Writes:          writes,
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "failed transaction",
+
+			req: &ExecuteTransactionAtHeightRequest{
+				Height:      mocks.GenericHeight,
+				Transaction: tx,
+			},
+
+			mockRun: &dps.DryRun{
+				Failed:          true,
+				ErrorMessage:    "dummy error",
+				ComputationUsed: 1,
+			},
+
+			wantRes: &ExecuteTransactionAtHeightResponse{
+				Height:          mocks.GenericHeight,
+				Failed:          true,
+				ErrorMessage:    "dummy error",
+				Events:          []*Event{},
+				ComputationUsed: 1,
+				Writes:          []*Register{},
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles missing transaction",
+
+			req: &ExecuteTransactionAtHeightRequest{
+				Height: mocks.GenericHeight,
+			},
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing invoker",
+
+			req: &ExecuteTransactionAtHeightRequest{
+				Height:      mocks.GenericHeight,
+				Transaction: tx,
+			},
+
+			noInvoker: true,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invoker failure",
+
+			req: &ExecuteTransactionAtHeightRequest{
+				Height:      mocks.GenericHeight,
+				Transaction: tx,
+			},
+
+			mockErr: mocks.GenericError,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			invoke := mocks.BaselineInvoker(t)
+			invoke.TransactionFunc = func(_ context.Context, height uint64, body *flow.TransactionBody) (*dps.DryRun, error) {
+				assert.Equal(t, test.req.Height, height)
+				assert.Equal(t, flow.HashToID(tx.ReferenceBlockID), body.ReferenceBlockID)
+				assert.Equal(t, []byte(tx.Script), body.Script)
+				assert.Equal(t, tx.Arguments, body.Arguments)
+				assert.Equal(t, tx.GasLimit, body.GasLimit)
+				assert.Equal(t, mocks.GenericAddress(0), body.ProposalKey.Address)
+				assert.Equal(t, tx.ProposalKey.KeyIndex, body.ProposalKey.KeyIndex)
+				assert.Equal(t, tx.ProposalKey.SequenceNumber, body.ProposalKey.SequenceNumber)
+				assert.Equal(t, mocks.GenericAddress(1), body.Payer)
+				assert.Equal(t, []flow.Address{mocks.GenericAddress(2)}, body.Authorizers)
+				return test.mockRun, test.mockErr
+			}
+
+			s := Server{
+				index:    mocks.BaselineReader(t),
+				invoke:   invoke,
+				validate: validator.New(),
+			}
+			if test.noInvoker {
+				s.invoke = nil
+			}
+
+			gotRes, gotErr := s.ExecuteTransactionAtHeight(context.Background(), test.req)
+
+			test.checkErr(t, gotErr)
+			if gotErr == nil {
+				assert.Equal(t, test.wantRes, gotRes)
 			}
 		})
 	}
 }
+
+func TestBatches(t *testing.T) {
+	type batch struct {
+		start uint64
+		end   uint64
+	}
+
+	tests := []struct {
+		name string
+
+		start uint64
+		end   uint64
+
+		want []batch
+	}{
+		{
+			name:  "single height",
+			start: mocks.GenericHeight,
+			end:   mocks.GenericHeight,
+			want:  []batch{{mocks.GenericHeight, mocks.GenericHeight}},
+		},
+		{
+			name:  "exactly one batch",
+			start: 0,
+			end:   MaxBatchSize - 1,
+			want:  []batch{{0, MaxBatchSize - 1}},
+		},
+		{
+			name:  "several batches",
+			start: 0,
+			end:   2*MaxBatchSize + 1,
+			want:  []batch{{0, MaxBatchSize - 1}, {MaxBatchSize, 2*MaxBatchSize - 1}, {2 * MaxBatchSize, 2*MaxBatchSize + 1}},
+		},
+		{
+			name:  "end of height range",
+			start: math.MaxUint64 - MaxBatchSize,
+			end:   math.MaxUint64,
+			want:  []batch{{math.MaxUint64 - MaxBatchSize, math.MaxUint64 - 1}, {math.MaxUint64, math.MaxUint64}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []batch
+			err := batches(test.start, test.end, func(start uint64, end uint64) error {
+				got = append(got, batch{start, end})
+				return nil
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("handles process failure", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		err := batches(0, 2*MaxBatchSize, func(uint64, uint64) error {
+			calls++
+			return mocks.GenericError
+		})
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+type subscribeBlocksMock struct {
+	grpc.ServerStream
+
+	ctx      context.Context
+	SendFunc func(*SubscribeBlocksResponse) error
+}
+
+func (s *subscribeBlocksMock) Context() context.Context {
+	return s.ctx
+}
+
+func (s *subscribeBlocksMock) Send(res *SubscribeBlocksResponse) error {
+	return s.SendFunc(res)
+}
+
+type listHeadersMock struct {
+	grpc.ServerStream
+
+	SendFunc func(*ListHeadersResponse) error
+}
+
+func (l *listHeadersMock) Send(res *ListHeadersResponse) error {
+	return l.SendFunc(res)
+}
+
+type listCommitsMock struct {
+	grpc.ServerStream
+
+	SendFunc func(*ListCommitsResponse) error
+}
+
+func (l *listCommitsMock) Send(res *ListCommitsResponse) error {
+	return l.SendFunc(res)
+}
+
+type getRegisterHistoryMock struct {
+	grpc.ServerStream
+
+	SendFunc func(*GetRegisterHistoryResponse) error
+}
+
+func (g *getRegisterHistoryMock) Send(res *GetRegisterHistoryResponse) error {
+	return g.SendFunc(res)
+}
+
+type getStateDiffMock struct {
+	grpc.ServerStream
+
+	SendFunc func(*GetStateDiffResponse) error
+}
+
+func (g *getStateDiffMock) Send(res *GetStateDiffResponse) error {
+	return g.SendFunc(res)
+}
+
+type getEventsRangeMock struct {
+	grpc.ServerStream
+
+	SendFunc func(*GetEventsRangeResponse) error
+}
+
+func (g *getEventsRangeMock) Send(res *GetEventsRangeResponse) error {
+	return g.SendFunc(res)
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// DefaultSporks is the spork table of the public DPS APIs.
+var DefaultSporks = Sporks{
+	{Name: "candidate-4", API: "candidate4.dps.optakt.io:5005", First: 1065711, Last: 2033591},
+	{Name: "candidate-5", API: "candidate5.dps.optakt.io:5005", First: 2033592, Last: 3187930},
+	{Name: "candidate-6", API: "candidate6.dps.optakt.io:5005", First: 3187931, Last: 4132132},
+	{Name: "candidate-7", API: "candidate7.dps.optakt.io:5005", First: 4132133, Last: 4972986},
+	{Name: "candidate-8", API: "candidate8.dps.optakt.io:5005", First: 4972987, Last: 6483245},
+	{Name: "candidate-9", API: "candidate9.dps.optakt.io:5005", First: 6483246, Last: 7601062},
+	{Name: "mainnet-1", API: "mainnet1.dps.optakt.io:5005", First: 7601063, Last: 8742958},
+	{Name: "mainnet-2", API: "mainnet2.dps.optakt.io:5005", First: 8742959, Last: 9737132},
+	{Name: "mainnet-3", API: "mainnet3.dps.optakt.io:5005", First: 9737133, Last: 9992019},
+	{Name: "mainnet-4", API: "mainnet4.dps.optakt.io:5005", First: 9992020, Last: 12020336},
+	{Name: "mainnet-5", API: "mainnet5.dps.optakt.io:5005", First: 12020337, Last: 12609236},
+	{Name: "mainnet-6", API: "mainnet6.dps.optakt.io:5005", First: 12609237, Last: 13404173},
+	{Name: "mainnet-7", API: "mainnet7.dps.optakt.io:5005", First: 13404174, Last: 13950741},
+	{Name: "mainnet-8", API: "mainnet8.dps.optakt.io:5005", First: 13950742, Last: 14892103},
+	{Name: "mainnet-9", API: "mainnet9.dps.optakt.io:5005", First: 14892104, Last: math.MaxUint64},
+}
+
+// Spork is an entry of a spork table, which maps a range of block heights to
+// the DPS API that serves them.
+type Spork struct {
+	Name  string `yaml:"name"`
+	API   string `yaml:"api"`
+	First uint64 `yaml:"first"`
+	Last  uint64 `yaml:"last"`
+}
+
+// Sporks is a spork table, ordered by height.
+type Sporks []Spork
+
+// ReadSporks reads a spork table from the given reader. The table can be given
+// in YAML or JSON format, as a list of entries with the `name`, `api`, `first`
+// and `last` fields. The last height can be omitted for the latest spork, in
+// which case it covers all heights after its first height.
+func ReadSporks(reader io.Reader) (Sporks, error) {
+
+	var sporks Sporks
+	err := yaml.NewDecoder(reader).Decode(&sporks)
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("spork table is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not decode spork table: %w", err)
+	}
+
+	sort.Slice(sporks, func(i int, j int) bool {
+		return sporks[i].First < sporks[j].First
+	})
+	if len(sporks) > 0 && sporks[len(sporks)-1].Last == 0 {
+		sporks[len(sporks)-1].Last = math.MaxUint64
+	}
+
+	err = sporks.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid spork table: %w", err)
+	}
+
+	return sporks, nil
+}
+
+// Find returns the spork that covers the given height.
+func (s Sporks) Find(height uint64) (Spork, error) {
+	for _, spork := range s {
+		if height >= spork.First && height <= spork.Last {
+			return spork, nil
+		}
+	}
+	return Spork{}, fmt.Errorf("no spork for height %d: %w", height, dps.ErrOutOfRange)
+}
+
+// validate checks that all entries of an ordered spork table are complete,
+// and that their height ranges do not overlap.
+func (s Sporks) validate() error {
+
+	if len(s) == 0 {
+		return fmt.Errorf("spork table is empty")
+	}
+
+	for i, spork := range s {
+		if spork.Name == "" {
+			return fmt.Errorf("missing name for spork with first height %d", spork.First)
+		}
+		if spork.API == "" {
+			return fmt.Errorf("missing API for spork %s", spork.Name)
+		}
+		if spork.Last < spork.First {
+			return fmt.Errorf("last height below first height for spork %s (first: %d, last: %d)", spork.Name, spork.First, spork.Last)
+		}
+		if i > 0 && spork.First <= s[i-1].Last {
+			return fmt.Errorf("overlapping heights for sporks %s and %s", s[i-1].Name, spork.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+func TestReadSporks(t *testing.T) {
+	tests := []struct {
+		name string
+
+		input string
+
+		wantSporks Sporks
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case with YAML",
+
+			input: `
+- name: spork-2
+  api: spork2.example.org:5005
+  first: 101
+- name: spork-1
+  api: spork1.example.org:5005
+  first: 1
+  last: 100
+`,
+
+			wantSporks: Sporks{
+				{Name: "spork-1", API: "spork1.example.org:5005", First: 1, Last: 100},
+				{Name: "spork-2", API: "spork2.example.org:5005", First: 101, Last: math.MaxUint64},
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "nominal case with JSON",
+
+			input: `[
+				{"name": "spork-1", "api": "spork1.example.org:5005", "first": 1, "last": 100},
+				{"name": "spork-2", "api": "spork2.example.org:5005", "first": 101, "last": 200}
+			]`,
+
+			wantSporks: Sporks{
+				{Name: "spork-1", API: "spork1.example.org:5005", First: 1, Last: 100},
+				{Name: "spork-2", API: "spork2.example.org:5005", First: 101, Last: 200},
+			},
+
+			checkErr: require.NoError,
+		},
+		{
+			name: "handles empty input",
+
+			input: "",
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles empty table",
+
+			input: "[]",
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles invalid syntax",
+
+			input: "{invalid",
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing name",
+
+			input: `[{"api": "spork1.example.org:5005", "first": 1}]`,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing API",
+
+			input: `[{"name": "spork-1", "first": 1}]`,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles missing last height before latest spork",
+
+			input: `[
+				{"name": "spork-1", "api": "spork1.example.org:5005", "first": 1},
+				{"name": "spork-2", "api": "spork2.example.org:5005", "first": 101}
+			]`,
+
+			checkErr: require.Error,
+		},
+		{
+			name: "handles overlapping heights",
+
+			input: `[
+				{"name": "spork-1", "api": "spork1.example.org:5005", "first": 1, "last": 100},
+				{"name": "spork-2", "api": "spork2.example.org:5005", "first": 100}
+			]`,
+
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ReadSporks(strings.NewReader(test.input))
+
+			test.checkErr(t, err)
+			if err == nil {
+				assert.Equal(t, test.wantSporks, got)
+			}
+		})
+	}
+}
+
+func TestSporks_Find(t *testing.T) {
+	sporks := Sporks{
+		{Name: "spork-1", API: "spork1.example.org:5005", First: 1, Last: 100},
+		{Name: "spork-2", API: "spork2.example.org:5005", First: 101, Last: math.MaxUint64},
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := sporks.Find(101)
+
+		require.NoError(t, err)
+		assert.Equal(t, sporks[1], got)
+	})
+
+	t.Run("handles height before first spork", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sporks.Find(0)
+
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+}
+
+func TestDefaultSporks(t *testing.T) {
+	err := DefaultSporks.validate()
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Gateway is an HTTP handler that exposes the methods of the DPS API as a REST
+// API with JSON responses. It uses a DPS API server as its backend, so that the
+// validation and the errors are the same as for the GRPC API. Identifiers,
+// commitments and other binary values are encoded as hexadecimal strings, and
+// the encoded payloads returned by the API are decoded before being returned.
+type Gateway struct {
+	server api.APIServer
+	codec  dps.Codec
+	routes []route
+}
+
+// NewGateway creates a new REST gateway on top of the given DPS API server. The
+// codec should be the same as the one used by the server to encode payloads.
+func NewGateway(server api.APIServer, codec dps.Codec) *Gateway {
+
+	g := Gateway{
+		server: server,
+		codec:  codec,
+	}
+
+	g.handle(http.MethodGet, "/first", g.First)
+	g.handle(http.MethodGet, "/last", g.Last)
+	g.handle(http.MethodGet, "/floor", g.Floor)
+	g.handle(http.MethodGet, "/blocks", g.SubscribeBlocks)
+	g.handle(http.MethodGet, "/blocks/{blockID}/height", g.HeightForBlock)
+	g.handle(http.MethodGet, "/blocks/{blockID}/header", g.HeaderByBlockID)
+	g.handle(http.MethodGet, "/timestamps/{timestamp}/height", g.HeightForTimestamp)
+	g.handle(http.MethodGet, "/headers", g.Headers)
+	g.handle(http.MethodGet, "/commits", g.Commits)
+	g.handle(http.MethodGet, "/events", g.EventsRange)
+	g.handle(http.MethodGet, "/events/{type}/heights", g.EventHeights)
+	g.handle(http.MethodGet, "/registers/{path}/history", g.RegisterHistory)
+	g.handle(http.MethodGet, "/diff", g.StateDiff)
+	g.handle(http.MethodGet, "/heights/{height}/header", g.Header)
+	g.handle(http.MethodGet, "/heights/{height}/commit", g.Commit)
+	g.handle(http.MethodGet, "/heights/{height}/events", g.Events)
+	g.handle(http.MethodGet, "/heights/{height}/registers", g.Registers)
+	g.handle(http.MethodGet, "/heights/{height}/proofs", g.Proofs)
+	g.handle(http.MethodGet, "/heights/{height}/collections", g.CollectionsForHeight)
+	g.handle(http.MethodGet, "/heights/{height}/transactions", g.TransactionsForHeight)
+	g.handle(http.MethodGet, "/heights/{height}/seals", g.SealsForHeight)
+	g.handle(http.MethodGet, "/heights/{height}/accounts/{address}", g.Account)
+	g.handle(http.MethodGet, "/heights/{height}/accounts/{address}/keys/{index}", g.AccountKey)
+	g.handle(http.MethodGet, "/accounts/{address}/transactions", g.TransactionsForAccount)
+	g.handle(http.MethodPost, "/heights/{height}/scripts", g.Script)
+	g.handle(http.MethodPost, "/heights/{height}/transactions", g.ExecuteTransaction)
+	g.handle(http.MethodGet, "/collections/{collectionID}", g.Collection)
+	g.handle(http.MethodGet, "/collections/{collectionID}/height", g.HeightForCollection)
+	g.handle(http.MethodGet, "/collections/{collectionID}/transactions", g.TransactionsForCollection)
+	g.handle(http.MethodGet, "/guarantees/{collectionID}", g.Guarantee)
+	g.handle(http.MethodGet, "/transactions/{transactionID}", g.Transaction)
+	g.handle(http.MethodGet, "/transactions/{transactionID}/height", g.HeightForTransaction)
+	g.handle(http.MethodGet, "/results/{transactionID}", g.Result)
+	g.handle(http.MethodGet, "/seals/{sealID}", g.Seal)
+	g.handle(http.MethodGet, "/seals/{sealID}/height", g.HeightForSeal)
+
+	return &g
+}
+
+// ServeHTTP implements the `http.Handler` interface. It dispatches the request
+// to the handler of the matching route and writes the JSON response or error.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	segments := split(r.URL.Path)
+	allowed := false
+	for _, route := range g.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+		if r.Method != route.method {
+			allowed = true
+			continue
+		}
+
+		err := route.handler(w, r, params)
+		if err != nil {
+			fail(w, err)
+		}
+		return
+	}
+
+	if allowed {
+		res := Error{
+			Code:    codes.Unimplemented.String(),
+			Message: fmt.Sprintf("method not allowed (method: %s, path: %s)", r.Method, r.URL.Path),
+		}
+		write(w, http.StatusMethodNotAllowed, res)
+		return
+	}
+
+	fail(w, status.Errorf(codes.NotFound, "unknown route (path: %s)", r.URL.Path))
+}
+
+func (g *Gateway) handle(method string, pattern string, handler handler) {
+	r := route{
+		method:   method,
+		segments: split(pattern),
+		handler:  handler,
+	}
+	g.routes = append(g.routes, r)
+}
+
+// respond writes the given value as JSON response.
+func respond(w http.ResponseWriter, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		return fmt.Errorf("could not encode response: %w", err)
+	}
+	return nil
+}
+
+// array writes a JSON array as response one element at a time, so that the
+// results of range requests are streamed instead of buffered in full.
+type array struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// add writes the given value as the next element of the array.
+func (a *array) add(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("could not encode element: %w", err)
+	}
+	separator := []byte(",")
+	if !a.started {
+		a.w.Header().Set("Content-Type", "application/json")
+		separator = []byte("[")
+		a.started = true
+	}
+	_, err = a.w.Write(append(separator, data...))
+	if err != nil {
+		return fmt.Errorf("could not write element: %w", err)
+	}
+	return nil
+}
+
+// close terminates the array, or writes an empty array if no elements were
+// added.
+func (a *array) close() error {
+	if !a.started {
+		a.w.Header().Set("Content-Type", "application/json")
+		_, err := a.w.Write([]byte("["))
+		if err != nil {
+			return fmt.Errorf("could not write array: %w", err)
+		}
+	}
+	_, err := a.w.Write([]byte("]\n"))
+	if err != nil {
+		return fmt.Errorf("could not write array: %w", err)
+	}
+	return nil
+}
+
+// abort returns the given error, so that it is written as error response, if
+// no element was written yet. Otherwise, the status code was already sent, so
+// it aborts the response instead, which lets the client know that it is
+// incomplete.
+func (a *array) abort(err error) error {
+	if !a.started {
+		return err
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// fail writes the given error as JSON response, with an HTTP status code that
+// matches the GRPC status code of the error.
+func fail(w http.ResponseWriter, err error) {
+
+	code := status.Code(err)
+	if errors.Is(err, dps.ErrInvalidArgument) {
+		code = codes.InvalidArgument
+	}
+
+	var statusCode int
+	switch code {
+	case codes.InvalidArgument:
+		statusCode = http.StatusBadRequest
+	case codes.NotFound, codes.OutOfRange:
+		statusCode = http.StatusNotFound
+	case codes.Unimplemented:
+		statusCode = http.StatusNotImplemented
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	res := Error{
+		Code:    code.String(),
+		Message: status.Convert(err).Message(),
+	}
+
+	write(w, statusCode, res)
+}
+
+func write(w http.ResponseWriter, statusCode int, res Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,472 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestGateway(t *testing.T) {
+	paths := make([]string, 0, 6)
+	for _, path := range mocks.GenericLedgerPaths(6) {
+		paths = append(paths, hex.EncodeToString(path[:]))
+	}
+	header := mocks.GenericHeader
+	blockID := header.ID()
+	txID := mocks.GenericTransaction(0).ID()
+	collID := mocks.GenericCollection(0).ID()
+	address := mocks.GenericAddress(0)
+	event := mocks.GenericEvent(0)
+
+	tests := []struct {
+		name string
+
+		method string
+		path   string
+		body   string
+
+		mockErr error
+
+		wantStatus int
+		wantBody   func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "first height",
+			method:     http.MethodGet,
+			path:       "/first",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var height Height
+				require.NoError(t, json.Unmarshal(body, &height))
+				assert.Equal(t, mocks.GenericHeight, height.Height)
+			},
+		},
+		{
+			name:       "retention floor",
+			method:     http.MethodGet,
+			path:       "/floor",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var height Height
+				require.NoError(t, json.Unmarshal(body, &height))
+				assert.Equal(t, mocks.GenericHeight, height.Height)
+			},
+		},
+		{
+			name:       "height for block",
+			method:     http.MethodGet,
+			path:       "/blocks/" + blockID.String() + "/height",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var height Height
+				require.NoError(t, json.Unmarshal(body, &height))
+				assert.Equal(t, mocks.GenericHeight, height.Height)
+			},
+		},
+		{
+			name:       "register history",
+			method:     http.MethodGet,
+			path:       "/registers/" + paths[0] + "/history?start=42&end=50",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var got RegisterHistory
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, paths[0], got.Path)
+				require.Len(t, got.Changes, 1)
+				assert.Equal(t, mocks.GenericHeight, got.Changes[0].Height)
+				assert.Equal(t, hex.EncodeToString(mocks.GenericLedgerPayload(0).Value), got.Changes[0].Value)
+			},
+		},
+		{
+			name:       "state diff",
+			method:     http.MethodGet,
+			path:       "/diff?start=42&end=50",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var got []Change
+				require.NoError(t, json.Unmarshal(body, &got))
+				changes := mocks.GenericChanges(4)
+				require.Len(t, got, 4)
+				assert.Equal(t, hex.EncodeToString(changes[0].Path[:]), got[0].Path)
+				require.NotNil(t, got[0].Before)
+				assert.Equal(t, hex.EncodeToString(changes[0].Before.Value), *got[0].Before)
+				assert.Equal(t, hex.EncodeToString(changes[0].After.Value), got[0].After)
+			},
+		},
+		{
+			name:       "invalid register path",
+			method:     http.MethodGet,
+			path:       "/registers/xyz/history",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "header by block ID",
+			method:     http.MethodGet,
+			path:       "/blocks/" + blockID.String() + "/header",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var got Header
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, header.Height, got.Height)
+				assert.Equal(t, blockID, got.Header.ID())
+			},
+		},
+		{
+			name:       "transactions for collection",
+			method:     http.MethodGet,
+			path:       "/collections/" + collID.String() + "/transactions",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var got CollectionTransactions
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, collID, got.CollectionID)
+				assert.Equal(t, mocks.GenericTransactionIDs(5), got.Identifiers)
+			},
+		},
+		{
+			name:       "height for seal",
+			method:     http.MethodGet,
+			path:       "/seals/" + mocks.GenericSeal(0).ID().String() + "/height",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var height Height
+				require.NoError(t, json.Unmarshal(body, &height))
+				assert.Equal(t, mocks.GenericHeight, height.Height)
+			},
+		},
+		{
+			name:       "height for timestamp",
+			method:     http.MethodGet,
+			path:       "/timestamps/" + header.Timestamp.Format(time.RFC3339Nano) + "/height",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var height Height
+				require.NoError(t, json.Unmarshal(body, &height))
+				assert.Equal(t, mocks.GenericHeight, height.Height)
+			},
+		},
+		{
+			name:       "invalid timestamp",
+			method:     http.MethodGet,
+			path:       "/timestamps/yesterday/height",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "commit",
+			method:     http.MethodGet,
+			path:       "/heights/425/commit",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var commit Commit
+				require.NoError(t, json.Unmarshal(body, &commit))
+				wantCommit := mocks.GenericCommit(0)
+				assert.Equal(t, hex.EncodeToString(wantCommit[:]), commit.Commit)
+			},
+		},
+		{
+			name:       "header",
+			method:     http.MethodGet,
+			path:       "/heights/425/header",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var got Header
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, blockID, got.Header.ID())
+			},
+		},
+		{
+			name:       "events",
+			method:     http.MethodGet,
+			path:       "/heights/425/events",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var events Events
+				require.NoError(t, json.Unmarshal(body, &events))
+				require.Len(t, events.Events, 4)
+				assert.True(t, json.Valid(events.Events[0].Payload))
+				assert.Contains(t, string(events.Events[0].Payload), `"type"`)
+			},
+		},
+		{
+			name:       "events with filters",
+			method:     http.MethodGet,
+			path:       "/heights/425/events?transactions=" + event.TransactionID.String() + "&fields=address==0x" + mocks.GenericAddress(0).Hex(),
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var events Events
+				require.NoError(t, json.Unmarshal(body, &events))
+				require.Len(t, events.Events, 1)
+				assert.Equal(t, event.TransactionID, events.Events[0].TransactionID)
+			},
+		},
+		{
+			name:       "events with invalid transaction ID",
+			method:     http.MethodGet,
+			path:       "/heights/425/events?transactions=xyz",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "event heights",
+			method:     http.MethodGet,
+			path:       "/events/" + string(mocks.GenericEventType(0)) + "/heights?start=425&end=430",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var heights EventHeights
+				require.NoError(t, json.Unmarshal(body, &heights))
+				assert.Equal(t, string(mocks.GenericEventType(0)), heights.Type)
+				assert.Equal(t, []uint64{mocks.GenericHeight}, heights.Heights)
+			},
+		},
+		{
+			name:       "event heights with invalid range",
+			method:     http.MethodGet,
+			path:       "/events/" + string(mocks.GenericEventType(0)) + "/heights?start=430&end=425",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "headers range",
+			method:     http.MethodGet,
+			path:       "/headers?start=425&end=426",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var headers []Header
+				require.NoError(t, json.Unmarshal(body, &headers))
+				require.Len(t, headers, 1)
+				assert.Equal(t, blockID, headers[0].Header.ID())
+			},
+		},
+		{
+			name:       "commits range",
+			method:     http.MethodGet,
+			path:       "/commits?start=425&end=428",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var commits []Commit
+				require.NoError(t, json.Unmarshal(body, &commits))
+				assert.Len(t, commits, 4)
+			},
+		},
+		{
+			name:       "registers",
+			method:     http.MethodGet,
+			path:       "/heights/425/registers?paths=" + strings.Join(paths, ","),
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var registers Registers
+				require.NoError(t, json.Unmarshal(body, &registers))
+				require.Len(t, registers.Registers, 6)
+				assert.Equal(t, paths[0], registers.Registers[0].Path)
+			},
+		},
+		{
+			name:       "transaction",
+			method:     http.MethodGet,
+			path:       "/transactions/" + txID.String(),
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var tx Transaction
+				require.NoError(t, json.Unmarshal(body, &tx))
+				assert.Equal(t, txID, tx.TransactionID)
+			},
+		},
+		{
+			name:       "account",
+			method:     http.MethodGet,
+			path:       "/heights/425/accounts/" + address.Hex(),
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var account Account
+				require.NoError(t, json.Unmarshal(body, &account))
+				assert.Equal(t, address.Hex(), account.Address)
+				assert.Len(t, account.Keys, 1)
+			},
+		},
+		{
+			name:       "transactions for account",
+			method:     http.MethodGet,
+			path:       "/accounts/" + address.Hex() + "/transactions?start=425&end=430&after=" + txID.String() + "&limit=10",
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var txs AccountTransactions
+				require.NoError(t, json.Unmarshal(body, &txs))
+				assert.Equal(t, address.Hex(), txs.Address)
+				require.Len(t, txs.Transactions, 4)
+				want := mocks.GenericAccountTransactions(4)[0]
+				assert.Equal(t, want.Height, txs.Transactions[0].Height)
+				assert.Equal(t, want.TransactionID, txs.Transactions[0].TransactionID)
+				assert.False(t, txs.Transactions[0].Proposer)
+				assert.True(t, txs.Transactions[0].Payer)
+				assert.True(t, txs.Transactions[0].Authorizer)
+			},
+		},
+		{
+			name:       "account with short address",
+			method:     http.MethodGet,
+			path:       "/heights/425/accounts/0x01",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "account with long address",
+			method:     http.MethodGet,
+			path:       "/heights/425/accounts/" + address.Hex() + "01",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "transactions for account with invalid cursor",
+			method:     http.MethodGet,
+			path:       "/accounts/" + address.Hex() + "/transactions?after=xyz",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "transactions for account with limit above maximum",
+			method:     http.MethodGet,
+			path:       "/accounts/" + address.Hex() + "/transactions?limit=1001",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "script",
+			method:     http.MethodPost,
+			path:       "/heights/425/scripts",
+			body:       `{"script":"pub fun main(): UFix64 { return 0.0 }","arguments":[]}`,
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var result ScriptResult
+				require.NoError(t, json.Unmarshal(body, &result))
+				assert.True(t, json.Valid(result.Result))
+			},
+		},
+		{
+			name:       "transaction",
+			method:     http.MethodPost,
+			path:       "/heights/425/transactions",
+			body:       `{"script":"transaction { execute {} }","arguments":[],"payer":"0000000000000001"}`,
+			wantStatus: http.StatusOK,
+			wantBody: func(t *testing.T, body []byte) {
+				var run DryRun
+				require.NoError(t, json.Unmarshal(body, &run))
+				assert.Equal(t, uint64(425), run.Height)
+				assert.False(t, run.Failed)
+				assert.Len(t, run.Events, len(mocks.GenericDryRun.Events))
+				assert.Equal(t, mocks.GenericDryRun.Fees, run.Fees)
+				assert.Len(t, run.Writes, len(mocks.GenericDryRun.Writes))
+			},
+		},
+		{
+			name:       "invalid identifier",
+			method:     http.MethodGet,
+			path:       "/transactions/invalid",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid range",
+			method:     http.MethodGet,
+			path:       "/headers?start=426&end=425",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "reversed headers range",
+			method:     http.MethodGet,
+			path:       "/headers?start=430&end=425",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "reversed commits range",
+			method:     http.MethodGet,
+			path:       "/commits?start=430&end=425",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid script request",
+			method:     http.MethodPost,
+			path:       "/heights/425/scripts",
+			body:       `invalid`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid transaction request",
+			method:     http.MethodPost,
+			path:       "/heights/425/transactions",
+			body:       `invalid`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			method:     http.MethodGet,
+			path:       "/seals/" + blockID.String(),
+			mockErr:    dps.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantBody: func(t *testing.T, body []byte) {
+				var res Error
+				require.NoError(t, json.Unmarshal(body, &res))
+				assert.Equal(t, "NotFound", res.Code)
+			},
+		},
+		{
+			name:       "unknown route",
+			method:     http.MethodGet,
+			path:       "/unknown",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			path:       "/first",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := mocks.BaselineReader(t)
+			index.SealFunc = func(sealID flow.Identifier) (*flow.Seal, error) {
+				return mocks.GenericSeal(0), test.mockErr
+			}
+
+			codec := zbor.NewCodec()
+			server := api.NewServer(index, codec, mocks.BaselineInvoker(t))
+			gateway := NewGateway(server, codec)
+
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+
+			gateway.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			if test.wantBody != nil {
+				test.wantBody(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,1088 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// First handles `GET /first` requests.
+func (g *Gateway) First(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	res, err := g.server.GetFirst(r.Context(), &api.GetFirstRequest{})
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// Last handles `GET /last` requests.
+func (g *Gateway) Last(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	res, err := g.server.GetLast(r.Context(), &api.GetLastRequest{})
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// Floor handles `GET /floor` requests.
+func (g *Gateway) Floor(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	res, err := g.server.GetFloor(r.Context(), &api.GetFloorRequest{})
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// HeightForBlock handles `GET /blocks/{blockID}/height` requests.
+func (g *Gateway) HeightForBlock(w http.ResponseWriter, r *http.Request, p params) error {
+
+	blockID, err := p.identifier("blockID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeightForBlockRequest{
+		BlockID: blockID,
+	}
+	res, err := g.server.GetHeightForBlock(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// Commit handles `GET /heights/{height}/commit` requests.
+func (g *Gateway) Commit(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetCommitRequest{
+		Height: height,
+	}
+	res, err := g.server.GetCommit(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	commit := Commit{
+		Height: res.Height,
+		Commit: hex.EncodeToString(res.Commit),
+	}
+
+	return respond(w, commit)
+}
+
+// Header handles `GET /heights/{height}/header` requests.
+func (g *Gateway) Header(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeaderRequest{
+		Height: height,
+	}
+	res, err := g.server.GetHeader(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var header flow.Header
+	err = g.codec.Unmarshal(res.Data, &header)
+	if err != nil {
+		return fmt.Errorf("could not decode header: %w", err)
+	}
+
+	return respond(w, Header{Height: res.Height, Header: &header})
+}
+
+// HeaderByBlockID handles `GET /blocks/{blockID}/header` requests.
+func (g *Gateway) HeaderByBlockID(w http.ResponseWriter, r *http.Request, p params) error {
+
+	blockID, err := p.identifier("blockID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeaderByBlockIDRequest{
+		BlockID: blockID,
+	}
+	res, err := g.server.GetHeaderByBlockID(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var header flow.Header
+	err = g.codec.Unmarshal(res.Data, &header)
+	if err != nil {
+		return fmt.Errorf("could not decode header: %w", err)
+	}
+
+	return respond(w, Header{Height: res.Height, Header: &header})
+}
+
+// Events handles `GET /heights/{height}/events` requests. Event types and type
+// prefixes, transaction IDs and field predicates can be given as comma-separated
+// lists with the `types`, `transactions` and `fields` query parameters.
+func (g *Gateway) Events(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+	txIDs, err := queryBytes(r, "transactions")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetEventsRequest{
+		Height:         height,
+		Types:          query(r, "types"),
+		TransactionIDs: txIDs,
+		Fields:         query(r, "fields"),
+	}
+	res, err := g.server.GetEvents(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var events []flow.Event
+	err = g.codec.Unmarshal(res.Data, &events)
+	if err != nil {
+		return fmt.Errorf("could not decode events: %w", err)
+	}
+
+	return respond(w, Events{Height: res.Height, Types: res.Types, Events: convertEvents(events)})
+}
+
+// HeightForTimestamp handles `GET /timestamps/{timestamp}/height` requests.
+func (g *Gateway) HeightForTimestamp(w http.ResponseWriter, r *http.Request, p params) error {
+
+	timestamp, err := p.timestamp("timestamp")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeightForTimestampRequest{
+		Timestamp: timestamp.UnixNano(),
+	}
+	res, err := g.server.GetHeightForTimestamp(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// Headers handles `GET /headers?start={start}&end={end}` requests.
+func (g *Gateway) Headers(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+
+	headers := array{w: w}
+	stream := listHeadersStream{
+		ctx: r.Context(),
+		send: func(res *api.ListHeadersResponse) error {
+			var header flow.Header
+			err := g.codec.Unmarshal(res.Data, &header)
+			if err != nil {
+				return fmt.Errorf("could not decode header (height: %d): %w", res.Height, err)
+			}
+			return headers.add(Header{Height: res.Height, Header: &header})
+		},
+	}
+
+	req := api.ListHeadersRequest{
+		Start: start,
+		End:   end,
+	}
+	err = g.server.ListHeaders(&req, &stream)
+	if err != nil {
+		return headers.abort(err)
+	}
+
+	return headers.close()
+}
+
+// Commits handles `GET /commits?start={start}&end={end}` requests.
+func (g *Gateway) Commits(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+
+	commits := array{w: w}
+	stream := listCommitsStream{
+		ctx: r.Context(),
+		send: func(res *api.ListCommitsResponse) error {
+			return commits.add(Commit{Height: res.Height, Commit: hex.EncodeToString(res.Commit)})
+		},
+	}
+
+	req := api.ListCommitsRequest{
+		Start: start,
+		End:   end,
+	}
+	err = g.server.ListCommits(&req, &stream)
+	if err != nil {
+		return commits.abort(err)
+	}
+
+	return commits.close()
+}
+
+// EventsRange handles `GET /events?start={start}&end={end}` requests. Event
+// types and type prefixes, transaction IDs and field predicates can be given as
+// comma-separated lists with the `types`, `transactions` and `fields` query
+// parameters.
+func (g *Gateway) EventsRange(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+	txIDs, err := queryBytes(r, "transactions")
+	if err != nil {
+		return err
+	}
+
+	events := array{w: w}
+	stream := getEventsRangeStream{
+		ctx: r.Context(),
+		send: func(res *api.GetEventsRangeResponse) error {
+			var evts []flow.Event
+			err := g.codec.Unmarshal(res.Data, &evts)
+			if err != nil {
+				return fmt.Errorf("could not decode events (height: %d): %w", res.Height, err)
+			}
+			return events.add(Events{Height: res.Height, Types: res.Types, Events: convertEvents(evts)})
+		},
+	}
+
+	req := api.GetEventsRangeRequest{
+		Start:          start,
+		End:            end,
+		Types:          query(r, "types"),
+		TransactionIDs: txIDs,
+		Fields:         query(r, "fields"),
+	}
+	err = g.server.GetEventsRange(&req, &stream)
+	if err != nil {
+		return events.abort(err)
+	}
+
+	return events.close()
+}
+
+// EventHeights handles `GET /events/{type}/heights?start={start}&end={end}`
+// requests.
+func (g *Gateway) EventHeights(w http.ResponseWriter, r *http.Request, p params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+
+	req := api.ListHeightsForEventRequest{
+		Type:  p["type"],
+		Start: start,
+		End:   end,
+	}
+	res, err := g.server.ListHeightsForEvent(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	heights := res.Heights
+	if heights == nil {
+		heights = []uint64{}
+	}
+
+	return respond(w, EventHeights{Type: res.Type, Heights: heights})
+}
+
+// SubscribeBlocks handles `GET /blocks?start={start}` requests. The response is
+// a stream of newline-delimited JSON objects, one for each indexed block, which
+// only ends when the client goes away.
+func (g *Gateway) SubscribeBlocks(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming not supported by response writer")
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	stream := subscribeBlocksStream{
+		ctx: r.Context(),
+		send: func(res *api.SubscribeBlocksResponse) error {
+			block := Block{
+				Height:  res.Height,
+				BlockID: hex.EncodeToString(res.BlockID),
+				Commit:  hex.EncodeToString(res.Commit),
+			}
+			err := encoder.Encode(block)
+			if err != nil {
+				return fmt.Errorf("could not encode block (height: %d): %w", res.Height, err)
+			}
+			flusher.Flush()
+			return nil
+		},
+	}
+
+	req := api.SubscribeBlocksRequest{
+		Start: start,
+	}
+
+	return g.server.SubscribeBlocks(&req, &stream)
+}
+
+// Registers handles `GET /heights/{height}/registers?paths={paths}` requests,
+// where paths are given as a comma-separated list of hex-encoded ledger paths.
+func (g *Gateway) Registers(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+	paths, err := queryBytes(r, "paths")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetRegisterValuesRequest{
+		Height: height,
+		Paths:  paths,
+	}
+	res, err := g.server.GetRegisterValues(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	registers := make([]Register, 0, len(res.Values))
+	for i, value := range res.Values {
+		register := Register{
+			Path:  hex.EncodeToString(res.Paths[i]),
+			Value: hex.EncodeToString(value),
+		}
+		registers = append(registers, register)
+	}
+
+	return respond(w, Registers{Height: res.Height, Registers: registers})
+}
+
+// RegisterHistory handles `GET /registers/{path}/history?start={start}&end={end}`
+// requests, where the path is a hex-encoded ledger path.
+func (g *Gateway) RegisterHistory(w http.ResponseWriter, r *http.Request, p params) error {
+
+	path, err := p.path("path")
+	if err != nil {
+		return err
+	}
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]RegisterChange, 0)
+	stream := getRegisterHistoryStream{
+		ctx: r.Context(),
+		send: func(res *api.GetRegisterHistoryResponse) error {
+			var payload ledger.Payload
+			err := g.codec.Unmarshal(res.Data, &payload)
+			if err != nil {
+				return fmt.Errorf("could not decode payload (height: %d): %w", res.Height, err)
+			}
+			changes = append(changes, RegisterChange{Height: res.Height, Value: hex.EncodeToString(payload.Value)})
+			return nil
+		},
+	}
+
+	req := api.GetRegisterHistoryRequest{
+		Path:  path,
+		Start: start,
+		End:   end,
+	}
+	err = g.server.GetRegisterHistory(&req, &stream)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, RegisterHistory{Path: hex.EncodeToString(path), Changes: changes})
+}
+
+// StateDiff handles `GET /diff?start={start}&end={end}` requests.
+func (g *Gateway) StateDiff(w http.ResponseWriter, r *http.Request, _ params) error {
+
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", start)
+	if err != nil {
+		return err
+	}
+
+	changes := array{w: w}
+	stream := getStateDiffStream{
+		ctx: r.Context(),
+		send: func(res *api.GetStateDiffResponse) error {
+			change := Change{
+				Path: hex.EncodeToString(res.Path),
+			}
+			if len(res.Before) > 0 {
+				var before ledger.Payload
+				err := g.codec.Unmarshal(res.Before, &before)
+				if err != nil {
+					return fmt.Errorf("could not decode payload before (path: %x): %w", res.Path, err)
+				}
+				value := hex.EncodeToString(before.Value)
+				change.Before = &value
+			}
+			var after ledger.Payload
+			err := g.codec.Unmarshal(res.After, &after)
+			if err != nil {
+				return fmt.Errorf("could not decode payload after (path: %x): %w", res.Path, err)
+			}
+			change.After = hex.EncodeToString(after.Value)
+			return changes.add(change)
+		},
+	}
+
+	req := api.GetStateDiffRequest{
+		Start: start,
+		End:   end,
+	}
+	err = g.server.GetStateDiff(&req, &stream)
+	if err != nil {
+		return changes.abort(err)
+	}
+
+	return changes.close()
+}
+
+// Proofs handles `GET /heights/{height}/proofs?paths={paths}` requests, where
+// paths are given as a comma-separated list of hex-encoded ledger paths.
+func (g *Gateway) Proofs(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+	paths, err := queryBytes(r, "paths")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetRegisterProofsRequest{
+		Height: height,
+		Paths:  paths,
+	}
+	res, err := g.server.GetRegisterProofs(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	proofs := make([]Proof, 0, len(res.Proofs))
+	for i, proof := range res.Proofs {
+		p := Proof{
+			Path:  hex.EncodeToString(res.Paths[i]),
+			Proof: hex.EncodeToString(proof),
+		}
+		proofs = append(proofs, p)
+	}
+
+	return respond(w, Proofs{Height: res.Height, Proofs: proofs})
+}
+
+// Collection handles `GET /collections/{collectionID}` requests.
+func (g *Gateway) Collection(w http.ResponseWriter, r *http.Request, p params) error {
+
+	collectionID, err := p.identifier("collectionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetCollectionRequest{
+		CollectionID: collectionID,
+	}
+	res, err := g.server.GetCollection(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var collection flow.LightCollection
+	err = g.codec.Unmarshal(res.Data, &collection)
+	if err != nil {
+		return fmt.Errorf("could not decode collection: %w", err)
+	}
+
+	return respond(w, Collection{CollectionID: collection.ID(), Transactions: collection.Transactions})
+}
+
+// HeightForCollection handles `GET /collections/{collectionID}/height` requests.
+func (g *Gateway) HeightForCollection(w http.ResponseWriter, r *http.Request, p params) error {
+
+	collID, err := p.identifier("collectionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeightForCollectionRequest{
+		CollectionID: collID,
+	}
+	res, err := g.server.GetHeightForCollection(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// TransactionsForCollection handles `GET /collections/{collectionID}/transactions` requests.
+func (g *Gateway) TransactionsForCollection(w http.ResponseWriter, r *http.Request, p params) error {
+
+	collID, err := p.identifier("collectionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.ListTransactionsForCollectionRequest{
+		CollectionID: collID,
+	}
+	res, err := g.server.ListTransactionsForCollection(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	collection := CollectionTransactions{
+		CollectionID: flow.HashToID(res.CollectionID),
+		Identifiers:  identifiers(res.TransactionIDs),
+	}
+
+	return respond(w, collection)
+}
+
+// CollectionsForHeight handles `GET /heights/{height}/collections` requests.
+func (g *Gateway) CollectionsForHeight(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	req := api.ListCollectionsForHeightRequest{
+		Height: height,
+	}
+	res, err := g.server.ListCollectionsForHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Identifiers{Height: res.Height, Identifiers: identifiers(res.CollectionIDs)})
+}
+
+// Guarantee handles `GET /guarantees/{collectionID}` requests.
+func (g *Gateway) Guarantee(w http.ResponseWriter, r *http.Request, p params) error {
+
+	collectionID, err := p.identifier("collectionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetGuaranteeRequest{
+		CollectionID: collectionID,
+	}
+	res, err := g.server.GetGuarantee(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var guarantee flow.CollectionGuarantee
+	err = g.codec.Unmarshal(res.Data, &guarantee)
+	if err != nil {
+		return fmt.Errorf("could not decode guarantee: %w", err)
+	}
+
+	converted := Guarantee{
+		CollectionID:     guarantee.CollectionID,
+		ReferenceBlockID: guarantee.ReferenceBlockID,
+		SignerIDs:        guarantee.SignerIDs,
+		Signature:        hex.EncodeToString(guarantee.Signature),
+	}
+
+	return respond(w, converted)
+}
+
+// Transaction handles `GET /transactions/{transactionID}` requests.
+func (g *Gateway) Transaction(w http.ResponseWriter, r *http.Request, p params) error {
+
+	txID, err := p.identifier("transactionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetTransactionRequest{
+		TransactionID: txID,
+	}
+	res, err := g.server.GetTransaction(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var tx flow.TransactionBody
+	err = g.codec.Unmarshal(res.Data, &tx)
+	if err != nil {
+		return fmt.Errorf("could not decode transaction: %w", err)
+	}
+
+	return respond(w, convertTransaction(&tx))
+}
+
+// HeightForTransaction handles `GET /transactions/{transactionID}/height`
+// requests.
+func (g *Gateway) HeightForTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+
+	txID, err := p.identifier("transactionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeightForTransactionRequest{
+		TransactionID: txID,
+	}
+	res, err := g.server.GetHeightForTransaction(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// TransactionsForHeight handles `GET /heights/{height}/transactions` requests.
+func (g *Gateway) TransactionsForHeight(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	req := api.ListTransactionsForHeightRequest{
+		Height: height,
+	}
+	res, err := g.server.ListTransactionsForHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Identifiers{Height: res.Height, Identifiers: identifiers(res.TransactionIDs)})
+}
+
+// TransactionsForAccount handles `GET /accounts/{address}/transactions?start={start}&end={end}&after={transactionID}&limit={limit}`
+// requests. Without an end height, the range extends to the last indexed height.
+func (g *Gateway) TransactionsForAccount(w http.ResponseWriter, r *http.Request, p params) error {
+
+	address, err := p.address("address")
+	if err != nil {
+		return err
+	}
+	last, err := g.server.GetLast(r.Context(), &api.GetLastRequest{})
+	if err != nil {
+		return err
+	}
+	start, err := queryUint(r, "start", 0)
+	if err != nil {
+		return err
+	}
+	end, err := queryUint(r, "end", last.Height)
+	if err != nil {
+		return err
+	}
+	limit, err := queryUint(r, "limit", 0)
+	if err != nil {
+		return err
+	}
+	if limit > api.MaxPageSize {
+		return fmt.Errorf("invalid limit (%d): %w", limit, dps.ErrInvalidArgument)
+	}
+
+	req := api.ListTransactionsForAccountRequest{
+		Address: address,
+		Start:   start,
+		End:     end,
+		Limit:   uint32(limit),
+	}
+	after := r.URL.Query().Get("after")
+	if after != "" {
+		txID, err := flow.HexStringToIdentifier(after)
+		if err != nil {
+			return fmt.Errorf("invalid after (%s): %w", err, dps.ErrInvalidArgument)
+		}
+		req.After = txID[:]
+	}
+	res, err := g.server.ListTransactionsForAccount(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	txs := make([]AccountTransaction, 0, len(res.Transactions))
+	for _, transaction := range res.Transactions {
+		tx := AccountTransaction{
+			Height:        transaction.Height,
+			TransactionID: flow.HashToID(transaction.TransactionID),
+			Proposer:      transaction.Proposer,
+			Payer:         transaction.Payer,
+			Authorizer:    transaction.Authorizer,
+		}
+		txs = append(txs, tx)
+	}
+
+	return respond(w, AccountTransactions{Address: hex.EncodeToString(res.Address), Transactions: txs})
+}
+
+// Result handles `GET /results/{transactionID}` requests.
+func (g *Gateway) Result(w http.ResponseWriter, r *http.Request, p params) error {
+
+	txID, err := p.identifier("transactionID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetResultRequest{
+		TransactionID: txID,
+	}
+	res, err := g.server.GetResult(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var result flow.TransactionResult
+	err = g.codec.Unmarshal(res.Data, &result)
+	if err != nil {
+		return fmt.Errorf("could not decode transaction result: %w", err)
+	}
+
+	converted := Result{
+		TransactionID:   result.TransactionID,
+		ErrorMessage:    result.ErrorMessage,
+		ComputationUsed: result.ComputationUsed,
+	}
+
+	return respond(w, converted)
+}
+
+// Seal handles `GET /seals/{sealID}` requests.
+func (g *Gateway) Seal(w http.ResponseWriter, r *http.Request, p params) error {
+
+	sealID, err := p.identifier("sealID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetSealRequest{
+		SealID: sealID,
+	}
+	res, err := g.server.GetSeal(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	var seal flow.Seal
+	err = g.codec.Unmarshal(res.Data, &seal)
+	if err != nil {
+		return fmt.Errorf("could not decode seal: %w", err)
+	}
+
+	converted := Seal{
+		SealID:     seal.ID(),
+		BlockID:    seal.BlockID,
+		ResultID:   seal.ResultID,
+		FinalState: hex.EncodeToString(seal.FinalState[:]),
+	}
+
+	return respond(w, converted)
+}
+
+// HeightForSeal handles `GET /seals/{sealID}/height` requests.
+func (g *Gateway) HeightForSeal(w http.ResponseWriter, r *http.Request, p params) error {
+
+	sealID, err := p.identifier("sealID")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetHeightForSealRequest{
+		SealID: sealID,
+	}
+	res, err := g.server.GetHeightForSeal(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Height{Height: res.Height})
+}
+
+// SealsForHeight handles `GET /heights/{height}/seals` requests.
+func (g *Gateway) SealsForHeight(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	req := api.ListSealsForHeightRequest{
+		Height: height,
+	}
+	res, err := g.server.ListSealsForHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, Identifiers{Height: res.Height, Identifiers: identifiers(res.SealIDs)})
+}
+
+// Account handles `GET /heights/{height}/accounts/{address}` requests.
+func (g *Gateway) Account(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+	address, err := p.address("address")
+	if err != nil {
+		return err
+	}
+
+	req := api.GetAccountAtHeightRequest{
+		Height:  height,
+		Address: address,
+	}
+	res, err := g.server.GetAccountAtHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(res.Keys))
+	for _, key := range res.Keys {
+		keys = append(keys, hex.EncodeToString(key))
+	}
+	contracts := make(map[string]string, len(res.Contracts))
+	for _, contract := range res.Contracts {
+		contracts[contract.Name] = string(contract.Code)
+	}
+
+	account := Account{
+		Height:    res.Height,
+		Address:   hex.EncodeToString(res.Address),
+		Balance:   res.Balance,
+		Keys:      keys,
+		Contracts: contracts,
+	}
+
+	return respond(w, account)
+}
+
+// AccountKey handles `GET /heights/{height}/accounts/{address}/keys/{index}`
+// requests.
+func (g *Gateway) AccountKey(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+	address, err := p.address("address")
+	if err != nil {
+		return err
+	}
+	index, err := parseUint("index", p["index"])
+	if err != nil {
+		return err
+	}
+
+	req := api.GetAccountKeyAtHeightRequest{
+		Height:  height,
+		Address: address,
+		Index:   uint32(index),
+	}
+	res, err := g.server.GetAccountKeyAtHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	key := AccountKey{
+		Height:  res.Height,
+		Address: hex.EncodeToString(res.Address),
+		Index:   res.Index,
+		Key:     hex.EncodeToString(res.Key),
+	}
+
+	return respond(w, key)
+}
+
+// Script handles `POST /heights/{height}/scripts` requests, with a JSON body
+// that contains the script and its JSON-Cadence arguments.
+func (g *Gateway) Script(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	var body ScriptRequest
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return fmt.Errorf("invalid script request (%s): %w", err, dps.ErrInvalidArgument)
+	}
+
+	arguments := make([][]byte, 0, len(body.Arguments))
+	for _, argument := range body.Arguments {
+		arguments = append(arguments, argument)
+	}
+
+	req := api.ExecuteScriptAtHeightRequest{
+		Height:    height,
+		Script:    []byte(body.Script),
+		Arguments: arguments,
+	}
+	res, err := g.server.ExecuteScriptAtHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return respond(w, ScriptResult{Height: res.Height, Result: res.Result})
+}
+
+// ExecuteTransaction handles `POST /heights/{height}/transactions` requests,
+// with a JSON body that contains the unsigned transaction to execute.
+func (g *Gateway) ExecuteTransaction(w http.ResponseWriter, r *http.Request, p params) error {
+
+	height, err := p.height("height")
+	if err != nil {
+		return err
+	}
+
+	var body TransactionRequest
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return fmt.Errorf("invalid transaction request (%s): %w", err, dps.ErrInvalidArgument)
+	}
+
+	arguments := make([][]byte, 0, len(body.Arguments))
+	for _, argument := range body.Arguments {
+		arguments = append(arguments, argument)
+	}
+
+	req := api.ExecuteTransactionAtHeightRequest{
+		Height: height,
+		Transaction: &api.Transaction{
+			ReferenceBlockID: body.ReferenceBlockID[:],
+			Script:           body.Script,
+			Arguments:        arguments,
+			GasLimit:         body.GasLimit,
+			ProposalKey: &api.ProposalKey{
+				Address:        body.ProposalKey.Address.Bytes(),
+				KeyIndex:       body.ProposalKey.KeyIndex,
+				SequenceNumber: body.ProposalKey.SequenceNumber,
+			},
+			Payer:       body.Payer.Bytes(),
+			Authorizers: convert.AddressesToBytes(body.Authorizers),
+		},
+	}
+	res, err := g.server.ExecuteTransactionAtHeight(r.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	run := DryRun{
+		Height:          res.Height,
+		Failed:          res.Failed,
+		ErrorMessage:    res.ErrorMessage,
+		Events:          make([]Event, 0, len(res.Events)),
+		ComputationUsed: res.ComputationUsed,
+		Fees:            res.Fees,
+		Writes:          make([]Write, 0, len(res.Writes)),
+	}
+	for _, event := range res.Events {
+		e := Event{
+			Type:             event.Type,
+			TransactionID:    flow.HashToID(event.TransactionID),
+			TransactionIndex: event.TransactionIndex,
+			EventIndex:       event.EventIndex,
+			Payload:          event.Payload,
+		}
+		run.Events = append(run.Events, e)
+	}
+	for _, register := range res.Writes {
+		write := Write{
+			Owner:      hex.EncodeToString(register.RegisterID.Owner),
+			Controller: hex.EncodeToString(register.RegisterID.Controller),
+			Key:        hex.EncodeToString(register.RegisterID.Key),
+			Value:      hex.EncodeToString(register.Value),
+		}
+		run.Writes = append(run.Writes, write)
+	}
+
+	return respond(w, run)
+}
@@ -0,0 +1,347 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Error is the JSON response for failed requests.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Height is the JSON response for requests that return a block height.
+type Height struct {
+	Height uint64 `json:"height"`
+}
+
+// Commit is the JSON representation of a state commitment at a given height.
+type Commit struct {
+	Height uint64 `json:"height"`
+	Commit string `json:"commit"`
+}
+
+// Header is the JSON representation of a block header at a given height.
+type Header struct {
+	Height uint64       `json:"height"`
+	Header *flow.Header `json:"header"`
+}
+
+// Events is the JSON representation of the events at a given height.
+type Events struct {
+	Height uint64   `json:"height"`
+	Types  []string `json:"types"`
+	Events []Event  `json:"events"`
+}
+
+// EventHeights is the JSON representation of the heights that contain events
+// of a given type.
+type EventHeights struct {
+	Type    string   `json:"type"`
+	Heights []uint64 `json:"heights"`
+}
+
+// Event is the JSON representation of an event, where the payload is included
+// as JSON-Cadence.
+type Event struct {
+	Type             string          `json:"type"`
+	TransactionID    flow.Identifier `json:"transaction_id"`
+	TransactionIndex uint32          `json:"transaction_index"`
+	EventIndex       uint32          `json:"event_index"`
+	Payload          json.RawMessage `json:"payload"`
+}
+
+// Register is the JSON representation of a register value.
+type Register struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// Registers is the JSON representation of register values at a given height.
+type Registers struct {
+	Height    uint64     `json:"height"`
+	Registers []Register `json:"registers"`
+}
+
+// RegisterChange is the JSON representation of a register value written at a
+// given height.
+type RegisterChange struct {
+	Height uint64 `json:"height"`
+	Value  string `json:"value"`
+}
+
+// RegisterHistory is the JSON representation of the changes to a register.
+type RegisterHistory struct {
+	Path    string           `json:"path"`
+	Changes []RegisterChange `json:"changes"`
+}
+
+// Change is the JSON representation of the change of a register value between
+// two heights. The value before is null if the register did not exist yet.
+type Change struct {
+	Path   string  `json:"path"`
+	Before *string `json:"before"`
+	After  string  `json:"after"`
+}
+
+// Proof is the JSON representation of an encoded register proof.
+type Proof struct {
+	Path  string `json:"path"`
+	Proof string `json:"proof"`
+}
+
+// Proofs is the JSON representation of register proofs at a given height.
+type Proofs struct {
+	Height uint64  `json:"height"`
+	Proofs []Proof `json:"proofs"`
+}
+
+// Identifiers is the JSON representation of a list of identifiers for a given
+// height.
+type Identifiers struct {
+	Height      uint64            `json:"height"`
+	Identifiers []flow.Identifier `json:"identifiers"`
+}
+
+// CollectionTransactions is the JSON representation of the list of transaction
+// identifiers within a given collection.
+type CollectionTransactions struct {
+	CollectionID flow.Identifier   `json:"collection_id"`
+	Identifiers  []flow.Identifier `json:"identifiers"`
+}
+
+// Collection is the JSON representation of a light collection.
+type Collection struct {
+	CollectionID flow.Identifier   `json:"collection_id"`
+	Transactions []flow.Identifier `json:"transactions"`
+}
+
+// Guarantee is the JSON representation of a collection guarantee.
+type Guarantee struct {
+	CollectionID     flow.Identifier   `json:"collection_id"`
+	ReferenceBlockID flow.Identifier   `json:"reference_block_id"`
+	SignerIDs        []flow.Identifier `json:"signer_ids"`
+	Signature        string            `json:"signature"`
+}
+
+// Transaction is the JSON representation of a transaction body, where the
+// script is included as text and the arguments as JSON-Cadence.
+type Transaction struct {
+	TransactionID      flow.Identifier        `json:"transaction_id"`
+	ReferenceBlockID   flow.Identifier        `json:"reference_block_id"`
+	Script             string                 `json:"script"`
+	Arguments          []json.RawMessage      `json:"arguments"`
+	GasLimit           uint64                 `json:"gas_limit"`
+	ProposalKey        ProposalKey            `json:"proposal_key"`
+	Payer              flow.Address           `json:"payer"`
+	Authorizers        []flow.Address         `json:"authorizers"`
+	PayloadSignatures  []TransactionSignature `json:"payload_signatures"`
+	EnvelopeSignatures []TransactionSignature `json:"envelope_signatures"`
+}
+
+// ProposalKey is the JSON representation of the proposal key of a transaction.
+type ProposalKey struct {
+	Address        flow.Address `json:"address"`
+	KeyIndex       uint64       `json:"key_index"`
+	SequenceNumber uint64       `json:"sequence_number"`
+}
+
+// TransactionSignature is the JSON representation of a transaction signature.
+type TransactionSignature struct {
+	Address     flow.Address `json:"address"`
+	SignerIndex int          `json:"signer_index"`
+	KeyIndex    uint64       `json:"key_index"`
+	Signature   string       `json:"signature"`
+}
+
+// Result is the JSON representation of a transaction result.
+type Result struct {
+	TransactionID   flow.Identifier `json:"transaction_id"`
+	ErrorMessage    string          `json:"error_message"`
+	ComputationUsed uint64          `json:"computation_used"`
+}
+
+// Seal is the JSON representation of a block seal.
+type Seal struct {
+	SealID     flow.Identifier `json:"seal_id"`
+	BlockID    flow.Identifier `json:"block_id"`
+	ResultID   flow.Identifier `json:"result_id"`
+	FinalState string          `json:"final_state"`
+}
+
+// Block is the JSON representation of a block notification.
+type Block struct {
+	Height  uint64 `json:"height"`
+	BlockID string `json:"block_id"`
+	Commit  string `json:"commit"`
+}
+
+// Account is the JSON representation of an account, where the keys are
+// encoded as hexadecimal strings and the contract code is included as text.
+type Account struct {
+	Height    uint64            `json:"height"`
+	Address   string            `json:"address"`
+	Balance   uint64            `json:"balance"`
+	Keys      []string          `json:"keys"`
+	Contracts map[string]string `json:"contracts"`
+}
+
+// AccountTransactions is the JSON representation of a page of transactions
+// that a given account took part in.
+type AccountTransactions struct {
+	Address      string               `json:"address"`
+	Transactions []AccountTransaction `json:"transactions"`
+}
+
+// AccountTransaction is the JSON representation of a transaction that an
+// account took part in, along with the roles of the account in it.
+type AccountTransaction struct {
+	Height        uint64          `json:"height"`
+	TransactionID flow.Identifier `json:"transaction_id"`
+	Proposer      bool            `json:"proposer"`
+	Payer         bool            `json:"payer"`
+	Authorizer    bool            `json:"authorizer"`
+}
+
+// AccountKey is the JSON representation of an encoded account key.
+type AccountKey struct {
+	Height  uint64 `json:"height"`
+	Address string `json:"address"`
+	Index   uint32 `json:"index"`
+	Key     string `json:"key"`
+}
+
+// ScriptRequest is the JSON body of script execution requests, where the
+// arguments are given as JSON-Cadence.
+type ScriptRequest struct {
+	Script    string            `json:"script"`
+	Arguments []json.RawMessage `json:"arguments"`
+}
+
+// ScriptResult is the JSON response of script execution requests, where the
+// result is included as JSON-Cadence.
+type ScriptResult struct {
+	Height uint64          `json:"height"`
+	Result json.RawMessage `json:"result"`
+}
+
+// TransactionRequest is the JSON body of transaction execution requests, where
+// the script is given as text and the arguments as JSON-Cadence. Signatures
+// are not needed, as they are not verified.
+type TransactionRequest struct {
+	ReferenceBlockID flow.Identifier   `json:"reference_block_id"`
+	Script           string            `json:"script"`
+	Arguments        []json.RawMessage `json:"arguments"`
+	GasLimit         uint64            `json:"gas_limit"`
+	ProposalKey      ProposalKey       `json:"proposal_key"`
+	Payer            flow.Address      `json:"payer"`
+	Authorizers      []flow.Address    `json:"authorizers"`
+}
+
+// DryRun is the JSON response of transaction execution requests, where the
+// event payloads are included as JSON-Cadence and the written registers as
+// hexadecimal strings.
+type DryRun struct {
+	Height          uint64  `json:"height"`
+	Failed          bool    `json:"failed"`
+	ErrorMessage    string  `json:"error_message"`
+	Events          []Event `json:"events"`
+	ComputationUsed uint64  `json:"computation_used"`
+	Fees            uint64  `json:"fees"`
+	Writes          []Write `json:"writes"`
+}
+
+// Write is the JSON representation of a register written by a transaction.
+type Write struct {
+	Owner      string `json:"owner"`
+	Controller string `json:"controller"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+}
+
+func convertEvents(events []flow.Event) []Event {
+	converted := make([]Event, 0, len(events))
+	for _, event := range events {
+		e := Event{
+			Type:             string(event.Type),
+			TransactionID:    event.TransactionID,
+			TransactionIndex: event.TransactionIndex,
+			EventIndex:       event.EventIndex,
+			Payload:          raw(event.Payload),
+		}
+		converted = append(converted, e)
+	}
+	return converted
+}
+
+func convertTransaction(tx *flow.TransactionBody) Transaction {
+	arguments := make([]json.RawMessage, 0, len(tx.Arguments))
+	for _, argument := range tx.Arguments {
+		arguments = append(arguments, raw(argument))
+	}
+	return Transaction{
+		TransactionID:    tx.ID(),
+		ReferenceBlockID: tx.ReferenceBlockID,
+		Script:           string(tx.Script),
+		Arguments:        arguments,
+		GasLimit:         tx.GasLimit,
+		ProposalKey: ProposalKey{
+			Address:        tx.ProposalKey.Address,
+			KeyIndex:       tx.ProposalKey.KeyIndex,
+			SequenceNumber: tx.ProposalKey.SequenceNumber,
+		},
+		Payer:              tx.Payer,
+		Authorizers:        tx.Authorizers,
+		PayloadSignatures:  convertSignatures(tx.PayloadSignatures),
+		EnvelopeSignatures: convertSignatures(tx.EnvelopeSignatures),
+	}
+}
+
+func convertSignatures(signatures []flow.TransactionSignature) []TransactionSignature {
+	converted := make([]TransactionSignature, 0, len(signatures))
+	for _, signature := range signatures {
+		s := TransactionSignature{
+			Address:     signature.Address,
+			SignerIndex: signature.SignerIndex,
+			KeyIndex:    signature.KeyIndex,
+			Signature:   hex.EncodeToString(signature.Signature),
+		}
+		converted = append(converted, s)
+	}
+	return converted
+}
+
+// raw includes the given data as is if it is valid JSON, which is the case for
+// JSON-Cadence payloads, and as a hex-encoded string otherwise.
+func raw(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return data
+	}
+	encoded, _ := json.Marshal(hex.EncodeToString(data))
+	return encoded
+}
+
+func identifiers(ids [][]byte) []flow.Identifier {
+	converted := make([]flow.Identifier, 0, len(ids))
+	for _, id := range ids {
+		converted = append(converted, flow.HashToID(id))
+	}
+	return converted
+}
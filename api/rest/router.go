@@ -0,0 +1,166 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// handler is the signature of the functions that handle requests for a route.
+type handler func(w http.ResponseWriter, r *http.Request, p params) error
+
+// route is a REST route, where the segments of the path that are between curly
+// braces are parameters.
+type route struct {
+	method   string
+	segments []string
+	handler  handler
+}
+
+// match checks whether the given path segments match the route and returns the
+// route parameters if they do.
+func (r route) match(segments []string) (params, bool) {
+
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+
+	p := make(params)
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			p[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return p, true
+}
+
+// split splits a path into its non-empty segments.
+func split(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// params are the parameters of a route.
+type params map[string]string
+
+// height parses the route parameter with the given name as a block height.
+func (p params) height(name string) (uint64, error) {
+	return parseUint(name, p[name])
+}
+
+// timestamp parses the route parameter with the given name as an RFC 3339
+// timestamp.
+func (p params) timestamp(name string) (time.Time, error) {
+	timestamp, err := time.Parse(time.RFC3339Nano, p[name])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+	}
+	return timestamp, nil
+}
+
+// identifier parses the route parameter with the given name as a hex-encoded
+// Flow identifier.
+func (p params) identifier(name string) ([]byte, error) {
+	id, err := flow.HexStringToIdentifier(p[name])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+	}
+	return id[:], nil
+}
+
+// address parses the route parameter with the given name as a hex-encoded
+// Flow address, which has to be exactly eight bytes long.
+func (p params) address(name string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(p[name], "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+	}
+	if len(data) != flow.AddressLength {
+		return nil, fmt.Errorf("invalid %s length (%d): %w", name, len(data), dps.ErrInvalidArgument)
+	}
+	return data, nil
+}
+
+// path parses the route parameter with the given name as a hex-encoded ledger
+// path.
+func (p params) path(name string) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(p[name], "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+	}
+	return data, nil
+}
+
+// query returns the comma-separated values of the query parameter with the
+// given name.
+func query(r *http.Request, name string) []string {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// queryUint parses the query parameter with the given name as an unsigned
+// integer. If the parameter is missing, the default value is returned.
+func queryUint(r *http.Request, name string, def uint64) (uint64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	return parseUint(name, value)
+}
+
+// queryBytes parses the comma-separated values of the query parameter with the
+// given name as hex-encoded byte slices.
+func queryBytes(r *http.Request, name string) ([][]byte, error) {
+	var values [][]byte
+	for _, value := range query(r, name) {
+		data, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+		}
+		values = append(values, data)
+	}
+	return values, nil
+}
+
+func parseUint(name string, value string) (uint64, error) {
+	number, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s (%s): %w", name, err, dps.ErrInvalidArgument)
+	}
+	return number, nil
+}
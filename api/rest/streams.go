@@ -0,0 +1,110 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package rest
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	api "github.com/optakt/flow-dps/api/dps"
+)
+
+// The following types adapt the streaming methods of the DPS API server to the
+// gateway, by forwarding the streamed responses to a callback.
+
+type listHeadersStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.ListHeadersResponse) error
+}
+
+func (s *listHeadersStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *listHeadersStream) Send(res *api.ListHeadersResponse) error {
+	return s.send(res)
+}
+
+type listCommitsStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.ListCommitsResponse) error
+}
+
+func (s *listCommitsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *listCommitsStream) Send(res *api.ListCommitsResponse) error {
+	return s.send(res)
+}
+
+type getEventsRangeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.GetEventsRangeResponse) error
+}
+
+func (s *getEventsRangeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *getEventsRangeStream) Send(res *api.GetEventsRangeResponse) error {
+	return s.send(res)
+}
+
+type getRegisterHistoryStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.GetRegisterHistoryResponse) error
+}
+
+func (s *getRegisterHistoryStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *getRegisterHistoryStream) Send(res *api.GetRegisterHistoryResponse) error {
+	return s.send(res)
+}
+
+type getStateDiffStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.GetStateDiffResponse) error
+}
+
+func (s *getStateDiffStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *getStateDiffStream) Send(res *api.GetStateDiffResponse) error {
+	return s.send(res)
+}
+
+type subscribeBlocksStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	send func(*api.SubscribeBlocksResponse) error
+}
+
+func (s *subscribeBlocksStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *subscribeBlocksStream) Send(res *api.SubscribeBlocksResponse) error {
+	return s.send(res)
+}
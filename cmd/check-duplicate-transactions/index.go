@@ -25,6 +25,7 @@ import (
 
 	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
 	"github.com/optakt/flow-dps/service/storage"
 )
 
@@ -36,18 +37,24 @@ func indexCheck(log zerolog.Logger, dir string) (map[uint64][]flow.Identifier, e
 	log.Info().Str("index", dir).Msg("starting index state duplicate check")
 
 	// Open the index database.
-	index, err := badger.Open(dps.DefaultOptions(dir).WithReadOnly(true))
+	db, err := badger.Open(dps.DefaultOptions(dir).WithReadOnly(true))
 	if err != nil {
 		return nil, fmt.Errorf("could not open state index (dir: %s): %w", dir, err)
 	}
-	defer index.Close()
+	defer db.Close()
 
 	// Initialize the storage library.
 	lib := storage.New(zbor.NewCodec())
 
+	// Make sure that the index uses the version supported by this release.
+	err = index.CheckVersion(db, lib)
+	if err != nil {
+		return nil, fmt.Errorf("could not check index version: %w", err)
+	}
+
 	// Retrieve the root height as a start height for duplicate check.
 	var first uint64
-	err = index.View(lib.RetrieveFirst(&first))
+	err = db.View(lib.RetrieveFirst(&first))
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve first: %w", err)
 	}
@@ -60,8 +67,8 @@ func indexCheck(log zerolog.Logger, dir string) (map[uint64][]flow.Identifier, e
 
 		// height => txIDs
 		var txIDs []flow.Identifier
-		err = index.View(lib.LookupTransactionsForHeight(height, &txIDs))
-		if errors.Is(err, badger.ErrKeyNotFound) {
+		err = db.View(lib.LookupTransactionsForHeight(height, &txIDs))
+		if errors.Is(err, dps.ErrNotFound) {
 			break
 		}
 		if err != nil {
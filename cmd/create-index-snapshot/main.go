@@ -27,7 +27,10 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 
+	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/storage"
 )
 
 const (
@@ -78,6 +81,13 @@ func run() int {
 	}
 	defer db.Close()
 
+	// Snapshots of outdated indexes are still useful, as they can be migrated
+	// after being restored, so we only warn about them.
+	err = index.CheckVersion(db, storage.New(zbor.NewCodec()))
+	if err != nil {
+		log.Warn().Err(err).Msg("index version is not supported by this release")
+	}
+
 	// We want to pipe everything to stdout in the end; if the user wants to
 	// create a file, he can redirect the output.
 	var writer io.Writer
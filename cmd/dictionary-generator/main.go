@@ -27,6 +27,8 @@ import (
 	"github.com/optakt/flow-dps/codec/generator"
 	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/storage"
 )
 
 const (
@@ -86,6 +88,14 @@ func run() int {
 
 	codec := zbor.NewCodec()
 
+	// Make sure that the index uses the version of the index schema and codec
+	// supported by this release, as we need to decode its values.
+	err = index.CheckVersion(db, storage.New(codec))
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
 	samplePath := flagSamplePath
 	if flagSamplePath == "" {
 		samplePath, err = os.MkdirTemp("", "samples")
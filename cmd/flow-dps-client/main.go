@@ -15,6 +15,8 @@
 package main
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"os"
 	"os/signal"
@@ -24,10 +26,12 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/encoding/json"
 
+	"github.com/optakt/flow-dps/api/auth"
 	"github.com/optakt/flow-dps/api/dps"
 	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/convert"
@@ -51,12 +55,23 @@ func run() int {
 
 	// Command line parameter initialization.
 	var (
-		flagAPI    string
-		flagCache  uint64
-		flagHeight uint64
-		flagLevel  string
-		flagParams string
-		flagScript string
+		flagAPI     string
+		flagCache   uint64
+		flagHeight  uint64
+		flagLevel   string
+		flagParams  string
+		flagRemote  bool
+		flagRetries uint
+		flagScript  string
+		flagSporks  string
+		flagTimeout time.Duration
+		flagTrusted string
+		flagVerify  bool
+
+		flagTLS     bool
+		flagTLSCA   string
+		flagTLSCert string
+		flagTLSKey  string
 	)
 
 	pflag.StringVarP(&flagAPI, "api", "a", "", "host for GRPC API server")
@@ -64,7 +79,17 @@ func run() int {
 	pflag.Uint64VarP(&flagHeight, "height", "h", 0, "block height to execute the script at")
 	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
 	pflag.StringVarP(&flagParams, "params", "p", "", "comma-separated list of Cadence parameters")
+	pflag.BoolVarP(&flagRemote, "remote", "r", false, "execute the script on the API server instead of locally")
+	pflag.UintVar(&flagRetries, "retries", 3, "maximum number of retries for requests to an unavailable API server")
 	pflag.StringVarP(&flagScript, "script", "s", "script.cdc", "path to file with Cadence script")
+	pflag.DurationVar(&flagTimeout, "timeout", 0, "deadline for each request to the API server (no deadline when zero)")
+	pflag.StringVar(&flagSporks, "sporks", "", "path to JSON or YAML file with spork table used to choose the API server (built-in table is used when left empty)")
+	pflag.StringVarP(&flagTrusted, "trusted", "t", "", "host for GRPC API server trusted to provide state commitments for verification")
+	pflag.BoolVarP(&flagVerify, "verify", "v", false, "verify register values against proofs for the state commitment")
+	pflag.BoolVar(&flagTLS, "tls", false, "connect to the GRPC API servers over TLS")
+	pflag.StringVar(&flagTLSCA, "tls-ca", "", "path to PEM-encoded CA certificates that server certificates have to be issued by (system CAs are used when left empty)")
+	pflag.StringVar(&flagTLSCert, "tls-cert", "", "path to PEM-encoded TLS client certificate")
+	pflag.StringVar(&flagTLSKey, "tls-key", "", "path to PEM-encoded TLS client private key")
 
 	pflag.Parse()
 
@@ -78,23 +103,75 @@ func run() int {
 	}
 	log = log.Level(level)
 
+	// Cancel pending requests to the API when interrupted.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-sig
+		log.Info().Msg("Flow DPS Client stopping")
+		cancel()
+	}()
+
+	// Verification of register values only works for local execution.
+	if flagRemote && flagVerify {
+		log.Error().Msg("verification is not supported for remote script execution")
+		return failure
+	}
+
 	// If no API server is given, choose based on height.
 	if flagAPI == "" {
-		for _, spork := range DefaultSporks {
-			if flagHeight >= spork.First && flagHeight <= spork.Last {
-				log.Info().Uint64("height", flagHeight).Str("spork", spork.Name).Str("api", spork.API).Msg("spork and API chosen based on height")
-				flagAPI = spork.API
-				break
+		sporks := dps.DefaultSporks
+		if flagSporks != "" {
+			file, err := os.Open(flagSporks)
+			if err != nil {
+				log.Error().Str("sporks", flagSporks).Err(err).Msg("could not open spork table")
+				return failure
+			}
+			sporks, err = dps.ReadSporks(file)
+			_ = file.Close()
+			if err != nil {
+				log.Error().Str("sporks", flagSporks).Err(err).Msg("could not read spork table")
+				return failure
 			}
 		}
+		spork, err := sporks.Find(flagHeight)
+		if err != nil {
+			log.Error().Uint64("height", flagHeight).Err(err).Msg("could not find spork and API for height")
+			return failure
+		}
+		log.Info().Uint64("height", flagHeight).Str("spork", spork.Name).Str("api", spork.API).Msg("spork and API chosen based on height")
+		flagAPI = spork.API
 	}
-	if flagAPI == "" {
-		log.Error().Uint64("height", flagHeight).Msg("could not find spork and API for height")
+
+	// Initialize the transport credentials. Giving a CA or a client key pair
+	// implies the use of TLS.
+	if (flagTLSCert == "") != (flagTLSKey == "") {
+		log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Msg("TLS certificate and key need to be provided together")
 		return failure
 	}
+	transport := grpc.WithInsecure()
+	if flagTLS || flagTLSCA != "" || flagTLSCert != "" {
+		var rootCAs *x509.CertPool
+		if flagTLSCA != "" {
+			rootCAs, err = auth.LoadCertPool(flagTLSCA)
+			if err != nil {
+				log.Error().Str("ca", flagTLSCA).Err(err).Msg("could not load TLS CAs")
+				return failure
+			}
+		}
+		var pair *auth.KeyPair
+		if flagTLSCert != "" {
+			pair, err = auth.LoadKeyPair(flagTLSCert, flagTLSKey)
+			if err != nil {
+				log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Err(err).Msg("could not load TLS key pair")
+				return failure
+			}
+		}
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(auth.ClientTLS(pair, rootCAs)))
+	}
 
 	// Initialize the API client.
-	conn, err := grpc.Dial(flagAPI, grpc.WithInsecure())
+	conn, err := grpc.Dial(flagAPI, transport)
 	if err != nil {
 		log.Error().Str("api", flagAPI).Err(err).Msg("could not dial API host")
 		return failure
@@ -122,17 +199,70 @@ func run() int {
 		}
 	}
 
+	// If remote execution is enabled, the API server executes the script on
+	// top of its local index, and we only need to print the result.
+	client := dps.NewAPIClient(conn)
+	if flagRemote {
+		var arguments [][]byte
+		for _, arg := range args {
+			argument, err := json.Encode(arg)
+			if err != nil {
+				log.Error().Err(err).Msg("could not encode Cadence value")
+				return failure
+			}
+			arguments = append(arguments, argument)
+		}
+		req := dps.ExecuteScriptAtHeightRequest{
+			Height:    flagHeight,
+			Script:    script,
+			Arguments: arguments,
+		}
+		if flagTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, flagTimeout)
+			defer cancel()
+		}
+		res, err := client.ExecuteScriptAtHeight(ctx, &req)
+		if err != nil {
+			log.Error().Err(err).Msg("could not execute script remotely")
+			return failure
+		}
+
+		fmt.Println(string(res.Result))
+
+		return success
+	}
+
 	// Initialize codec.
 	codec := zbor.NewCodec()
 
+	// If verification is enabled, check register values against proofs, and
+	// optionally take the state commitments from a trusted API server.
+	options := []func(*dps.IndexConfig){
+		dps.WithTimeout(flagTimeout),
+		dps.WithRetries(flagRetries),
+	}
+	if flagVerify {
+		options = append(options, dps.WithVerification())
+	}
+	if flagVerify && flagTrusted != "" {
+		trustedConn, err := grpc.Dial(flagTrusted, transport)
+		if err != nil {
+			log.Error().Str("trusted", flagTrusted).Err(err).Msg("could not dial trusted API host")
+			return failure
+		}
+		defer trustedConn.Close()
+		trusted := dps.IndexFromAPI(dps.NewAPIClient(trustedConn), codec, dps.WithTimeout(flagTimeout), dps.WithRetries(flagRetries))
+		options = append(options, dps.WithTrustedCommits(trusted))
+	}
+
 	// Execute the script using remote lookup and read.
-	client := dps.NewAPIClient(conn)
-	invoke, err := invoker.New(dps.IndexFromAPI(client, codec), invoker.WithCacheSize(flagCache))
+	invoke, err := invoker.New(dps.IndexFromAPI(client, codec, options...), invoker.WithCacheSize(flagCache))
 	if err != nil {
 		log.Error().Err(err).Msg("could not initialize invoker")
 		return failure
 	}
-	result, err := invoke.Script(flagHeight, script, args)
+	result, err := invoke.Script(ctx, flagHeight, script, args)
 	if err != nil {
 		log.Error().Err(err).Msg("could not invoke script")
 		return failure
@@ -119,7 +119,7 @@ func run() int {
 	// Check if index already exists.
 	read := index.NewReader(indexDB, storage)
 	_, err = read.First()
-	empty := errors.Is(err, badger.ErrKeyNotFound)
+	empty := errors.Is(err, dps.ErrNotFound)
 	if err != nil && !empty {
 		log.Error().Err(err).Msg("could not get first height from index reader")
 		return failure
@@ -129,6 +129,14 @@ func run() int {
 		return failure
 	}
 
+	// Make sure that an existing index uses the version of the index schema and
+	// codec supported by this release; new indexes get it at bootstrap.
+	err = index.CheckVersion(indexDB, storage)
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
 	// The chain is responsible for reading blockchain data from the protocol state.
 	disk := chain.FromDisk(protocolDB)
 
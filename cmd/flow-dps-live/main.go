@@ -17,8 +17,11 @@ package main
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -35,6 +38,9 @@ import (
 	"github.com/spf13/pflag"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	sdk "github.com/onflow/flow-go-sdk/crypto"
 	"github.com/onflow/flow-go/cmd/bootstrap/utils"
@@ -42,14 +48,18 @@ import (
 	unstaked "github.com/onflow/flow-go/follower"
 	"github.com/onflow/flow-go/model/bootstrap"
 
+	"github.com/optakt/flow-dps/api/auth"
 	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/api/rest"
 	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/engine"
 	"github.com/optakt/flow-dps/ledger/forest"
 	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/service/cloud"
+	"github.com/optakt/flow-dps/service/health"
 	"github.com/optakt/flow-dps/service/index"
 	"github.com/optakt/flow-dps/service/initializer"
+	"github.com/optakt/flow-dps/service/invoker"
 	"github.com/optakt/flow-dps/service/loader"
 	"github.com/optakt/flow-dps/service/mapper"
 	"github.com/optakt/flow-dps/service/metrics"
@@ -77,31 +87,47 @@ func run() int {
 		flagAddress    string
 		flagBootstrap  string
 		flagBucket     string
+		flagCache      uint64
 		flagCheckpoint string
 		flagData       string
 		flagIndex      string
 		flagLevel      string
 		flagMetrics    string
+		flagProofs     bool
+		flagREST       string
 		flagSkip       bool
 
 		flagFlushInterval time.Duration
+		flagHealthLag     uint64
+		flagRetention     uint64
 		flagSeedAddress   string
 		flagSeedKey       string
+		flagTLSCert       string
+		flagTLSKey        string
+		flagTLSClientCA   string
 	)
 
 	pflag.StringVarP(&flagAddress, "address", "a", "127.0.0.1:5005", "bind address for serving DPS API")
 	pflag.StringVarP(&flagBootstrap, "bootstrap", "b", "bootstrap", "path to directory with bootstrap information for spork")
 	pflag.StringVarP(&flagBucket, "bucket", "u", "", "Google Cloude Storage bucket with block data records")
+	pflag.Uint64VarP(&flagCache, "cache", "e", 1_000_000_000, "maximum cache size for register reads in bytes")
 	pflag.StringVarP(&flagCheckpoint, "checkpoint", "c", "", "path to root checkpoint file for execution state trie")
 	pflag.StringVarP(&flagData, "data", "d", "data", "path to database directory for protocol data")
 	pflag.StringVarP(&flagIndex, "index", "i", "index", "path to database directory for state index")
 	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
 	pflag.StringVarP(&flagMetrics, "metrics", "m", "", "address on which to expose metrics (no metrics are exposed when left empty)")
+	pflag.BoolVarP(&flagProofs, "proofs", "p", false, "serve register proofs, which restores the state trie from the index (not served when disabled)")
+	pflag.StringVarP(&flagREST, "rest", "r", "", "bind address for serving REST API (no REST API is served when left empty)")
 	pflag.BoolVarP(&flagSkip, "skip", "s", false, "skip indexing of execution state ledger registers")
 
 	pflag.DurationVar(&flagFlushInterval, "flush-interval", 1*time.Second, "interval for flushing badger transactions (0s for disabled)")
+	pflag.Uint64Var(&flagHealthLag, "health-lag", 100, "maximum number of finalized blocks missing from the index before reporting as not serving")
+	pflag.Uint64Var(&flagRetention, "retention", 0, "number of heights for which the full register history is kept (0 for unlimited)")
 	pflag.StringVar(&flagSeedAddress, "seed-address", "", "host address of seed node to follow consensus")
 	pflag.StringVar(&flagSeedKey, "seed-key", "", "hex-encoded public network key of seed node to follow consensus")
+	pflag.StringVar(&flagTLSCert, "tls-cert", "", "path to PEM-encoded TLS certificate (APIs are served in plaintext when left empty)")
+	pflag.StringVar(&flagTLSKey, "tls-key", "", "path to PEM-encoded TLS private key")
+	pflag.StringVar(&flagTLSClientCA, "tls-client-ca", "", "path to PEM-encoded CA certificates used to verify client certificates (client certificates are not requested when left empty)")
 
 	pflag.Parse()
 
@@ -156,16 +182,24 @@ func run() int {
 	storage := storage.New(codec)
 	read := index.NewReader(indexDB, storage)
 	first, err := read.First()
-	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+	if err != nil && !errors.Is(err, dps.ErrNotFound) {
 		log.Error().Err(err).Msg("could not get first height from index reader")
 		return failure
 	}
-	empty := errors.Is(err, badger.ErrKeyNotFound)
+	empty := errors.Is(err, dps.ErrNotFound)
 	if empty && flagCheckpoint == "" {
 		log.Error().Msg("index database is empty, please provide root checkpoint (-c, --checkpoint) to bootstrap")
 		return failure
 	}
 
+	// Make sure that an existing index uses the version of the index schema and
+	// codec supported by this release; new indexes get it at bootstrap.
+	err = index.CheckVersion(indexDB, storage)
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
 	// We initialize the writer with a flush interval, which will make sure that
 	// Badger transactions are committed to the database, even if they don't
 	// fill up fast enough. This avoids having latency between when we add data
@@ -175,6 +209,7 @@ func run() int {
 		indexDB,
 		storage,
 		index.WithFlushInterval(flagFlushInterval),
+		index.WithPruning(flagRetention, index.DefaultConfig.PruneInterval),
 	)
 	defer func() {
 		err := write.Close()
@@ -366,13 +401,43 @@ func run() int {
 		mapper.WithTransition(mapper.StatusForward, transitions.ForwardHeight),
 	)
 
+	// If a TLS key pair is given, the APIs are served over TLS, and the key
+	// pair is reloaded whenever its files are rotated on disk. If client CAs
+	// are given, clients are required to present a certificate issued by one
+	// of them.
+	var tlsConfig *tls.Config
+	if (flagTLSCert == "") != (flagTLSKey == "") {
+		log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Msg("TLS certificate and key need to be provided together")
+		return failure
+	}
+	if flagTLSClientCA != "" && flagTLSCert == "" {
+		log.Error().Str("client_ca", flagTLSClientCA).Msg("verifying client certificates requires a TLS certificate and key")
+		return failure
+	}
+	if flagTLSCert != "" {
+		pair, err := auth.LoadKeyPair(flagTLSCert, flagTLSKey)
+		if err != nil {
+			log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Err(err).Msg("could not load TLS key pair")
+			return failure
+		}
+		var clientCAs *x509.CertPool
+		if flagTLSClientCA != "" {
+			clientCAs, err = auth.LoadCertPool(flagTLSClientCA)
+			if err != nil {
+				log.Error().Str("client_ca", flagTLSClientCA).Err(err).Msg("could not load TLS client CAs")
+				return failure
+			}
+		}
+		tlsConfig = auth.ServerTLS(pair, clientCAs, tls.RequireAndVerifyClientCert)
+	}
+
 	// Next, we initialize the GRPC server that will serve the DPS API on top of
 	// the index database that is generated live by the mapper.
 	logOpts := []logging.Option{
 		logging.WithLevels(logging.DefaultServerCodeToLevel),
 	}
 	interceptor := grpczerolog.InterceptorLogger(log.With().Str("engine", "grpc_server").Logger())
-	gsvr := grpc.NewServer(
+	gsvrOpts := []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(
 			tags.UnaryServerInterceptor(),
 			logging.UnaryServerInterceptor(interceptor, logOpts...),
@@ -381,8 +446,48 @@ func run() int {
 			tags.StreamServerInterceptor(),
 			logging.StreamServerInterceptor(interceptor, logOpts...),
 		),
+	}
+	if tlsConfig != nil {
+		gsvrOpts = append(gsvrOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	gsvr := grpc.NewServer(gsvrOpts...)
+
+	// The invoker is shared between all requests, so that register reads for
+	// script execution and account retrieval use the same cache.
+	invoke, err := invoker.New(read, invoker.WithCacheSize(flagCache))
+	if err != nil {
+		log.Error().Err(err).Msg("could not initialize invoker")
+		return failure
+	}
+	var serverOpts []func(*api.Config)
+	if flagProofs {
+		serverOpts = append(serverOpts, api.WithProofs())
+	}
+	server := api.NewServer(read, codec, invoke, serverOpts...)
+
+	// The health server reports whether the index is keeping up with the
+	// consensus follower, so that load balancers can stop routing requests to
+	// replicas that have fallen behind. Until the first check, we report the
+	// services as not serving.
+	services := []string{"", api.API_ServiceDesc.ServiceName}
+	hsvr := grpchealth.NewServer()
+	for _, service := range services {
+		hsvr.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+	monitor := health.NewMonitor(log, hsvr, consensus, read,
+		health.WithMaxLag(flagHealthLag),
+		health.WithServices(services...),
 	)
-	server := api.NewServer(read, codec)
+
+	// The REST gateway uses the same server as backend, so that it serves the
+	// same data as the GRPC API. It also uses the same TLS configuration, so
+	// that it requires the same client certificates.
+	restEnabled := flagREST != ""
+	rsvr := &http.Server{
+		Addr:      flagREST,
+		Handler:   rest.NewGateway(server, codec),
+		TLSConfig: tlsConfig,
+	}
 
 	// This section launches the main executing components in their own
 	// goroutine, so they can run concurrently. Afterwards, we wait for an
@@ -401,6 +506,7 @@ func run() int {
 			"api",
 			func() error {
 				api.RegisterAPIServer(gsvr, server)
+				grpc_health_v1.RegisterHealthServer(gsvr, hsvr)
 
 				err := gsvr.Serve(listener)
 				if err == grpc.ErrServerStopped {
@@ -414,9 +520,53 @@ func run() int {
 				return nil
 			},
 			func() {
+				hsvr.Shutdown()
 				gsvr.GracefulStop()
 			},
 		).
+		Component(
+			"health",
+			func() error {
+				return monitor.Run()
+			},
+			func() {
+				monitor.Stop()
+			},
+		).
+		Component(
+			"rest",
+			func() error {
+				if !restEnabled {
+					return nil
+				}
+
+				var err error
+				if tlsConfig != nil {
+					err = rsvr.ListenAndServeTLS("", "")
+				} else {
+					err = rsvr.ListenAndServe()
+				}
+				if errors.Is(err, http.ErrServerClosed) {
+					log.Debug().Msg("rest server stopped")
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+
+				return nil
+			},
+			func() {
+				if !restEnabled {
+					return
+				}
+
+				err := rsvr.Shutdown(context.Background())
+				if err != nil {
+					log.Error().Err(err).Msg("could not stop rest server")
+				}
+			},
+		).
 		Component(
 			"follower",
 			func() error {
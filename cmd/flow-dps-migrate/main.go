@@ -0,0 +1,115 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/engine"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/migrator"
+	"github.com/optakt/flow-dps/service/storage"
+)
+
+const (
+	success = 0
+	failure = 1
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+
+	// Signal catching for clean shutdown.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	// Command line parameter initialization.
+	var (
+		flagBatch uint
+		flagIndex string
+		flagLevel string
+	)
+
+	pflag.UintVarP(&flagBatch, "batch", "b", migrator.DefaultConfig.BatchSize, "number of entries rewritten per transaction")
+	pflag.StringVarP(&flagIndex, "index", "i", "index", "path to database directory for state index")
+	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
+
+	pflag.Parse()
+
+	// Logger initialization.
+	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
+	log := zerolog.New(os.Stderr).With().Timestamp().Logger().Level(zerolog.DebugLevel)
+	level, err := zerolog.ParseLevel(flagLevel)
+	if err != nil {
+		log.Error().Str("level", flagLevel).Err(err).Msg("could not parse log level")
+		return failure
+	}
+	log = log.Level(level)
+
+	// Open the index database.
+	db, err := badger.Open(dps.DefaultOptions(flagIndex))
+	if err != nil {
+		log.Error().Str("index", flagIndex).Err(err).Msg("could not open index database")
+		return failure
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close index database")
+		}
+	}()
+
+	// Initialize the migrator and register the migration steps for all
+	// released versions of the index.
+	storage := storage.New(zbor.NewCodec())
+	migrate := migrator.New(log, db, storage,
+		migrator.WithBatchSize(flagBatch),
+	)
+	for _, step := range migrator.Steps() {
+		err = migrate.Register(step)
+		if err != nil {
+			log.Error().Err(err).Msg("could not register migration step")
+			return failure
+		}
+	}
+
+	err = engine.New(log, "Flow DPS Migrate", sig).
+		Component(
+			"migrator",
+			func() error {
+				return migrate.Run()
+			},
+			func() {
+				migrate.Stop()
+			},
+		).
+		Run()
+	if err != nil {
+		log.Error().Err(err).Msg("failed")
+		return failure
+	}
+
+	return success
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/engine"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/storage"
+)
+
+const (
+	success = 0
+	failure = 1
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+
+	// Signal catching for clean shutdown.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+
+	// Command line parameter initialization.
+	var (
+		flagIndex     string
+		flagLevel     string
+		flagRetention uint64
+	)
+
+	pflag.StringVarP(&flagIndex, "index", "i", "index", "path to database directory for state index")
+	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
+	pflag.Uint64VarP(&flagRetention, "retention", "r", 0, "number of heights for which the full register history is kept")
+
+	pflag.Parse()
+
+	// Logger initialization.
+	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
+	log := zerolog.New(os.Stderr).With().Timestamp().Logger().Level(zerolog.DebugLevel)
+	level, err := zerolog.ParseLevel(flagLevel)
+	if err != nil {
+		log.Error().Str("level", flagLevel).Err(err).Msg("could not parse log level")
+		return failure
+	}
+	log = log.Level(level)
+
+	if flagRetention == 0 {
+		log.Error().Msg("retention needs to be greater than zero")
+		return failure
+	}
+
+	// Open the index database.
+	db, err := badger.Open(dps.DefaultOptions(flagIndex))
+	if err != nil {
+		log.Error().Str("index", flagIndex).Err(err).Msg("could not open index database")
+		return failure
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close index database")
+		}
+	}()
+
+	storage := storage.New(zbor.NewCodec())
+	err = index.CheckVersion(db, storage)
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
+	// Determine the retention floor from the last indexed height. If the floor
+	// is not above the first indexed height, there is nothing to prune. We do
+	// not compare against the current retention floor, so that a run that was
+	// interrupted is completed when running again with the same retention.
+	read := index.NewReader(db, storage)
+	first, err := read.First()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get first height")
+		return failure
+	}
+	last, err := read.Last()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get last height")
+		return failure
+	}
+	if last <= first+flagRetention {
+		log.Info().Uint64("first", first).Uint64("last", last).Msg("no register history to prune")
+		return success
+	}
+	floor := last - flagRetention
+
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	err = engine.New(log, "Flow DPS Prune", sig).
+		Component(
+			"pruner",
+			func() error {
+				defer wg.Done()
+				log.Info().Uint64("floor", floor).Msg("pruning register history")
+				err := index.Prune(db, storage, floor, done)
+				if err != nil {
+					return err
+				}
+				log.Info().Uint64("floor", floor).Msg("register history pruned")
+				return nil
+			},
+			func() {
+				close(done)
+				wg.Wait()
+			},
+		).
+		Run()
+	if err != nil {
+		log.Error().Err(err).Msg("failed")
+		return failure
+	}
+
+	return success
+}
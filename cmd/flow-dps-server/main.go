@@ -15,6 +15,9 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net"
 	"net/http"
@@ -26,15 +29,21 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/pflag"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 
 	grpczerolog "github.com/grpc-ecosystem/go-grpc-middleware/providers/zerolog/v2"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/tags"
 
+	"github.com/optakt/flow-dps/api/auth"
 	api "github.com/optakt/flow-dps/api/dps"
+	"github.com/optakt/flow-dps/api/rest"
 	"github.com/optakt/flow-dps/codec/zbor"
 	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/invoker"
 	"github.com/optakt/flow-dps/service/storage"
 )
 
@@ -55,14 +64,35 @@ func run() int {
 
 	// Command line parameter initialization.
 	var (
-		flagAddress string
-		flagLevel   string
-		flagIndex   string
+		flagAddress   string
+		flagBandwidth uint64
+		flagCache     uint64
+		flagLevel     string
+		flagIndex     string
+		flagMaxPaths  uint
+		flagProofs    bool
+		flagRequests  float64
+		flagREST      string
+		flagTokens    string
+
+		flagTLSCert     string
+		flagTLSKey      string
+		flagTLSClientCA string
 	)
 
 	pflag.StringVarP(&flagAddress, "address", "a", "127.0.0.1:5005", "bind address for serving DPS API")
+	pflag.Uint64VarP(&flagCache, "cache", "e", 1_000_000_000, "maximum cache size for register reads in bytes")
 	pflag.StringVarP(&flagIndex, "index", "i", "index", "path to database directory for state index")
 	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
+	pflag.UintVar(&flagMaxPaths, "max-paths", 1000, "maximum number of registers per request for register values or proofs (unlimited when zero)")
+	pflag.BoolVarP(&flagProofs, "proofs", "p", false, "serve register proofs, which restores the state trie from the index (not served when disabled)")
+	pflag.StringVarP(&flagREST, "rest", "r", "", "bind address for serving REST API (no REST API is served when left empty)")
+	pflag.StringVar(&flagTokens, "auth-tokens", "", "path to file with static API tokens (no authentication is required when left empty)")
+	pflag.Float64Var(&flagRequests, "limit-requests", 0, "maximum number of requests per second per client (unlimited when zero)")
+	pflag.Uint64Var(&flagBandwidth, "limit-bandwidth", 0, "maximum number of response bytes per second per client (unlimited when zero)")
+	pflag.StringVar(&flagTLSCert, "tls-cert", "", "path to PEM-encoded TLS certificate (APIs are served in plaintext when left empty)")
+	pflag.StringVar(&flagTLSKey, "tls-key", "", "path to PEM-encoded TLS private key")
+	pflag.StringVar(&flagTLSClientCA, "tls-client-ca", "", "path to PEM-encoded CA certificates used to verify client certificates (client certificates are not requested when left empty)")
 
 	pflag.Parse()
 
@@ -88,22 +118,123 @@ func run() int {
 	codec := zbor.NewCodec()
 	storage := storage.New(codec)
 
+	// Make sure that the index uses the version of the index schema and codec
+	// supported by this release.
+	err = index.CheckVersion(db, storage)
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
+	// Authentication and rate limiting initialization.
+	var authOpts []func(*auth.Config)
+	if flagTokens != "" {
+		file, err := os.Open(flagTokens)
+		if err != nil {
+			log.Error().Str("tokens", flagTokens).Err(err).Msg("could not open tokens file")
+			return failure
+		}
+		tokens, err := auth.ReadTokens(file)
+		_ = file.Close()
+		if err != nil {
+			log.Error().Str("tokens", flagTokens).Err(err).Msg("could not read tokens file")
+			return failure
+		}
+		authOpts = append(authOpts, auth.WithTokens(tokens))
+	}
+	if flagRequests > 0 {
+		authOpts = append(authOpts, auth.WithRequestLimit(flagRequests, 0))
+	}
+	if flagBandwidth > 0 {
+		authOpts = append(authOpts, auth.WithBandwidthLimit(float64(flagBandwidth), 0))
+	}
+
+	// TLS initialization, which reloads the key pair whenever the files are
+	// rotated on disk. When client certificates are verified, they are also
+	// used to identify clients. Clients that authenticate with a token may
+	// still connect without a certificate.
+	var tlsConfig *tls.Config
+	if (flagTLSCert == "") != (flagTLSKey == "") {
+		log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Msg("TLS certificate and key need to be provided together")
+		return failure
+	}
+	if flagTLSClientCA != "" && flagTLSCert == "" {
+		log.Error().Str("client_ca", flagTLSClientCA).Msg("verifying client certificates requires a TLS certificate and key")
+		return failure
+	}
+	if flagTLSCert != "" {
+		pair, err := auth.LoadKeyPair(flagTLSCert, flagTLSKey)
+		if err != nil {
+			log.Error().Str("cert", flagTLSCert).Str("key", flagTLSKey).Err(err).Msg("could not load TLS key pair")
+			return failure
+		}
+		var clientCAs *x509.CertPool
+		clientAuth := tls.RequireAndVerifyClientCert
+		if flagTLSClientCA != "" {
+			clientCAs, err = auth.LoadCertPool(flagTLSClientCA)
+			if err != nil {
+				log.Error().Str("client_ca", flagTLSClientCA).Err(err).Msg("could not load TLS client CAs")
+				return failure
+			}
+			authOpts = append(authOpts, auth.WithCertificates())
+		}
+		if flagTokens != "" {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+		tlsConfig = auth.ServerTLS(pair, clientCAs, clientAuth)
+	}
+	authOpts = append(authOpts, auth.WithPublicServices(grpc_health_v1.Health_ServiceDesc.ServiceName))
+	authenticator := auth.NewInterceptor(authOpts...)
+
 	// GRPC API initialization.
 	opts := []logging.Option{
 		logging.WithLevels(logging.DefaultServerCodeToLevel),
 	}
-	gsvr := grpc.NewServer(
+	gsvrOpts := []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(
 			tags.UnaryServerInterceptor(),
 			logging.UnaryServerInterceptor(grpczerolog.InterceptorLogger(log), opts...),
+			authenticator.Unary(),
 		),
 		grpc.ChainStreamInterceptor(
 			tags.StreamServerInterceptor(),
 			logging.StreamServerInterceptor(grpczerolog.InterceptorLogger(log), opts...),
+			authenticator.Stream(),
 		),
-	)
+	}
+	if tlsConfig != nil {
+		gsvrOpts = append(gsvrOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	gsvr := grpc.NewServer(gsvrOpts...)
+
+	// The index of the server is static, so the server is healthy for as long
+	// as it runs.
+	hsvr := health.NewServer()
+	hsvr.SetServingStatus(api.API_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
 	index := index.NewReader(db, storage)
-	server := api.NewServer(index, codec)
+
+	// The invoker is shared between all requests, so that register reads for
+	// script execution and account retrieval use the same cache.
+	invoke, err := invoker.New(index, invoker.WithCacheSize(flagCache))
+	if err != nil {
+		log.Error().Err(err).Msg("could not initialize invoker")
+		return failure
+	}
+	serverOpts := []func(*api.Config){
+		api.WithMaxPaths(flagMaxPaths),
+	}
+	if flagProofs {
+		serverOpts = append(serverOpts, api.WithProofs())
+	}
+	server := api.NewServer(index, codec, invoke, serverOpts...)
+
+	// REST gateway initialization, which uses the same server as backend and
+	// the same authentication and rate limits as the GRPC API.
+	rsvr := &http.Server{
+		Addr:      flagREST,
+		Handler:   authenticator.Handler(rest.NewGateway(server, codec)),
+		TLSConfig: tlsConfig,
+	}
 
 	// This section launches the main executing components in their own
 	// goroutine, so they can run concurrently. Afterwards, we wait for an
@@ -118,6 +249,7 @@ func run() int {
 	go func() {
 		log.Info().Msg("Flow DPS Server starting")
 		api.RegisterAPIServer(gsvr, server)
+		grpc_health_v1.RegisterHealthServer(gsvr, hsvr)
 		err = gsvr.Serve(listener)
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Warn().Err(err).Msg("Flow DPS Server failed")
@@ -127,6 +259,22 @@ func run() int {
 		}
 		log.Info().Msg("Flow DPS Server stopped")
 	}()
+	if flagREST != "" {
+		go func() {
+			log.Info().Str("address", flagREST).Msg("Flow DPS REST gateway starting")
+			var err error
+			if tlsConfig != nil {
+				err = rsvr.ListenAndServeTLS("", "")
+			} else {
+				err = rsvr.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Warn().Err(err).Msg("Flow DPS REST gateway failed")
+				return
+			}
+			log.Info().Msg("Flow DPS REST gateway stopped")
+		}()
+	}
 
 	select {
 	case <-sig:
@@ -143,6 +291,13 @@ func run() int {
 		os.Exit(1)
 	}()
 
+	if flagREST != "" {
+		err = rsvr.Shutdown(context.Background())
+		if err != nil {
+			log.Error().Err(err).Msg("could not stop REST gateway")
+		}
+	}
+	hsvr.Shutdown()
 	gsvr.GracefulStop()
 
 	return success
@@ -0,0 +1,155 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/loader"
+	"github.com/optakt/flow-dps/service/mapper"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-dps/service/verifier"
+)
+
+const (
+	success = 0
+	failure = 1
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+
+	// Command line parameter initialization.
+	var (
+		flagHeights []uint
+		flagIndex   string
+		flagLevel   string
+		flagSkip    bool
+	)
+
+	pflag.UintSliceVar(&flagHeights, "heights", nil, "heights at which to verify the state commitment (last height when left empty)")
+	pflag.StringVarP(&flagIndex, "index", "i", "index", "path to database directory for state index")
+	pflag.StringVarP(&flagLevel, "level", "l", "info", "log output level")
+	pflag.BoolVarP(&flagSkip, "skip", "s", false, "skip verification of state commitments for indexes without ledger registers")
+
+	pflag.Parse()
+
+	// Logger initialization.
+	zerolog.TimestampFunc = func() time.Time { return time.Now().UTC() }
+	log := zerolog.New(os.Stderr).With().Timestamp().Logger().Level(zerolog.DebugLevel)
+	level, err := zerolog.ParseLevel(flagLevel)
+	if err != nil {
+		log.Error().Str("level", flagLevel).Err(err).Msg("could not parse log level")
+		return failure
+	}
+	log = log.Level(level)
+
+	// Open the index database.
+	db, err := badger.Open(dps.DefaultOptions(flagIndex))
+	if err != nil {
+		log.Error().Str("index", flagIndex).Err(err).Msg("could not open index database")
+		return failure
+	}
+	defer func() {
+		err := db.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("could not close index database")
+		}
+	}()
+
+	storage := storage.New(zbor.NewCodec())
+	err = index.CheckVersion(db, storage)
+	if err != nil {
+		log.Error().Err(err).Msg("could not check index version")
+		return failure
+	}
+
+	// Initialize the verifier, which restores the execution state trie from
+	// the index while ignoring all register updates above the verified height.
+	read := index.NewReader(db, storage)
+	load := func(height uint64) mapper.Loader {
+		return loader.FromIndex(log, storage, db, loader.WithExclude(loader.ExcludeAbove(height)))
+	}
+	verify := verifier.New(log, read, load)
+
+	first, err := read.First()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get first height")
+		return failure
+	}
+	last, err := read.Last()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get last height")
+		return failure
+	}
+
+	// The execution state can only be restored at heights for which the
+	// register history was not pruned.
+	floor, err := read.Floor()
+	if err != nil {
+		log.Error().Err(err).Msg("could not get floor height")
+		return failure
+	}
+
+	heights := make([]uint64, 0, len(flagHeights))
+	for _, height := range flagHeights {
+		heights = append(heights, uint64(height))
+	}
+	if len(heights) == 0 {
+		heights = append(heights, last)
+	}
+	for _, height := range heights {
+		if height < floor || height > last {
+			log.Error().Uint64("height", height).Uint64("floor", floor).Uint64("last", last).Msg("height outside of indexed range")
+			return failure
+		}
+	}
+
+	// First, we check that all of the indexed data is present for each height.
+	log.Info().Uint64("first", first).Uint64("last", last).Msg("verifying index completeness")
+	err = verify.Completeness(first, last)
+	if err != nil {
+		log.Error().Err(err).Msg("could not verify index completeness")
+		return failure
+	}
+
+	// Then, we check that the execution state restored from the index matches
+	// the indexed state commitment at each of the selected heights.
+	if !flagSkip {
+		for _, height := range heights {
+			log.Info().Uint64("height", height).Msg("verifying state commitment")
+			err = verify.Commit(height)
+			if err != nil {
+				log.Error().Uint64("height", height).Err(err).Msg("could not verify state commitment")
+				return failure
+			}
+		}
+	}
+
+	log.Info().Msg("index verified")
+
+	return success
+}
@@ -83,8 +83,9 @@ func run() int {
 	defer db.Close()
 
 	// Check if the database is empty.
-	index := index.NewReader(db, storage.New(zbor.NewCodec()))
-	_, err = index.First()
+	lib := storage.New(zbor.NewCodec())
+	read := index.NewReader(db, lib)
+	_, err = read.First()
 	if err == nil {
 		log.Error().Msg("database directory already contains index database")
 		return failure
@@ -131,6 +132,13 @@ func run() int {
 		return failure
 	}
 
+	// Snapshots can be restored regardless of their version, but they might
+	// need to be migrated before they can be used.
+	err = index.CheckVersion(db, lib)
+	if err != nil {
+		log.Warn().Err(err).Msg("restored index version is not supported by this release")
+	}
+
 	log.Info().Msg("snapshot restoration complete")
 
 	return success
@@ -0,0 +1,174 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package trie
+
+import (
+	"runtime"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	flowproof "github.com/onflow/flow-go/ledger/common/proof"
+)
+
+// Prove generates a batch proof for the given paths. Paths that are present in
+// the trie get an inclusion proof, which includes their payload, while paths
+// that are missing get a non-inclusion proof. The proofs use the Flow proof
+// format and should be checked with `Verify`.
+func (t *Trie) Prove(paths []ledger.Path) *ledger.TrieBatchProof {
+
+	sema := semaphore.NewWeighted(int64(2*runtime.GOMAXPROCS(0) + 1))
+
+	batch := ledger.NewTrieBatchProof()
+	for _, path := range paths {
+		proof := t.prove(sema, path)
+		batch.AppendProof(proof)
+	}
+
+	return batch
+}
+
+func (t *Trie) prove(sema *semaphore.Weighted, path ledger.Path) *ledger.TrieProof {
+
+	// By default, a proof is a non-inclusion proof with an empty payload, which
+	// proves that the path ends in an empty sub-trie.
+	proof := ledger.NewTrieProof()
+	proof.Path = path
+
+	current := t.root
+	depth := 0
+	for {
+		switch node := current.(type) {
+
+		// If we hit a `nil` node, the trie is empty, and the default
+		// non-inclusion proof is all we need.
+		case nil:
+			return proof
+
+		// If we hit a leaf directly, we are at the bottom of the trie.
+		case *Leaf:
+			include(proof, node)
+			return proof
+
+		// If we hit a branch, the sibling on the side we are not following is
+		// always a non-default node, so we add its hash to the proof.
+		case *Branch:
+
+			// A zero bit goes left, a one bit goes right.
+			sibling := node.right
+			current = node.left
+			if bitutils.Bit(path[:], depth) == 1 {
+				sibling = node.left
+				current = node.right
+			}
+
+			interim := sibling.Hash(sema, maxDepth-depth-1)
+			proof.Interims = append(proof.Interims, interim)
+			bitutils.SetBit(proof.Flags, depth)
+			proof.Steps++
+			depth++
+
+		// If we hit an extension, the siblings along all of its bits are empty
+		// sub-tries with default hashes, unless our path diverges from it.
+		case *Extension:
+
+			// An extension with a leaf as child is the equivalent of a Flow
+			// compact leaf, so we are done.
+			leaf, ok := node.child.(*Leaf)
+			if ok {
+				include(proof, leaf)
+				return proof
+			}
+
+			// If our path diverges from the extension at some bit, we end up in
+			// an empty sub-trie, and the sibling at that bit is the remaining
+			// part of the extension, which we need to hash.
+			for i := 0; i <= int(node.count); i++ {
+				if bitutils.Bit(path[:], depth+i) == bitutils.Bit(node.path[:], depth+i) {
+					continue
+				}
+				interim := remainder(sema, node, depth, depth+i)
+				proof.Interims = append(proof.Interims, interim)
+				bitutils.SetBit(proof.Flags, depth+i)
+				proof.Steps += uint8(i + 1)
+				return proof
+			}
+
+			// Otherwise, we follow the extension all the way to its child.
+			proof.Steps += node.count + 1
+			depth += int(node.count) + 1
+			current = node.child
+		}
+	}
+}
+
+// include adds the given leaf to the proof. If the leaf is on the proof's path,
+// the proof becomes an inclusion proof; otherwise, the leaf is a different
+// register that shares the same path prefix, and it proves the non-inclusion
+// of the requested path.
+func include(proof *ledger.TrieProof, leaf *Leaf) {
+	proof.Inclusion = *leaf.path == proof.Path
+	proof.Path = *leaf.path
+	proof.Payload = leaf.payload
+}
+
+// Verify checks the given proof for the given path against the given root hash.
+// Contrary to the Flow proof verification, it also accepts non-inclusion proofs
+// only if they are backed by the trie: the hashes of the proof always have to
+// add up to the root hash. A non-inclusion proof then either ends in an empty
+// sub-trie, or in the leaf of a different path with the same prefix.
+func Verify(path ledger.Path, proof *ledger.TrieProof, root ledger.RootHash) bool {
+
+	// First, make sure that the proof follows the requested path for all of
+	// its steps.
+	for i := 0; i < int(proof.Steps); i++ {
+		if bitutils.Bit(path[:], i) != bitutils.Bit(proof.Path[:], i) {
+			return false
+		}
+	}
+
+	// Then, we make sure that the proof is consistent with its inclusion flag.
+	switch {
+	case proof.Inclusion && proof.Path != path:
+		return false
+	case !proof.Inclusion && proof.Path == path && len(proof.Payload.Value) != 0:
+		return false
+	}
+
+	// Finally, the hashes along the path have to add up to the root hash, for
+	// both inclusion and non-inclusion proofs.
+	check := *proof
+	check.Inclusion = true
+
+	return flowproof.VerifyTrieProof(&check, ledger.State(root))
+}
+
+// remainder computes the hash of the part of the given extension that starts
+// below the given bit, for an extension that starts at the given depth.
+func remainder(sema *semaphore.Weighted, ext *Extension, depth int, bit int) hash.Hash {
+	height := maxDepth - depth
+	computed := ext.child.Hash(sema, height-int(ext.count)-1)
+	for i := height - int(ext.count); i < maxDepth-bit; i++ {
+		empty := ledger.GetDefaultHashForHeight(i)
+		if bitutils.Bit(ext.path[:], maxDepth-i) == 0 {
+			computed = hash.HashInterNode(computed, empty)
+		} else {
+			computed = hash.HashInterNode(empty, computed)
+		}
+	}
+	return computed
+}
@@ -497,3 +497,103 @@ func BenchmarkTrie_Read(b *testing.B) {
 		}
 	})
 }
+
+func TestTrie_Prove(t *testing.T) {
+
+	const totalValues = 5000
+	const totalMissing = 100
+
+	generator := helpers.NewGenerator()
+	paths, payloads := helpers.SampleRandomRegisterWrites(generator, totalValues)
+
+	present := make(map[ledger.Path]struct{}, totalValues)
+	for _, path := range paths {
+		present[path] = struct{}{}
+	}
+	missing := make([]ledger.Path, 0, totalMissing)
+	for len(missing) < totalMissing {
+		path := utils.PathByUint16LeftPadded(generator.Next())
+		_, ok := present[path]
+		if ok {
+			continue
+		}
+		missing = append(missing, path)
+	}
+
+	tr := trie.NewEmptyTrie()
+	ref := reference.NewEmptyMTrie()
+
+	var err error
+	tr, err = tr.Mutate(paths, payloads)
+	require.NoError(t, err)
+
+	// The reference trie sorts paths and payloads in place, while our trie keeps
+	// pointers to the given paths, so we give the reference its own copies.
+	refPaths := make([]ledger.Path, len(paths))
+	copy(refPaths, paths)
+	refPayloads := make([]ledger.Payload, len(payloads))
+	copy(refPayloads, payloads)
+	ref, err = reference.NewTrieWithUpdatedRegisters(ref, refPaths, refPayloads)
+	require.NoError(t, err)
+
+	root := tr.RootHash()
+
+	t.Run("inclusion proofs match reference", func(t *testing.T) {
+		got := tr.Prove(paths)
+
+		// The reference also permutes the paths in place when proving.
+		copy(refPaths, paths)
+		want := make(map[ledger.Path]*ledger.TrieProof, totalValues)
+		for _, p := range ref.UnsafeProofs(refPaths).Proofs {
+			want[p.Path] = p
+		}
+
+		require.Len(t, got.Proofs, totalValues)
+		for i, p := range got.Proofs {
+			assert.True(t, p.Inclusion)
+			assert.True(t, p.Equals(want[paths[i]]), "mismatch at index %d", i)
+			assert.True(t, trie.Verify(paths[i], p, root), "invalid proof at index %d", i)
+		}
+	})
+
+	t.Run("non-inclusion proofs for missing paths", func(t *testing.T) {
+		got := tr.Prove(missing)
+
+		require.Len(t, got.Proofs, totalMissing)
+		for i, p := range got.Proofs {
+			assert.False(t, p.Inclusion)
+			assert.True(t, trie.Verify(missing[i], p, root), "invalid proof at index %d", i)
+		}
+	})
+
+	t.Run("tampered payload fails verification", func(t *testing.T) {
+		got := tr.Prove(paths[:1])
+
+		got.Proofs[0].Payload = ledger.NewPayload(payloads[0].Key, ledger.Value(`tampered`))
+		assert.False(t, trie.Verify(paths[0], got.Proofs[0], root))
+	})
+
+	t.Run("non-inclusion proof for present path fails verification", func(t *testing.T) {
+		got := tr.Prove(paths[:1])
+
+		got.Proofs[0].Inclusion = false
+		got.Proofs[0].Payload = ledger.EmptyPayload()
+		assert.False(t, trie.Verify(paths[0], got.Proofs[0], root))
+	})
+
+	t.Run("proof for different path fails verification", func(t *testing.T) {
+		got := tr.Prove(paths[:1])
+
+		assert.False(t, trie.Verify(missing[0], got.Proofs[0], root))
+	})
+
+	t.Run("empty trie", func(t *testing.T) {
+		empty := trie.NewEmptyTrie()
+		got := empty.Prove(paths[:1])
+
+		require.Len(t, got.Proofs, 1)
+		assert.False(t, got.Proofs[0].Inclusion)
+		assert.Zero(t, got.Proofs[0].Steps)
+		assert.True(t, trie.Verify(paths[0], got.Proofs[0], empty.RootHash()))
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// EventPayload decodes the Cadence payload of the given event and returns it
+// encoded as JSON-Cadence.
+func EventPayload(event flow.Event) ([]byte, error) {
+
+	value, err := json.Decode(event.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode event payload: %w", err)
+	}
+
+	payload, err := json.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode event payload: %w", err)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/encoding/json"
+
+	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestEventPayload(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		event := mocks.GenericEvent(0)
+
+		got, err := convert.EventPayload(event)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, string(json.MustEncode(mocks.GenericCadenceEvent(0))), string(got))
+	})
+
+	t.Run("handles invalid payload", func(t *testing.T) {
+		t.Parallel()
+
+		event := mocks.GenericEvent(0)
+		event.Payload = mocks.GenericBytes
+
+		_, err := convert.EventPayload(event)
+
+		assert.Error(t, err)
+	})
+}
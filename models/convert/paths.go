@@ -17,7 +17,11 @@ package convert
 import (
 	"fmt"
 
+	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 // PathsToBytes converts a slice of ledger paths into a slice of byte slices.
@@ -43,3 +47,27 @@ func BytesToPaths(bb [][]byte) ([]ledger.Path, error) {
 	}
 	return paths, nil
 }
+
+// RegisterIDToPath converts a flow register ID into the ledger path at which
+// the execution state stores the register.
+func RegisterIDToPath(regID flow.RegisterID) (ledger.Path, error) {
+	path, err := pathfinder.KeyToPath(state.RegisterIDToKey(regID), complete.DefaultPathFinderVersion)
+	if err != nil {
+		return ledger.DummyPath, fmt.Errorf("could not convert key to path: %w", err)
+	}
+	return path, nil
+}
+
+// RegisterIDsToPaths converts a slice of flow register IDs into a slice of the
+// ledger paths at which the execution state stores the registers.
+func RegisterIDsToPaths(regIDs []flow.RegisterID) ([]ledger.Path, error) {
+	paths := make([]ledger.Path, 0, len(regIDs))
+	for _, regID := range regIDs {
+		path, err := RegisterIDToPath(regID)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert register ID (%s): %w", regID.String(), err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
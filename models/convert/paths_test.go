@@ -18,6 +18,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/models/convert"
 	"github.com/optakt/flow-dps/testing/mocks"
@@ -73,3 +79,41 @@ func TestBytesToPaths(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestRegisterIDToPath(t *testing.T) {
+	regID := flow.NewRegisterID(
+		string(mocks.GenericAddress(0).Bytes()),
+		"",
+		"public_key_0",
+	)
+
+	got, err := convert.RegisterIDToPath(regID)
+
+	require.NoError(t, err)
+	key := ledger.NewKey([]ledger.KeyPart{
+		ledger.NewKeyPart(0, mocks.GenericAddress(0).Bytes()),
+		ledger.NewKeyPart(1, nil),
+		ledger.NewKeyPart(2, []byte("public_key_0")),
+	})
+	want, err := pathfinder.KeyToPath(key, complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestRegisterIDsToPaths(t *testing.T) {
+	regIDs := []flow.RegisterID{
+		flow.NewRegisterID(string(mocks.GenericAddress(0).Bytes()), "", "public_key_0"),
+		flow.NewRegisterID(string(mocks.GenericAddress(1).Bytes()), string(mocks.GenericAddress(1).Bytes()), "contract_names"),
+	}
+
+	got, err := convert.RegisterIDsToPaths(regIDs)
+
+	require.NoError(t, err)
+	require.Len(t, got, len(regIDs))
+	for i, regID := range regIDs {
+		want, err := convert.RegisterIDToPath(regID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got[i])
+	}
+	assert.NotEqual(t, got[0], got[1])
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/encoding"
+)
+
+// ProofsToBytes converts a batch proof into a slice of byte slices, with one
+// encoded trie proof per element.
+func ProofsToBytes(proofs *ledger.TrieBatchProof) [][]byte {
+	bb := make([][]byte, 0, proofs.Size())
+	for _, proof := range proofs.Proofs {
+		b := encoding.EncodeTrieProof(proof)
+		bb = append(bb, b)
+	}
+	return bb
+}
+
+// BytesToProofs converts a slice of byte slices, each holding an encoded trie
+// proof, into a batch proof.
+func BytesToProofs(bb [][]byte) (*ledger.TrieBatchProof, error) {
+	proofs := ledger.NewTrieBatchProof()
+	for _, b := range bb {
+		proof, err := encoding.DecodeTrieProof(b)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode proof: %w", err)
+		}
+		proofs.AppendProof(proof)
+	}
+	return proofs, nil
+}
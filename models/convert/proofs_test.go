@@ -0,0 +1,66 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger/common/encoding"
+
+	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestProofsToBytes(t *testing.T) {
+	proofs := mocks.GenericTrieBatchProof(4)
+
+	var bb [][]byte
+	for _, proof := range proofs.Proofs {
+		bb = append(bb, encoding.EncodeTrieProof(proof))
+	}
+
+	got := convert.ProofsToBytes(proofs)
+
+	assert.Equal(t, bb, got)
+}
+
+func TestBytesToProofs(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		proofs := mocks.GenericTrieBatchProof(4)
+
+		var bb [][]byte
+		for _, proof := range proofs.Proofs {
+			bb = append(bb, encoding.EncodeTrieProof(proof))
+		}
+
+		got, err := convert.BytesToProofs(bb)
+
+		require.NoError(t, err)
+		assert.True(t, proofs.Equals(got))
+	})
+
+	t.Run("invalid proof should fail", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := convert.BytesToProofs([][]byte{mocks.GenericBytes})
+
+		assert.Error(t, err)
+	})
+}
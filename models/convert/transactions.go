@@ -0,0 +1,55 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// TransactionArguments decodes the Cadence arguments of the given transaction
+// and returns each of them encoded as JSON-Cadence.
+func TransactionArguments(tx *flow.TransactionBody) ([][]byte, error) {
+
+	arguments := make([][]byte, 0, len(tx.Arguments))
+	for index, argument := range tx.Arguments {
+
+		value, err := json.Decode(argument)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode transaction argument (index: %d): %w", index, err)
+		}
+
+		encoded, err := json.Encode(value)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode transaction argument (index: %d): %w", index, err)
+		}
+
+		arguments = append(arguments, encoded)
+	}
+
+	return arguments, nil
+}
+
+// AddressesToBytes converts a slice of flow addresses into a slice of byte
+// slices.
+func AddressesToBytes(addresses []flow.Address) [][]byte {
+	bb := make([][]byte, 0, len(addresses))
+	for _, address := range addresses {
+		bb = append(bb, address.Bytes())
+	}
+	return bb
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package convert_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/encoding/json"
+
+	"github.com/optakt/flow-dps/models/convert"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestTransactionArguments(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		tx := mocks.GenericTransaction(0)
+		tx.Arguments = [][]byte{
+			json.MustEncode(mocks.GenericAmount(0)),
+			json.MustEncode(mocks.GenericAmount(1)),
+		}
+
+		got, err := convert.TransactionArguments(tx)
+
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.JSONEq(t, string(tx.Arguments[0]), string(got[0]))
+		assert.JSONEq(t, string(tx.Arguments[1]), string(got[1]))
+	})
+
+	t.Run("handles invalid argument", func(t *testing.T) {
+		t.Parallel()
+
+		tx := mocks.GenericTransaction(0)
+		tx.Arguments = [][]byte{mocks.GenericBytes}
+
+		_, err := convert.TransactionArguments(tx)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestAddressesToBytes(t *testing.T) {
+	addresses := mocks.GenericAddresses(4)
+
+	got := convert.AddressesToBytes(addresses)
+
+	require.Len(t, got, len(addresses))
+	for i, address := range addresses {
+		assert.Equal(t, address[:], got[i])
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Role is a set of roles that an account can have in a transaction.
+type Role uint8
+
+// The roles an account can have in a transaction. An account can have several
+// roles in the same transaction, in which case they are combined.
+const (
+	RoleProposer Role = 1 << iota
+	RolePayer
+	RoleAuthorizer
+)
+
+// Has returns whether the set of roles includes the given role.
+func (r Role) Has(role Role) bool {
+	return r&role == role
+}
+
+// Roles returns the roles of each account that takes part in the given
+// transaction, by address.
+func Roles(tx *flow.TransactionBody) map[flow.Address]Role {
+
+	roles := make(map[flow.Address]Role)
+	roles[tx.ProposalKey.Address] |= RoleProposer
+	roles[tx.Payer] |= RolePayer
+	for _, authorizer := range tx.Authorizers {
+		roles[authorizer] |= RoleAuthorizer
+	}
+
+	return roles
+}
+
+// AccountTransaction is a transaction that an account took part in, with the
+// height of the block that included it and the roles of the account in it.
+type AccountTransaction struct {
+	Height        uint64
+	TransactionID flow.Identifier
+	Roles         Role
+}
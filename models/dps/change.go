@@ -0,0 +1,27 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"github.com/onflow/flow-go/ledger"
+)
+
+// Change represents the difference of a single ledger register between two
+// heights. The payload before is nil if the register did not exist yet.
+type Change struct {
+	Path   ledger.Path
+	Before *ledger.Payload
+	After  *ledger.Payload
+}
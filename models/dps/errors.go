@@ -20,6 +20,11 @@ import (
 
 // Sentinel errors.
 var (
-	ErrFinished    = errors.New("finished")
-	ErrUnavailable = errors.New("unavailable")
+	ErrFinished        = errors.New("finished")
+	ErrUnavailable     = errors.New("unavailable")
+	ErrInvalidProof    = errors.New("invalid proof")
+	ErrUntrusted       = errors.New("untrusted")
+	ErrNotFound        = errors.New("not found")
+	ErrOutOfRange      = errors.New("out of range")
+	ErrInvalidArgument = errors.New("invalid argument")
 )
@@ -0,0 +1,47 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"context"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Invoker represents something that can retrieve accounts and execute Cadence
+// scripts and transactions against the execution state at a given height. The
+// given context is passed on to the index reads needed for execution.
+type Invoker interface {
+	Key(ctx context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
+	Account(ctx context.Context, height uint64, address flow.Address) (*flow.Account, error)
+	Script(ctx context.Context, height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	Transaction(ctx context.Context, height uint64, tx *flow.TransactionBody) (*DryRun, error)
+}
+
+// DryRun is the outcome of executing a transaction against the execution state
+// at a given height without persisting any of its changes. A failed transaction
+// is not an error; it is reported by the failed flag and the error message,
+// just like it would be in a transaction result. The fees are the transaction
+// fees charged to the payer, in the smallest unit of FLOW, and the events and
+// writes of their deduction are part of the outcome.
+type DryRun struct {
+	Failed          bool
+	ErrorMessage    string
+	Events          []flow.Event
+	ComputationUsed uint64
+	Fees            uint64
+	Writes          []flow.RegisterEntry
+}
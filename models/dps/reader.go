@@ -15,6 +15,9 @@
 package dps
 
 import (
+	"context"
+	"time"
+
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -23,14 +26,27 @@ import (
 type Reader interface {
 	First() (uint64, error)
 	Last() (uint64, error)
+	Floor() (uint64, error)
 
 	HeightForBlock(blockID flow.Identifier) (uint64, error)
 	HeightForTransaction(txID flow.Identifier) (uint64, error)
+	HeightForTime(timestamp time.Time) (uint64, error)
+	HeightForCollection(collID flow.Identifier) (uint64, error)
+	HeightForSeal(sealID flow.Identifier) (uint64, error)
 
 	Commit(height uint64) (flow.StateCommitment, error)
 	Header(height uint64) (*flow.Header, error)
+	HeaderByBlockID(blockID flow.Identifier) (*flow.Header, error)
 	Events(height uint64, types ...flow.EventType) ([]flow.Event, error)
 	Values(height uint64, paths []ledger.Path) ([]ledger.Value, error)
+	Proofs(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error)
+
+	Headers(start uint64, end uint64) ([]*flow.Header, error)
+	Commits(start uint64, end uint64) (map[uint64]flow.StateCommitment, error)
+	EventsRange(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error)
+	EventHeights(typ flow.EventType, start uint64, end uint64) ([]uint64, error)
+	RegisterHistory(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error)
+	StateDiff(start uint64, end uint64, process func(change Change) error) error
 
 	Collection(collID flow.Identifier) (*flow.LightCollection, error)
 	Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error)
@@ -40,5 +56,14 @@ type Reader interface {
 
 	CollectionsByHeight(height uint64) ([]flow.Identifier, error)
 	TransactionsByHeight(height uint64) ([]flow.Identifier, error)
+	TransactionsByCollection(collID flow.Identifier) ([]flow.Identifier, error)
+	TransactionsByAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]AccountTransaction, error)
 	SealsByHeight(height uint64) ([]flow.Identifier, error)
 }
+
+// ContextReader represents a reader that can be bound to a context, so that
+// its reads are aborted when the context is canceled or its deadline expires.
+type ContextReader interface {
+	Reader
+	WithContext(ctx context.Context) Reader
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/convert"
+)
+
+// RegistersByKey reads the values of the registers with the given register IDs
+// from the given index, as they were after the execution of the finalized block
+// at the given height. The register IDs are converted to ledger paths in the
+// same way as the execution state does, and registers that were never written
+// have an empty value.
+func RegistersByKey(index Reader, height uint64, regIDs []flow.RegisterID) (map[flow.RegisterID]flow.RegisterValue, error) {
+
+	paths, err := convert.RegisterIDsToPaths(regIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert register IDs: %w", err)
+	}
+
+	values, err := index.Values(height, paths)
+	if err != nil {
+		return nil, fmt.Errorf("could not read registers: %w", err)
+	}
+	if len(values) != len(regIDs) {
+		return nil, fmt.Errorf("mismatch between register IDs and values (register IDs: %d, values: %d)", len(regIDs), len(values))
+	}
+
+	registers := make(map[flow.RegisterID]flow.RegisterValue, len(regIDs))
+	for index, regID := range regIDs {
+		registers[regID] = flow.RegisterValue(values[index])
+	}
+
+	return registers, nil
+}
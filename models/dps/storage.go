@@ -15,6 +15,8 @@
 package dps
 
 import (
+	"time"
+
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/ledger"
@@ -33,17 +35,30 @@ type Library interface {
 type ReadLibrary interface {
 	RetrieveFirst(height *uint64) func(*badger.Txn) error
 	RetrieveLast(height *uint64) func(*badger.Txn) error
+	RetrieveVersion(version *uint64) func(*badger.Txn) error
+	RetrieveFloor(height *uint64) func(*badger.Txn) error
+	RetrieveMigration(version uint64, cursor *[]byte) func(*badger.Txn) error
 
 	LookupHeightForBlock(blockID flow.Identifier, height *uint64) func(*badger.Txn) error
 	LookupHeightForTransaction(txID flow.Identifier, height *uint64) func(*badger.Txn) error
+	LookupHeightForCollection(collID flow.Identifier, height *uint64) func(*badger.Txn) error
+	LookupHeightForSeal(sealID flow.Identifier, height *uint64) func(*badger.Txn) error
+	LookupHeightForTime(timestamp time.Time, height *uint64) func(*badger.Txn) error
+	LookupHeightsForEvent(typ flow.EventType, start uint64, end uint64, heights *[]uint64) func(*badger.Txn) error
 
 	RetrieveCommit(height uint64, commit *flow.StateCommitment) func(*badger.Txn) error
 	RetrieveHeader(height uint64, header *flow.Header) func(*badger.Txn) error
 	RetrieveEvents(height uint64, types []flow.EventType, events *[]flow.Event) func(*badger.Txn) error
 	RetrievePayload(height uint64, path ledger.Path, payload *ledger.Payload) func(*badger.Txn) error
 
+	RetrieveHeaders(start uint64, end uint64, headers *[]*flow.Header) func(*badger.Txn) error
+	RetrieveCommits(start uint64, end uint64, commits map[uint64]flow.StateCommitment) func(*badger.Txn) error
+	RetrieveEventsRange(start uint64, end uint64, types []flow.EventType, events map[uint64][]flow.Event) func(*badger.Txn) error
+	RetrieveRegisterHistory(path ledger.Path, start uint64, end uint64, payloads map[uint64]*ledger.Payload) func(*badger.Txn) error
+
 	LookupTransactionsForHeight(height uint64, txIDs *[]flow.Identifier) func(*badger.Txn) error
 	LookupTransactionsForCollection(collID flow.Identifier, txIDs *[]flow.Identifier) func(*badger.Txn) error
+	LookupTransactionsForAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint, txs *[]AccountTransaction) func(*badger.Txn) error
 	LookupCollectionsForHeight(height uint64, collIDs *[]flow.Identifier) func(*badger.Txn) error
 	LookupSealsForHeight(height uint64, sealIDs *[]flow.Identifier) func(*badger.Txn) error
 
@@ -54,6 +69,7 @@ type ReadLibrary interface {
 	RetrieveSeal(sealID flow.Identifier, seal *flow.Seal) func(*badger.Txn) error
 
 	IterateLedger(exclude func(height uint64) bool, process func(path ledger.Path, payload *ledger.Payload) error) func(*badger.Txn) error
+	IterateStateDiff(start uint64, end uint64, process func(path ledger.Path, before *ledger.Payload, after *ledger.Payload) error) func(*badger.Txn) error
 }
 
 // WriteLibrary represents something that produces operations to write on
@@ -61,17 +77,26 @@ type ReadLibrary interface {
 type WriteLibrary interface {
 	SaveFirst(height uint64) func(*badger.Txn) error
 	SaveLast(height uint64) func(*badger.Txn) error
+	SaveVersion(version uint64) func(*badger.Txn) error
+	SaveFloor(height uint64) func(*badger.Txn) error
+	SaveMigration(version uint64, cursor []byte) func(*badger.Txn) error
 
 	IndexHeightForBlock(blockID flow.Identifier, height uint64) func(*badger.Txn) error
 	IndexHeightForTransaction(txID flow.Identifier, height uint64) func(*badger.Txn) error
+	IndexHeightForCollection(collID flow.Identifier, height uint64) func(*badger.Txn) error
+	IndexHeightForSeal(sealID flow.Identifier, height uint64) func(*badger.Txn) error
+	IndexHeightForTime(timestamp time.Time, height uint64) func(*badger.Txn) error
+	IndexHeightForEvent(typ flow.EventType, height uint64) func(*badger.Txn) error
 
 	SaveCommit(height uint64, commit flow.StateCommitment) func(*badger.Txn) error
 	SaveHeader(height uint64, header *flow.Header) func(*badger.Txn) error
 	SaveEvents(height uint64, typ flow.EventType, events []flow.Event) func(*badger.Txn) error
 	SavePayload(height uint64, path ledger.Path, payload *ledger.Payload) func(*badger.Txn) error
 
+	IndexPathForHeight(height uint64, path ledger.Path) func(*badger.Txn) error
 	IndexTransactionsForHeight(height uint64, txIDs []flow.Identifier) func(*badger.Txn) error
 	IndexTransactionsForCollection(collID flow.Identifier, txIDs []flow.Identifier) func(*badger.Txn) error
+	IndexTransactionForAccount(address flow.Address, height uint64, txID flow.Identifier, roles Role) func(*badger.Txn) error
 	IndexCollectionsForHeight(height uint64, collIDs []flow.Identifier) func(*badger.Txn) error
 	IndexSealsForHeight(height uint64, sealIDs []flow.Identifier) func(*badger.Txn) error
 
@@ -80,4 +105,6 @@ type WriteLibrary interface {
 	SaveTransaction(transaction *flow.TransactionBody) func(*badger.Txn) error
 	SaveResult(results *flow.TransactionResult) func(*badger.Txn) error
 	SaveSeal(seal *flow.Seal) func(*badger.Txn) error
+
+	PrunePayloads(floor uint64, cursor []byte, limit uint, next *[]byte) func(*badger.Txn) error
 }
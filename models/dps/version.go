@@ -0,0 +1,22 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package dps
+
+// IndexVersion is the version of the index schema and codec that this release
+// reads and writes. It has to be increased whenever the storage prefixes, the
+// layout of keys or the codec dictionaries change in a way that makes existing
+// indexes unreadable, and a migration step to the new version has to be added
+// to the migrator.
+const IndexVersion = uint64(7)
@@ -0,0 +1,57 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package health
+
+import (
+	"time"
+)
+
+// DefaultConfig is the default configuration for the health monitor.
+var DefaultConfig = Config{
+	Interval: time.Second,  // interval at which the indexing lag is checked
+	MaxLag:   100,          // maximum number of finalized heights that can be missing from the index
+	Services: []string{""}, // services for which the serving status is updated
+}
+
+// Config is the configuration of the health monitor.
+type Config struct {
+	Interval time.Duration
+	MaxLag   uint64
+	Services []string
+}
+
+// WithInterval sets the interval at which the health monitor compares the
+// finalized height with the last indexed height.
+func WithInterval(interval time.Duration) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Interval = interval
+	}
+}
+
+// WithMaxLag sets the maximum number of finalized heights that can be missing
+// from the index before the services are reported as not serving.
+func WithMaxLag(lag uint64) func(*Config) {
+	return func(cfg *Config) {
+		cfg.MaxLag = lag
+	}
+}
+
+// WithServices sets the names of the services for which the serving status is
+// updated. The empty name stands for the overall health of the server.
+func WithServices(services ...string) func(*Config) {
+	return func(cfg *Config) {
+		cfg.Services = services
+	}
+}
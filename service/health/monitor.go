@@ -0,0 +1,111 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package health
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Monitor periodically compares the last height finalized by consensus with
+// the last height in the index, and reports the services as not serving when
+// the index falls too far behind, so that load balancers can route requests to
+// replicas that are up to date.
+type Monitor struct {
+	log     zerolog.Logger
+	cfg     Config
+	status  Status
+	chain   Finalizer
+	index   dps.Reader
+	current grpc_health_v1.HealthCheckResponse_ServingStatus
+	stop    chan struct{}
+}
+
+// NewMonitor creates a new health monitor that reports the serving status to
+// the given status server, based on the finalized height of the given chain
+// and the last indexed height of the given index.
+func NewMonitor(log zerolog.Logger, status Status, chain Finalizer, index dps.Reader, options ...func(*Config)) *Monitor {
+
+	cfg := DefaultConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	m := Monitor{
+		log:     log.With().Str("component", "health_monitor").Logger(),
+		cfg:     cfg,
+		status:  status,
+		chain:   chain,
+		index:   index,
+		current: grpc_health_v1.HealthCheckResponse_UNKNOWN,
+		stop:    make(chan struct{}),
+	}
+
+	return &m
+}
+
+// Run checks the indexing lag at the configured interval, until the monitor is
+// stopped.
+func (m *Monitor) Run() error {
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	m.check()
+	for {
+		select {
+		case <-m.stop:
+			return nil
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// Stop stops the health monitor.
+func (m *Monitor) Stop() {
+	close(m.stop)
+}
+
+func (m *Monitor) check() {
+
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	finalized := m.chain.Finalized()
+	last, err := m.index.Last()
+	switch {
+	case err != nil:
+		m.log.Warn().Err(err).Msg("could not get last indexed height")
+		status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	case finalized > last && finalized-last > m.cfg.MaxLag:
+		status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+
+	if status != m.current {
+		m.log.Info().
+			Uint64("finalized", finalized).
+			Uint64("last", last).
+			Str("status", status.String()).
+			Msg("serving status changed")
+		m.current = status
+	}
+
+	for _, service := range m.cfg.Services {
+		m.status.SetServingStatus(service, status)
+	}
+}
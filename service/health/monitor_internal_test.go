@@ -0,0 +1,158 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestNewMonitor(t *testing.T) {
+	status := mocks.BaselineStatus(t)
+	chain := mocks.BaselineFinalizer(t)
+	index := mocks.BaselineReader(t)
+
+	m := NewMonitor(zerolog.Nop(), status, chain, index,
+		WithInterval(time.Minute),
+		WithMaxLag(42),
+		WithServices("", "dps.API"),
+	)
+
+	require.NotNil(t, m)
+	assert.Equal(t, status, m.status)
+	assert.Equal(t, chain, m.chain)
+	assert.Equal(t, index, m.index)
+	assert.Equal(t, time.Minute, m.cfg.Interval)
+	assert.Equal(t, uint64(42), m.cfg.MaxLag)
+	assert.Equal(t, []string{"", "dps.API"}, m.cfg.Services)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_UNKNOWN, m.current)
+	assert.NotNil(t, m.stop)
+}
+
+func TestMonitor_Run(t *testing.T) {
+	checked := make(chan struct{}, 1)
+	status := mocks.BaselineStatus(t)
+	status.SetServingStatusFunc = func(string, grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		select {
+		case checked <- struct{}{}:
+		default:
+		}
+	}
+
+	m := NewMonitor(zerolog.Nop(), status, mocks.BaselineFinalizer(t), mocks.BaselineReader(t), WithInterval(time.Millisecond))
+
+	done := make(chan error)
+	go func() {
+		done <- m.Run()
+	}()
+
+	<-checked
+	m.Stop()
+
+	assert.NoError(t, <-done)
+}
+
+func TestMonitor_Check(t *testing.T) {
+	tests := []struct {
+		name string
+
+		finalized uint64
+		last      uint64
+		lastErr   error
+
+		wantStatus grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name: "index up to date",
+
+			finalized: mocks.GenericHeight,
+			last:      mocks.GenericHeight,
+
+			wantStatus: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "index within maximum lag",
+
+			finalized: mocks.GenericHeight + 10,
+			last:      mocks.GenericHeight,
+
+			wantStatus: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "index ahead of consensus",
+
+			finalized: mocks.GenericHeight,
+			last:      mocks.GenericHeight + 10,
+
+			wantStatus: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "index beyond maximum lag",
+
+			finalized: mocks.GenericHeight + 11,
+			last:      mocks.GenericHeight,
+
+			wantStatus: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name: "handles index failure",
+
+			finalized: mocks.GenericHeight,
+			lastErr:   mocks.GenericError,
+
+			wantStatus: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			chain := mocks.BaselineFinalizer(t)
+			chain.FinalizedFunc = func() uint64 {
+				return test.finalized
+			}
+
+			index := mocks.BaselineReader(t)
+			index.LastFunc = func() (uint64, error) {
+				return test.last, test.lastErr
+			}
+
+			got := make(map[string]grpc_health_v1.HealthCheckResponse_ServingStatus)
+			status := mocks.BaselineStatus(t)
+			status.SetServingStatusFunc = func(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+				got[service] = status
+			}
+
+			m := NewMonitor(zerolog.Nop(), status, chain, index, WithMaxLag(10), WithServices("", "dps.API"))
+			m.check()
+
+			want := map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":        test.wantStatus,
+				"dps.API": test.wantStatus,
+			}
+			assert.Equal(t, want, got)
+			assert.Equal(t, test.wantStatus, m.current)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package health
+
+import (
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Status represents something that reports the serving status of services,
+// such as the standard GRPC health server.
+type Status interface {
+	SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus)
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"sync"
+
+	"github.com/optakt/flow-dps/ledger/trie"
+)
+
+// MaxProofCache is the maximum number of restored state tries that the reader
+// keeps in memory. Larger cache sizes are capped to it, as each restored trie
+// holds the complete execution state.
+const MaxProofCache = 16
+
+// trieCache is a bounded cache of restored state tries by height. When it is
+// full, the least recently used trie is evicted to make room for a new one. It
+// also keeps track of the restores in progress, so that concurrent requests for
+// the same height share a single restore, and it limits the number of restores
+// for different heights that run at the same time.
+type trieCache struct {
+	sync.Mutex
+	size    uint
+	heights []uint64
+	tries   map[uint64]*trie.Trie
+	pending map[uint64]*restore
+	slots   chan struct{}
+}
+
+// restore is a trie restore in progress. The done channel is closed once the
+// trie and the error are set.
+type restore struct {
+	done chan struct{}
+	tree *trie.Trie
+	err  error
+}
+
+func newTrieCache(size uint, restores uint) *trieCache {
+
+	if size > MaxProofCache {
+		size = MaxProofCache
+	}
+	if restores == 0 {
+		restores = 1
+	}
+
+	c := trieCache{
+		size:    size,
+		heights: make([]uint64, 0, size),
+		tries:   make(map[uint64]*trie.Trie, size),
+		pending: make(map[uint64]*restore),
+		slots:   make(chan struct{}, restores),
+	}
+
+	return &c
+}
+
+// load returns the trie for the given height from the cache, or restores it
+// with the given function and adds it to the cache. The lock on the cache is
+// not held during the restore, so that requests for cached tries are not
+// blocked by it. Requests for a height that is already being restored wait for
+// that restore instead of starting their own.
+func (c *trieCache) load(height uint64, restoreTrie func() (*trie.Trie, error)) (*trie.Trie, error) {
+
+	c.Lock()
+	tree, ok := c.get(height)
+	if ok {
+		c.Unlock()
+		return tree, nil
+	}
+	call, ok := c.pending[height]
+	if ok {
+		c.Unlock()
+		<-call.done
+		return call.tree, call.err
+	}
+	call = &restore{done: make(chan struct{})}
+	c.pending[height] = call
+	c.Unlock()
+
+	c.slots <- struct{}{}
+	call.tree, call.err = restoreTrie()
+	<-c.slots
+
+	c.Lock()
+	delete(c.pending, height)
+	if call.err == nil {
+		c.put(height, call.tree)
+	}
+	c.Unlock()
+	close(call.done)
+
+	return call.tree, call.err
+}
+
+// get returns the trie for the given height, if it is cached, and marks it as
+// the most recently used one.
+func (c *trieCache) get(height uint64) (*trie.Trie, bool) {
+	tree, ok := c.tries[height]
+	if !ok {
+		return nil, false
+	}
+	c.touch(height)
+	return tree, true
+}
+
+// put adds the trie for the given height to the cache, evicting the least
+// recently used trie if the cache is full.
+func (c *trieCache) put(height uint64, tree *trie.Trie) {
+	if c.size == 0 {
+		return
+	}
+	_, ok := c.tries[height]
+	if ok {
+		c.tries[height] = tree
+		c.touch(height)
+		return
+	}
+	if uint(len(c.heights)) >= c.size {
+		delete(c.tries, c.heights[0])
+		c.heights = c.heights[1:]
+	}
+	c.tries[height] = tree
+	c.heights = append(c.heights, height)
+}
+
+// touch moves the given height to the end of the recency list.
+func (c *trieCache) touch(height uint64) {
+	for i, cached := range c.heights {
+		if cached == height {
+			c.heights = append(c.heights[:i], c.heights[i+1:]...)
+			break
+		}
+	}
+	c.heights = append(c.heights, height)
+}
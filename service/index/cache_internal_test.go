@@ -0,0 +1,206 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/ledger/trie"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestTrieCache(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		tree := trie.NewEmptyTrie()
+		c := newTrieCache(2, 1)
+		c.put(1, tree)
+
+		got, ok := c.get(1)
+
+		assert.True(t, ok)
+		assert.Same(t, tree, got)
+	})
+
+	t.Run("evicts least recently used trie", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTrieCache(2, 1)
+		c.put(1, trie.NewEmptyTrie())
+		c.put(2, trie.NewEmptyTrie())
+		_, _ = c.get(1)
+		c.put(3, trie.NewEmptyTrie())
+
+		_, ok := c.get(1)
+		assert.True(t, ok)
+		_, ok = c.get(2)
+		assert.False(t, ok)
+		_, ok = c.get(3)
+		assert.True(t, ok)
+		assert.Len(t, c.tries, 2)
+		assert.Len(t, c.heights, 2)
+	})
+
+	t.Run("replaces trie at same height", func(t *testing.T) {
+		t.Parallel()
+
+		tree := trie.NewEmptyTrie()
+		c := newTrieCache(2, 1)
+		c.put(1, trie.NewEmptyTrie())
+		c.put(1, tree)
+
+		got, ok := c.get(1)
+
+		assert.True(t, ok)
+		assert.Same(t, tree, got)
+		assert.Len(t, c.heights, 1)
+	})
+
+	t.Run("does not cache with zero size", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTrieCache(0, 1)
+		c.put(1, trie.NewEmptyTrie())
+
+		_, ok := c.get(1)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("caps cache size", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTrieCache(MaxProofCache+1, 1)
+
+		assert.Equal(t, uint(MaxProofCache), c.size)
+	})
+}
+
+func TestTrieCache_Load(t *testing.T) {
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		tree := trie.NewEmptyTrie()
+		calls := 0
+		restore := func() (*trie.Trie, error) {
+			calls++
+			return tree, nil
+		}
+		c := newTrieCache(2, 1)
+
+		got, err := c.load(1, restore)
+		require.NoError(t, err)
+		assert.Same(t, tree, got)
+
+		got, err = c.load(1, restore)
+		require.NoError(t, err)
+		assert.Same(t, tree, got)
+
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, c.pending)
+	})
+
+	t.Run("shares restore for same height", func(t *testing.T) {
+		t.Parallel()
+
+		tree := trie.NewEmptyTrie()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var calls int32
+		restore := func() (*trie.Trie, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+			}
+			<-release
+			return tree, nil
+		}
+		c := newTrieCache(2, 1)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			got, err := c.load(1, restore)
+			assert.NoError(t, err)
+			assert.Same(t, tree, got)
+		}()
+		<-started
+		go func() {
+			defer wg.Done()
+			got, err := c.load(1, restore)
+			assert.NoError(t, err)
+			assert.Same(t, tree, got)
+		}()
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("serves cached tries during restore", func(t *testing.T) {
+		t.Parallel()
+
+		tree := trie.NewEmptyTrie()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		c := newTrieCache(2, 1)
+		c.put(1, tree)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := c.load(2, func() (*trie.Trie, error) {
+				close(started)
+				<-release
+				return trie.NewEmptyTrie(), nil
+			})
+			assert.NoError(t, err)
+		}()
+		<-started
+
+		got, err := c.load(1, func() (*trie.Trie, error) {
+			return nil, errors.New("should not restore cached trie")
+		})
+		close(release)
+		<-done
+
+		require.NoError(t, err)
+		assert.Same(t, tree, got)
+	})
+
+	t.Run("handles restore failure", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTrieCache(2, 1)
+
+		_, err := c.load(1, func() (*trie.Trie, error) {
+			return nil, mocks.GenericError
+		})
+		assert.ErrorIs(t, err, mocks.GenericError)
+
+		_, ok := c.get(1)
+		assert.False(t, ok)
+		assert.Empty(t, c.pending)
+	})
+}
@@ -22,12 +22,20 @@ import (
 var DefaultConfig = Config{
 	ConcurrentTransactions: 16,          // same value as used for batches in badger
 	FlushInterval:          time.Second, // maximum idle time before flushing transaction
+	PruneRetention:         0,           // no pruning of the register history
+	PruneInterval:          1000,        // minimum number of heights between pruning runs
+	ProofCache:             1,           // number of restored state tries kept by the reader for proofs
+	ProofRestores:          1,           // number of state tries restored for proofs at the same time
 }
 
 // Config is the configuration of a DPS index.
 type Config struct {
 	ConcurrentTransactions uint
 	FlushInterval          time.Duration
+	PruneRetention         uint64
+	PruneInterval          uint64
+	ProofCache             uint
+	ProofRestores          uint
 }
 
 // WithConcurrentTransactions specifies the maximum concurrent transactions
@@ -46,3 +54,35 @@ func WithFlushInterval(interval time.Duration) func(*Config) {
 		cfg.FlushInterval = interval
 	}
 }
+
+// WithPruning enables the pruning of the register history, so that the full
+// history is only kept for the given number of heights below the last indexed
+// height. Pruning runs whenever the retention floor advanced by at least the
+// given interval since the previous run.
+func WithPruning(retention uint64, interval uint64) func(*Config) {
+	return func(cfg *Config) {
+		cfg.PruneRetention = retention
+		cfg.PruneInterval = interval
+	}
+}
+
+// WithProofCache sets the number of state tries restored for register proofs
+// that the index reader keeps in memory, so that repeated proofs at the same
+// heights do not restore the trie again. Each restored trie holds the complete
+// execution state, so the cache should be kept small; a size of zero disables
+// the cache, and sizes above `MaxProofCache` are capped.
+func WithProofCache(size uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.ProofCache = size
+	}
+}
+
+// WithProofRestores sets the number of state tries that the index reader can
+// restore for register proofs at the same time, at different heights. Each
+// restore loads the complete execution state into memory, so further requests
+// wait until a restore completes.
+func WithProofRestores(restores uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.ProofRestores = restores
+	}
+}
@@ -19,14 +19,18 @@ package index_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/ledger/trie"
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/service/index"
 	"github.com/optakt/flow-dps/service/storage"
 	"github.com/optakt/flow-dps/testing/helpers"
@@ -48,6 +52,24 @@ func TestIndex(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.Equal(t, mocks.GenericHeight, got)
+
+		version, err := index.Version(db, storage.New(zbor.NewCodec()))
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		reader, _, db := setupIndex(t)
+		defer db.Close()
+
+		_, err := reader.First()
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+
+		_, err = reader.Seal(mocks.GenericSeal(0).ID())
+		assert.ErrorIs(t, err, dps.ErrNotFound)
 	})
 
 	t.Run("last", func(t *testing.T) {
@@ -115,6 +137,123 @@ func TestIndex(t *testing.T) {
 		assert.Equal(t, mocks.GenericHeader, got)
 	})
 
+	t.Run("header by block ID", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		blockID := mocks.GenericHeader.ID()
+		assert.NoError(t, writer.Height(blockID, mocks.GenericHeight))
+		assert.NoError(t, writer.Header(mocks.GenericHeight, mocks.GenericHeader))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.HeaderByBlockID(blockID)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeader, got)
+
+		_, err = reader.HeaderByBlockID(mocks.GenericSeal(0).ID())
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+	})
+
+	t.Run("height for time", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		base := mocks.GenericHeader.Timestamp
+		for i := uint64(0); i < 4; i++ {
+			header := *mocks.GenericHeader
+			header.Height = mocks.GenericHeight + i
+			header.Timestamp = base.Add(time.Duration(i) * time.Minute)
+			assert.NoError(t, writer.Header(header.Height, &header))
+		}
+		// Only the first three blocks are finalized and fully indexed.
+		assert.NoError(t, writer.Last(mocks.GenericHeight+2))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.HeightForTime(base)
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight, got)
+
+		got, err = reader.HeightForTime(base.Add(90 * time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight+1, got)
+
+		got, err = reader.HeightForTime(base.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, mocks.GenericHeight+2, got)
+
+		_, err = reader.HeightForTime(base.Add(-time.Second))
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		commits := mocks.GenericCommits(4)
+		events := mocks.GenericEvents(4, mocks.GenericEventTypes(2)...)
+
+		start := mocks.GenericHeight
+		end := mocks.GenericHeight + 3
+
+		assert.NoError(t, writer.First(start))
+		assert.NoError(t, writer.Last(end))
+		for height := start; height <= end; height++ {
+			assert.NoError(t, writer.Header(height, &flow.Header{Height: height}))
+			assert.NoError(t, writer.Commit(height, commits[height-start]))
+			assert.NoError(t, writer.Events(height, events))
+		}
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		// NOTE: The following subtests should NOT be run in parallel, because of the deferral
+		// to close the database above.
+		t.Run("headers", func(t *testing.T) {
+			got, err := reader.Headers(start+1, end)
+
+			require.NoError(t, err)
+			require.Len(t, got, 3)
+			for i, header := range got {
+				assert.Equal(t, start+1+uint64(i), header.Height)
+			}
+		})
+
+		t.Run("commits", func(t *testing.T) {
+			got, err := reader.Commits(start, end-1)
+
+			require.NoError(t, err)
+			require.Len(t, got, 3)
+			for i, commit := range commits[:3] {
+				assert.Equal(t, commit, got[start+uint64(i)])
+			}
+		})
+
+		t.Run("events", func(t *testing.T) {
+			got, err := reader.EventsRange(start, end, mocks.GenericEventType(0))
+
+			require.NoError(t, err)
+			assert.Len(t, got, 4)
+			for _, evts := range got {
+				assert.ElementsMatch(t, []flow.Event{events[0], events[2]}, evts)
+			}
+		})
+
+		t.Run("out of range", func(t *testing.T) {
+			_, err := reader.Headers(start, end+1)
+			assert.ErrorIs(t, err, dps.ErrOutOfRange)
+
+			_, err = reader.Commits(end, start)
+			assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+		})
+	})
+
 	t.Run("payloads", func(t *testing.T) {
 		t.Parallel()
 
@@ -137,6 +276,113 @@ func TestIndex(t *testing.T) {
 		assert.ElementsMatch(t, values, got)
 	})
 
+	t.Run("register history", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		path := mocks.GenericLedgerPath(0)
+		payloads := mocks.GenericLedgerPayloads(3)
+
+		assert.NoError(t, writer.First(mocks.GenericHeight))
+		assert.NoError(t, writer.Last(mocks.GenericHeight+10))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight, []ledger.Path{path}, payloads[0:1]))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight+5, []ledger.Path{path}, payloads[1:2]))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight+10, []ledger.Path{path}, payloads[2:3]))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+9)
+
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, payloads[0], got[mocks.GenericHeight])
+		assert.Equal(t, payloads[1], got[mocks.GenericHeight+5])
+
+		_, err = reader.RegisterHistory(path, mocks.GenericHeight, mocks.GenericHeight+11)
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+
+	t.Run("state diff", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		paths := mocks.GenericLedgerPaths(3)
+		payloads := mocks.GenericLedgerPayloads(4)
+
+		assert.NoError(t, writer.First(mocks.GenericHeight))
+		assert.NoError(t, writer.Last(mocks.GenericHeight+2))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight, paths, payloads[0:3]))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight+1, paths[1:2], payloads[3:4]))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		var got []dps.Change
+		err := reader.StateDiff(mocks.GenericHeight, mocks.GenericHeight+2, func(change dps.Change) error {
+			got = append(got, change)
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, paths[1], got[0].Path)
+		assert.Equal(t, payloads[1], got[0].Before)
+		assert.Equal(t, payloads[3], got[0].After)
+
+		err = reader.StateDiff(mocks.GenericHeight+2, mocks.GenericHeight, func(dps.Change) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, dps.ErrInvalidArgument)
+	})
+
+	t.Run("proofs", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		// We only index the first three paths, so the last one should get a
+		// non-inclusion proof.
+		paths := mocks.GenericLedgerPaths(4)
+		payloads := mocks.GenericLedgerPayloads(3)
+
+		registers := make([]ledger.Path, 3)
+		copy(registers, paths)
+		values := make([]ledger.Payload, 0, len(payloads))
+		for _, payload := range payloads {
+			values = append(values, *payload)
+		}
+		tree, err := trie.NewEmptyTrie().Mutate(registers, values)
+		require.NoError(t, err)
+		root := tree.RootHash()
+
+		assert.NoError(t, writer.First(mocks.GenericHeight))
+		assert.NoError(t, writer.Last(mocks.GenericHeight))
+		assert.NoError(t, writer.Commit(mocks.GenericHeight, flow.StateCommitment(root)))
+		assert.NoError(t, writer.Payloads(mocks.GenericHeight, paths[:3], payloads))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.Proofs(mocks.GenericHeight, paths)
+
+		require.NoError(t, err)
+		require.Len(t, got.Proofs, 4)
+		for i, proof := range got.Proofs {
+			assert.Equal(t, i < 3, proof.Inclusion)
+			assert.True(t, trie.Verify(paths[i], proof, root))
+		}
+
+		// The second call is served from the cached trie.
+		cached, err := reader.Proofs(mocks.GenericHeight, paths[:1])
+
+		require.NoError(t, err)
+		require.Len(t, cached.Proofs, 1)
+		assert.True(t, trie.Verify(paths[0], cached.Proofs[0], root))
+	})
+
 	t.Run("collections", func(t *testing.T) {
 		t.Parallel()
 
@@ -166,7 +412,17 @@ func TestIndex(t *testing.T) {
 		})
 
 		t.Run("retrieve transactions from collection", func(t *testing.T) {
-			// For now this index is not used.
+			got, err := reader.TransactionsByCollection(collections[0].ID())
+
+			require.NoError(t, err)
+			assert.Equal(t, collections[0].Transactions, got)
+		})
+
+		t.Run("retrieve height for collection", func(t *testing.T) {
+			got, err := reader.HeightForCollection(collections[0].ID())
+
+			require.NoError(t, err)
+			assert.Equal(t, mocks.GenericHeight, got)
 		})
 	})
 
@@ -289,6 +545,87 @@ func TestIndex(t *testing.T) {
 		})
 	})
 
+	t.Run("event heights", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		withdrawalType := mocks.GenericEventType(0)
+		depositType := mocks.GenericEventType(1)
+
+		assert.NoError(t, writer.First(mocks.GenericHeight))
+		assert.NoError(t, writer.Last(mocks.GenericHeight+3))
+		assert.NoError(t, writer.Events(mocks.GenericHeight, mocks.GenericEvents(2, withdrawalType)))
+		assert.NoError(t, writer.Events(mocks.GenericHeight+1, mocks.GenericEvents(2, depositType)))
+		assert.NoError(t, writer.Events(mocks.GenericHeight+3, mocks.GenericEvents(2, withdrawalType, depositType)))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.EventHeights(withdrawalType, mocks.GenericHeight, mocks.GenericHeight+3)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{mocks.GenericHeight, mocks.GenericHeight + 3}, got)
+
+		got, err = reader.EventHeights(depositType, mocks.GenericHeight+2, mocks.GenericHeight+3)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{mocks.GenericHeight + 3}, got)
+
+		_, err = reader.EventHeights(depositType, mocks.GenericHeight, mocks.GenericHeight+4)
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+
+	t.Run("transactions by account", func(t *testing.T) {
+		t.Parallel()
+
+		reader, writer, db := setupIndex(t)
+		defer db.Close()
+
+		addresses := mocks.GenericAddresses(2)
+		transactions := mocks.GenericTransactions(2)
+		transactions[0].ProposalKey.Address = addresses[0]
+		transactions[0].Payer = addresses[1]
+		transactions[0].Authorizers = []flow.Address{addresses[0]}
+		transactions[1].ProposalKey.Address = addresses[1]
+		transactions[1].Payer = addresses[1]
+		transactions[1].Authorizers = []flow.Address{addresses[1]}
+
+		assert.NoError(t, writer.First(mocks.GenericHeight))
+		assert.NoError(t, writer.Last(mocks.GenericHeight+1))
+		assert.NoError(t, writer.Transactions(mocks.GenericHeight, transactions[:1]))
+		assert.NoError(t, writer.Transactions(mocks.GenericHeight+1, transactions[1:]))
+		// Close the writer to make it commit its transactions.
+		require.NoError(t, writer.Close())
+
+		got, err := reader.TransactionsByAccount(addresses[0], mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 0)
+
+		require.NoError(t, err)
+		want := []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: transactions[0].ID(), Roles: dps.RoleProposer | dps.RoleAuthorizer},
+		}
+		assert.Equal(t, want, got)
+
+		got, err = reader.TransactionsByAccount(addresses[1], mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 1)
+
+		require.NoError(t, err)
+		want = []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: transactions[0].ID(), Roles: dps.RolePayer},
+		}
+		assert.Equal(t, want, got)
+
+		got, err = reader.TransactionsByAccount(addresses[1], got[0].Height, mocks.GenericHeight+1, got[0].TransactionID, 1)
+
+		require.NoError(t, err)
+		want = []dps.AccountTransaction{
+			{Height: mocks.GenericHeight + 1, TransactionID: transactions[1].ID(), Roles: dps.RoleProposer | dps.RolePayer | dps.RoleAuthorizer},
+		}
+		assert.Equal(t, want, got)
+
+		_, err = reader.TransactionsByAccount(addresses[0], mocks.GenericHeight, mocks.GenericHeight+2, flow.ZeroID, 0)
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+	})
+
 	t.Run("seals", func(t *testing.T) {
 		t.Parallel()
 
@@ -316,6 +653,13 @@ func TestIndex(t *testing.T) {
 			require.NoError(t, err)
 			assert.ElementsMatch(t, got, mocks.GenericSealIDs(4))
 		})
+
+		t.Run("retrieve height for seal", func(t *testing.T) {
+			got, err := reader.HeightForSeal(seals[0].ID())
+
+			require.NoError(t, err)
+			assert.Equal(t, mocks.GenericHeight, got)
+		})
 	})
 }
 
@@ -0,0 +1,98 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// pruneBatchSize is the number of payload keys that are processed in a single
+// transaction when pruning the register history.
+const pruneBatchSize = 10_000
+
+// pruneRetries is the number of times a batch that conflicts with concurrent
+// writes to the index is retried before pruning fails. The delay before the
+// first retry is doubled for each following retry, up to the maximum.
+const (
+	pruneRetries    = 10
+	pruneBackoff    = 10 * time.Millisecond
+	pruneMaxBackoff = time.Second
+)
+
+// Prune deletes the register history before the given floor height. For each
+// path, it keeps all payloads above the floor, and the last payload at or below
+// it, so that the registers can still be read at any height from the floor
+// onwards. The floor is saved before any payloads are deleted, so that heights
+// with incomplete register state are rejected by readers right away. If the
+// given channel is closed, pruning stops after the current batch; it can be
+// completed by pruning again at the same floor or at a higher one.
+func Prune(db *badger.DB, lib dps.WriteLibrary, floor uint64, done <-chan struct{}) error {
+	return prune(db, lib, floor, pruneBatchSize, done)
+}
+
+func prune(db *badger.DB, lib dps.WriteLibrary, floor uint64, batch uint, done <-chan struct{}) error {
+
+	err := db.Update(lib.SaveFloor(floor))
+	if err != nil {
+		return fmt.Errorf("could not save retention floor: %w", err)
+	}
+
+	var cursor []byte
+	retries := 0
+	backoff := pruneBackoff
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+			// continue
+		}
+
+		var next []byte
+		err = db.Update(lib.PrunePayloads(floor, cursor, batch, &next))
+		if errors.Is(err, badger.ErrConflict) && retries < pruneRetries {
+			// The batch conflicted with a concurrent write to the index, so
+			// we retry it from the same cursor after giving the writer some
+			// time to complete.
+			select {
+			case <-done:
+				return nil
+			case <-time.After(backoff):
+			}
+			retries++
+			backoff *= 2
+			if backoff > pruneMaxBackoff {
+				backoff = pruneMaxBackoff
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not prune payloads: %w", err)
+		}
+		if next == nil {
+			return nil
+		}
+
+		cursor = next
+		retries = 0
+		backoff = pruneBackoff
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-dps/testing/helpers"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestPrune(t *testing.T) {
+	paths := mocks.GenericLedgerPaths(3)
+	payloads := mocks.GenericLedgerPayloads(5)
+	values := mocks.GenericLedgerValues(5)
+	first := mocks.GenericHeight
+	last := mocks.GenericHeight + 4
+	floor := mocks.GenericHeight + 2
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveFirst(first)))
+		require.NoError(t, db.Update(lib.SaveLast(last)))
+		for i := range payloads {
+			for _, path := range paths {
+				require.NoError(t, db.Update(lib.SavePayload(first+uint64(i), path, payloads[i])))
+			}
+		}
+
+		err := prune(db, lib, floor, 2, make(chan struct{}))
+		require.NoError(t, err)
+
+		reader := NewReader(db, lib)
+
+		// Blocks remain available from the first height, while the registers
+		// can only be read from the floor onwards.
+		got, err := reader.First()
+		require.NoError(t, err)
+		assert.Equal(t, first, got)
+
+		got, err = reader.Floor()
+		require.NoError(t, err)
+		assert.Equal(t, floor, got)
+
+		// The register values at the floor still need to be available, while
+		// the ones below it are rejected.
+		vals, err := reader.Values(floor, paths)
+		require.NoError(t, err)
+		for _, val := range vals {
+			assert.Equal(t, values[2], val)
+		}
+
+		_, err = reader.Values(floor-1, paths)
+		assert.ErrorIs(t, err, dps.ErrOutOfRange)
+
+		// Only the last payload at or before the floor should remain.
+		for _, path := range paths {
+			history, err := reader.RegisterHistory(path, floor, last)
+			require.NoError(t, err)
+			assert.Len(t, history, 3)
+		}
+	})
+
+	t.Run("handles interruption", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveFirst(first)))
+		require.NoError(t, db.Update(lib.SaveLast(last)))
+		for i := range payloads {
+			require.NoError(t, db.Update(lib.SavePayload(first+uint64(i), paths[0], payloads[i])))
+		}
+
+		done := make(chan struct{})
+		close(done)
+
+		err := prune(db, lib, floor, 2, done)
+		require.NoError(t, err)
+
+		// Even if pruning was interrupted, the floor needs to be saved, as we
+		// can no longer guarantee that the heights below it are complete.
+		got, err := NewReader(db, lib).Floor()
+		require.NoError(t, err)
+		assert.Equal(t, floor, got)
+	})
+
+	t.Run("handles floor below first height", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveFirst(floor)))
+
+		err := prune(db, lib, first, 2, make(chan struct{}))
+		require.NoError(t, err)
+
+		got, err := NewReader(db, lib).Floor()
+		require.NoError(t, err)
+		assert.Equal(t, floor, got)
+	})
+}
+
+func TestWriter_Pruning(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	path := mocks.GenericLedgerPath(0)
+	payloads := mocks.GenericLedgerPayloads(5)
+
+	lib := storage.New(zbor.NewCodec())
+	writer := NewWriter(db, lib,
+		WithFlushInterval(0),
+		WithPruning(2, 1),
+	)
+
+	// We commit the payloads before pruning is triggered, so that the pruning
+	// run is guaranteed to see them.
+	require.NoError(t, db.Update(lib.SaveFirst(mocks.GenericHeight)))
+	for i := range payloads {
+		require.NoError(t, db.Update(lib.SavePayload(mocks.GenericHeight+uint64(i), path, payloads[i])))
+	}
+
+	last := mocks.GenericHeight + 4
+	require.NoError(t, writer.Last(last))
+	require.NoError(t, writer.Close())
+
+	reader := NewReader(db, lib)
+
+	floor, err := reader.Floor()
+	require.NoError(t, err)
+	assert.Equal(t, last-2, floor)
+
+	history, err := reader.RegisterHistory(path, floor, last)
+	require.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, payloads[2], history[floor])
+
+	// Pruning should not delete anything else than superseded payloads.
+	var payload ledger.Payload
+	err = db.View(lib.RetrievePayload(last, path, &payload))
+	require.NoError(t, err)
+	assert.Equal(t, *payloads[4], payload)
+}
+
+func TestWriter_PruningResumesFromSavedFloor(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	lib := storage.New(zbor.NewCodec())
+	saved := mocks.GenericHeight + 10
+	require.NoError(t, db.Update(lib.SaveFirst(mocks.GenericHeight)))
+	require.NoError(t, db.Update(lib.SaveFloor(saved)))
+
+	writer := NewWriter(db, lib,
+		WithFlushInterval(0),
+		WithPruning(2, 5),
+	)
+
+	// The floor for this height has not advanced far enough past the saved
+	// floor, so no pruning run should be triggered.
+	require.NoError(t, writer.Last(saved+4))
+	require.NoError(t, writer.Close())
+
+	reader := NewReader(db, lib)
+
+	floor, err := reader.Floor()
+	require.NoError(t, err)
+	assert.Equal(t, saved, floor)
+}
@@ -17,30 +17,39 @@ package index
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dgraph-io/badger/v2"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
 
+	"github.com/optakt/flow-dps/ledger/trie"
 	"github.com/optakt/flow-dps/models/dps"
 )
 
 // Reader implements the `index.Reader` interface on top of the DPS server's
 // Badger database index.
 type Reader struct {
-	db  *badger.DB
-	lib dps.ReadLibrary
+	db    *badger.DB
+	lib   dps.ReadLibrary
+	tries *trieCache
 }
 
 // NewReader creates a new index reader, using the given database as the
 // underlying state repository. It is recommended to provide a read-only Badger
 // database.
-func NewReader(db *badger.DB, lib dps.ReadLibrary) *Reader {
+func NewReader(db *badger.DB, lib dps.ReadLibrary, options ...func(*Config)) *Reader {
+
+	cfg := DefaultConfig
+	for _, option := range options {
+		option(&cfg)
+	}
 
 	r := Reader{
-		db:  db,
-		lib: lib,
+		db:    db,
+		lib:   lib,
+		tries: newTrieCache(cfg.ProofCache, cfg.ProofRestores),
 	}
 
 	return &r
@@ -53,6 +62,31 @@ func (r *Reader) First() (uint64, error) {
 	return height, err
 }
 
+// Floor returns the lowest height at which the complete register state can be
+// read. It is the first indexed height, unless the register history was pruned,
+// in which case it is the retention floor. Blocks, events and transactions
+// remain available from the first height regardless.
+func (r *Reader) Floor() (uint64, error) {
+	first, err := r.First()
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve first height: %w", err)
+	}
+
+	var floor uint64
+	err = r.db.View(r.lib.RetrieveFloor(&floor))
+	if errors.Is(err, dps.ErrNotFound) {
+		return first, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve retention floor: %w", err)
+	}
+	if floor > first {
+		return floor, nil
+	}
+
+	return first, nil
+}
+
 // Last returns the height of the last finalized block that was indexed.
 func (r *Reader) Last() (uint64, error) {
 	var height uint64
@@ -67,6 +101,30 @@ func (r *Reader) HeightForBlock(blockID flow.Identifier) (uint64, error) {
 	return height, err
 }
 
+// HeightForTime returns the height of the last finalized block with a timestamp
+// at or before the given time.
+func (r *Reader) HeightForTime(timestamp time.Time) (uint64, error) {
+	last, err := r.Last()
+	if err != nil {
+		return 0, fmt.Errorf("could not check last height: %w", err)
+	}
+
+	var height uint64
+	err = r.db.View(r.lib.LookupHeightForTime(timestamp, &height))
+	if err != nil {
+		return 0, fmt.Errorf("could not look up height for time: %w", err)
+	}
+
+	// The timestamp index is written together with the headers, so it can be
+	// ahead of the last height while a block is still being indexed. As block
+	// timestamps are monotonic, the last height is then the correct answer.
+	if height > last {
+		height = last
+	}
+
+	return height, nil
+}
+
 // Commit returns the commitment of the execution state as it was after the
 // execution of the finalized block at the given height.
 func (r *Reader) Commit(height uint64) (flow.StateCommitment, error) {
@@ -82,28 +140,116 @@ func (r *Reader) Header(height uint64) (*flow.Header, error) {
 	return &header, err
 }
 
+// HeaderByBlockID returns the header for the finalized block with the given
+// block identifier.
+func (r *Reader) HeaderByBlockID(blockID flow.Identifier) (*flow.Header, error) {
+	height, err := r.HeightForBlock(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not look up height for block: %w", err)
+	}
+
+	header, err := r.Header(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve header: %w", err)
+	}
+
+	return header, nil
+}
+
+// Headers returns the headers for the finalized blocks at all heights between
+// the given start and end heights, both inclusive.
+func (r *Reader) Headers(start uint64, end uint64) ([]*flow.Header, error) {
+	err := r.checkRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	headers := make([]*flow.Header, 0, end-start+1)
+	err = r.db.View(r.lib.RetrieveHeaders(start, end, &headers))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve headers: %w", err)
+	}
+
+	return headers, nil
+}
+
+// Commits returns the commitments of the execution state as it was after the
+// execution of the finalized blocks at all heights between the given start and
+// end heights, both inclusive, by height.
+func (r *Reader) Commits(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+	err := r.checkRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	commits := make(map[uint64]flow.StateCommitment, end-start+1)
+	err = r.db.View(r.lib.RetrieveCommits(start, end, commits))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve commits: %w", err)
+	}
+
+	return commits, nil
+}
+
+// EventsRange returns the events of all transactions that were part of the
+// finalized blocks at all heights between the given start and end heights,
+// both inclusive, by height. Heights without events are omitted. It can
+// optionally filter them by event type; if no event types are given, all
+// events are returned.
+func (r *Reader) EventsRange(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+	err := r.checkRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	events := make(map[uint64][]flow.Event)
+	err = r.db.View(r.lib.RetrieveEventsRange(start, end, types, events))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve events: %w", err)
+	}
+
+	return events, nil
+}
+
+// EventHeights returns the heights between the given start and end heights,
+// both inclusive, of the finalized blocks that contain events of the given type.
+func (r *Reader) EventHeights(typ flow.EventType, start uint64, end uint64) ([]uint64, error) {
+	err := r.checkRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	heights := make([]uint64, 0)
+	err = r.db.View(r.lib.LookupHeightsForEvent(typ, start, end, &heights))
+	if err != nil {
+		return nil, fmt.Errorf("could not look up heights for event: %w", err)
+	}
+
+	return heights, nil
+}
+
 // Values returns the Ledger values of the execution state at the given paths
 // as they were after the execution of the finalized block at the given height.
 // For compatibility with existing Flow execution node code, a path that is not
 // found within the indexed execution state returns a nil value without error.
 func (r *Reader) Values(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
-	first, err := r.First()
+	floor, err := r.Floor()
 	if err != nil {
-		return nil, fmt.Errorf("could not check first height: %w", err)
+		return nil, fmt.Errorf("could not check floor height: %w", err)
 	}
 	last, err := r.Last()
 	if err != nil {
 		return nil, fmt.Errorf("could not check last height: %w", err)
 	}
-	if height < first || height > last {
-		return nil, fmt.Errorf("invalid height (given: %d, first: %d, last: %d)", height, first, last)
+	if height < floor || height > last {
+		return nil, fmt.Errorf("invalid height (given: %d, floor: %d, last: %d): %w", height, floor, last, dps.ErrOutOfRange)
 	}
 	values := make([]ledger.Value, 0, len(paths))
 	err = r.db.View(func(tx *badger.Txn) error {
 		for _, path := range paths {
 			var payload ledger.Payload
 			err := r.lib.RetrievePayload(height, path, &payload)(tx)
-			if errors.Is(err, badger.ErrKeyNotFound) {
+			if errors.Is(err, dps.ErrNotFound) {
 				values = append(values, nil)
 				continue
 			}
@@ -117,6 +263,125 @@ func (r *Reader) Values(height uint64, paths []ledger.Path) ([]ledger.Value, err
 	return values, err
 }
 
+// Proofs returns batch proofs for the given paths of the execution state as it
+// was after the execution of the finalized block at the given height. Paths
+// that are not found within the indexed execution state get a non-inclusion
+// proof. The proofs can be verified against the state commitment at the given
+// height. As the index does not store the state trie itself, it is restored
+// from the indexed payloads, which is expensive. Restored tries are kept in a
+// bounded cache, concurrent requests for the same height share one restore,
+// and the number of restores running at the same time is limited.
+func (r *Reader) Proofs(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+	floor, err := r.Floor()
+	if err != nil {
+		return nil, fmt.Errorf("could not check floor height: %w", err)
+	}
+	last, err := r.Last()
+	if err != nil {
+		return nil, fmt.Errorf("could not check last height: %w", err)
+	}
+	if height < floor || height > last {
+		return nil, fmt.Errorf("invalid height (given: %d, floor: %d, last: %d): %w", height, floor, last, dps.ErrOutOfRange)
+	}
+
+	tree, err := r.tries.load(height, func() (*trie.Trie, error) {
+		return r.restore(height)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load trie: %w", err)
+	}
+
+	proofs := tree.Prove(paths)
+
+	return proofs, nil
+}
+
+// restore restores the state trie after the finalized block at the given height
+// from the indexed payloads, and checks it against the state commitment.
+func (r *Reader) restore(height uint64) (*trie.Trie, error) {
+
+	commit, err := r.Commit(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve commit: %w", err)
+	}
+
+	// We collect the latest payload for each path at the given height, and then
+	// build the trie with a single mutation.
+	var registers []ledger.Path
+	var payloads []ledger.Payload
+	exclude := func(h uint64) bool {
+		return h > height
+	}
+	process := func(path ledger.Path, payload *ledger.Payload) error {
+		registers = append(registers, path)
+		payloads = append(payloads, *payload)
+		return nil
+	}
+	err = r.db.View(r.lib.IterateLedger(exclude, process))
+	if err != nil {
+		return nil, fmt.Errorf("could not iterate ledger: %w", err)
+	}
+
+	tree, err := trie.NewEmptyTrie().Mutate(registers, payloads)
+	if err != nil {
+		return nil, fmt.Errorf("could not restore trie: %w", err)
+	}
+
+	// If the restored trie does not match the commit, the proofs would not
+	// verify, so we fail early.
+	hash := tree.RootHash()
+	if flow.StateCommitment(hash) != commit {
+		return nil, fmt.Errorf("restored trie does not match commit (hash: %x, commit: %x)", hash, commit)
+	}
+
+	return tree, nil
+}
+
+// RegisterHistory returns the ledger payloads that were written to the given
+// path by the finalized blocks at all heights between the given start and end
+// heights, both inclusive, by height. Heights at which the path was not written
+// are omitted.
+func (r *Reader) RegisterHistory(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+	err := r.checkRegisterRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	payloads := make(map[uint64]*ledger.Payload)
+	err = r.db.View(r.lib.RetrieveRegisterHistory(path, start, end, payloads))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve register history: %w", err)
+	}
+
+	return payloads, nil
+}
+
+// StateDiff calls the given callback with the change to each ledger register
+// between the execution state after the finalized block at the given start
+// height and the one after the finalized block at the given end height, in
+// ascending order of ledger paths.
+func (r *Reader) StateDiff(start uint64, end uint64, process func(change dps.Change) error) error {
+	err := r.checkRegisterRange(start, end)
+	if err != nil {
+		return fmt.Errorf("could not check range: %w", err)
+	}
+
+	forward := func(path ledger.Path, before *ledger.Payload, after *ledger.Payload) error {
+		change := dps.Change{
+			Path:   path,
+			Before: before,
+			After:  after,
+		}
+		return process(change)
+	}
+	err = r.db.View(r.lib.IterateStateDiff(start, end, forward))
+	if err != nil {
+		return fmt.Errorf("could not iterate state diff: %w", err)
+	}
+
+	return nil
+}
+
 // Collection returns the collection with the given ID.
 func (r *Reader) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
 	var collection flow.LightCollection
@@ -131,6 +396,14 @@ func (r *Reader) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
 	return collIDs, err
 }
 
+// HeightForCollection returns the height of the block within which the given
+// collection identifier is.
+func (r *Reader) HeightForCollection(collID flow.Identifier) (uint64, error) {
+	var height uint64
+	err := r.db.View(r.lib.LookupHeightForCollection(collID, &height))
+	return height, err
+}
+
 // Guarantee returns the guarantee with the given collection ID.
 func (r *Reader) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
 	var collection flow.CollectionGuarantee
@@ -160,6 +433,34 @@ func (r *Reader) TransactionsByHeight(height uint64) ([]flow.Identifier, error)
 	return txIDs, err
 }
 
+// TransactionsByCollection returns the transaction IDs within the collection
+// with the given ID.
+func (r *Reader) TransactionsByCollection(collID flow.Identifier) ([]flow.Identifier, error) {
+	var txIDs []flow.Identifier
+	err := r.db.View(r.lib.LookupTransactionsForCollection(collID, &txIDs))
+	return txIDs, err
+}
+
+// TransactionsByAccount returns up to the given limit of transactions that the
+// account with the given address took part in between the given start and end
+// heights, both inclusive, ordered by height and transaction ID. When a
+// transaction ID is given, only transactions after it are returned, which
+// allows continuing from the last transaction of a previous page.
+func (r *Reader) TransactionsByAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+	err := r.checkRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("could not check range: %w", err)
+	}
+
+	txs := make([]dps.AccountTransaction, 0)
+	err = r.db.View(r.lib.LookupTransactionsForAccount(address, start, end, after, limit, &txs))
+	if err != nil {
+		return nil, fmt.Errorf("could not look up transactions for account: %w", err)
+	}
+
+	return txs, nil
+}
+
 // Result returns the transaction result for the given transaction ID.
 func (r *Reader) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
 	var result flow.TransactionResult
@@ -180,7 +481,7 @@ func (r *Reader) Events(height uint64, types ...flow.EventType) ([]flow.Event, e
 		return nil, fmt.Errorf("could not check last height: %w", err)
 	}
 	if height < first || height > last {
-		return nil, fmt.Errorf("invalid height (given: %d, first: %d, last: %d)", height, first, last)
+		return nil, fmt.Errorf("invalid height (given: %d, first: %d, last: %d): %w", height, first, last, dps.ErrOutOfRange)
 	}
 
 	var events []flow.Event
@@ -205,3 +506,50 @@ func (r *Reader) SealsByHeight(height uint64) ([]flow.Identifier, error) {
 	err := r.db.View(r.lib.LookupSealsForHeight(height, &sealIDs))
 	return sealIDs, err
 }
+
+// HeightForSeal returns the height of the block within which the given seal
+// identifier is.
+func (r *Reader) HeightForSeal(sealID flow.Identifier) (uint64, error) {
+	var height uint64
+	err := r.db.View(r.lib.LookupHeightForSeal(sealID, &height))
+	return height, err
+}
+
+// checkRange makes sure that the given height range is valid and within the
+// range of indexed heights.
+func (r *Reader) checkRange(start uint64, end uint64) error {
+	if start > end {
+		return fmt.Errorf("invalid range (start: %d, end: %d): %w", start, end, dps.ErrInvalidArgument)
+	}
+	first, err := r.First()
+	if err != nil {
+		return fmt.Errorf("could not check first height: %w", err)
+	}
+	return r.checkBounds(start, end, first)
+}
+
+// checkRegisterRange makes sure that the given height range is valid and within
+// the range of heights for which the complete register state can be read.
+func (r *Reader) checkRegisterRange(start uint64, end uint64) error {
+	if start > end {
+		return fmt.Errorf("invalid range (start: %d, end: %d): %w", start, end, dps.ErrInvalidArgument)
+	}
+	floor, err := r.Floor()
+	if err != nil {
+		return fmt.Errorf("could not check floor height: %w", err)
+	}
+	return r.checkBounds(start, end, floor)
+}
+
+// checkBounds makes sure that the given height range is between the given
+// lowest height and the last indexed height.
+func (r *Reader) checkBounds(start uint64, end uint64, lowest uint64) error {
+	last, err := r.Last()
+	if err != nil {
+		return fmt.Errorf("could not check last height: %w", err)
+	}
+	if start < lowest || end > last {
+		return fmt.Errorf("invalid range (start: %d, end: %d, first: %d, last: %d): %w", start, end, lowest, last, dps.ErrOutOfRange)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/optakt/flow-dps/models/dps"
+)
+
+// Version returns the version of the index schema and codec used by the index
+// in the given database. Indexes that were created before the version was
+// stored have version zero. If the index is empty, it returns an error that
+// wraps `dps.ErrNotFound`.
+func Version(db *badger.DB, lib dps.ReadLibrary) (uint64, error) {
+
+	var version uint64
+	err := db.View(lib.RetrieveVersion(&version))
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, dps.ErrNotFound) {
+		return 0, fmt.Errorf("could not retrieve version: %w", err)
+	}
+
+	// If there is no version, the index is either empty, or it was created
+	// before we started storing the version.
+	var first uint64
+	err = db.View(lib.RetrieveFirst(&first))
+	if err != nil {
+		return 0, fmt.Errorf("could not retrieve first height: %w", err)
+	}
+
+	return 0, nil
+}
+
+// CheckVersion checks that the index in the given database uses the version of
+// the index schema and codec supported by this release. Empty indexes pass the
+// check, as the version is written when they are bootstrapped.
+func CheckVersion(db *badger.DB, lib dps.ReadLibrary) error {
+
+	version, err := Version(db, lib)
+	if errors.Is(err, dps.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get index version: %w", err)
+	}
+
+	if version < dps.IndexVersion {
+		return fmt.Errorf("index version is outdated, please run flow-dps-migrate (index: %d, supported: %d)", version, dps.IndexVersion)
+	}
+	if version > dps.IndexVersion {
+		return fmt.Errorf("index version is newer than supported, please upgrade (index: %d, supported: %d)", version, dps.IndexVersion)
+	}
+
+	return nil
+}
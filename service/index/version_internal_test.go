@@ -0,0 +1,104 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-dps/testing/helpers"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		name string
+
+		first   bool
+		version uint64
+
+		wantVersion uint64
+		checkErr    require.ErrorAssertionFunc
+		checkCheck  require.ErrorAssertionFunc
+	}{
+		{
+			name: "nominal case",
+
+			first:   true,
+			version: dps.IndexVersion,
+
+			wantVersion: dps.IndexVersion,
+			checkErr:    require.NoError,
+			checkCheck:  require.NoError,
+		},
+		{
+			name: "empty index",
+
+			checkErr:   require.Error,
+			checkCheck: require.NoError,
+		},
+		{
+			name: "index without version",
+
+			first: true,
+
+			wantVersion: 0,
+			checkErr:    require.NoError,
+			checkCheck:  require.Error,
+		},
+		{
+			name: "index with newer version",
+
+			first:   true,
+			version: dps.IndexVersion + 1,
+
+			wantVersion: dps.IndexVersion + 1,
+			checkErr:    require.NoError,
+			checkCheck:  require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := helpers.InMemoryDB(t)
+			defer db.Close()
+
+			lib := storage.New(zbor.NewCodec())
+			if test.first {
+				require.NoError(t, db.Update(lib.SaveFirst(mocks.GenericHeight)))
+			}
+			if test.version != 0 {
+				require.NoError(t, db.Update(lib.SaveVersion(test.version)))
+			}
+
+			version, err := Version(db, lib)
+			test.checkErr(t, err)
+			if err == nil {
+				assert.Equal(t, test.wantVersion, version)
+			}
+
+			err = CheckVersion(db, lib)
+			test.checkCheck(t, err)
+		})
+	}
+}
@@ -44,12 +44,16 @@ type Writer struct {
 
 	done  chan struct{}   // signals when no more new operations will be added
 	mutex *sync.Mutex     // guards the current transaction against concurrent access
-	wg    *sync.WaitGroup // keeps track of when the flush goroutine should exit
+	wg    *sync.WaitGroup // keeps track of when the flush and prune goroutines exit
+
+	floor   uint64        // retention floor of the last pruning run
+	pruning chan struct{} // holds a token while a pruning run is in progress
 }
 
 // NewWriter creates a new index writer that writes new indexing data to the
-// given Badger database.
-func NewWriter(db *badger.DB, lib dps.WriteLibrary, options ...func(*Config)) *Writer {
+// given Badger database. It continues pruning from the retention floor saved
+// in the database, if there is one.
+func NewWriter(db *badger.DB, lib dps.Library, options ...func(*Config)) *Writer {
 
 	cfg := DefaultConfig
 	for _, option := range options {
@@ -62,11 +66,23 @@ func NewWriter(db *badger.DB, lib dps.WriteLibrary, options ...func(*Config)) *W
 		cfg:  cfg,
 		tx:   db.NewTransaction(true),
 		sema: semaphore.NewWeighted(int64(cfg.ConcurrentTransactions)),
-		err:  make(chan error, cfg.ConcurrentTransactions),
+		err:  make(chan error, cfg.ConcurrentTransactions+1),
 
 		done:  make(chan struct{}),
 		mutex: &sync.Mutex{},
 		wg:    &sync.WaitGroup{},
+
+		floor:   0,
+		pruning: make(chan struct{}, 1),
+	}
+
+	// If the floor can't be read, we start from zero, which only means that
+	// the first pruning run happens earlier than needed; pruning again at the
+	// same floor or above it is harmless.
+	var floor uint64
+	err := db.View(lib.RetrieveFloor(&floor))
+	if err == nil {
+		w.floor = floor
 	}
 
 	// No flush interval means that flushing is disabled, and we only commit
@@ -81,14 +97,26 @@ func NewWriter(db *badger.DB, lib dps.WriteLibrary, options ...func(*Config)) *W
 	return &w
 }
 
-// First indexes the height of the first finalized block.
+// First indexes the height of the first finalized block. As it is written when
+// the index is bootstrapped, it also writes the version of the index schema and
+// codec used by this release.
 func (w *Writer) First(height uint64) error {
-	return w.apply(w.lib.SaveFirst(height))
+	return w.apply(w.lib.SaveVersion(dps.IndexVersion), w.lib.SaveFirst(height))
 }
 
-// Last indexes the height of the last finalized block.
+// Last indexes the height of the last finalized block. If pruning is enabled,
+// it also prunes the register history below the retention window in the
+// background.
 func (w *Writer) Last(height uint64) error {
-	return w.apply(w.lib.SaveLast(height))
+
+	err := w.apply(w.lib.SaveLast(height))
+	if err != nil {
+		return err
+	}
+
+	w.trim(height)
+
+	return nil
 }
 
 // Height indexes the height for the given block ID.
@@ -102,9 +130,13 @@ func (w *Writer) Commit(height uint64, commit flow.StateCommitment) error {
 	return w.apply(w.lib.SaveCommit(height, commit))
 }
 
-// Header indexes the given header of a finalized block at the given height.
+// Header indexes the given header of a finalized block at the given height,
+// as well as the height for the timestamp of the header.
 func (w *Writer) Header(height uint64, header *flow.Header) error {
-	return w.apply(w.lib.SaveHeader(height, header))
+	return w.apply(
+		w.lib.SaveHeader(height, header),
+		w.lib.IndexHeightForTime(header.Timestamp, height),
+	)
 }
 
 // Payloads indexes the given payloads, which should represent a trie update
@@ -116,11 +148,12 @@ func (w *Writer) Payloads(height uint64, paths []ledger.Path, payloads []*ledger
 		return fmt.Errorf("mismatch between paths and payloads counts")
 	}
 
-	ops := make([]func(*badger.Txn) error, 0, len(payloads))
+	ops := make([]func(*badger.Txn) error, 0, 2*len(payloads))
 
 	for i, path := range paths {
 		payload := payloads[i]
 		ops = append(ops, w.lib.SavePayload(height, path, payload))
+		ops = append(ops, w.lib.IndexPathForHeight(height, path))
 	}
 
 	return w.apply(ops...)
@@ -129,7 +162,7 @@ func (w *Writer) Payloads(height uint64, paths []ledger.Path, payloads []*ledger
 // Collections indexes the collections at the given height.
 func (w *Writer) Collections(height uint64, collections []*flow.LightCollection) error {
 
-	ops := make([]func(*badger.Txn) error, 0, 2*len(collections)+1)
+	ops := make([]func(*badger.Txn) error, 0, 3*len(collections)+1)
 
 	collIDs := make([]flow.Identifier, 0, len(collections))
 	for _, collection := range collections {
@@ -137,6 +170,7 @@ func (w *Writer) Collections(height uint64, collections []*flow.LightCollection)
 		collIDs = append(collIDs, collID)
 		ops = append(ops, w.lib.SaveCollection(collection))
 		ops = append(ops, w.lib.IndexTransactionsForCollection(collID, collection.Transactions))
+		ops = append(ops, w.lib.IndexHeightForCollection(collID, height))
 	}
 
 	ops = append(ops, w.lib.IndexCollectionsForHeight(height, collIDs))
@@ -155,10 +189,11 @@ func (w *Writer) Guarantees(_ uint64, guarantees []*flow.CollectionGuarantee) er
 	return w.apply(ops...)
 }
 
-// Transactions indexes the transactions at the given height.
+// Transactions indexes the transactions at the given height, as well as each
+// transaction for the accounts that took part in it.
 func (w *Writer) Transactions(height uint64, transactions []*flow.TransactionBody) error {
 
-	ops := make([]func(*badger.Txn) error, 0, 2*len(transactions)+1)
+	ops := make([]func(*badger.Txn) error, 0, 3*len(transactions)+1)
 
 	txIDs := make([]flow.Identifier, 0, len(transactions))
 	for _, transaction := range transactions {
@@ -166,6 +201,9 @@ func (w *Writer) Transactions(height uint64, transactions []*flow.TransactionBod
 		txIDs = append(txIDs, txID)
 		ops = append(ops, w.lib.SaveTransaction(transaction))
 		ops = append(ops, w.lib.IndexHeightForTransaction(txID, height))
+		for address, roles := range dps.Roles(transaction) {
+			ops = append(ops, w.lib.IndexTransactionForAccount(address, height, txID, roles))
+		}
 	}
 
 	ops = append(ops, w.lib.IndexTransactionsForHeight(height, txIDs))
@@ -186,7 +224,7 @@ func (w *Writer) Results(results []*flow.TransactionResult) error {
 }
 
 // Events indexes the events, which should represent all events of the finalized
-// block at the given height.
+// block at the given height, as well as the height for each of their types.
 func (w *Writer) Events(height uint64, events []flow.Event) error {
 
 	buckets := make(map[flow.EventType][]flow.Event)
@@ -194,10 +232,11 @@ func (w *Writer) Events(height uint64, events []flow.Event) error {
 		buckets[event.Type] = append(buckets[event.Type], event)
 	}
 
-	ops := make([]func(*badger.Txn) error, 0, len(buckets))
+	ops := make([]func(*badger.Txn) error, 0, 2*len(buckets))
 
 	for typ, set := range buckets {
 		ops = append(ops, w.lib.SaveEvents(height, typ, set))
+		ops = append(ops, w.lib.IndexHeightForEvent(typ, height))
 	}
 
 	return w.apply(ops...)
@@ -207,13 +246,14 @@ func (w *Writer) Events(height uint64, events []flow.Event) error {
 // block at the given height.
 func (w *Writer) Seals(height uint64, seals []*flow.Seal) error {
 
-	ops := make([]func(*badger.Txn) error, 0, len(seals)+1)
+	ops := make([]func(*badger.Txn) error, 0, 2*len(seals)+1)
 
 	sealIDs := make([]flow.Identifier, 0, len(seals))
 	for _, seal := range seals {
 		sealID := seal.ID()
 		sealIDs = append(sealIDs, sealID)
 		ops = append(ops, w.lib.SaveSeal(seal))
+		ops = append(ops, w.lib.IndexHeightForSeal(sealID, height))
 	}
 
 	ops = append(ops, w.lib.IndexSealsForHeight(height, sealIDs))
@@ -256,6 +296,50 @@ func (w *Writer) apply(ops ...func(*badger.Txn) error) error {
 	return nil
 }
 
+func (w *Writer) trim(height uint64) {
+
+	// A retention of zero means pruning is disabled; we also don't prune until
+	// we have indexed more heights than the retention window.
+	retention := w.cfg.PruneRetention
+	if retention == 0 || height <= retention {
+		return
+	}
+
+	// We only prune once the floor has advanced by the configured interval, as
+	// each run has to iterate over all of the register history.
+	floor := height - retention
+	if floor < w.floor+w.cfg.PruneInterval {
+		return
+	}
+
+	// If a previous run is still in progress, we skip this one; the next run
+	// will cover the heights up to its own floor.
+	select {
+	case w.pruning <- struct{}{}:
+	default:
+		return
+	}
+
+	w.floor = floor
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() { <-w.pruning }()
+
+		// Errors are reported to the apply function in the same way as
+		// errors from committing transactions. The error channel has one
+		// extra slot for this, so we never block the commit callbacks.
+		err := Prune(w.db, w.lib, floor, w.done)
+		if err != nil {
+			select {
+			case w.err <- fmt.Errorf("could not prune register history: %w", err):
+			default:
+			}
+		}
+	}()
+}
+
 func (w *Writer) committed(err error) {
 
 	// When a transaction is fully committed, we get the result in this
@@ -274,7 +358,7 @@ func (w *Writer) committed(err error) {
 func (w *Writer) Close() error {
 
 	// Shut down the ticker that makes sure we commit after a certain time
-	// without new operations, then drain the tick channel.
+	// without new operations, as well as any pruning run in progress.
 	close(w.done)
 	w.wg.Wait()
 
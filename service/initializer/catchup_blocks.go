@@ -35,7 +35,7 @@ func CatchupBlocks(db *badger.DB, read dps.Reader) ([]flow.Identifier, error) {
 	// We need to know for which blocks we don't need the execution records
 	// anymore, which is basically up to the last indexed block.
 	indexed, err := read.Last()
-	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+	if err != nil && !errors.Is(err, dps.ErrNotFound) {
 		return nil, fmt.Errorf("could not get last indexed: %w", err)
 	}
 
@@ -43,7 +43,7 @@ func CatchupBlocks(db *badger.DB, read dps.Reader) ([]flow.Identifier, error) {
 	// records just after root height (for all the blocks), so we put the
 	// last indexed height at root. If there is no root height, we don't need
 	// to catch up with anything, because the protocol state is also empty.
-	if errors.Is(err, badger.ErrKeyNotFound) {
+	if errors.Is(err, dps.ErrNotFound) {
 		var root uint64
 		err = db.View(operation.RetrieveRootHeight(&root))
 		if errors.Is(err, storage.ErrNotFound) {
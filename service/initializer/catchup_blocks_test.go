@@ -17,12 +17,12 @@ package initializer_test
 import (
 	"testing"
 
-	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/storage/badger/operation"
 
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/service/initializer"
 	"github.com/optakt/flow-dps/testing/helpers"
 	"github.com/optakt/flow-dps/testing/mocks"
@@ -71,7 +71,7 @@ func TestCatchupBlocks(t *testing.T) {
 
 		reader := mocks.BaselineReader(t)
 		reader.LastFunc = func() (uint64, error) {
-			return 0, badger.ErrKeyNotFound
+			return 0, dps.ErrNotFound
 		}
 
 		got, err := initializer.CatchupBlocks(db, reader)
@@ -113,7 +113,7 @@ func TestCatchupBlocks(t *testing.T) {
 
 		reader := mocks.BaselineReader(t)
 		reader.LastFunc = func() (uint64, error) {
-			return 0, badger.ErrKeyNotFound
+			return 0, dps.ErrNotFound
 		}
 
 		_, err := initializer.CatchupBlocks(db, reader)
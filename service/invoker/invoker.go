@@ -15,6 +15,7 @@
 package invoker
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/dgraph-io/ristretto"
@@ -77,10 +78,10 @@ func New(index dps.Reader, options ...func(*Config)) (*Invoker, error) {
 }
 
 // Key returns the public key of the account with the given address.
-func (i *Invoker) Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+func (i *Invoker) Key(ctx context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
 
 	// Retrieve the account at the specified block height.
-	account, err := i.Account(height, address)
+	account, err := i.Account(ctx, height, address)
 	if err != nil {
 		return nil, fmt.Errorf("could not retrieve account: %w", err)
 	}
@@ -104,27 +105,31 @@ func (i *Invoker) Key(height uint64, address flow.Address, index int) (*flow.Acc
 }
 
 // Account returns the account with the given address.
-func (i *Invoker) Account(height uint64, address flow.Address) (*flow.Account, error) {
+func (i *Invoker) Account(ctx context.Context, height uint64, address flow.Address) (*flow.Account, error) {
+
+	// Bind the index to the given context, so that the reads are aborted if
+	// the caller goes away.
+	index := bindIndex(ctx, i.index)
 
 	// Look up the current block and commit for the block.
-	header, err := i.index.Header(height)
+	header, err := index.Header(height)
 	if err != nil {
 		return nil, fmt.Errorf("could not get header: %w", err)
 	}
 
-	ctx := fvm.NewContext(zerolog.Nop(), fvm.WithBlockHeader(header))
+	vmCtx := fvm.NewContext(zerolog.Nop(), fvm.WithBlockHeader(header))
 
 	// Initialize the read function. We use a shared cache between all heights
 	// here. It's a smart cache, which means that items that are accessed often
 	// are more likely to be kept, regardless of height. This allows us to put
 	// an upper bound on total cache size while using it for all heights.
-	read := readRegister(i.index, i.cache, header.Height)
+	read := readRegister(index, i.cache, header.Height)
 
 	// Initialize the view of the execution state on top of the ledger by
 	// using the read function at a specific commit.
 	view := delta.NewView(read)
 
-	account, err := i.vm.GetAccount(ctx, address, view, programs.NewEmptyPrograms())
+	account, err := i.vm.GetAccount(vmCtx, address, view, programs.NewEmptyPrograms())
 	if err != nil {
 		return nil, fmt.Errorf("could not get account at height %d: %w", header.Height, err)
 	}
@@ -133,7 +138,7 @@ func (i *Invoker) Account(height uint64, address flow.Address) (*flow.Account, e
 }
 
 // Script executes the given Cadence script and returns its result.
-func (i *Invoker) Script(height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+func (i *Invoker) Script(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error) {
 
 	// Encode the arguments from Cadence values to byte slices.
 	var args [][]byte
@@ -145,21 +150,25 @@ func (i *Invoker) Script(height uint64, script []byte, arguments []cadence.Value
 		args = append(args, arg)
 	}
 
+	// Bind the index to the given context, so that the reads are aborted if
+	// the caller goes away.
+	index := bindIndex(ctx, i.index)
+
 	// Look up the current block and commit for the block.
-	header, err := i.index.Header(height)
+	header, err := index.Header(height)
 	if err != nil {
 		return nil, fmt.Errorf("could not get header: %w", err)
 	}
 
 	// Initialize the virtual machine context with the given block header so
 	// that parameters related to the block are available from within the script.
-	ctx := fvm.NewContext(zerolog.Nop(), fvm.WithBlockHeader(header))
+	vmCtx := fvm.NewContext(zerolog.Nop(), fvm.WithBlockHeader(header))
 
 	// Initialize the read function. We use a shared cache between all heights
 	// here. It's a smart cache, which means that items that are accessed often
 	// are more likely to be kept, regardless of height. This allows us to put
 	// an upper bound on total cache size while using it for all heights.
-	read := readRegister(i.index, i.cache, height)
+	read := readRegister(index, i.cache, height)
 
 	// Initialize the view of the execution state on top of the ledger by
 	// using the read function at a specific commit.
@@ -174,7 +183,7 @@ func (i *Invoker) Script(height uint64, script []byte, arguments []cadence.Value
 
 	// The script procedure is then run using the Flow virtual machine and all
 	// the constructed contextual parameters.
-	err = i.vm.Run(ctx, proc, view, programs)
+	err = i.vm.Run(vmCtx, proc, view, programs)
 	if err != nil {
 		return nil, fmt.Errorf("could not run script: %w", err)
 	}
@@ -184,3 +193,76 @@ func (i *Invoker) Script(height uint64, script []byte, arguments []cadence.Value
 
 	return proc.Value, nil
 }
+
+// Transaction executes the given transaction against the execution state at the
+// given height and returns its outcome, without persisting any of its changes.
+// Signatures and sequence numbers are not checked, so the transaction does not
+// need to be signed. Transaction fees are deducted from the payer as they would
+// be on the network, so a payer that cannot pay them makes the transaction fail,
+// and the fees are reported in the outcome.
+func (i *Invoker) Transaction(ctx context.Context, height uint64, tx *flow.TransactionBody) (*dps.DryRun, error) {
+
+	// Bind the index to the given context, so that the reads are aborted if
+	// the caller goes away.
+	index := bindIndex(ctx, i.index)
+
+	// Look up the current block and commit for the block.
+	header, err := index.Header(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", err)
+	}
+
+	// Initialize the virtual machine context with the given block header, and
+	// with a list of transaction processors that leaves out the verification
+	// of signatures and the check of sequence numbers. The fees are deducted by
+	// the transaction invocator, which needs the chain of the block to find
+	// the fees contract.
+	vmCtx := fvm.NewContext(zerolog.Nop(),
+		fvm.WithBlockHeader(header),
+		fvm.WithChain(header.ChainID.Chain()),
+		fvm.WithTransactionFeesEnabled(true),
+		fvm.WithTransactionProcessors(
+			fvm.NewTransactionAccountFrozenChecker(),
+			fvm.NewTransactionAccountFrozenEnabler(),
+			fvm.NewTransactionInvocator(zerolog.Nop()),
+		),
+	)
+
+	// Initialize the read function and the view on top of it. All writes of
+	// the transaction end up in the delta of the view, which is discarded
+	// once we have collected the write set.
+	read := readRegister(index, i.cache, height)
+	view := delta.NewView(read)
+
+	proc := fvm.Transaction(tx, 0)
+	err = i.vm.Run(vmCtx, proc, view, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, fmt.Errorf("could not run transaction: %w", err)
+	}
+
+	// Collect the register writes in a deterministic order.
+	regIDs, values := view.Delta().RegisterUpdates()
+	writes := make([]flow.RegisterEntry, 0, len(regIDs))
+	for i, regID := range regIDs {
+		write := flow.RegisterEntry{
+			Key:   regID,
+			Value: values[i],
+		}
+		writes = append(writes, write)
+	}
+
+	// The execution environment of this version of Flow charges a flat fee for
+	// every transaction, regardless of the computation it used.
+	run := dps.DryRun{
+		Failed:          proc.Err != nil,
+		Events:          proc.Events,
+		ComputationUsed: proc.ComputationUsed,
+		Fees:            uint64(fvm.DefaultTransactionFees),
+		Writes:          writes,
+	}
+	if proc.Err != nil {
+		run.ErrorMessage = proc.Err.Error()
+	}
+
+	return &run, nil
+}
@@ -15,6 +15,7 @@
 package invoker
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -91,7 +92,7 @@ func TestInvoker_Script(t *testing.T) {
 			cadence.NewUInt64(1337),
 		}
 
-		val, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, values)
+		val, err := invoke.Script(context.Background(), mocks.GenericHeight, mocks.GenericBytes, values)
 
 		require.NoError(t, err)
 		assert.Equal(t, testValue, val)
@@ -108,7 +109,7 @@ func TestInvoker_Script(t *testing.T) {
 		invoke := baselineInvoker(t)
 		invoke.index = index
 
-		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
+		_, err := invoke.Script(context.Background(), mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
 
 		assert.Error(t, err)
 	})
@@ -128,7 +129,7 @@ func TestInvoker_Script(t *testing.T) {
 		invoke := baselineInvoker(t)
 		invoke.vm = vm
 
-		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
+		_, err := invoke.Script(context.Background(), mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
 
 		assert.Error(t, err)
 	})
@@ -144,7 +145,117 @@ func TestInvoker_Script(t *testing.T) {
 		invoke := baselineInvoker(t)
 		invoke.vm = vm
 
-		_, err := invoke.Script(mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
+		_, err := invoke.Script(context.Background(), mocks.GenericHeight, mocks.GenericBytes, []cadence.Value{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestInvoker_Transaction(t *testing.T) {
+	tx := mocks.GenericTransaction(0)
+	regID := mocks.GenericDryRun.Writes[0].Key
+	value := mocks.GenericDryRun.Writes[0].Value
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+
+			return mocks.GenericHeader, nil
+		}
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(ctx fvm.Context, proc fvm.Procedure, v state.View, programs *programs.Programs) error {
+			assert.NotNil(t, programs)
+
+			// Make sure that neither signatures nor sequence numbers are checked.
+			for _, processor := range ctx.TransactionProcessors {
+				switch processor.(type) {
+				case *fvm.TransactionSignatureVerifier, *fvm.TransactionSequenceNumberChecker:
+					t.Errorf("unexpected transaction processor %T", processor)
+				}
+			}
+
+			// Make sure that fees are deducted on the chain of the block.
+			assert.True(t, ctx.TransactionFeesEnabled)
+			assert.Equal(t, mocks.GenericHeader.ChainID, ctx.Chain.ChainID())
+
+			require.IsType(t, proc, &fvm.TransactionProcedure{})
+			p := proc.(*fvm.TransactionProcedure)
+			assert.Equal(t, tx, p.Transaction)
+
+			err := v.Set(regID.Owner, regID.Controller, regID.Key, value)
+			require.NoError(t, err)
+
+			p.Events = mocks.GenericDryRun.Events
+			p.ComputationUsed = mocks.GenericDryRun.ComputationUsed
+
+			return nil
+		}
+
+		invoke := baselineInvoker(t)
+		invoke.index = index
+		invoke.vm = vm
+
+		run, err := invoke.Transaction(context.Background(), mocks.GenericHeight, tx)
+
+		require.NoError(t, err)
+		assert.Equal(t, &mocks.GenericDryRun, run)
+	})
+
+	t.Run("handles failed transaction", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(ctx fvm.Context, proc fvm.Procedure, v state.View, programs *programs.Programs) error {
+			require.IsType(t, proc, &fvm.TransactionProcedure{})
+			p := proc.(*fvm.TransactionProcedure)
+			p.Err = errors.NewEventLimitExceededError(2, 1)
+
+			return nil
+		}
+
+		invoke := baselineInvoker(t)
+		invoke.vm = vm
+
+		run, err := invoke.Transaction(context.Background(), mocks.GenericHeight, tx)
+
+		require.NoError(t, err)
+		assert.True(t, run.Failed)
+		assert.NotEmpty(t, run.ErrorMessage)
+		assert.Empty(t, run.Writes)
+	})
+
+	t.Run("handles indexer failure on Header", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+		index.HeaderFunc = func(uint64) (*flow.Header, error) {
+			return nil, mocks.GenericError
+		}
+
+		invoke := baselineInvoker(t)
+		invoke.index = index
+
+		_, err := invoke.Transaction(context.Background(), mocks.GenericHeight, tx)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles vm failure on Run", func(t *testing.T) {
+		t.Parallel()
+
+		vm := mocks.BaselineVirtualMachine(t)
+		vm.RunFunc = func(fvm.Context, fvm.Procedure, state.View, *programs.Programs) error {
+			return mocks.GenericError
+		}
+
+		invoke := baselineInvoker(t)
+		invoke.vm = vm
+
+		_, err := invoke.Transaction(context.Background(), mocks.GenericHeight, tx)
 
 		assert.Error(t, err)
 	})
@@ -175,7 +286,7 @@ func TestInvoker_Account(t *testing.T) {
 		invoke.vm = vm
 		invoke.index = index
 
-		account, err := invoke.Account(mocks.GenericHeight, mocks.GenericAccount.Address)
+		account, err := invoke.Account(context.Background(), mocks.GenericHeight, mocks.GenericAccount.Address)
 
 		require.NoError(t, err)
 		assert.Equal(t, &mocks.GenericAccount, account)
@@ -192,7 +303,7 @@ func TestInvoker_Account(t *testing.T) {
 		invoke := baselineInvoker(t)
 		invoke.index = index
 
-		_, err := invoke.Account(mocks.GenericHeight, mocks.GenericAccount.Address)
+		_, err := invoke.Account(context.Background(), mocks.GenericHeight, mocks.GenericAccount.Address)
 
 		assert.Error(t, err)
 	})
@@ -208,7 +319,7 @@ func TestInvoker_Account(t *testing.T) {
 		invoke := baselineInvoker(t)
 		invoke.vm = vm
 
-		_, err := invoke.Account(mocks.GenericHeight, mocks.GenericAccount.Address)
+		_, err := invoke.Account(context.Background(), mocks.GenericHeight, mocks.GenericAccount.Address)
 
 		assert.Error(t, err)
 	})
@@ -15,18 +15,27 @@
 package invoker
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/engine/execution/state/delta"
 	"github.com/onflow/flow-go/ledger"
-	"github.com/onflow/flow-go/ledger/common/pathfinder"
-	"github.com/onflow/flow-go/ledger/complete"
 	"github.com/onflow/flow-go/model/flow"
 
+	"github.com/optakt/flow-dps/models/convert"
 	"github.com/optakt/flow-dps/models/dps"
 )
 
+// bindIndex binds the given index to the given context if it supports it, so
+// that index reads over the network are aborted when the context is done.
+func bindIndex(ctx context.Context, index dps.Reader) dps.Reader {
+	reader, ok := index.(dps.ContextReader)
+	if !ok {
+		return index
+	}
+	return reader.WithContext(ctx)
+}
+
 func readRegister(index dps.Reader, cache Cache, height uint64) delta.GetRegisterFunc {
 	return func(owner string, controller string, key string) (flow.RegisterValue, error) {
 
@@ -37,9 +46,9 @@ func readRegister(index dps.Reader, cache Cache, height uint64) delta.GetRegiste
 		}
 
 		regID := flow.NewRegisterID(owner, controller, key)
-		path, err := pathfinder.KeyToPath(state.RegisterIDToKey(regID), complete.DefaultPathFinderVersion)
+		path, err := convert.RegisterIDToPath(regID)
 		if err != nil {
-			return nil, fmt.Errorf("could not convert key to path: %w", err)
+			return nil, fmt.Errorf("could not convert register ID: %w", err)
 		}
 
 		values, err := index.Values(height, []ledger.Path{path})
@@ -15,15 +15,59 @@
 package invoker
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/testing/mocks"
 )
 
+type contextReader struct {
+	*mocks.Reader
+	bind func(ctx context.Context) dps.Reader
+}
+
+func (c *contextReader) WithContext(ctx context.Context) dps.Reader {
+	return c.bind(ctx)
+}
+
+func TestBindIndex(t *testing.T) {
+	t.Run("nominal case with context reader", func(t *testing.T) {
+		t.Parallel()
+
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, mocks.GenericHeight)
+
+		bound := mocks.BaselineReader(t)
+		index := &contextReader{
+			Reader: mocks.BaselineReader(t),
+			bind: func(got context.Context) dps.Reader {
+				assert.Equal(t, mocks.GenericHeight, got.Value(key{}))
+				return bound
+			},
+		}
+
+		got := bindIndex(ctx, index)
+
+		assert.Same(t, bound, got)
+	})
+
+	t.Run("nominal case without context reader", func(t *testing.T) {
+		t.Parallel()
+
+		index := mocks.BaselineReader(t)
+
+		got := bindIndex(context.Background(), index)
+
+		assert.Same(t, index, got)
+	})
+}
+
 func TestReadRegister(t *testing.T) {
 	owner := string(mocks.GenericLedgerKey.KeyParts[0].Value)
 	controller := string(mocks.GenericLedgerKey.KeyParts[1].Value)
@@ -75,3 +75,12 @@ func ExcludeAtOrBelow(threshold uint64) Exclude {
 		return height <= threshold
 	}
 }
+
+// ExcludeAbove is an exclude function that ignores heights above the given
+// threshold height. It can be used to restore the execution state trie as it
+// was after the given height, for example to verify its state commitment.
+func ExcludeAbove(threshold uint64) Exclude {
+	return func(height uint64) bool {
+		return height > threshold
+	}
+}
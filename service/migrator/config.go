@@ -0,0 +1,35 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package migrator
+
+// DefaultConfig is the default configuration for the migrator.
+var DefaultConfig = Config{
+	BatchSize: 1000,
+}
+
+// Config contains the configuration options for the migrator.
+type Config struct {
+	BatchSize uint
+}
+
+// WithBatchSize sets the number of entries that a migration step processes in
+// a single transaction. Progress is stored after each batch, so it is also the
+// maximum amount of work that is repeated when an interrupted migration is
+// resumed.
+func WithBatchSize(size uint) func(*Config) {
+	return func(cfg *Config) {
+		cfg.BatchSize = size
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package migrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+)
+
+// Migrator upgrades an index to the version of the index schema and codec used
+// by this release, by applying the registered migration steps in order. Each
+// step processes its entries in batches and stores its progress after each
+// batch, so that an interrupted migration can be resumed where it stopped.
+type Migrator struct {
+	log   zerolog.Logger
+	db    *badger.DB
+	lib   dps.Library
+	cfg   Config
+	steps map[uint64]Step
+	stop  chan struct{}
+	wg    *sync.WaitGroup
+}
+
+// New creates a new migrator for the index in the given database.
+func New(log zerolog.Logger, db *badger.DB, lib dps.Library, options ...func(*Config)) *Migrator {
+
+	cfg := DefaultConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	m := Migrator{
+		log:   log.With().Str("component", "migrator").Logger(),
+		db:    db,
+		lib:   lib,
+		cfg:   cfg,
+		steps: make(map[uint64]Step),
+		stop:  make(chan struct{}),
+		wg:    &sync.WaitGroup{},
+	}
+
+	return &m
+}
+
+// Register registers the given migration step. There can only be one step for
+// each version, and steps can't upgrade the index beyond the version supported
+// by this release.
+func (m *Migrator) Register(step Step) error {
+
+	if step.Version == 0 || step.Version > dps.IndexVersion {
+		return fmt.Errorf("invalid step version (version: %d, supported: %d)", step.Version, dps.IndexVersion)
+	}
+
+	_, ok := m.steps[step.Version]
+	if ok {
+		return fmt.Errorf("duplicate step version (version: %d)", step.Version)
+	}
+
+	m.steps[step.Version] = step
+
+	return nil
+}
+
+// Run applies the migration steps needed to upgrade the index to the version
+// supported by this release. It returns without error if the migration is
+// stopped, in which case it can be resumed by running it again.
+func (m *Migrator) Run() error {
+	m.wg.Add(1)
+	defer m.wg.Done()
+
+	if m.cfg.BatchSize == 0 {
+		return fmt.Errorf("invalid batch size (%d)", m.cfg.BatchSize)
+	}
+
+	version, err := index.Version(m.db, m.lib)
+	if errors.Is(err, dps.ErrNotFound) {
+		m.log.Info().Msg("index is empty, nothing to migrate")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not get index version: %w", err)
+	}
+	if version > dps.IndexVersion {
+		return fmt.Errorf("index version is newer than supported (index: %d, supported: %d)", version, dps.IndexVersion)
+	}
+
+	// Make sure that we have all of the steps we need before we start, so
+	// that we don't leave the index at an intermediate version.
+	for next := version + 1; next <= dps.IndexVersion; next++ {
+		_, ok := m.steps[next]
+		if !ok {
+			return fmt.Errorf("missing migration step (version: %d)", next)
+		}
+	}
+
+	m.log.Info().Uint64("from", version).Uint64("to", dps.IndexVersion).Msg("starting index migration")
+
+	for next := version + 1; next <= dps.IndexVersion; next++ {
+		step := m.steps[next]
+		done, err := m.apply(step)
+		if err != nil {
+			return fmt.Errorf("could not apply migration step (version: %d): %w", step.Version, err)
+		}
+		if !done {
+			m.log.Info().Uint64("version", step.Version).Msg("index migration stopped, run it again to resume")
+			return nil
+		}
+		m.log.Info().Uint64("version", step.Version).Str("description", step.Description).Msg("migration step applied")
+	}
+
+	m.log.Info().Uint64("version", dps.IndexVersion).Msg("index migration complete")
+
+	return nil
+}
+
+// Stop stops the migration after the batch that is currently being processed
+// and waits for it to be committed.
+func (m *Migrator) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// apply applies the given step and returns whether it was completed.
+func (m *Migrator) apply(step Step) (bool, error) {
+
+	// Steps without rewrite or index function only update the version of the
+	// index.
+	if step.Rewrite == nil && step.Index == nil {
+		err := m.db.Update(m.lib.SaveVersion(step.Version))
+		if err != nil {
+			return false, fmt.Errorf("could not save version: %w", err)
+		}
+		return true, nil
+	}
+
+	// If the step was interrupted before, we continue after the last entry
+	// that was processed.
+	var cursor []byte
+	err := m.db.View(m.lib.RetrieveMigration(step.Version, &cursor))
+	if err != nil && !errors.Is(err, dps.ErrNotFound) {
+		return false, fmt.Errorf("could not retrieve migration cursor: %w", err)
+	}
+	if len(cursor) > 0 {
+		m.log.Info().Uint64("version", step.Version).Hex("cursor", cursor).Msg("resuming migration step")
+	}
+
+	for {
+		select {
+		case <-m.stop:
+			return false, nil
+		default:
+			// continue
+		}
+
+		var done bool
+		err = m.db.Update(func(tx *badger.Txn) error {
+			var err error
+			cursor, done, err = m.batch(tx, step, cursor)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("could not process batch: %w", err)
+		}
+		if done {
+			return true, nil
+		}
+	}
+}
+
+// batch processes the next batch of entries of the given step after the given
+// cursor, and returns the new cursor and whether the step is complete. The
+// progress is stored in the same transaction as the rewritten entries, and the
+// version is updated in the same transaction as the last batch.
+func (m *Migrator) batch(tx *badger.Txn, step Step, cursor []byte) ([]byte, bool, error) {
+
+	type entry struct {
+		key   []byte
+		value []byte
+	}
+
+	// We first collect the entries of the batch and close the iterator, so
+	// that the rewritten entries do not show up in the iteration.
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte{step.Prefix}
+	it := tx.NewIterator(opts)
+	start := opts.Prefix
+	if cursor != nil {
+		start = cursor
+	}
+	var entries []entry
+	for it.Seek(start); it.ValidForPrefix(opts.Prefix) && uint(len(entries)) < m.cfg.BatchSize; it.Next() {
+		item := it.Item()
+		if bytes.Equal(item.Key(), cursor) {
+			continue
+		}
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			it.Close()
+			return nil, false, fmt.Errorf("could not copy value (key: %x): %w", item.Key(), err)
+		}
+		entries = append(entries, entry{key: item.KeyCopy(nil), value: value})
+	}
+	it.Close()
+
+	for _, entry := range entries {
+		err := m.rewrite(tx, step, entry.key, entry.value)
+		if err != nil {
+			return nil, false, err
+		}
+		err = m.index(tx, step, entry.key, entry.value)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// If the batch is not full, we have reached the end of the prefix, and the
+	// index is now at the version of the step.
+	if uint(len(entries)) < m.cfg.BatchSize {
+		err := m.lib.SaveVersion(step.Version)(tx)
+		if err != nil {
+			return nil, false, fmt.Errorf("could not save version: %w", err)
+		}
+		return nil, true, nil
+	}
+
+	cursor = entries[len(entries)-1].key
+	err := m.lib.SaveMigration(step.Version, cursor)(tx)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not save migration cursor: %w", err)
+	}
+
+	return cursor, false, nil
+}
+
+// rewrite replaces the given entry with the entry returned by the rewrite
+// function of the given step, if it has one.
+func (m *Migrator) rewrite(tx *badger.Txn, step Step, key []byte, value []byte) error {
+
+	if step.Rewrite == nil {
+		return nil
+	}
+
+	rewritten, value, err := step.Rewrite(key, value)
+	if err != nil {
+		return fmt.Errorf("could not rewrite entry (key: %x): %w", key, err)
+	}
+	if !bytes.Equal(rewritten, key) {
+		err = tx.Delete(key)
+		if err != nil {
+			return fmt.Errorf("could not delete entry (key: %x): %w", key, err)
+		}
+	}
+	if rewritten == nil {
+		return nil
+	}
+	err = tx.Set(rewritten, value)
+	if err != nil {
+		return fmt.Errorf("could not set entry (key: %x): %w", rewritten, err)
+	}
+
+	return nil
+}
+
+// index writes the additional entries of the index function of the given step
+// for the given entry, if it has one.
+func (m *Migrator) index(tx *badger.Txn, step Step, key []byte, value []byte) error {
+
+	if step.Index == nil {
+		return nil
+	}
+
+	err := step.Index(tx, m.lib, key, value)
+	if err != nil {
+		return fmt.Errorf("could not index entry (key: %x): %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,551 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-dps/testing/helpers"
+	"github.com/optakt/flow-dps/testing/mocks"
+)
+
+const (
+	testPrefix  = 0xf0
+	movedPrefix = 0xf1
+)
+
+func TestNew(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	lib := storage.New(zbor.NewCodec())
+
+	m := New(mocks.NoopLogger, db, lib, WithBatchSize(42))
+
+	assert.Equal(t, db, m.db)
+	assert.Equal(t, lib, m.lib)
+	assert.Equal(t, uint(42), m.cfg.BatchSize)
+	assert.Empty(t, m.steps)
+	assert.NotNil(t, m.stop)
+	assert.NotNil(t, m.wg)
+}
+
+func TestMigrator_Register(t *testing.T) {
+	tests := []struct {
+		name string
+
+		steps []Step
+
+		checkErr require.ErrorAssertionFunc
+	}{
+		{
+			name:     "nominal case",
+			steps:    Steps(),
+			checkErr: require.NoError,
+		},
+		{
+			name:     "handles zero version",
+			steps:    []Step{{Version: 0}},
+			checkErr: require.Error,
+		},
+		{
+			name:     "handles unsupported version",
+			steps:    []Step{{Version: dps.IndexVersion + 1}},
+			checkErr: require.Error,
+		},
+		{
+			name:     "handles duplicate version",
+			steps:    []Step{{Version: 1}, {Version: 1}},
+			checkErr: require.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := Migrator{steps: make(map[uint64]Step)}
+
+			var err error
+			for _, step := range test.steps {
+				err = m.Register(step)
+				if err != nil {
+					break
+				}
+			}
+
+			test.checkErr(t, err)
+		})
+	}
+}
+
+func TestMigrator_Run(t *testing.T) {
+
+	// double rewrites each entry in place, doubling its value.
+	double := func(key []byte, value []byte) ([]byte, []byte, error) {
+		return key, []byte{value[0] * 2}, nil
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: double})
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+		assert.Equal(t, map[byte]byte{0: 0, 1: 2, 2: 4, 3: 6, 4: 8}, entries(t, db, testPrefix))
+	})
+
+	t.Run("moves and deletes entries", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		move := func(key []byte, value []byte) ([]byte, []byte, error) {
+			if value[0]%2 == 1 {
+				return nil, nil, nil
+			}
+			return []byte{movedPrefix, key[1]}, value, nil
+		}
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: move})
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+		assert.Empty(t, entries(t, db, testPrefix))
+		assert.Equal(t, map[byte]byte{0: 0, 2: 2, 4: 4}, entries(t, db, movedPrefix))
+	})
+
+	t.Run("writes index entries", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		index := func(tx *badger.Txn, _ dps.Library, key []byte, value []byte) error {
+			if value[0]%2 == 1 {
+				return nil
+			}
+			return tx.Set([]byte{movedPrefix, key[1]}, value)
+		}
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Index: index})
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+		assert.Equal(t, map[byte]byte{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}, entries(t, db, testPrefix))
+		assert.Equal(t, map[byte]byte{0: 0, 2: 2, 4: 4}, entries(t, db, movedPrefix))
+	})
+
+	t.Run("backfills paths for heights", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 0)
+		defer db.Close()
+
+		paths := mocks.GenericLedgerPaths(2)
+		payloads := mocks.GenericLedgerPayloads(3)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveVersion(4)))
+		require.NoError(t, db.Update(lib.SavePayload(mocks.GenericHeight, paths[0], payloads[0])))
+		require.NoError(t, db.Update(lib.SavePayload(mocks.GenericHeight, paths[1], payloads[1])))
+		require.NoError(t, db.Update(lib.SavePayload(mocks.GenericHeight+1, paths[0], payloads[2])))
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+
+		var got []ledger.Path
+		op := lib.IterateStateDiff(mocks.GenericHeight, mocks.GenericHeight+1, func(path ledger.Path, _ *ledger.Payload, _ *ledger.Payload) error {
+			got = append(got, path)
+			return nil
+		})
+		require.NoError(t, db.View(op))
+		assert.Equal(t, []ledger.Path{paths[0]}, got)
+	})
+
+	t.Run("backfills event heights", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 0)
+		defer db.Close()
+
+		types := mocks.GenericEventTypes(2)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveVersion(5)))
+		require.NoError(t, db.Update(lib.SaveEvents(mocks.GenericHeight, types[0], mocks.GenericEvents(1, types[0]))))
+		require.NoError(t, db.Update(lib.SaveEvents(mocks.GenericHeight+1, types[1], mocks.GenericEvents(1, types[1]))))
+		require.NoError(t, db.Update(lib.SaveEvents(mocks.GenericHeight+2, types[0], mocks.GenericEvents(1, types[0]))))
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+
+		var heights []uint64
+		require.NoError(t, db.View(lib.LookupHeightsForEvent(types[0], mocks.GenericHeight, mocks.GenericHeight+2, &heights)))
+		assert.Equal(t, []uint64{mocks.GenericHeight, mocks.GenericHeight + 2}, heights)
+	})
+
+	t.Run("backfills transactions for accounts", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 0)
+		defer db.Close()
+
+		addresses := mocks.GenericAddresses(3)
+		transactions := mocks.GenericTransactions(2)
+		transactions[0].ProposalKey.Address = addresses[0]
+		transactions[0].Payer = addresses[0]
+		transactions[0].Authorizers = []flow.Address{addresses[1]}
+		transactions[1].ProposalKey.Address = addresses[1]
+		transactions[1].Payer = addresses[2]
+		transactions[1].Authorizers = []flow.Address{addresses[1]}
+
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveVersion(6)))
+		for i, transaction := range transactions {
+			require.NoError(t, db.Update(lib.SaveTransaction(transaction)))
+			require.NoError(t, db.Update(lib.IndexTransactionsForHeight(mocks.GenericHeight+uint64(i), []flow.Identifier{transaction.ID()})))
+		}
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+
+		var got []dps.AccountTransaction
+		require.NoError(t, db.View(lib.LookupTransactionsForAccount(addresses[1], mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 0, &got)))
+		want := []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: transactions[0].ID(), Roles: dps.RoleAuthorizer},
+			{Height: mocks.GenericHeight + 1, TransactionID: transactions[1].ID(), Roles: dps.RoleProposer | dps.RoleAuthorizer},
+		}
+		assert.Equal(t, want, got)
+
+		got = nil
+		require.NoError(t, db.View(lib.LookupTransactionsForAccount(addresses[0], mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 0, &got)))
+		want = []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: transactions[0].ID(), Roles: dps.RoleProposer | dps.RolePayer},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("backfills heights for timestamps", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 0)
+		defer db.Close()
+
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveVersion(1)))
+		for i := uint64(0); i < 3; i++ {
+			header := *mocks.GenericHeader
+			header.Height = mocks.GenericHeight + i
+			header.Timestamp = mocks.GenericHeader.Timestamp.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, db.Update(lib.SaveHeader(header.Height, &header)))
+		}
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+
+		var height uint64
+		require.NoError(t, db.View(lib.LookupHeightForTime(mocks.GenericHeader.Timestamp.Add(90*time.Second), &height)))
+		assert.Equal(t, mocks.GenericHeight+1, height)
+	})
+
+	t.Run("backfills heights for collections and seals", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 0)
+		defer db.Close()
+
+		collIDs := mocks.GenericCollectionIDs(4)
+		sealIDs := mocks.GenericSealIDs(4)
+		lib := storage.New(zbor.NewCodec())
+		require.NoError(t, db.Update(lib.SaveVersion(2)))
+		for i := uint64(0); i < 2; i++ {
+			require.NoError(t, db.Update(lib.IndexCollectionsForHeight(mocks.GenericHeight+i, collIDs[2*i:2*i+2])))
+			require.NoError(t, db.Update(lib.IndexSealsForHeight(mocks.GenericHeight+i, sealIDs[2*i:2*i+2])))
+		}
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+
+		for i, collID := range collIDs {
+			var height uint64
+			require.NoError(t, db.View(lib.LookupHeightForCollection(collID, &height)))
+			assert.Equal(t, mocks.GenericHeight+uint64(i/2), height)
+		}
+		for i, sealID := range sealIDs {
+			var height uint64
+			require.NoError(t, db.View(lib.LookupHeightForSeal(sealID, &height)))
+			assert.Equal(t, mocks.GenericHeight+uint64(i/2), height)
+		}
+	})
+
+	t.Run("resumes after last processed entry", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		require.NoError(t, db.Update(m.lib.SaveMigration(1, []byte{testPrefix, 2})))
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: double})
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+		assert.Equal(t, map[byte]byte{0: 0, 1: 1, 2: 2, 3: 6, 4: 8}, entries(t, db, testPrefix))
+	})
+
+	t.Run("only updates version without rewrite", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		for _, step := range Steps() {
+			require.NoError(t, m.Register(step))
+		}
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, dps.IndexVersion, version(t, m))
+		assert.Equal(t, map[byte]byte{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}, entries(t, db, testPrefix))
+	})
+
+	t.Run("skips up to date index", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		require.NoError(t, db.Update(m.lib.SaveVersion(dps.IndexVersion)))
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, map[byte]byte{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}, entries(t, db, testPrefix))
+	})
+
+	t.Run("skips empty index", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, false, 0)
+		defer db.Close()
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		var got uint64
+		assert.ErrorIs(t, db.View(m.lib.RetrieveVersion(&got)), dps.ErrNotFound)
+	})
+
+	t.Run("stops before next batch", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: double})
+		m.Stop()
+
+		err := m.Run()
+
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), version(t, m))
+		assert.Equal(t, map[byte]byte{0: 0, 1: 1, 2: 2, 3: 3, 4: 4}, entries(t, db, testPrefix))
+	})
+
+	t.Run("handles missing step", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		err := m.Run()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles newer index version", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		require.NoError(t, db.Update(m.lib.SaveVersion(dps.IndexVersion+1)))
+
+		err := m.Run()
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles rewrite failure", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		fail := func([]byte, []byte) ([]byte, []byte, error) {
+			return nil, nil, mocks.GenericError
+		}
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: fail})
+
+		err := m.Run()
+
+		assert.Error(t, err)
+		assert.Equal(t, uint64(0), version(t, m))
+	})
+
+	t.Run("handles invalid batch size", func(t *testing.T) {
+		t.Parallel()
+
+		m, db := setupMigrator(t, true, 5)
+		defer db.Close()
+
+		m.cfg.BatchSize = 0
+		register(t, m, Step{Version: 1, Prefix: testPrefix, Rewrite: double})
+
+		err := m.Run()
+
+		assert.Error(t, err)
+	})
+}
+
+// setupMigrator creates a migrator with a batch size of two on top of an index
+// without version, which has the given number of entries with the test prefix.
+func setupMigrator(t *testing.T, first bool, count int) (*Migrator, *badger.DB) {
+	t.Helper()
+
+	db := helpers.InMemoryDB(t)
+	lib := storage.New(zbor.NewCodec())
+
+	err := db.Update(func(tx *badger.Txn) error {
+		if first {
+			err := lib.SaveFirst(mocks.GenericHeight)(tx)
+			if err != nil {
+				return err
+			}
+		}
+		for i := 0; i < count; i++ {
+			err := tx.Set([]byte{testPrefix, byte(i)}, []byte{byte(i)})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	m := New(mocks.NoopLogger, db, lib, WithBatchSize(2))
+
+	return m, db
+}
+
+// register registers the given step for the first version, along with the
+// released steps for all later versions, so that the index can be migrated up
+// to the version supported by this release.
+func register(t *testing.T, m *Migrator, step Step) {
+	t.Helper()
+
+	require.NoError(t, m.Register(step))
+	for _, released := range Steps() {
+		if released.Version == step.Version {
+			continue
+		}
+		require.NoError(t, m.Register(released))
+	}
+}
+
+// version returns the version of the index, where a missing version is zero.
+func version(t *testing.T, m *Migrator) uint64 {
+	t.Helper()
+
+	var version uint64
+	err := m.db.View(m.lib.RetrieveVersion(&version))
+	if err != nil {
+		require.ErrorIs(t, err, dps.ErrNotFound)
+	}
+
+	return version
+}
+
+// entries returns the value of each entry with the given prefix, keyed by the
+// second byte of its key.
+func entries(t *testing.T, db *badger.DB, prefix byte) map[byte]byte {
+	t.Helper()
+
+	entries := make(map[byte]byte)
+	err := db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte{prefix}
+		it := tx.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			entries[it.Item().Key()[1]] = value[0]
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	return entries
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package migrator
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/storage"
+)
+
+// Step is a migration step, which upgrades the index from the version before
+// it to its version. If it has a rewrite function, it is called for each entry
+// whose key starts with the prefix of the step, and returns the key and value
+// that should replace the entry. When the returned key is nil, the entry is
+// deleted; when it differs from the original key, the entry is moved. Keys
+// should not be moved to a position after the original key within the same
+// prefix, as the entry would then be processed again. If it has an index
+// function, it is called for each of the same entries within the transaction
+// of the batch, and can read from the index and write additional entries to
+// backfill new indexes. A step without rewrite or index function only updates
+// the version of the index.
+type Step struct {
+	Version     uint64
+	Description string
+	Prefix      uint8
+	Rewrite     func(key []byte, value []byte) ([]byte, []byte, error)
+	Index       func(tx *badger.Txn, lib dps.Library, key []byte, value []byte) error
+}
+
+// Steps returns the migration steps for all versions of the index schema and
+// codec that were released. Indexes created before the version was stored
+// have version zero and share the layout of the first version.
+func Steps() []Step {
+	steps := []Step{
+		{
+			Version:     1,
+			Description: "store index version",
+		},
+		{
+			Version:     2,
+			Description: "index heights for block timestamps",
+			Prefix:      storage.PrefixHeader,
+			Index:       indexTimeHeights,
+		},
+		{
+			Version:     3,
+			Description: "index heights for collections",
+			Prefix:      storage.PrefixCollectionsForHeight,
+			Index:       indexCollectionHeights,
+		},
+		{
+			Version:     4,
+			Description: "index heights for seals",
+			Prefix:      storage.PrefixSealsForHeight,
+			Index:       indexSealHeights,
+		},
+		{
+			Version:     5,
+			Description: "index paths for heights",
+			Prefix:      storage.PrefixPayload,
+			Index:       indexHeightPaths,
+		},
+		{
+			Version:     6,
+			Description: "index heights for event types",
+			Prefix:      storage.PrefixEvents,
+			Index:       indexEventHeights,
+		},
+		{
+			Version:     7,
+			Description: "index transactions for accounts",
+			Prefix:      storage.PrefixTransactionsForHeight,
+			Index:       indexAccountTransactions,
+		},
+	}
+
+	return steps
+}
+
+// indexTimeHeights indexes the height of the given entry of the header index
+// for the timestamp of its block, which requires retrieving the header.
+func indexTimeHeights(tx *badger.Txn, lib dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+8 {
+		return fmt.Errorf("invalid header key length (length: %d)", len(key))
+	}
+
+	height := binary.BigEndian.Uint64(key[1:])
+	var header flow.Header
+	err := lib.RetrieveHeader(height, &header)(tx)
+	if err != nil {
+		return fmt.Errorf("could not retrieve header (height: %d): %w", height, err)
+	}
+
+	err = lib.IndexHeightForTime(header.Timestamp, height)(tx)
+	if err != nil {
+		return fmt.Errorf("could not index height for time (height: %d): %w", height, err)
+	}
+
+	return nil
+}
+
+// indexCollectionHeights indexes the height of the given entry of the block
+// collections index for each of the collections it includes.
+func indexCollectionHeights(tx *badger.Txn, lib dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+8 {
+		return fmt.Errorf("invalid collections key length (length: %d)", len(key))
+	}
+
+	height := binary.BigEndian.Uint64(key[1:])
+	var collIDs []flow.Identifier
+	err := lib.LookupCollectionsForHeight(height, &collIDs)(tx)
+	if err != nil {
+		return fmt.Errorf("could not look up collections (height: %d): %w", height, err)
+	}
+
+	for _, collID := range collIDs {
+		err = lib.IndexHeightForCollection(collID, height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not index height for collection (id: %x): %w", collID, err)
+		}
+	}
+
+	return nil
+}
+
+// indexSealHeights indexes the height of the given entry of the block seals
+// index for each of the seals it includes.
+func indexSealHeights(tx *badger.Txn, lib dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+8 {
+		return fmt.Errorf("invalid seals key length (length: %d)", len(key))
+	}
+
+	height := binary.BigEndian.Uint64(key[1:])
+	var sealIDs []flow.Identifier
+	err := lib.LookupSealsForHeight(height, &sealIDs)(tx)
+	if err != nil {
+		return fmt.Errorf("could not look up seals (height: %d): %w", height, err)
+	}
+
+	for _, sealID := range sealIDs {
+		err = lib.IndexHeightForSeal(sealID, height)(tx)
+		if err != nil {
+			return fmt.Errorf("could not index height for seal (id: %x): %w", sealID, err)
+		}
+	}
+
+	return nil
+}
+
+// indexHeightPaths derives the key of the height paths index from the key of
+// the payloads index, which both consist of the ledger path and the height, in
+// opposite order.
+func indexHeightPaths(tx *badger.Txn, _ dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+pathfinder.PathByteSize+8 {
+		return fmt.Errorf("invalid payload key length (length: %d)", len(key))
+	}
+
+	index := make([]byte, 0, len(key))
+	index = append(index, storage.PrefixPathsForHeight)
+	index = append(index, key[1+pathfinder.PathByteSize:]...)
+	index = append(index, key[1:1+pathfinder.PathByteSize]...)
+
+	return tx.Set(index, nil)
+}
+
+// indexEventHeights derives the key of the event heights index from the key of
+// the events index, which both consist of the height and the event type hash,
+// in opposite order.
+func indexEventHeights(tx *badger.Txn, _ dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+8+8 {
+		return fmt.Errorf("invalid events key length (length: %d)", len(key))
+	}
+
+	index := make([]byte, 0, len(key))
+	index = append(index, storage.PrefixHeightsForEvent)
+	index = append(index, key[1+8:]...)
+	index = append(index, key[1:1+8]...)
+
+	return tx.Set(index, nil)
+}
+
+// indexAccountTransactions indexes each transaction at the height of the given
+// entry of the transactions by height index for the accounts that took part in
+// it, which requires retrieving the transaction bodies.
+func indexAccountTransactions(tx *badger.Txn, lib dps.Library, key []byte, _ []byte) error {
+
+	if len(key) != 1+8 {
+		return fmt.Errorf("invalid transactions key length (length: %d)", len(key))
+	}
+
+	height := binary.BigEndian.Uint64(key[1:])
+	var txIDs []flow.Identifier
+	err := lib.LookupTransactionsForHeight(height, &txIDs)(tx)
+	if err != nil {
+		return fmt.Errorf("could not look up transactions (height: %d): %w", height, err)
+	}
+
+	for _, txID := range txIDs {
+		var transaction flow.TransactionBody
+		err = lib.RetrieveTransaction(txID, &transaction)(tx)
+		if err != nil {
+			return fmt.Errorf("could not retrieve transaction (id: %x): %w", txID, err)
+		}
+		for address, roles := range dps.Roles(&transaction) {
+			err = lib.IndexTransactionForAccount(address, height, txID, roles)(tx)
+			if err != nil {
+				return fmt.Errorf("could not index transaction for account (address: %s): %w", address, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -15,10 +15,16 @@
 package storage
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/hashicorp/go-multierror"
+
+	"github.com/onflow/flow-go/ledger"
+
+	"github.com/optakt/flow-dps/models/dps"
 )
 
 // Fallback goes through the provided operations until one of them succeeds.
@@ -54,9 +60,29 @@ func Combine(ops ...func(*badger.Txn) error) func(*badger.Txn) error {
 	}
 }
 
+// previousPath returns the ledger path that directly precedes the given path.
+// If the given path is the zero path, it returns false instead of wrapping
+// around to the highest path.
+func previousPath(path ledger.Path) (ledger.Path, bool) {
+	var zero ledger.Path
+	if path == zero {
+		return path, false
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i] = path[i] - 1
+		if path[i] != 0xff {
+			break
+		}
+	}
+	return path, true
+}
+
 func (l *Library) retrieve(key []byte, v interface{}) func(tx *badger.Txn) error {
 	return func(tx *badger.Txn) error {
 		item, err := tx.Get(key)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return fmt.Errorf("could not get value (key: %x): %w", key, dps.ErrNotFound)
+		}
 		if err != nil {
 			return fmt.Errorf("could not get value (key: %x): %w", key, err)
 		}
@@ -87,3 +113,40 @@ func (l *Library) save(key []byte, value interface{}) func(*badger.Txn) error {
 		return nil
 	}
 }
+
+// iterate steps through all keys with the given prefix that are followed by a
+// height between the given start and end heights, both inclusive, and calls the
+// given callback with the key and the value of each of them.
+func (l *Library) iterate(prefix uint8, start uint64, end uint64, process func(key []byte, val []byte) error) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		opts := badger.DefaultIteratorOptions
+		// NOTE: this is an optimization only, it does not enforce that all
+		// results in the iteration have this prefix.
+		opts.Prefix = EncodeKey(prefix)
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// As heights are encoded in big endian, the keys are sorted by height,
+		// and we can stop as soon as we go past the end height.
+		for it.Seek(EncodeKey(prefix, start)); it.ValidForPrefix(opts.Prefix); it.Next() {
+
+			item := it.Item()
+			key := item.Key()
+			height := binary.BigEndian.Uint64(key[1:9])
+			if height > end {
+				break
+			}
+
+			err := item.Value(func(val []byte) error {
+				return process(key, val)
+			})
+			if err != nil {
+				return fmt.Errorf("could not process value (key: %x): %w", key, err)
+			}
+		}
+
+		return nil
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/testing/helpers"
 	"github.com/optakt/flow-dps/testing/mocks"
 )
@@ -73,7 +74,7 @@ func TestLibrary_Retrieve(t *testing.T) {
 		err := db.View(l.retrieve([]byte{13, 37}, &got))
 
 		require.Error(t, err)
-		assert.True(t, errors.Is(err, badger.ErrKeyNotFound))
+		assert.True(t, errors.Is(err, dps.ErrNotFound))
 
 	})
 
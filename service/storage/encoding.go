@@ -33,6 +33,9 @@ func EncodeKey(prefix uint8, segments ...interface{}) []byte {
 		case flow.Identifier:
 			val = make([]byte, 32)
 			copy(val, s[:])
+		case flow.Address:
+			val = make([]byte, flow.AddressLength)
+			copy(val, s[:])
 		case ledger.Path:
 			val = make([]byte, 32)
 			copy(val, s[:])
@@ -15,9 +15,13 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
+	"sort"
+	"time"
 
 	"github.com/OneOfOne/xxhash"
 	"github.com/dgraph-io/badger/v2"
@@ -25,6 +29,8 @@ import (
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
 )
 
 // SaveFirst is an operation that writes the height of the first indexed block.
@@ -37,6 +43,23 @@ func (l *Library) SaveLast(height uint64) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixLast), height)
 }
 
+// SaveVersion is an operation that writes the version of the index schema and codec.
+func (l *Library) SaveVersion(version uint64) func(*badger.Txn) error {
+	return l.save(EncodeKey(PrefixVersion), version)
+}
+
+// SaveMigration is an operation that writes the key of the last entry processed
+// by the migration to the given version.
+func (l *Library) SaveMigration(version uint64, cursor []byte) func(*badger.Txn) error {
+	return l.save(EncodeKey(PrefixMigration, version), cursor)
+}
+
+// SaveFloor is an operation that writes the retention floor, which is the lowest
+// height for which the register history is complete.
+func (l *Library) SaveFloor(height uint64) func(*badger.Txn) error {
+	return l.save(EncodeKey(PrefixFloor), height)
+}
+
 // IndexHeightForBlock is an operation that indexes the given height for its block identifier.
 func (l *Library) IndexHeightForBlock(blockID flow.Identifier, height uint64) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixHeightForBlock, blockID), height)
@@ -58,11 +81,28 @@ func (l *Library) SaveEvents(height uint64, typ flow.EventType, events []flow.Ev
 	return l.save(EncodeKey(PrefixEvents, height, hash), events)
 }
 
+// IndexHeightForEvent is an operation that indexes the given height for the given
+// event type. As the height is part of the key, no value is stored.
+func (l *Library) IndexHeightForEvent(typ flow.EventType, height uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		hash := xxhash.ChecksumString64(string(typ))
+		return tx.Set(EncodeKey(PrefixHeightsForEvent, hash, height), nil)
+	}
+}
+
 // SavePayload is an operation that writes the height of a slice of paths and a slice of payloads.
 func (l *Library) SavePayload(height uint64, path ledger.Path, payload *ledger.Payload) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixPayload, path, height), payload)
 }
 
+// IndexPathForHeight is an operation that indexes the given ledger path as
+// written at the given height.
+func (l *Library) IndexPathForHeight(height uint64, path ledger.Path) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		return tx.Set(EncodeKey(PrefixPathsForHeight, height, path), nil)
+	}
+}
+
 // SaveTransaction is an operation that writes the given transaction.
 func (l *Library) SaveTransaction(transaction *flow.TransactionBody) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixTransaction, transaction.ID()), transaction)
@@ -73,6 +113,29 @@ func (l *Library) IndexHeightForTransaction(txID flow.Identifier, height uint64)
 	return l.save(EncodeKey(PrefixHeightForTransaction, txID), height)
 }
 
+// IndexHeightForTime is an operation that indexes the given height for the timestamp of its block.
+func (l *Library) IndexHeightForTime(timestamp time.Time, height uint64) func(*badger.Txn) error {
+	// Timestamps before the Unix epoch can't be encoded in the unsigned key,
+	// where they would wrap around to the latest timestamps, so we skip them;
+	// lookups for such timestamps never find a block either.
+	if timestamp.UnixNano() < 0 {
+		return func(*badger.Txn) error {
+			return nil
+		}
+	}
+	return l.save(EncodeKey(PrefixHeightForTime, uint64(timestamp.UnixNano())), height)
+}
+
+// IndexHeightForCollection is an operation that indexes the given height for its collection identifier.
+func (l *Library) IndexHeightForCollection(collID flow.Identifier, height uint64) func(*badger.Txn) error {
+	return l.save(EncodeKey(PrefixHeightForCollection, collID), height)
+}
+
+// IndexHeightForSeal is an operation that indexes the given height for its seal identifier.
+func (l *Library) IndexHeightForSeal(sealID flow.Identifier, height uint64) func(*badger.Txn) error {
+	return l.save(EncodeKey(PrefixHeightForSeal, sealID), height)
+}
+
 // SaveCollection is an operation that writes the given collection.
 func (l *Library) SaveCollection(collection *flow.LightCollection) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixCollection, collection.ID()), collection)
@@ -99,6 +162,14 @@ func (l *Library) IndexTransactionsForCollection(collID flow.Identifier, txIDs [
 	return l.save(EncodeKey(PrefixTransactionsForCollection, collID), txIDs)
 }
 
+// IndexTransactionForAccount is an operation that indexes the given transaction for
+// an account that took part in it, with the roles of the account as a single byte.
+func (l *Library) IndexTransactionForAccount(address flow.Address, height uint64, txID flow.Identifier, roles dps.Role) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		return tx.Set(EncodeKey(PrefixTransactionsForAccount, address, height, txID), []byte{byte(roles)})
+	}
+}
+
 // IndexCollectionsForHeight is an operation that indexes the height of a slice of collection identifiers.
 func (l *Library) IndexCollectionsForHeight(height uint64, collIDs []flow.Identifier) func(*badger.Txn) error {
 	return l.save(EncodeKey(PrefixCollectionsForHeight, height), collIDs)
@@ -124,6 +195,23 @@ func (l *Library) RetrieveLast(height *uint64) func(*badger.Txn) error {
 	return l.retrieve(EncodeKey(PrefixLast), height)
 }
 
+// RetrieveVersion retrieves the version of the index schema and codec.
+func (l *Library) RetrieveVersion(version *uint64) func(*badger.Txn) error {
+	return l.retrieve(EncodeKey(PrefixVersion), version)
+}
+
+// RetrieveFloor retrieves the retention floor, which is the lowest height for
+// which the register history is complete.
+func (l *Library) RetrieveFloor(height *uint64) func(*badger.Txn) error {
+	return l.retrieve(EncodeKey(PrefixFloor), height)
+}
+
+// RetrieveMigration retrieves the key of the last entry processed by the
+// migration to the given version.
+func (l *Library) RetrieveMigration(version uint64, cursor *[]byte) func(*badger.Txn) error {
+	return l.retrieve(EncodeKey(PrefixMigration, version), cursor)
+}
+
 // LookupHeightForBlock retrieves the height of the given block identifier.
 func (l *Library) LookupHeightForBlock(blockID flow.Identifier, height *uint64) func(*badger.Txn) error {
 	return l.retrieve(EncodeKey(PrefixHeightForBlock, blockID), height)
@@ -183,6 +271,74 @@ func (l *Library) RetrieveEvents(height uint64, types []flow.EventType, events *
 	}
 }
 
+// RetrieveHeaders retrieves the headers for all heights between the given start
+// and end heights, both inclusive, in a single iteration.
+func (l *Library) RetrieveHeaders(start uint64, end uint64, headers *[]*flow.Header) func(*badger.Txn) error {
+	process := func(_ []byte, val []byte) error {
+		var header flow.Header
+		err := l.codec.Unmarshal(val, &header)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal header: %w", err)
+		}
+		*headers = append(*headers, &header)
+		return nil
+	}
+	return l.iterate(PrefixHeader, start, end, process)
+}
+
+// RetrieveCommits retrieves the commits for all heights between the given start
+// and end heights, both inclusive, in a single iteration, and adds them to the
+// given map by height.
+func (l *Library) RetrieveCommits(start uint64, end uint64, commits map[uint64]flow.StateCommitment) func(*badger.Txn) error {
+	process := func(key []byte, val []byte) error {
+		var commit flow.StateCommitment
+		err := l.codec.Unmarshal(val, &commit)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal commit: %w", err)
+		}
+		height := binary.BigEndian.Uint64(key[1:])
+		commits[height] = commit
+		return nil
+	}
+	return l.iterate(PrefixCommit, start, end, process)
+}
+
+// RetrieveEventsRange retrieves the events for all heights between the given
+// start and end heights, both inclusive, in a single iteration, and adds them to
+// the given map by height. If no types were provided, all events are retrieved.
+func (l *Library) RetrieveEventsRange(start uint64, end uint64, types []flow.EventType, events map[uint64][]flow.Event) func(*badger.Txn) error {
+
+	lookup := make(map[uint64]struct{})
+	for _, typ := range types {
+		hash := xxhash.ChecksumString64(string(typ))
+		lookup[hash] = struct{}{}
+	}
+
+	process := func(key []byte, val []byte) error {
+
+		// If types were given for filtering, discard events which should not be included.
+		hash := binary.BigEndian.Uint64(key[1+8:])
+		_, ok := lookup[hash]
+		if len(lookup) != 0 && !ok {
+			return nil
+		}
+
+		// Unmarshal event batch and add them to the events of their height.
+		var evts []flow.Event
+		err := l.codec.Unmarshal(val, &evts)
+		if err != nil {
+			return fmt.Errorf("could not unmarshal events: %w", err)
+		}
+
+		height := binary.BigEndian.Uint64(key[1:])
+		events[height] = append(events[height], evts...)
+
+		return nil
+	}
+
+	return l.iterate(PrefixEvents, start, end, process)
+}
+
 // RetrievePayload retrieves the ledger payloads at the given height that match the given path.
 func (l *Library) RetrievePayload(height uint64, path ledger.Path, payload *ledger.Payload) func(*badger.Txn) error {
 	return func(tx *badger.Txn) error {
@@ -200,7 +356,7 @@ func (l *Library) RetrievePayload(height uint64, path ledger.Path, payload *ledg
 
 		it.Seek(key)
 		if !it.Valid() {
-			return badger.ErrKeyNotFound
+			return fmt.Errorf("could not find payload (path: %x, height: %d): %w", path, height, dps.ErrNotFound)
 		}
 
 		err := it.Item().Value(func(val []byte) error {
@@ -211,6 +367,44 @@ func (l *Library) RetrievePayload(height uint64, path ledger.Path, payload *ledg
 	}
 }
 
+// RetrieveRegisterHistory retrieves the ledger payloads written to the given
+// path at all heights between the given start and end heights, both inclusive,
+// in a single iteration, and adds them to the given map by height.
+func (l *Library) RetrieveRegisterHistory(path ledger.Path, start uint64, end uint64, payloads map[uint64]*ledger.Payload) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = EncodeKey(PrefixPayload, path)
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// The keys for a path are sorted by height, so we can seek to the start
+		// height and stop as soon as we go past the end height.
+		for it.Seek(EncodeKey(PrefixPayload, path, start)); it.ValidForPrefix(opts.Prefix); it.Next() {
+
+			item := it.Item()
+			key := item.Key()
+			height := binary.BigEndian.Uint64(key[1+pathfinder.PathByteSize:])
+			if height > end {
+				break
+			}
+
+			var payload ledger.Payload
+			err := item.Value(func(val []byte) error {
+				return l.codec.Unmarshal(val, &payload)
+			})
+			if err != nil {
+				return fmt.Errorf("could not decode payload (path: %x, height: %d): %w", path, height, err)
+			}
+
+			payloads[height] = &payload
+		}
+
+		return nil
+	}
+}
+
 // RetrieveCollection retrieves the collection with the given identifier.
 func (l *Library) RetrieveCollection(collectionID flow.Identifier, collection *flow.LightCollection) func(*badger.Txn) error {
 	return l.retrieve(EncodeKey(PrefixCollection, collectionID), collection)
@@ -231,6 +425,83 @@ func (l *Library) LookupHeightForTransaction(txID flow.Identifier, height *uint6
 	return l.retrieve(EncodeKey(PrefixHeightForTransaction, txID), height)
 }
 
+// LookupHeightForCollection retrieves the height of the collection with the given identifier.
+func (l *Library) LookupHeightForCollection(collID flow.Identifier, height *uint64) func(*badger.Txn) error {
+	return l.retrieve(EncodeKey(PrefixHeightForCollection, collID), height)
+}
+
+// LookupHeightForSeal retrieves the height of the seal with the given identifier.
+func (l *Library) LookupHeightForSeal(sealID flow.Identifier, height *uint64) func(*badger.Txn) error {
+	return l.retrieve(EncodeKey(PrefixHeightForSeal, sealID), height)
+}
+
+// LookupHeightForTime retrieves the height of the last block with a timestamp at or before the given one.
+func (l *Library) LookupHeightForTime(timestamp time.Time, height *uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		// No block can have a timestamp before the Unix epoch, and converting
+		// it to the unsigned key would wrap around to the latest timestamps.
+		if timestamp.UnixNano() < 0 {
+			return fmt.Errorf("could not find height (timestamp: %s): %w", timestamp.Format(time.RFC3339Nano), dps.ErrNotFound)
+		}
+
+		key := EncodeKey(PrefixHeightForTime, uint64(timestamp.UnixNano()))
+		it := tx.NewIterator(badger.IteratorOptions{
+			PrefetchSize:   0,
+			PrefetchValues: false,
+			Reverse:        true,
+			AllVersions:    false,
+			InternalAccess: false,
+			Prefix:         EncodeKey(PrefixHeightForTime),
+		})
+		defer it.Close()
+
+		// As timestamps are encoded in big endian, seeking in reverse gives us
+		// the block with the closest timestamp at or before the given one.
+		it.Seek(key)
+		if !it.Valid() {
+			return fmt.Errorf("could not find height (timestamp: %s): %w", timestamp.Format(time.RFC3339Nano), dps.ErrNotFound)
+		}
+
+		err := it.Item().Value(func(val []byte) error {
+			return l.codec.Unmarshal(val, height)
+		})
+		if err != nil {
+			return fmt.Errorf("could not decode height (timestamp: %s): %w", timestamp.Format(time.RFC3339Nano), err)
+		}
+
+		return nil
+	}
+}
+
+// LookupHeightsForEvent retrieves the heights between the given start and end
+// heights, both inclusive, at which events of the given type were indexed.
+func (l *Library) LookupHeightsForEvent(typ flow.EventType, start uint64, end uint64, heights *[]uint64) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		hash := xxhash.ChecksumString64(string(typ))
+		prefix := EncodeKey(PrefixHeightsForEvent, hash)
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// As heights are encoded in big endian, seeking to the start height
+		// gives us all of the heights in ascending order.
+		for it.Seek(EncodeKey(PrefixHeightsForEvent, hash, start)); it.ValidForPrefix(prefix); it.Next() {
+			height := binary.BigEndian.Uint64(it.Item().Key()[1+8:])
+			if height > end {
+				break
+			}
+			*heights = append(*heights, height)
+		}
+
+		return nil
+	}
+}
+
 // RetrieveSeal retrieves the seal with the given identifier.
 func (l *Library) RetrieveSeal(sealID flow.Identifier, seal *flow.Seal) func(*badger.Txn) error {
 	return l.retrieve(EncodeKey(PrefixSeal, sealID), seal)
@@ -252,6 +523,68 @@ func (l *Library) LookupTransactionsForCollection(collID flow.Identifier, txIDs
 	return l.retrieve(EncodeKey(PrefixTransactionsForCollection, collID), txIDs)
 }
 
+// LookupTransactionsForAccount retrieves the transactions that the account with the
+// given address took part in between the given start and end heights, both inclusive,
+// ordered by height and transaction identifier. If a transaction identifier is given
+// to start after, the transactions at the start height up to and including it are
+// skipped. A limit of zero retrieves all transactions in the range.
+func (l *Library) LookupTransactionsForAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint, txs *[]dps.AccountTransaction) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		prefix := EncodeKey(PrefixTransactionsForAccount, address)
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		seek := EncodeKey(PrefixTransactionsForAccount, address, start)
+		if after != flow.ZeroID {
+			seek = EncodeKey(PrefixTransactionsForAccount, address, start, after)
+		}
+
+		for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+			if limit > 0 && uint(len(*txs)) >= limit {
+				break
+			}
+
+			item := it.Item()
+			key := item.Key()
+			if after != flow.ZeroID && bytes.Equal(key, seek) {
+				continue
+			}
+
+			height := binary.BigEndian.Uint64(key[1+flow.AddressLength:])
+			if height > end {
+				break
+			}
+
+			var txID flow.Identifier
+			copy(txID[:], key[1+flow.AddressLength+8:])
+
+			var roles dps.Role
+			err := item.Value(func(val []byte) error {
+				if len(val) != 1 {
+					return fmt.Errorf("invalid roles length (%d)", len(val))
+				}
+				roles = dps.Role(val[0])
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("could not decode roles (key: %x): %w", key, err)
+			}
+
+			*txs = append(*txs, dps.AccountTransaction{
+				Height:        height,
+				TransactionID: txID,
+				Roles:         roles,
+			})
+		}
+
+		return nil
+	}
+}
+
 // LookupSealsForHeight retrieves the identifiers of seals at the given height.
 func (l *Library) LookupSealsForHeight(height uint64, sealIDs *[]flow.Identifier) func(*badger.Txn) error {
 	return l.retrieve(EncodeKey(PrefixSealsForHeight, height), sealIDs)
@@ -328,16 +661,10 @@ func (l *Library) IterateLedger(exclude func(height uint64) bool, process func(p
 			// use the maximum possible height. If the decrement doesn't work,
 			// we have reached the zero path and we can break; otherwise, we
 			// would just wrap around to the maximum key again.
-			var zero ledger.Path
-			if path == zero {
+			path, ok := previousPath(path)
+			if !ok {
 				break
 			}
-			for i := len(path) - 1; i >= 0; i-- {
-				path[i] = path[i] - 1
-				if path[i] != 0xff {
-					break
-				}
-			}
 			sentinel = EncodeKey(PrefixPayload, path, uint64(math.MaxUint64))
 			it.Seek(sentinel)
 		}
@@ -345,3 +672,151 @@ func (l *Library) IterateLedger(exclude func(height uint64) bool, process func(p
 		return nil
 	}
 }
+
+// IterateStateDiff calls the given callback for each ledger path that was
+// written between the given start height, exclusive, and the given end height,
+// inclusive, with its payloads at both heights, in ascending order of paths.
+// The paths are taken from the paths index of the heights within the range, so
+// the cost only depends on the number of registers written within it. If the
+// path did not exist yet at the start height, the payload before is nil. Paths
+// whose value ends up being unchanged are skipped.
+func (l *Library) IterateStateDiff(start uint64, end uint64, process func(path ledger.Path, before *ledger.Payload, after *ledger.Payload) error) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		prefix := EncodeKey(PrefixPathsForHeight)
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+
+		// As heights are encoded in big endian, seeking to the height after the
+		// start height gives us the paths of all heights in ascending order.
+		var paths []ledger.Path
+		it := tx.NewIterator(opts)
+		for it.Seek(EncodeKey(PrefixPathsForHeight, start+1)); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().Key()
+			height := binary.BigEndian.Uint64(key[1 : 1+8])
+			if height > end {
+				break
+			}
+			var path ledger.Path
+			copy(path[:], key[1+8:])
+			paths = append(paths, path)
+		}
+		it.Close()
+
+		// A path can be written at several heights within the range, so we sort
+		// the paths and skip the duplicates, which then follow each other.
+		sort.Slice(paths, func(i int, j int) bool {
+			return bytes.Compare(paths[i][:], paths[j][:]) < 0
+		})
+
+		for i, path := range paths {
+			if i > 0 && path == paths[i-1] {
+				continue
+			}
+
+			var after ledger.Payload
+			err := l.RetrievePayload(end, path, &after)(tx)
+			if err != nil {
+				return fmt.Errorf("could not retrieve payload after (path: %x, height: %d): %w", path, end, err)
+			}
+
+			var before *ledger.Payload
+			var payload ledger.Payload
+			err = l.RetrievePayload(start, path, &payload)(tx)
+			if err != nil && !errors.Is(err, dps.ErrNotFound) {
+				return fmt.Errorf("could not retrieve payload before (path: %x, height: %d): %w", path, start, err)
+			}
+			if err == nil {
+				before = &payload
+			}
+
+			if before != nil && bytes.Equal(before.Value, after.Value) {
+				continue
+			}
+
+			err = process(path, before, &after)
+			if err != nil {
+				return fmt.Errorf("could not process register (path: %x): %w", path, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// PrunePayloads is an operation that deletes the payloads that were superseded
+// at or before the given floor height, so that only the last payload at or
+// before the floor remains for each path. It steps through at most the given
+// number of payload keys, starting at the given cursor, and sets the next
+// cursor to resume from, which is nil once all payloads have been processed.
+// The limit needs to be at least two for the pruning to make progress.
+func (l *Library) PrunePayloads(floor uint64, cursor []byte, limit uint, next *[]byte) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+
+		prefix := EncodeKey(PrefixPayload)
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+
+		start := prefix
+		if cursor != nil {
+			start = cursor
+		}
+
+		// As the keys are sorted by path, and then by height, all payloads at
+		// or below the floor for a path directly follow each other, and each of
+		// them supersedes the one before it. We thus keep track of the last one
+		// we have seen for the current path, and delete it when we see another.
+		var superseded [][]byte
+		var candidate []byte
+		var count uint
+		*next = nil
+		it := tx.NewIterator(opts)
+		for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			path := key[1 : 1+pathfinder.PathByteSize]
+
+			// If we reached the limit, we resume from the candidate of the
+			// current path, if there is one, so that it can still be superseded.
+			if count >= limit {
+				*next = key
+				if candidate != nil && bytes.Equal(candidate[1:1+pathfinder.PathByteSize], path) {
+					*next = candidate
+				}
+				break
+			}
+			count++
+
+			height := binary.BigEndian.Uint64(key[1+pathfinder.PathByteSize:])
+			if height > floor {
+				candidate = nil
+				continue
+			}
+			if candidate != nil && bytes.Equal(candidate[1:1+pathfinder.PathByteSize], path) {
+				superseded = append(superseded, candidate)
+			}
+			candidate = key
+		}
+		it.Close()
+
+		// Along with each payload, we delete the entry of its path in the paths
+		// index of its height, which has the same segments in opposite order.
+		for _, key := range superseded {
+			err := tx.Delete(key)
+			if err != nil {
+				return fmt.Errorf("could not delete payload (key: %x): %w", key, err)
+			}
+			index := make([]byte, 0, len(key))
+			index = append(index, PrefixPathsForHeight)
+			index = append(index, key[1+pathfinder.PathByteSize:]...)
+			index = append(index, key[1:1+pathfinder.PathByteSize]...)
+			err = tx.Delete(index)
+			if err != nil {
+				return fmt.Errorf("could not delete path index entry (key: %x): %w", index, err)
+			}
+		}
+
+		return nil
+	}
+}
@@ -15,7 +15,11 @@
 package storage
 
 import (
+	"bytes"
+	"encoding/binary"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/OneOfOne/xxhash"
 	"github.com/dgraph-io/badger/v2"
@@ -23,9 +27,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/model/flow"
 
 	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/models/dps"
 	"github.com/optakt/flow-dps/service/loader"
 	"github.com/optakt/flow-dps/testing/helpers"
 	"github.com/optakt/flow-dps/testing/mocks"
@@ -131,6 +137,156 @@ func TestLibrary_SaveAndRetrieveLast(t *testing.T) {
 	})
 }
 
+func TestLibrary_SaveAndRetrieveVersion(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	testKey := EncodeKey(PrefixVersion)
+
+	t.Run("save version", func(t *testing.T) {
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.Equal(t, dps.IndexVersion, v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.SaveVersion(dps.IndexVersion))
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve version", func(t *testing.T) {
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey, mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, mocks.GenericBytes, b)
+			assert.IsType(t, new(uint64), v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.RetrieveVersion(&got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+}
+
+func TestLibrary_SaveAndRetrieveMigration(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	testKey := EncodeKey(PrefixMigration, dps.IndexVersion)
+
+	t.Run("save migration cursor", func(t *testing.T) {
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.Equal(t, mocks.GenericBytes, v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.SaveMigration(dps.IndexVersion, mocks.GenericBytes))
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve migration cursor", func(t *testing.T) {
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey, mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, mocks.GenericBytes, b)
+			assert.IsType(t, &[]byte{}, v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got []byte
+		err = db.View(l.RetrieveMigration(dps.IndexVersion, &got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+}
+
+func TestLibrary_SaveAndRetrieveFloor(t *testing.T) {
+	db := helpers.InMemoryDB(t)
+	defer db.Close()
+
+	testKey := EncodeKey(PrefixFloor)
+
+	t.Run("save floor", func(t *testing.T) {
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.Equal(t, mocks.GenericHeight, v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.SaveFloor(mocks.GenericHeight))
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve floor", func(t *testing.T) {
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey, mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, mocks.GenericBytes, b)
+			assert.IsType(t, new(uint64), v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.RetrieveFloor(&got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+}
+
 func TestLibrary_SaveAndRetrieveCommit(t *testing.T) {
 	db := helpers.InMemoryDB(t)
 	defer db.Close()
@@ -423,6 +579,155 @@ func TestLibrary_SaveAndRetrieveEvents(t *testing.T) {
 	})
 }
 
+func TestLibrary_RetrieveHeaders(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		for i := uint64(0); i < 5; i++ {
+			height := mocks.GenericHeight + i
+			require.NoError(t, db.Update(l.SaveHeader(height, &flow.Header{Height: height})))
+			require.NoError(t, db.Update(l.SaveCommit(height, mocks.GenericCommit(int(i)))))
+		}
+
+		var got []*flow.Header
+		err := db.View(l.RetrieveHeaders(mocks.GenericHeight+1, mocks.GenericHeight+3, &got))
+
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		for i, header := range got {
+			assert.Equal(t, mocks.GenericHeight+1+uint64(i), header.Height)
+		}
+	})
+
+	t.Run("handles decoding failure", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(EncodeKey(PrefixHeader, mocks.GenericHeight), mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func([]byte, interface{}) error {
+			return mocks.GenericError
+		}
+		l := &Library{codec}
+
+		var got []*flow.Header
+		err = db.View(l.RetrieveHeaders(mocks.GenericHeight, mocks.GenericHeight, &got))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLibrary_RetrieveCommits(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		for i := uint64(0); i < 5; i++ {
+			height := mocks.GenericHeight + i
+			require.NoError(t, db.Update(l.SaveCommit(height, mocks.GenericCommit(int(i)))))
+			require.NoError(t, db.Update(l.SaveHeader(height, &flow.Header{Height: height})))
+		}
+
+		got := make(map[uint64]flow.StateCommitment)
+		err := db.View(l.RetrieveCommits(mocks.GenericHeight, mocks.GenericHeight+4, got))
+
+		require.NoError(t, err)
+		require.Len(t, got, 5)
+		for i, commit := range mocks.GenericCommits(5) {
+			assert.Equal(t, commit, got[mocks.GenericHeight+uint64(i)])
+		}
+	})
+
+	t.Run("handles empty range", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		require.NoError(t, db.Update(l.SaveCommit(mocks.GenericHeight, mocks.GenericCommit(0))))
+
+		got := make(map[uint64]flow.StateCommitment)
+		err := db.View(l.RetrieveCommits(mocks.GenericHeight+1, mocks.GenericHeight+4, got))
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestLibrary_RetrieveEventsRange(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		events := mocks.GenericEvents(4, mocks.GenericEventTypes(2)...)
+		for i := uint64(0); i < 5; i++ {
+			height := mocks.GenericHeight + i
+			require.NoError(t, db.Update(l.SaveEvents(height, mocks.GenericEventType(0), []flow.Event{events[0], events[2]})))
+			require.NoError(t, db.Update(l.SaveEvents(height, mocks.GenericEventType(1), []flow.Event{events[1], events[3]})))
+		}
+
+		got := make(map[uint64][]flow.Event)
+		err := db.View(l.RetrieveEventsRange(mocks.GenericHeight+1, mocks.GenericHeight+2, nil, got))
+
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.ElementsMatch(t, events, got[mocks.GenericHeight+1])
+		assert.ElementsMatch(t, events, got[mocks.GenericHeight+2])
+	})
+
+	t.Run("filters by type", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		events := mocks.GenericEvents(4, mocks.GenericEventTypes(2)...)
+		for i := uint64(0); i < 5; i++ {
+			height := mocks.GenericHeight + i
+			require.NoError(t, db.Update(l.SaveEvents(height, mocks.GenericEventType(0), []flow.Event{events[0], events[2]})))
+			require.NoError(t, db.Update(l.SaveEvents(height, mocks.GenericEventType(1), []flow.Event{events[1], events[3]})))
+		}
+
+		got := make(map[uint64][]flow.Event)
+		types := []flow.EventType{mocks.GenericEventType(1)}
+		err := db.View(l.RetrieveEventsRange(mocks.GenericHeight, mocks.GenericHeight+4, types, got))
+
+		require.NoError(t, err)
+		require.Len(t, got, 5)
+		for _, evts := range got {
+			assert.ElementsMatch(t, []flow.Event{events[1], events[3]}, evts)
+		}
+	})
+}
+
 func TestLibrary_SaveAndRetrievePayload(t *testing.T) {
 	testKey1 := EncodeKey(PrefixPayload, mocks.GenericLedgerPath(0), mocks.GenericHeight)
 	testKey2 := EncodeKey(PrefixPayload, mocks.GenericLedgerPath(0), mocks.GenericHeight*2)
@@ -682,6 +987,54 @@ func TestLibrary_SaveAndRetrievePayload(t *testing.T) {
 	})
 }
 
+func TestLibrary_RetrieveRegisterHistory(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		paths := mocks.GenericLedgerPaths(2)
+		payloads := mocks.GenericLedgerPayloads(5)
+		for i := uint64(0); i < 5; i++ {
+			height := mocks.GenericHeight + 2*i
+			require.NoError(t, db.Update(l.SavePayload(height, paths[0], payloads[i])))
+			require.NoError(t, db.Update(l.SavePayload(height, paths[1], payloads[i])))
+		}
+
+		got := make(map[uint64]*ledger.Payload)
+		err := db.View(l.RetrieveRegisterHistory(paths[0], mocks.GenericHeight+1, mocks.GenericHeight+6, got))
+
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, payloads[1], got[mocks.GenericHeight+2])
+		assert.Equal(t, payloads[2], got[mocks.GenericHeight+4])
+		assert.Equal(t, payloads[3], got[mocks.GenericHeight+6])
+	})
+
+	t.Run("path without changes in range", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		path := mocks.GenericLedgerPath(0)
+		require.NoError(t, db.Update(l.SavePayload(mocks.GenericHeight, path, mocks.GenericLedgerPayload(0))))
+
+		got := make(map[uint64]*ledger.Payload)
+		err := db.View(l.RetrieveRegisterHistory(path, mocks.GenericHeight+1, mocks.GenericHeight+10, got))
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
 func TestLibrary_IndexAndLookupHeightForBlock(t *testing.T) {
 	blockID := mocks.GenericHeader.ID()
 	testKey := EncodeKey(PrefixHeightForBlock, blockID)
@@ -856,10 +1209,11 @@ func TestLibrary_IndexAndLookupHeightForTransaction(t *testing.T) {
 	})
 }
 
-func TestIndexAndLookup_TransactionsForHeight(t *testing.T) {
-	testKey := EncodeKey(PrefixTransactionsForHeight, mocks.GenericHeight)
+func TestLibrary_IndexAndLookupHeightForCollection(t *testing.T) {
+	collID := mocks.GenericCollection(0).ID()
+	testKey := EncodeKey(PrefixHeightForCollection, collID)
 
-	t.Run("save transactions", func(t *testing.T) {
+	t.Run("save height of collection", func(t *testing.T) {
 		t.Parallel()
 
 		db := helpers.InMemoryDB(t)
@@ -867,7 +1221,477 @@ func TestIndexAndLookup_TransactionsForHeight(t *testing.T) {
 
 		codec := mocks.BaselineCodec(t)
 		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
-			assert.IsType(t, []flow.Identifier{}, v)
+			assert.IsType(t, uint64(0), v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.IndexHeightForCollection(collID, mocks.GenericHeight))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve height of collection", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey, mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, mocks.GenericBytes, b)
+			assert.IsType(t, &mocks.GenericHeight, v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.LookupHeightForCollection(collID, &got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+}
+
+func TestLibrary_IndexAndLookupHeightForSeal(t *testing.T) {
+	sealID := mocks.GenericSeal(0).ID()
+	testKey := EncodeKey(PrefixHeightForSeal, sealID)
+
+	t.Run("save height of seal", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.IsType(t, uint64(0), v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.IndexHeightForSeal(sealID, mocks.GenericHeight))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve height of seal", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey, mocks.GenericBytes)
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, mocks.GenericBytes, b)
+			assert.IsType(t, &mocks.GenericHeight, v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.LookupHeightForSeal(sealID, &got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+}
+
+func TestLibrary_IndexAndLookupHeightForTime(t *testing.T) {
+	timestamp := mocks.GenericHeader.Timestamp
+	testKey1 := EncodeKey(PrefixHeightForTime, uint64(timestamp.UnixNano()))
+	testKey2 := EncodeKey(PrefixHeightForTime, uint64(timestamp.Add(time.Minute).UnixNano()))
+
+	t.Run("save height for time", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.IsType(t, uint64(0), v)
+			return mocks.GenericLedgerValue(0), nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		err := db.Update(l.IndexHeightForTime(timestamp, mocks.GenericHeight))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("retrieve height for exact time", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			err := tx.Set(testKey1, mocks.GenericLedgerValue(0))
+			require.NoError(t, err)
+
+			err = tx.Set(testKey2, mocks.GenericLedgerValue(1))
+			require.NoError(t, err)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			assert.Equal(t, []byte(mocks.GenericLedgerValue(0)), b)
+			assert.IsType(t, &mocks.GenericHeight, v)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.LookupHeightForTime(timestamp, &got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+
+	t.Run("retrieve height for time between blocks", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			err := tx.Set(testKey1, mocks.GenericLedgerValue(0))
+			require.NoError(t, err)
+
+			err = tx.Set(testKey2, mocks.GenericLedgerValue(1))
+			require.NoError(t, err)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		decodeCallCount := 0
+		codec := mocks.BaselineCodec(t)
+		codec.UnmarshalFunc = func(b []byte, v interface{}) error {
+			// We should find the second value, as it is the last one before the given time.
+			assert.Equal(t, []byte(mocks.GenericLedgerValue(1)), b)
+			decodeCallCount++
+
+			return nil
+		}
+
+		l := &Library{
+			codec: codec,
+		}
+
+		var got uint64
+		err = db.View(l.LookupHeightForTime(timestamp.Add(time.Hour), &got))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decodeCallCount)
+	})
+
+	t.Run("retrieve height for time before first block", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey1, mocks.GenericLedgerValue(0))
+		})
+		require.NoError(t, err)
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		var got uint64
+		err = db.View(l.LookupHeightForTime(timestamp.Add(-time.Second), &got))
+
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+	})
+
+	t.Run("handles time before the Unix epoch", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Set(testKey1, mocks.GenericLedgerValue(0))
+		})
+		require.NoError(t, err)
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		before := time.Unix(0, -1)
+		err = db.Update(l.IndexHeightForTime(before, mocks.GenericHeight+1))
+		require.NoError(t, err)
+
+		var got uint64
+		err = db.View(l.LookupHeightForTime(before, &got))
+		assert.ErrorIs(t, err, dps.ErrNotFound)
+
+		err = db.View(func(tx *badger.Txn) error {
+			_, err := tx.Get(EncodeKey(PrefixHeightForTime, uint64(before.UnixNano())))
+			return err
+		})
+		assert.ErrorIs(t, err, badger.ErrKeyNotFound)
+	})
+}
+
+func TestLibrary_IndexAndLookupHeightsForEvent(t *testing.T) {
+	types := mocks.GenericEventTypes(2)
+
+	t.Run("index height for event", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		err := db.Update(l.IndexHeightForEvent(types[0], mocks.GenericHeight))
+		require.NoError(t, err)
+
+		hash := xxhash.ChecksumString64(string(types[0]))
+		err = db.View(func(tx *badger.Txn) error {
+			_, err := tx.Get(EncodeKey(PrefixHeightsForEvent, hash, mocks.GenericHeight))
+			return err
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("lookup heights for event", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		for i := uint64(0); i < 5; i++ {
+			require.NoError(t, db.Update(l.IndexHeightForEvent(types[i%2], mocks.GenericHeight+i)))
+		}
+
+		var got []uint64
+		err := db.View(l.LookupHeightsForEvent(types[0], mocks.GenericHeight+1, mocks.GenericHeight+4, &got))
+
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{mocks.GenericHeight + 2, mocks.GenericHeight + 4}, got)
+	})
+
+	t.Run("lookup heights for unknown event", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		require.NoError(t, db.Update(l.IndexHeightForEvent(types[0], mocks.GenericHeight)))
+
+		var got []uint64
+		err := db.View(l.LookupHeightsForEvent(types[1], mocks.GenericHeight, mocks.GenericHeight+4, &got))
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestLibrary_IndexAndLookupTransactionsForAccount(t *testing.T) {
+	address := mocks.GenericAddress(0)
+	other := mocks.GenericAddress(1)
+
+	// Transaction identifiers are ordered by their bytes within a height, so
+	// we sort them to know which order the lookup returns them in.
+	txIDs := mocks.GenericTransactionIDs(3)
+	sort.Slice(txIDs, func(i, j int) bool {
+		return bytes.Compare(txIDs[i][:], txIDs[j][:]) < 0
+	})
+
+	t.Run("index transaction for account", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+
+		roles := dps.RolePayer | dps.RoleAuthorizer
+		err := db.Update(l.IndexTransactionForAccount(address, mocks.GenericHeight, txIDs[0], roles))
+		require.NoError(t, err)
+
+		var got []byte
+		err = db.View(func(tx *badger.Txn) error {
+			item, err := tx.Get(EncodeKey(PrefixTransactionsForAccount, address, mocks.GenericHeight, txIDs[0]))
+			if err != nil {
+				return err
+			}
+			got, err = item.ValueCopy(nil)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{byte(roles)}, got)
+	})
+
+	// Indexes the first transaction at the generic height and all three
+	// transactions at the height after it for the address, as well as one
+	// transaction for another address.
+	setup := func(t *testing.T, db *badger.DB, l *Library) {
+		t.Helper()
+
+		require.NoError(t, db.Update(l.IndexTransactionForAccount(address, mocks.GenericHeight, txIDs[0], dps.RoleProposer)))
+		for _, txID := range txIDs {
+			require.NoError(t, db.Update(l.IndexTransactionForAccount(address, mocks.GenericHeight+1, txID, dps.RolePayer)))
+		}
+		require.NoError(t, db.Update(l.IndexTransactionForAccount(other, mocks.GenericHeight+1, txIDs[0], dps.RoleAuthorizer)))
+	}
+
+	t.Run("lookup transactions for account", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+		setup(t, db, l)
+
+		var got []dps.AccountTransaction
+		err := db.View(l.LookupTransactionsForAccount(address, mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 0, &got))
+
+		require.NoError(t, err)
+		want := []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: txIDs[0], Roles: dps.RoleProposer},
+			{Height: mocks.GenericHeight + 1, TransactionID: txIDs[0], Roles: dps.RolePayer},
+			{Height: mocks.GenericHeight + 1, TransactionID: txIDs[1], Roles: dps.RolePayer},
+			{Height: mocks.GenericHeight + 1, TransactionID: txIDs[2], Roles: dps.RolePayer},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("lookup transactions for account within range", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+		setup(t, db, l)
+
+		var got []dps.AccountTransaction
+		err := db.View(l.LookupTransactionsForAccount(address, mocks.GenericHeight, mocks.GenericHeight, flow.ZeroID, 0, &got))
+
+		require.NoError(t, err)
+		want := []dps.AccountTransaction{
+			{Height: mocks.GenericHeight, TransactionID: txIDs[0], Roles: dps.RoleProposer},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("lookup transactions for account with limit and cursor", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+		setup(t, db, l)
+
+		var got []dps.AccountTransaction
+		err := db.View(l.LookupTransactionsForAccount(address, mocks.GenericHeight+1, mocks.GenericHeight+1, txIDs[0], 1, &got))
+
+		require.NoError(t, err)
+		want := []dps.AccountTransaction{
+			{Height: mocks.GenericHeight + 1, TransactionID: txIDs[1], Roles: dps.RolePayer},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("lookup transactions for unknown account", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		l := &Library{
+			codec: mocks.BaselineCodec(t),
+		}
+		setup(t, db, l)
+
+		var got []dps.AccountTransaction
+		err := db.View(l.LookupTransactionsForAccount(mocks.GenericAddress(2), mocks.GenericHeight, mocks.GenericHeight+1, flow.ZeroID, 0, &got))
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestIndexAndLookup_TransactionsForHeight(t *testing.T) {
+	testKey := EncodeKey(PrefixTransactionsForHeight, mocks.GenericHeight)
+
+	t.Run("save transactions", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := mocks.BaselineCodec(t)
+		codec.MarshalFunc = func(v interface{}) ([]byte, error) {
+			assert.IsType(t, []flow.Identifier{}, v)
 			return mocks.GenericLedgerValue(0), nil
 		}
 
@@ -1377,3 +2201,251 @@ func TestLibrary_IterateLedger(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestLibrary_IterateStateDiff(t *testing.T) {
+	paths := mocks.GenericLedgerPaths(5)
+	payloads := mocks.GenericLedgerPayloads(5)
+
+	// save writes the payload along with the paths index entry, as the index
+	// writer does.
+	save := func(t *testing.T, db *badger.DB, l *Library, height uint64, path ledger.Path, payload *ledger.Payload) {
+		t.Helper()
+		require.NoError(t, db.Update(l.SavePayload(height, path, payload)))
+		require.NoError(t, db.Update(l.IndexPathForHeight(height, path)))
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		// The first path is changed twice within the range.
+		save(t, db, l, 10, paths[0], payloads[0])
+		save(t, db, l, 20, paths[0], payloads[2])
+		save(t, db, l, 22, paths[0], payloads[1])
+		// The second path is only written before the range.
+		save(t, db, l, 10, paths[1], payloads[1])
+		// The third path is created within the range.
+		save(t, db, l, 20, paths[2], payloads[2])
+		// The fourth path is written within the range with the same value.
+		save(t, db, l, 10, paths[3], payloads[3])
+		save(t, db, l, 20, paths[3], payloads[3])
+		// The fifth path is only written after the range.
+		save(t, db, l, 30, paths[4], payloads[4])
+
+		var got []ledger.Path
+		before := make(map[ledger.Path]*ledger.Payload)
+		after := make(map[ledger.Path]*ledger.Payload)
+		op := l.IterateStateDiff(15, 25, func(path ledger.Path, b *ledger.Payload, a *ledger.Payload) error {
+			got = append(got, path)
+			before[path] = b
+			after[path] = a
+
+			return nil
+		})
+
+		err := db.View(op)
+
+		require.NoError(t, err)
+		want := []ledger.Path{paths[0], paths[2]}
+		sort.Slice(want, func(i int, j int) bool {
+			return bytes.Compare(want[i][:], want[j][:]) < 0
+		})
+		assert.Equal(t, want, got)
+		assert.Equal(t, payloads[0], before[paths[0]])
+		assert.Equal(t, payloads[1], after[paths[0]])
+		assert.Nil(t, before[paths[2]])
+		assert.Equal(t, payloads[2], after[paths[2]])
+	})
+
+	t.Run("handles empty range", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		save(t, db, l, 10, paths[0], payloads[0])
+		save(t, db, l, 20, paths[0], payloads[1])
+
+		called := false
+		op := l.IterateStateDiff(20, 20, func(ledger.Path, *ledger.Payload, *ledger.Payload) error {
+			called = true
+
+			return nil
+		})
+
+		err := db.View(op)
+
+		require.NoError(t, err)
+		assert.False(t, called)
+	})
+
+	t.Run("handles process failure", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		codec := zbor.NewCodec()
+		l := &Library{codec}
+
+		save(t, db, l, 20, paths[0], payloads[0])
+
+		op := l.IterateStateDiff(10, 20, func(ledger.Path, *ledger.Payload, *ledger.Payload) error {
+			return mocks.GenericError
+		})
+
+		err := db.View(op)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLibrary_PrunePayloads(t *testing.T) {
+	entries := 3
+	heights := 5
+	floor := mocks.GenericHeight + 2
+	paths := mocks.GenericLedgerPaths(entries)
+	payloads := mocks.GenericLedgerPayloads(heights)
+
+	// populate writes all payloads for all paths, and returns a database where
+	// each path has payloads at five heights, two of them below the floor.
+	populate := func(t *testing.T) (*badger.DB, *Library) {
+		t.Helper()
+
+		db := helpers.InMemoryDB(t)
+		l := &Library{zbor.NewCodec()}
+		for _, path := range paths {
+			for i := 0; i < heights; i++ {
+				height := mocks.GenericHeight + uint64(i)
+				require.NoError(t, db.Update(l.SavePayload(height, path, payloads[i])))
+				require.NoError(t, db.Update(l.IndexPathForHeight(height, path)))
+			}
+		}
+
+		return db, l
+	}
+
+	// remaining returns the heights of the payloads left for each path.
+	remaining := func(t *testing.T, db *badger.DB) map[ledger.Path][]uint64 {
+		t.Helper()
+
+		got := make(map[ledger.Path][]uint64)
+		err := db.View(func(tx *badger.Txn) error {
+			prefix := EncodeKey(PrefixPayload)
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			opts.Prefix = prefix
+			it := tx.NewIterator(opts)
+			defer it.Close()
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				key := it.Item().Key()
+				var path ledger.Path
+				copy(path[:], key[1:1+pathfinder.PathByteSize])
+				height := binary.BigEndian.Uint64(key[1+pathfinder.PathByteSize:])
+				got[path] = append(got[path], height)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		return got
+	}
+
+	// indexed returns the heights of the paths index entries for each path.
+	indexed := func(t *testing.T, db *badger.DB) map[ledger.Path][]uint64 {
+		t.Helper()
+
+		got := make(map[ledger.Path][]uint64)
+		err := db.View(func(tx *badger.Txn) error {
+			prefix := EncodeKey(PrefixPathsForHeight)
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			opts.Prefix = prefix
+			it := tx.NewIterator(opts)
+			defer it.Close()
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				key := it.Item().Key()
+				var path ledger.Path
+				copy(path[:], key[1+8:])
+				height := binary.BigEndian.Uint64(key[1 : 1+8])
+				got[path] = append(got[path], height)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		return got
+	}
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		db, l := populate(t)
+		defer db.Close()
+
+		var next []byte
+		err := db.Update(l.PrunePayloads(floor, nil, 100, &next))
+
+		require.NoError(t, err)
+		assert.Nil(t, next)
+
+		got := remaining(t, db)
+		require.Len(t, got, entries)
+		for _, path := range paths {
+			assert.Equal(t, []uint64{floor, floor + 1, floor + 2}, got[path])
+		}
+		assert.Equal(t, got, indexed(t, db))
+	})
+
+	t.Run("resumes from cursor", func(t *testing.T) {
+		t.Parallel()
+
+		db, l := populate(t)
+		defer db.Close()
+
+		var cursor []byte
+		for i := 0; ; i++ {
+			require.Less(t, i, entries*heights, "pruning did not make progress")
+
+			var next []byte
+			err := db.Update(l.PrunePayloads(floor, cursor, 2, &next))
+			require.NoError(t, err)
+			if next == nil {
+				break
+			}
+
+			cursor = next
+		}
+
+		got := remaining(t, db)
+		require.Len(t, got, entries)
+		for _, path := range paths {
+			assert.Equal(t, []uint64{floor, floor + 1, floor + 2}, got[path])
+		}
+	})
+
+	t.Run("handles floor below all payloads", func(t *testing.T) {
+		t.Parallel()
+
+		db, l := populate(t)
+		defer db.Close()
+
+		var next []byte
+		err := db.Update(l.PrunePayloads(mocks.GenericHeight-1, nil, 100, &next))
+
+		require.NoError(t, err)
+
+		got := remaining(t, db)
+		for _, path := range paths {
+			assert.Len(t, got[path], heights)
+		}
+	})
+}
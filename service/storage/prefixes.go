@@ -20,21 +20,32 @@ const (
 
 	PrefixHeightForBlock       = 7
 	PrefixHeightForTransaction = 16
+	PrefixHeightForTime        = 18
+	PrefixHeightForCollection  = 19
+	PrefixHeightForSeal        = 20
+	PrefixHeightsForEvent      = 24
 
 	PrefixCommit  = 4
 	PrefixHeader  = 3
 	PrefixEvents  = 5
 	PrefixPayload = 6
 
+	PrefixPathsForHeight = 26
+
 	PrefixTransaction = 8
 	PrefixCollection  = 10
 	PrefixGuarantee   = 17
 
 	PrefixTransactionsForHeight     = 9
 	PrefixTransactionsForCollection = 12
+	PrefixTransactionsForAccount    = 25
 	PrefixCollectionsForHeight      = 11
 	PrefixResults                   = 13
 
 	PrefixSeal           = 14
 	PrefixSealsForHeight = 15
+
+	PrefixVersion   = 21
+	PrefixMigration = 22
+	PrefixFloor     = 23
 )
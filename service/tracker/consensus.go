@@ -16,6 +16,7 @@ package tracker
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/dgraph-io/badger/v2"
 	"github.com/rs/zerolog"
@@ -71,11 +72,17 @@ func (c *Consensus) OnBlockFinalized(blockID flow.Identifier) {
 		return
 	}
 
-	c.last = header.Height
+	atomic.StoreUint64(&c.last, header.Height)
 
 	c.log.Debug().Hex("block", blockID[:]).Uint64("height", header.Height).Msg("block finalization processed")
 }
 
+// Finalized returns the height of the last block that was finalized by the
+// consensus follower.
+func (c *Consensus) Finalized() uint64 {
+	return atomic.LoadUint64(&c.last)
+}
+
 // Root returns the root height from the underlying protocol state.
 func (c *Consensus) Root() (uint64, error) {
 
@@ -93,7 +100,7 @@ func (c *Consensus) Root() (uint64, error) {
 // than the returned payload are purged from the cache.
 func (c *Consensus) Header(height uint64) (*flow.Header, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -115,7 +122,7 @@ func (c *Consensus) Header(height uint64) (*flow.Header, error) {
 // Guarantees returns the collection guarantees for the given height, if available.
 func (c *Consensus) Guarantees(height uint64) ([]*flow.CollectionGuarantee, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -147,7 +154,7 @@ func (c *Consensus) Guarantees(height uint64) ([]*flow.CollectionGuarantee, erro
 // Seals returns the block seals for the given height, if available.
 func (c *Consensus) Seals(height uint64) ([]*flow.Seal, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -183,7 +190,7 @@ func (c *Consensus) Seals(height uint64) ([]*flow.Seal, error) {
 // Commit returns the state commitment for the given height, if available.
 func (c *Consensus) Commit(height uint64) (flow.StateCommitment, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return flow.DummyStateCommitment, dps.ErrUnavailable
 	}
 
@@ -205,7 +212,7 @@ func (c *Consensus) Commit(height uint64) (flow.StateCommitment, error) {
 // given height.
 func (c *Consensus) Collections(height uint64) ([]*flow.LightCollection, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -233,7 +240,7 @@ func (c *Consensus) Collections(height uint64) ([]*flow.LightCollection, error)
 // given height.
 func (c *Consensus) Transactions(height uint64) ([]*flow.TransactionBody, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -260,7 +267,7 @@ func (c *Consensus) Transactions(height uint64) ([]*flow.TransactionBody, error)
 // given height.
 func (c *Consensus) Results(height uint64) ([]*flow.TransactionResult, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -282,7 +289,7 @@ func (c *Consensus) Results(height uint64) ([]*flow.TransactionResult, error) {
 // given height.
 func (c *Consensus) Events(height uint64) ([]flow.Event, error) {
 
-	if height > c.last {
+	if height > atomic.LoadUint64(&c.last) {
 		return nil, dps.ErrUnavailable
 	}
 
@@ -97,6 +97,14 @@ func TestConsensus_OnBlockFinalized(t *testing.T) {
 	})
 }
 
+func TestConsensus_Finalized(t *testing.T) {
+	cons := BaselineConsensus(t, WithLast(mocks.GenericHeight))
+
+	got := cons.Finalized()
+
+	assert.Equal(t, mocks.GenericHeight, got)
+}
+
 func BaselineConsensus(t *testing.T, opts ...func(*Consensus)) *Consensus {
 	t.Helper()
 
@@ -0,0 +1,166 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package verifier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/mapper"
+)
+
+// Verifier verifies the integrity of a DPS index. It can check that all of the
+// indexed data is present for a range of heights, and that the execution state
+// restored from the index matches the state commitments at given heights.
+type Verifier struct {
+	log  zerolog.Logger
+	read dps.Reader
+	load func(height uint64) mapper.Loader
+}
+
+// New creates a new verifier for the index accessed through the given reader.
+// The given load function should return a loader that restores the execution
+// state trie as it was after the given height.
+func New(log zerolog.Logger, read dps.Reader, load func(height uint64) mapper.Loader) *Verifier {
+
+	v := Verifier{
+		log:  log.With().Str("component", "verifier").Logger(),
+		read: read,
+		load: load,
+	}
+
+	return &v
+}
+
+// Completeness checks that the header, the commit, the collections, the
+// transactions and the transaction results are indexed for every height in
+// the given range. It logs each missing entry and returns an error once all
+// heights have been checked, if any of them were incomplete.
+func (v *Verifier) Completeness(start uint64, end uint64) error {
+
+	if start > end {
+		return fmt.Errorf("start height above end height (start: %d, end: %d)", start, end)
+	}
+
+	incomplete := uint64(0)
+	for height := start; height <= end; height++ {
+
+		missing, err := v.missing(height)
+		if err != nil {
+			return fmt.Errorf("could not check height (height: %d): %w", height, err)
+		}
+		if len(missing) > 0 {
+			v.log.Error().Uint64("height", height).Strs("missing", missing).Msg("incomplete height in index")
+			incomplete++
+		}
+
+		if (height-start+1)%10000 == 0 {
+			v.log.Debug().Uint64("height", height).Uint64("incomplete", incomplete).Msg("checking index completeness")
+		}
+	}
+
+	if incomplete > 0 {
+		return fmt.Errorf("index is incomplete (incomplete: %d, total: %d)", incomplete, end-start+1)
+	}
+
+	return nil
+}
+
+// Commit restores the execution state trie as it was after the given height,
+// and checks that its root hash matches the state commitment indexed for the
+// height.
+func (v *Verifier) Commit(height uint64) error {
+
+	commit, err := v.read.Commit(height)
+	if err != nil {
+		return fmt.Errorf("could not get state commitment: %w", err)
+	}
+
+	tree, err := v.load(height).Trie()
+	if err != nil {
+		return fmt.Errorf("could not restore execution state trie: %w", err)
+	}
+
+	hash := flow.StateCommitment(tree.RootHash())
+	if hash != commit {
+		return fmt.Errorf("state commitment mismatch (height: %d, indexed: %x, restored: %x)", height, commit, hash)
+	}
+
+	return nil
+}
+
+// missing returns a description of each entry that is missing from the index
+// for the given height. Any error other than a missing entry aborts the check.
+func (v *Verifier) missing(height uint64) ([]string, error) {
+
+	var missing []string
+	check := func(err error, entry string) error {
+		if errors.Is(err, dps.ErrNotFound) {
+			missing = append(missing, entry)
+			return nil
+		}
+		return err
+	}
+
+	_, err := v.read.Header(height)
+	err = check(err, "header")
+	if err != nil {
+		return nil, fmt.Errorf("could not get header: %w", err)
+	}
+
+	_, err = v.read.Commit(height)
+	err = check(err, "commit")
+	if err != nil {
+		return nil, fmt.Errorf("could not get commit: %w", err)
+	}
+
+	collIDs, err := v.read.CollectionsByHeight(height)
+	err = check(err, "collections")
+	if err != nil {
+		return nil, fmt.Errorf("could not get collections: %w", err)
+	}
+	for _, collID := range collIDs {
+		_, err = v.read.Collection(collID)
+		err = check(err, fmt.Sprintf("collection %x", collID))
+		if err != nil {
+			return nil, fmt.Errorf("could not get collection (collection: %x): %w", collID, err)
+		}
+	}
+
+	txIDs, err := v.read.TransactionsByHeight(height)
+	err = check(err, "transactions")
+	if err != nil {
+		return nil, fmt.Errorf("could not get transactions: %w", err)
+	}
+	for _, txID := range txIDs {
+		_, err = v.read.Transaction(txID)
+		err = check(err, fmt.Sprintf("transaction %x", txID))
+		if err != nil {
+			return nil, fmt.Errorf("could not get transaction (transaction: %x): %w", txID, err)
+		}
+		_, err = v.read.Result(txID)
+		err = check(err, fmt.Sprintf("result %x", txID))
+		if err != nil {
+			return nil, fmt.Errorf("could not get result (transaction: %x): %w", txID, err)
+		}
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package verifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/codec/zbor"
+	"github.com/optakt/flow-dps/ledger/trie"
+	"github.com/optakt/flow-dps/models/dps"
+	"github.com/optakt/flow-dps/service/index"
+	"github.com/optakt/flow-dps/service/loader"
+	"github.com/optakt/flow-dps/service/mapper"
+	"github.com/optakt/flow-dps/service/storage"
+	"github.com/optakt/flow-dps/testing/helpers"
+	"github.com/optakt/flow-dps/testing/mocks"
+	loaderMocks "github.com/optakt/flow-dps/testing/mocks/loader"
+)
+
+func TestNew(t *testing.T) {
+	read := mocks.BaselineReader(t)
+	load := func(uint64) mapper.Loader { return loaderMocks.BaselineMock(t) }
+
+	v := New(mocks.NoopLogger, read, load)
+
+	assert.Equal(t, read, v.read)
+	assert.NotNil(t, v.load)
+}
+
+func TestVerifier_Completeness(t *testing.T) {
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		heights := make(map[uint64]struct{})
+		read := mocks.BaselineReader(t)
+		read.HeaderFunc = func(height uint64) (*flow.Header, error) {
+			heights[height] = struct{}{}
+			return mocks.GenericHeader, nil
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+		}
+
+		err := v.Completeness(mocks.GenericHeight, mocks.GenericHeight+4)
+
+		require.NoError(t, err)
+		assert.Len(t, heights, 5)
+	})
+
+	t.Run("handles missing entries", func(t *testing.T) {
+		t.Parallel()
+
+		missing := mocks.GenericTransactionIDs(5)[2]
+		read := mocks.BaselineReader(t)
+		read.ResultFunc = func(txID flow.Identifier) (*flow.TransactionResult, error) {
+			if txID == missing {
+				return nil, dps.ErrNotFound
+			}
+			return mocks.GenericResult(0), nil
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+		}
+
+		err := v.Completeness(mocks.GenericHeight, mocks.GenericHeight+4)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles missing heights", func(t *testing.T) {
+		t.Parallel()
+
+		read := mocks.BaselineReader(t)
+		read.CommitFunc = func(height uint64) (flow.StateCommitment, error) {
+			if height == mocks.GenericHeight+1 {
+				return flow.DummyStateCommitment, dps.ErrNotFound
+			}
+			return mocks.GenericCommit(0), nil
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+		}
+
+		err := v.Completeness(mocks.GenericHeight, mocks.GenericHeight+4)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles reader failure", func(t *testing.T) {
+		t.Parallel()
+
+		read := mocks.BaselineReader(t)
+		read.TransactionsByHeightFunc = func(uint64) ([]flow.Identifier, error) {
+			return nil, mocks.GenericError
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+		}
+
+		err := v.Completeness(mocks.GenericHeight, mocks.GenericHeight+4)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles invalid range", func(t *testing.T) {
+		t.Parallel()
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: mocks.BaselineReader(t),
+		}
+
+		err := v.Completeness(mocks.GenericHeight+1, mocks.GenericHeight)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifier_Commit(t *testing.T) {
+	empty := flow.StateCommitment(trie.NewEmptyTrie().RootHash())
+
+	t.Run("nominal case", func(t *testing.T) {
+		t.Parallel()
+
+		read := mocks.BaselineReader(t)
+		read.CommitFunc = func(height uint64) (flow.StateCommitment, error) {
+			assert.Equal(t, mocks.GenericHeight, height)
+			return empty, nil
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+			load: func(height uint64) mapper.Loader {
+				assert.Equal(t, mocks.GenericHeight, height)
+				return loaderMocks.BaselineMock(t)
+			},
+		}
+
+		err := v.Commit(mocks.GenericHeight)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("handles mismatching commit", func(t *testing.T) {
+		t.Parallel()
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: mocks.BaselineReader(t),
+			load: func(uint64) mapper.Loader { return loaderMocks.BaselineMock(t) },
+		}
+
+		err := v.Commit(mocks.GenericHeight)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("handles reader failure", func(t *testing.T) {
+		t.Parallel()
+
+		read := mocks.BaselineReader(t)
+		read.CommitFunc = func(uint64) (flow.StateCommitment, error) {
+			return flow.DummyStateCommitment, mocks.GenericError
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+			load: func(uint64) mapper.Loader { return loaderMocks.BaselineMock(t) },
+		}
+
+		err := v.Commit(mocks.GenericHeight)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("handles loader failure", func(t *testing.T) {
+		t.Parallel()
+
+		read := mocks.BaselineReader(t)
+		read.CommitFunc = func(uint64) (flow.StateCommitment, error) {
+			return empty, nil
+		}
+
+		load := loaderMocks.BaselineMock(t)
+		load.TrieFunc = func() (*trie.Trie, error) {
+			return nil, mocks.GenericError
+		}
+
+		v := &Verifier{
+			log:  mocks.NoopLogger,
+			read: read,
+			load: func(uint64) mapper.Loader { return load },
+		}
+
+		err := v.Commit(mocks.GenericHeight)
+
+		assert.ErrorIs(t, err, mocks.GenericError)
+	})
+
+	t.Run("restores trie from index at height", func(t *testing.T) {
+		t.Parallel()
+
+		db := helpers.InMemoryDB(t)
+		defer db.Close()
+
+		paths := mocks.GenericLedgerPaths(4)
+		payloads := mocks.GenericLedgerPayloads(4)
+
+		// The first two heights each update all of the registers, so that
+		// the trie after the first height differs from the final one.
+		lib := storage.New(zbor.NewCodec())
+		tree := trie.NewEmptyTrie()
+		for i, payload := range payloads[:2] {
+			height := mocks.GenericHeight + uint64(i)
+			update := make([]ledger.Payload, 0, len(paths))
+			for _, path := range paths {
+				require.NoError(t, db.Update(lib.SavePayload(height, path, payload)))
+				update = append(update, *payload)
+			}
+
+			var err error
+			tree, err = tree.Mutate(paths, update)
+			require.NoError(t, err)
+			commit := flow.StateCommitment(tree.RootHash())
+			require.NoError(t, db.Update(lib.SaveCommit(height, commit)))
+		}
+
+		v := New(mocks.NoopLogger, index.NewReader(db, lib), func(height uint64) mapper.Loader {
+			return loader.FromIndex(mocks.NoopLogger, lib, db, loader.WithExclude(loader.ExcludeAbove(height)))
+		})
+
+		assert.NoError(t, v.Commit(mocks.GenericHeight))
+		assert.NoError(t, v.Commit(mocks.GenericHeight+1))
+	})
+}
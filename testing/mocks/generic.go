@@ -15,11 +15,13 @@
 package mocks
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -31,6 +33,8 @@ import (
 	chash "github.com/onflow/flow-go/crypto/hash"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/mempool/entity"
 
@@ -82,6 +86,19 @@ var (
 			},
 		},
 	}
+
+	GenericDryRun = dps.DryRun{
+		Failed:          false,
+		Events:          GenericEvents(2),
+		ComputationUsed: 42,
+		Fees:            10000,
+		Writes: []flow.RegisterEntry{
+			{
+				Key:   flow.NewRegisterID(string(GenericAddress(0).Bytes()), "", "key"),
+				Value: GenericBytes,
+			},
+		},
+	}
 )
 
 func GenericBlockIDs(number int) []flow.Identifier {
@@ -198,6 +215,58 @@ func GenericLedgerPayload(index int) *ledger.Payload {
 	return GenericLedgerPayloads(index + 1)[index]
 }
 
+func GenericChanges(number int) []dps.Change {
+	paths := GenericLedgerPaths(number)
+	payloads := GenericLedgerPayloads(2 * number)
+
+	// Changes are always sorted by ascending path.
+	sort.Slice(paths, func(i int, j int) bool {
+		return bytes.Compare(paths[i][:], paths[j][:]) < 0
+	})
+
+	var changes []dps.Change
+	for i := 0; i < number; i++ {
+		change := dps.Change{
+			Path:   paths[i],
+			Before: payloads[i],
+			After:  payloads[number+i],
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func GenericTrieBatchProof(number int) *ledger.TrieBatchProof {
+	// Ensure consistent deterministic results.
+	random := rand.New(rand.NewSource(4))
+
+	paths := GenericLedgerPaths(number)
+	payloads := GenericLedgerPayloads(number)
+
+	batch := ledger.NewTrieBatchProof()
+	for i := 0; i < number; i++ {
+		proof := ledger.NewTrieProof()
+		proof.Path = paths[i]
+		proof.Payload = payloads[i]
+		proof.Inclusion = true
+		proof.Steps = 2
+		for j := 0; j < int(proof.Steps); j++ {
+			var interim hash.Hash
+			binary.BigEndian.PutUint64(interim[0:], random.Uint64())
+			binary.BigEndian.PutUint64(interim[8:], random.Uint64())
+			binary.BigEndian.PutUint64(interim[16:], random.Uint64())
+			binary.BigEndian.PutUint64(interim[24:], random.Uint64())
+			proof.Interims = append(proof.Interims, interim)
+			bitutils.SetBit(proof.Flags, j)
+		}
+
+		batch.AppendProof(proof)
+	}
+
+	return batch
+}
+
 func GenericTransactions(number int) []*flow.TransactionBody {
 	var txs []*flow.TransactionBody
 	for i := 0; i < number; i++ {
@@ -225,6 +294,22 @@ func GenericTransaction(index int) *flow.TransactionBody {
 	return GenericTransactions(index + 1)[index]
 }
 
+func GenericAccountTransactions(number int) []dps.AccountTransaction {
+	txIDs := GenericTransactionIDs(number)
+
+	var txs []dps.AccountTransaction
+	for i := 0; i < number; i++ {
+		tx := dps.AccountTransaction{
+			Height:        GenericHeight + uint64(i),
+			TransactionID: txIDs[i],
+			Roles:         dps.RolePayer | dps.RoleAuthorizer,
+		}
+		txs = append(txs, tx)
+	}
+
+	return txs
+}
+
 func GenericEventTypes(number int) []flow.EventType {
 	// Ensure consistent deterministic results.
 	random := rand.New(rand.NewSource(4))
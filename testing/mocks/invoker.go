@@ -15,44 +15,55 @@
 package mocks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
 )
 
 type Invoker struct {
-	KeyFunc     func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
-	AccountFunc func(height uint64, address flow.Address) (*flow.Account, error)
-	ScriptFunc  func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	KeyFunc         func(ctx context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error)
+	AccountFunc     func(ctx context.Context, height uint64, address flow.Address) (*flow.Account, error)
+	ScriptFunc      func(ctx context.Context, height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error)
+	TransactionFunc func(ctx context.Context, height uint64, tx *flow.TransactionBody) (*dps.DryRun, error)
 }
 
 func BaselineInvoker(t *testing.T) *Invoker {
 	t.Helper()
 
 	i := Invoker{
-		KeyFunc: func(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+		KeyFunc: func(ctx context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
 			return &GenericAccount.Keys[0], nil
 		},
-		AccountFunc: func(height uint64, address flow.Address) (*flow.Account, error) {
+		AccountFunc: func(ctx context.Context, height uint64, address flow.Address) (*flow.Account, error) {
 			return &GenericAccount, nil
 		},
-		ScriptFunc: func(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+		ScriptFunc: func(ctx context.Context, height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
 			return GenericAmount(0), nil
 		},
+		TransactionFunc: func(ctx context.Context, height uint64, tx *flow.TransactionBody) (*dps.DryRun, error) {
+			return &GenericDryRun, nil
+		},
 	}
 
 	return &i
 }
 
-func (i *Invoker) Key(height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
-	return i.KeyFunc(height, address, index)
+func (i *Invoker) Key(ctx context.Context, height uint64, address flow.Address, index int) (*flow.AccountPublicKey, error) {
+	return i.KeyFunc(ctx, height, address, index)
+}
+
+func (i *Invoker) Account(ctx context.Context, height uint64, address flow.Address) (*flow.Account, error) {
+	return i.AccountFunc(ctx, height, address)
 }
 
-func (i *Invoker) Account(height uint64, address flow.Address) (*flow.Account, error) {
-	return i.AccountFunc(height, address)
+func (i *Invoker) Script(ctx context.Context, height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
+	return i.ScriptFunc(ctx, height, script, parameters)
 }
 
-func (i *Invoker) Script(height uint64, script []byte, parameters []cadence.Value) (cadence.Value, error) {
-	return i.ScriptFunc(height, script, parameters)
+func (i *Invoker) Transaction(ctx context.Context, height uint64, tx *flow.TransactionBody) (*dps.DryRun, error) {
+	return i.TransactionFunc(ctx, height, tx)
 }
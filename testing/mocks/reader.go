@@ -16,28 +16,45 @@ package mocks
 
 import (
 	"testing"
+	"time"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/model/flow"
+
+	"github.com/optakt/flow-dps/models/dps"
 )
 
 type Reader struct {
-	FirstFunc                func() (uint64, error)
-	LastFunc                 func() (uint64, error)
-	HeightForBlockFunc       func(blockID flow.Identifier) (uint64, error)
-	CommitFunc               func(height uint64) (flow.StateCommitment, error)
-	HeaderFunc               func(height uint64) (*flow.Header, error)
-	EventsFunc               func(height uint64, types ...flow.EventType) ([]flow.Event, error)
-	ValuesFunc               func(height uint64, paths []ledger.Path) ([]ledger.Value, error)
-	CollectionFunc           func(collID flow.Identifier) (*flow.LightCollection, error)
-	CollectionsByHeightFunc  func(height uint64) ([]flow.Identifier, error)
-	GuaranteeFunc            func(collID flow.Identifier) (*flow.CollectionGuarantee, error)
-	TransactionFunc          func(txID flow.Identifier) (*flow.TransactionBody, error)
-	HeightForTransactionFunc func(txID flow.Identifier) (uint64, error)
-	TransactionsByHeightFunc func(height uint64) ([]flow.Identifier, error)
-	ResultFunc               func(txID flow.Identifier) (*flow.TransactionResult, error)
-	SealFunc                 func(sealID flow.Identifier) (*flow.Seal, error)
-	SealsByHeightFunc        func(height uint64) ([]flow.Identifier, error)
+	FirstFunc                    func() (uint64, error)
+	LastFunc                     func() (uint64, error)
+	FloorFunc                    func() (uint64, error)
+	HeightForBlockFunc           func(blockID flow.Identifier) (uint64, error)
+	CommitFunc                   func(height uint64) (flow.StateCommitment, error)
+	HeaderFunc                   func(height uint64) (*flow.Header, error)
+	HeaderByBlockIDFunc          func(blockID flow.Identifier) (*flow.Header, error)
+	EventsFunc                   func(height uint64, types ...flow.EventType) ([]flow.Event, error)
+	ValuesFunc                   func(height uint64, paths []ledger.Path) ([]ledger.Value, error)
+	ProofsFunc                   func(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error)
+	HeadersFunc                  func(start uint64, end uint64) ([]*flow.Header, error)
+	CommitsFunc                  func(start uint64, end uint64) (map[uint64]flow.StateCommitment, error)
+	EventsRangeFunc              func(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error)
+	EventHeightsFunc             func(typ flow.EventType, start uint64, end uint64) ([]uint64, error)
+	RegisterHistoryFunc          func(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error)
+	StateDiffFunc                func(start uint64, end uint64, process func(change dps.Change) error) error
+	CollectionFunc               func(collID flow.Identifier) (*flow.LightCollection, error)
+	CollectionsByHeightFunc      func(height uint64) ([]flow.Identifier, error)
+	HeightForCollectionFunc      func(collID flow.Identifier) (uint64, error)
+	GuaranteeFunc                func(collID flow.Identifier) (*flow.CollectionGuarantee, error)
+	TransactionFunc              func(txID flow.Identifier) (*flow.TransactionBody, error)
+	HeightForTransactionFunc     func(txID flow.Identifier) (uint64, error)
+	HeightForTimeFunc            func(timestamp time.Time) (uint64, error)
+	TransactionsByHeightFunc     func(height uint64) ([]flow.Identifier, error)
+	TransactionsByCollectionFunc func(collID flow.Identifier) ([]flow.Identifier, error)
+	TransactionsByAccountFunc    func(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error)
+	ResultFunc                   func(txID flow.Identifier) (*flow.TransactionResult, error)
+	SealFunc                     func(sealID flow.Identifier) (*flow.Seal, error)
+	SealsByHeightFunc            func(height uint64) ([]flow.Identifier, error)
+	HeightForSealFunc            func(sealID flow.Identifier) (uint64, error)
 }
 
 func BaselineReader(t *testing.T) *Reader {
@@ -50,6 +67,9 @@ func BaselineReader(t *testing.T) *Reader {
 		LastFunc: func() (uint64, error) {
 			return GenericHeight, nil
 		},
+		FloorFunc: func() (uint64, error) {
+			return GenericHeight, nil
+		},
 		HeightForBlockFunc: func(blockID flow.Identifier) (uint64, error) {
 			return GenericHeight, nil
 		},
@@ -59,18 +79,55 @@ func BaselineReader(t *testing.T) *Reader {
 		HeaderFunc: func(height uint64) (*flow.Header, error) {
 			return GenericHeader, nil
 		},
+		HeaderByBlockIDFunc: func(blockID flow.Identifier) (*flow.Header, error) {
+			return GenericHeader, nil
+		},
 		EventsFunc: func(height uint64, types ...flow.EventType) ([]flow.Event, error) {
 			return GenericEvents(4, GenericEventTypes(2)...), nil
 		},
 		ValuesFunc: func(height uint64, paths []ledger.Path) ([]ledger.Value, error) {
 			return GenericLedgerValues(6), nil
 		},
+		ProofsFunc: func(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+			return GenericTrieBatchProof(6), nil
+		},
+		HeadersFunc: func(start uint64, end uint64) ([]*flow.Header, error) {
+			return []*flow.Header{GenericHeader}, nil
+		},
+		CommitsFunc: func(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+			commits := make(map[uint64]flow.StateCommitment)
+			for height := start; height <= end; height++ {
+				commits[height] = GenericCommit(int(height - start))
+			}
+			return commits, nil
+		},
+		EventsRangeFunc: func(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+			return map[uint64][]flow.Event{GenericHeight: GenericEvents(4)}, nil
+		},
+		EventHeightsFunc: func(typ flow.EventType, start uint64, end uint64) ([]uint64, error) {
+			return []uint64{GenericHeight}, nil
+		},
+		RegisterHistoryFunc: func(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+			return map[uint64]*ledger.Payload{GenericHeight: GenericLedgerPayload(0)}, nil
+		},
+		StateDiffFunc: func(start uint64, end uint64, process func(change dps.Change) error) error {
+			for _, change := range GenericChanges(4) {
+				err := process(change)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
 		CollectionFunc: func(collID flow.Identifier) (*flow.LightCollection, error) {
 			return GenericCollection(0), nil
 		},
 		CollectionsByHeightFunc: func(height uint64) ([]flow.Identifier, error) {
 			return GenericCollectionIDs(5), nil
 		},
+		HeightForCollectionFunc: func(collID flow.Identifier) (uint64, error) {
+			return GenericHeight, nil
+		},
 		GuaranteeFunc: func(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
 			return GenericGuarantee(0), nil
 		},
@@ -80,9 +137,18 @@ func BaselineReader(t *testing.T) *Reader {
 		HeightForTransactionFunc: func(blockID flow.Identifier) (uint64, error) {
 			return GenericHeight, nil
 		},
+		HeightForTimeFunc: func(timestamp time.Time) (uint64, error) {
+			return GenericHeight, nil
+		},
 		TransactionsByHeightFunc: func(height uint64) ([]flow.Identifier, error) {
 			return GenericTransactionIDs(5), nil
 		},
+		TransactionsByCollectionFunc: func(collID flow.Identifier) ([]flow.Identifier, error) {
+			return GenericTransactionIDs(5), nil
+		},
+		TransactionsByAccountFunc: func(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+			return GenericAccountTransactions(4), nil
+		},
 		ResultFunc: func(txID flow.Identifier) (*flow.TransactionResult, error) {
 			return GenericResult(0), nil
 		},
@@ -92,6 +158,9 @@ func BaselineReader(t *testing.T) *Reader {
 		SealsByHeightFunc: func(height uint64) ([]flow.Identifier, error) {
 			return GenericSealIDs(5), nil
 		},
+		HeightForSealFunc: func(sealID flow.Identifier) (uint64, error) {
+			return GenericHeight, nil
+		},
 	}
 
 	return &r
@@ -105,6 +174,10 @@ func (r *Reader) Last() (uint64, error) {
 	return r.LastFunc()
 }
 
+func (r *Reader) Floor() (uint64, error) {
+	return r.FloorFunc()
+}
+
 func (r *Reader) HeightForBlock(blockID flow.Identifier) (uint64, error) {
 	return r.HeightForBlockFunc(blockID)
 }
@@ -117,6 +190,10 @@ func (r *Reader) Header(height uint64) (*flow.Header, error) {
 	return r.HeaderFunc(height)
 }
 
+func (r *Reader) HeaderByBlockID(blockID flow.Identifier) (*flow.Header, error) {
+	return r.HeaderByBlockIDFunc(blockID)
+}
+
 func (r *Reader) Events(height uint64, types ...flow.EventType) ([]flow.Event, error) {
 	return r.EventsFunc(height, types...)
 }
@@ -125,6 +202,34 @@ func (r *Reader) Values(height uint64, paths []ledger.Path) ([]ledger.Value, err
 	return r.ValuesFunc(height, paths)
 }
 
+func (r *Reader) Proofs(height uint64, paths []ledger.Path) (*ledger.TrieBatchProof, error) {
+	return r.ProofsFunc(height, paths)
+}
+
+func (r *Reader) Headers(start uint64, end uint64) ([]*flow.Header, error) {
+	return r.HeadersFunc(start, end)
+}
+
+func (r *Reader) Commits(start uint64, end uint64) (map[uint64]flow.StateCommitment, error) {
+	return r.CommitsFunc(start, end)
+}
+
+func (r *Reader) EventsRange(start uint64, end uint64, types ...flow.EventType) (map[uint64][]flow.Event, error) {
+	return r.EventsRangeFunc(start, end, types...)
+}
+
+func (r *Reader) EventHeights(typ flow.EventType, start uint64, end uint64) ([]uint64, error) {
+	return r.EventHeightsFunc(typ, start, end)
+}
+
+func (r *Reader) RegisterHistory(path ledger.Path, start uint64, end uint64) (map[uint64]*ledger.Payload, error) {
+	return r.RegisterHistoryFunc(path, start, end)
+}
+
+func (r *Reader) StateDiff(start uint64, end uint64, process func(change dps.Change) error) error {
+	return r.StateDiffFunc(start, end, process)
+}
+
 func (r *Reader) Collection(collID flow.Identifier) (*flow.LightCollection, error) {
 	return r.CollectionFunc(collID)
 }
@@ -133,6 +238,10 @@ func (r *Reader) CollectionsByHeight(height uint64) ([]flow.Identifier, error) {
 	return r.CollectionsByHeightFunc(height)
 }
 
+func (r *Reader) HeightForCollection(collID flow.Identifier) (uint64, error) {
+	return r.HeightForCollectionFunc(collID)
+}
+
 func (r *Reader) Guarantee(collID flow.Identifier) (*flow.CollectionGuarantee, error) {
 	return r.GuaranteeFunc(collID)
 }
@@ -145,10 +254,22 @@ func (r *Reader) HeightForTransaction(txID flow.Identifier) (uint64, error) {
 	return r.HeightForTransactionFunc(txID)
 }
 
+func (r *Reader) HeightForTime(timestamp time.Time) (uint64, error) {
+	return r.HeightForTimeFunc(timestamp)
+}
+
 func (r *Reader) TransactionsByHeight(height uint64) ([]flow.Identifier, error) {
 	return r.TransactionsByHeightFunc(height)
 }
 
+func (r *Reader) TransactionsByCollection(collID flow.Identifier) ([]flow.Identifier, error) {
+	return r.TransactionsByCollectionFunc(collID)
+}
+
+func (r *Reader) TransactionsByAccount(address flow.Address, start uint64, end uint64, after flow.Identifier, limit uint) ([]dps.AccountTransaction, error) {
+	return r.TransactionsByAccountFunc(address, start, end, after, limit)
+}
+
 func (r *Reader) Result(txID flow.Identifier) (*flow.TransactionResult, error) {
 	return r.ResultFunc(txID)
 }
@@ -160,3 +281,7 @@ func (r *Reader) Seal(sealID flow.Identifier) (*flow.Seal, error) {
 func (r *Reader) SealsByHeight(height uint64) ([]flow.Identifier, error) {
 	return r.SealsByHeightFunc(height)
 }
+
+func (r *Reader) HeightForSeal(sealID flow.Identifier) (uint64, error) {
+	return r.HeightForSealFunc(sealID)
+}
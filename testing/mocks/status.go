@@ -0,0 +1,39 @@
+// Copyright 2021 Optakt Labs OÜ
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy of
+// the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations under
+// the License.
+
+package mocks
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Status struct {
+	SetServingStatusFunc func(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus)
+}
+
+func BaselineStatus(t *testing.T) *Status {
+	t.Helper()
+
+	s := Status{
+		SetServingStatusFunc: func(string, grpc_health_v1.HealthCheckResponse_ServingStatus) {},
+	}
+
+	return &s
+}
+
+func (s *Status) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.SetServingStatusFunc(service, status)
+}